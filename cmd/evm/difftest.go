@@ -0,0 +1,130 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/difftest"
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	DiffSeedFlag = &cli.Int64Flag{
+		Name:  "seed",
+		Usage: "Seed of the random test cases (default: current time)",
+	}
+	DiffIterationsFlag = &cli.IntFlag{
+		Name:  "iterations",
+		Usage: "Number of test cases to run, 0 for unlimited",
+		Value: 1000,
+	}
+	DiffExternalFlag = &cli.StringFlag{
+		Name:  "t8n",
+		Usage: "External t8n command to check too, e.g. \"evm t8n\"",
+	}
+)
+
+var diffTestCommand = &cli.Command{
+	Action: diffTestCmd,
+	Name:   "difftest",
+	Usage:  "runs random state transitions through t8n and the block processor, comparing the results",
+	Description: `
+The difftest command generates random pre-states and transaction sets, applies
+them through the state transition tool and the block processor, and optionally an
+external t8n command, and stops at the first divergence in the receipts, logs or
+post-state. The diverging case is minimized and written to the output directory
+in the t8n input format, as alloc.json, env.json and txs.rlp.`,
+	Flags: []cli.Flag{
+		DiffSeedFlag,
+		DiffIterationsFlag,
+		DiffExternalFlag,
+		t8ntool.ForknameFlag,
+		t8ntool.OutputBasedir,
+	},
+}
+
+func diffTestCmd(ctx *cli.Context) error {
+	fork := ctx.String(t8ntool.ForknameFlag.Name)
+	config, _, err := tests.GetChainConfig(fork)
+	if err != nil {
+		return fmt.Errorf("failed constructing chain configuration: %v", err)
+	}
+	seed := time.Now().UnixNano()
+	if ctx.IsSet(DiffSeedFlag.Name) {
+		seed = ctx.Int64(DiffSeedFlag.Name)
+	}
+	var external []string
+	if ctx.IsSet(DiffExternalFlag.Name) {
+		external = strings.Fields(ctx.String(DiffExternalFlag.Name))
+	}
+	// Configure the go-ethereum logger. Progress is reported directly, as the
+	// logs of every processed test case are rarely of interest.
+	glogger := log.NewGlogHandler(log.NewTerminalHandler(os.Stderr, false))
+	glogger.Verbosity(log.FromLegacyLevel(ctx.Int(VerbosityFlag.Name)))
+	log.SetDefault(log.NewLogger(glogger))
+
+	fmt.Fprintf(os.Stderr, "Running differential test cases, fork %s, seed %d\n", fork, seed)
+
+	var (
+		rng   = rand.New(rand.NewSource(seed))
+		input = make([]byte, 4096)
+		limit = ctx.Int(DiffIterationsFlag.Name)
+	)
+	for i := 0; limit == 0 || i < limit; i++ {
+		rng.Read(input)
+
+		c := difftest.Generate(fork, config, input)
+		d, err := difftest.Run(c, external)
+		if err != nil {
+			return fmt.Errorf("case %d: %v", i, err)
+		}
+		if d == nil {
+			if (i+1)%100 == 0 {
+				fmt.Fprintf(os.Stderr, "%d test cases passed\n", i+1)
+			}
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Case %d diverges, minimizing: %v\n", i, d)
+		c = difftest.Minimize(c, difftest.Reproduce(d, external))
+		if found, err := difftest.Run(c, external); err == nil && found != nil {
+			d = found // report the divergence of the reproducer
+		}
+
+		dir := ctx.String(t8ntool.OutputBasedir.Name)
+		if dir == "" {
+			dir = "."
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := c.WriteInputs(dir); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote reproducer with %d transactions and %d accounts to %s, replay it with\n", len(c.Txs), len(c.Pre.Pre), dir)
+		fmt.Fprintf(os.Stderr, "  evm t8n --input.alloc alloc.json --input.env env.json --input.txs txs.rlp --state.fork %s --state.reward %d\n", fork, c.Reward())
+		return d
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+)
+
+var eofParseCommand = &cli.Command{
+	Action:    eofParseCmd,
+	Name:      "eofparse",
+	Usage:     "parses and validates EOF containers",
+	ArgsUsage: "<file>",
+	Description: `
+The eofparse command validates EOF v1 containers given as hex, either with
+--input, from a file with one container per line, or from stdin. For each
+container it prints "OK" followed by the code section sizes, or the error.`,
+}
+
+func eofParseCmd(ctx *cli.Context) error {
+	jt, err := vm.LookupEOFInstructionSet(params.Rules{IsPrague: true})
+	if err != nil {
+		return err
+	}
+	if ctx.IsSet(InputFlag.Name) {
+		fmt.Println(parseEOF(ctx.String(InputFlag.Name), &jt))
+		return nil
+	}
+	in := os.Stdin
+	if fn := ctx.Args().First(); len(fn) > 0 {
+		if in, err = os.Open(fn); err != nil {
+			return err
+		}
+		defer in.Close()
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fmt.Println(parseEOF(line, &jt))
+	}
+	return scanner.Err()
+}
+
+// parseEOF validates a hex encoded EOF container, returning the result line.
+func parseEOF(input string, jt *vm.JumpTable) string {
+	var c vm.Container
+	if err := c.UnmarshalBinary(common.FromHex(input)); err != nil {
+		return fmt.Sprintf("err: %v", err)
+	}
+	if err := c.ValidateCode(jt); err != nil {
+		return fmt.Sprintf("err: %v", err)
+	}
+	sizes := make([]string, len(c.Code))
+	for i, code := range c.Code {
+		sizes[i] = fmt.Sprint(len(code))
+	}
+	return "OK " + strings.Join(sizes, ",")
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package difftest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+// Tests that the state transition tool and the block processor agree on random
+// cases under a range of rulesets.
+func TestDifferential(t *testing.T) {
+	t.Parallel()
+
+	for _, fork := range []string{"Frontier", "Byzantium", "Istanbul", "London", "Merge", "Shanghai"} {
+		config, _, err := tests.GetChainConfig(fork)
+		if err != nil {
+			t.Fatalf("%s: failed to get config: %v", fork, err)
+		}
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 50; i++ {
+			input := make([]byte, 2048)
+			rng.Read(input)
+
+			c := Generate(fork, config, input)
+			d, err := Run(c, nil)
+			if err != nil {
+				t.Fatalf("%s case %d: failed to run: %v", fork, i, err)
+			}
+			if d != nil {
+				t.Errorf("%s case %d: %v", fork, i, d)
+			}
+		}
+	}
+}
+
+// Tests that differences in the post-state are reported at the first diverging
+// account and slot.
+func TestCompareAllocs(t *testing.T) {
+	config, _, _ := tests.GetChainConfig("Shanghai")
+	c := Generate("Shanghai", config, []byte("some input to generate a test case from, long enough for contracts"))
+
+	out, err := runT8n(c)
+	if err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	if d := compare("t8n", out, out); d != nil {
+		t.Fatalf("identical outcomes diverge: %v", d)
+	}
+	tampered, _ := runT8n(c)
+	addr := sortedAddresses(tampered.alloc)[0]
+	account := tampered.alloc[addr]
+	account.Storage = map[common.Hash]common.Hash{{0x01}: {0x02}}
+	tampered.alloc[addr] = account
+
+	d := compare("t8n", tampered, out)
+	if d == nil {
+		t.Fatal("tampered post-state not detected")
+	}
+	if want := fmt.Sprintf("account %x slot %x", addr, common.Hash{0x01}); d.Field != want {
+		t.Errorf("divergence field mismatch: have %q, want %q", d.Field, want)
+	}
+}
+
+// Tests that minimization drops everything not needed to reproduce a divergence.
+func TestMinimize(t *testing.T) {
+	config, _, _ := tests.GetChainConfig("Shanghai")
+
+	// Find a case with multiple transactions and withdrawals
+	var c *Case
+	for seed := int64(0); c == nil; seed++ {
+		input := make([]byte, 2048)
+		rand.New(rand.NewSource(seed)).Read(input)
+		if gen := Generate("Shanghai", config, input); len(gen.Txs) > 3 && len(gen.Pre.Env.Withdrawals) > 0 {
+			c = gen
+		}
+	}
+	// Pretend the case diverges whenever the last transaction is present
+	target := c.Txs[len(c.Txs)-1].Hash()
+	diverges := func(c *Case) bool {
+		for _, tx := range c.Txs {
+			if tx.Hash() == target {
+				return true
+			}
+		}
+		return false
+	}
+	reduced := Minimize(c, diverges)
+	if len(reduced.Txs) != 1 || reduced.Txs[0].Hash() != target {
+		t.Errorf("transactions not minimized: have %d", len(reduced.Txs))
+	}
+	if len(reduced.Pre.Env.Withdrawals) != 0 {
+		t.Errorf("withdrawals not minimized: have %d", len(reduced.Pre.Env.Withdrawals))
+	}
+	if len(reduced.Pre.Pre) != 0 {
+		t.Errorf("accounts not minimized: have %d", len(reduced.Pre.Pre))
+	}
+	// The original case must not be modified
+	if !diverges(c) || len(c.Pre.Env.Withdrawals) == 0 || len(c.Pre.Pre) == 0 {
+		t.Errorf("original case modified")
+	}
+}
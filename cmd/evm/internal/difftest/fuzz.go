@@ -0,0 +1,47 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package difftest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+// Fuzz is the entry point for go-fuzz, checking the cases generated from the
+// input under the Shanghai rules. Divergences are minimized and reported as a
+// panic.
+func Fuzz(input []byte) int {
+	config, _, err := tests.GetChainConfig("Shanghai")
+	if err != nil {
+		panic(err)
+	}
+	c := Generate("Shanghai", config, input)
+	d, err := Run(c, nil)
+	if err != nil {
+		panic(err)
+	}
+	if d != nil {
+		repro, _ := json.Marshal(Minimize(c, Reproduce(d, nil)))
+		panic(fmt.Sprintf("%v\nreproducer: %s", d, repro))
+	}
+	if len(c.Txs) == 0 {
+		return 0
+	}
+	return 1
+}
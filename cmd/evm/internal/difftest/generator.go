@@ -0,0 +1,487 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// Package difftest implements a differential fuzzer, generating random pre-states
+// and transaction sets, and cross-checking the results of the state transition
+// tool against the block processor of the core package.
+package difftest
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// maxSenders is the number of accounts transactions may be sent from.
+const maxSenders = 4
+
+// senderKeys are the deterministic private keys of the transaction senders.
+var senderKeys = func() []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, maxSenders)
+	for i := range keys {
+		keys[i], _ = crypto.ToECDSA(crypto.Keccak256([]byte{byte(i)}))
+	}
+	return keys
+}()
+
+// Case is a single differential test case: a pre-state, a block environment and
+// the transactions to apply, with the ruleset they are applied under.
+type Case struct {
+	Fork   string              // Name of the ruleset, as understood by t8n
+	Config *params.ChainConfig // Chain configuration of the ruleset
+	Pre    t8ntool.Prestate    // Pre-state and block environment
+	Txs    types.Transactions  // Transactions to apply, possibly invalid ones
+}
+
+// number returns the block number of the case as a big int.
+func (c *Case) number() *big.Int {
+	return new(big.Int).SetUint64(c.Pre.Env.Number)
+}
+
+// merged returns whether the case is executed under proof-of-stake rules.
+func (c *Case) merged() bool {
+	return c.Config.TerminalTotalDifficulty != nil && c.Config.TerminalTotalDifficulty.BitLen() == 0
+}
+
+// Reward returns the block reward to pay to the coinbase, or -1 if none, as
+// expected by t8n.
+func (c *Case) Reward() int64 {
+	switch {
+	case c.merged():
+		return -1
+	case c.Config.IsConstantinople(c.number()):
+		return 2 * params.Ether
+	case c.Config.IsByzantium(c.number()):
+		return 3 * params.Ether
+	default:
+		return 5 * params.Ether
+	}
+}
+
+// genesis returns the genesis block specification with the pre-state, the parent
+// of the block the transactions are applied in.
+func (c *Case) genesis() *core.Genesis {
+	genesis := &core.Genesis{
+		Config:     c.Config,
+		Alloc:      c.Pre.Pre,
+		GasLimit:   c.Pre.Env.GasLimit,
+		Difficulty: c.Pre.Env.Difficulty,
+	}
+	if c.merged() {
+		genesis.Difficulty = new(big.Int)
+	}
+	return genesis
+}
+
+// setBlockHashes sets the block hashes in the environment to the hash of the
+// genesis block. It needs to be called whenever the pre-state changes.
+func (c *Case) setBlockHashes() {
+	c.Pre.Env.BlockHashes = map[math.HexOrDecimal64]common.Hash{
+		0: c.genesis().ToBlock().Hash(),
+	}
+}
+
+// Copy returns a deep copy of the case, which can be modified without affecting
+// the original.
+func (c *Case) Copy() *Case {
+	cpy := &Case{
+		Fork:   c.Fork,
+		Config: c.Config,
+		Pre: t8ntool.Prestate{
+			Env: c.Pre.Env,
+			Pre: make(core.GenesisAlloc, len(c.Pre.Pre)),
+		},
+		Txs: append(types.Transactions{}, c.Txs...),
+	}
+	if c.Pre.Env.Withdrawals != nil {
+		cpy.Pre.Env.Withdrawals = append([]*types.Withdrawal{}, c.Pre.Env.Withdrawals...)
+	}
+	for addr, account := range c.Pre.Pre {
+		if account.Storage != nil {
+			storage := make(map[common.Hash]common.Hash, len(account.Storage))
+			for key, value := range account.Storage {
+				storage[key] = value
+			}
+			account.Storage = storage
+		}
+		cpy.Pre.Pre[addr] = account
+	}
+	return cpy
+}
+
+// source is a deterministic stream of random values drawn from the fuzzer input.
+// Once the input is exhausted, zeroes are returned.
+type source struct {
+	data []byte
+}
+
+func (s *source) byte() byte {
+	if len(s.data) == 0 {
+		return 0
+	}
+	b := s.data[0]
+	s.data = s.data[1:]
+	return b
+}
+
+func (s *source) bytes(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = s.byte()
+	}
+	return b
+}
+
+func (s *source) uint16() uint16 {
+	return binary.BigEndian.Uint16(s.bytes(2))
+}
+
+func (s *source) intn(n int) int {
+	return int(s.uint16()) % n
+}
+
+func (s *source) bool() bool {
+	return s.byte()&1 == 1
+}
+
+// Generate derives a test case under the given ruleset from the input bytes.
+// The same input always results in the same case.
+func Generate(fork string, config *params.ChainConfig, input []byte) *Case {
+	var (
+		s = &source{data: input}
+		c = &Case{
+			Fork:   fork,
+			Config: config,
+			Pre:    t8ntool.Prestate{Pre: make(core.GenesisAlloc)},
+		}
+		senders   = make([]common.Address, 1+s.intn(maxSenders))
+		contracts = make([]common.Address, s.intn(6))
+	)
+	// Create the senders and the contracts they interact with. The contracts can
+	// call each other, the senders and some precompiles.
+	for i := range senders {
+		senders[i] = crypto.PubkeyToAddress(senderKeys[i].PublicKey)
+	}
+	for i := range contracts {
+		contracts[i] = common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+	}
+	targets := append(append([]common.Address{}, senders...), contracts...)
+	for i := 1; i <= 9; i++ {
+		targets = append(targets, common.BigToAddress(big.NewInt(int64(i))))
+	}
+	for _, addr := range senders {
+		balance := new(big.Int).Mul(big.NewInt(int64(s.uint16())+1), big.NewInt(params.GWei))
+		if s.intn(8) != 0 {
+			balance.Mul(balance, big.NewInt(params.GWei)) // most senders can afford their transactions
+		}
+		c.Pre.Pre[addr] = core.GenesisAccount{Balance: balance}
+	}
+	for _, addr := range contracts {
+		account := core.GenesisAccount{
+			Balance: big.NewInt(int64(s.uint16())),
+			Code:    s.program(targets),
+			Nonce:   uint64(s.intn(2)),
+			Storage: make(map[common.Hash]common.Hash),
+		}
+		for i := s.intn(4); i > 0; i-- {
+			account.Storage[common.Hash{31: byte(s.intn(8))}] = common.BytesToHash(s.bytes(1 + s.intn(32)))
+		}
+		c.Pre.Pre[addr] = account
+	}
+	// Set up the block environment
+	env := &c.Pre.Env
+	env.Number = 1
+	env.Timestamp = 1000
+	env.GasLimit = params.GenesisGasLimit
+	if s.intn(8) == 0 {
+		env.GasLimit = 100_000 // too low for all transactions to fit
+	}
+	switch s.intn(4) {
+	case 0:
+		env.Coinbase = senders[0]
+	case 1:
+		env.Coinbase = targets[s.intn(len(targets))]
+	default:
+		env.Coinbase = common.BytesToAddress(s.bytes(common.AddressLength))
+	}
+	if c.Config.IsLondon(c.number()) {
+		env.BaseFee = big.NewInt(int64(s.uint16()) + 7)
+	}
+	if c.merged() {
+		env.Random = new(big.Int).SetBytes(s.bytes(common.HashLength))
+	} else {
+		env.Difficulty = big.NewInt(int64(s.uint16()) + params.MinimumDifficulty.Int64())
+	}
+	if c.Config.IsShanghai(c.number(), env.Timestamp) {
+		// The t8n input format can't express an empty list of withdrawals, as it
+		// omits them, so there is always at least one
+		env.Withdrawals = []*types.Withdrawal{}
+		for i := 1 + s.intn(2); i > 0; i-- {
+			env.Withdrawals = append(env.Withdrawals, &types.Withdrawal{
+				Index:     uint64(len(env.Withdrawals)),
+				Validator: uint64(s.uint16()),
+				Address:   targets[s.intn(len(targets))],
+				Amount:    uint64(s.uint16()),
+			})
+		}
+	}
+	// Generate the transactions, occasionally invalid ones
+	var (
+		signer = types.MakeSigner(c.Config, c.number(), env.Timestamp)
+		nonces = make([]uint64, len(senders))
+	)
+	for i := s.intn(16); i > 0; i-- {
+		sender := s.intn(len(senders))
+
+		nonce := nonces[sender]
+		if s.intn(32) == 0 {
+			nonce++ // nonce gap, rejected along with the later ones of the sender
+		} else {
+			nonces[sender]++
+		}
+		var (
+			to    *common.Address
+			data  []byte
+			value = new(big.Int)
+			gas   = params.TxGas + uint64(s.uint16())*8
+		)
+		switch n := s.intn(8); {
+		case n == 0:
+			data = initcode(s.program(targets))
+			gas += params.TxGasContractCreation
+		case n < 5 && len(contracts) > 0:
+			to = &contracts[s.intn(len(contracts))]
+			data = s.bytes(s.intn(33))
+		case n == 5:
+			to = &targets[s.intn(len(targets))]
+		default:
+			addr := common.BytesToAddress(s.bytes(common.AddressLength))
+			to = &addr
+		}
+		if s.intn(16) == 0 {
+			gas = params.TxGas / 2 // below intrinsic gas, rejected
+		}
+		if s.intn(4) == 0 {
+			value.SetUint64(uint64(s.uint16()) * params.GWei)
+		}
+		var (
+			tip    = big.NewInt(int64(s.byte()))
+			feeCap = new(big.Int).Add(tip, big.NewInt(int64(s.byte())))
+		)
+		if env.BaseFee != nil {
+			feeCap.Add(feeCap, env.BaseFee)
+		}
+		var inner types.TxData
+		switch n := s.intn(3); {
+		case n == 2 && c.Config.IsLondon(c.number()):
+			inner = &types.DynamicFeeTx{
+				ChainID:    c.Config.ChainID,
+				Nonce:      nonce,
+				GasTipCap:  tip,
+				GasFeeCap:  feeCap,
+				Gas:        gas,
+				To:         to,
+				Value:      value,
+				Data:       data,
+				AccessList: s.accessList(targets),
+			}
+		case n >= 1 && c.Config.IsBerlin(c.number()):
+			inner = &types.AccessListTx{
+				ChainID:    c.Config.ChainID,
+				Nonce:      nonce,
+				GasPrice:   feeCap,
+				Gas:        gas,
+				To:         to,
+				Value:      value,
+				Data:       data,
+				AccessList: s.accessList(targets),
+			}
+		default:
+			inner = &types.LegacyTx{
+				Nonce:    nonce,
+				GasPrice: feeCap,
+				Gas:      gas,
+				To:       to,
+				Value:    value,
+				Data:     data,
+			}
+		}
+		tx, err := types.SignNewTx(senderKeys[sender], signer, inner)
+		if err != nil {
+			panic(err)
+		}
+		c.Txs = append(c.Txs, tx)
+	}
+	c.setBlockHashes()
+	return c
+}
+
+// accessList generates a random access list over the given accounts.
+func (s *source) accessList(targets []common.Address) types.AccessList {
+	var list types.AccessList
+	for i := s.intn(3); i > 0; i-- {
+		tuple := types.AccessTuple{Address: targets[s.intn(len(targets))], StorageKeys: []common.Hash{}}
+		for j := s.intn(3); j > 0; j-- {
+			tuple.StorageKeys = append(tuple.StorageKeys, common.Hash{31: byte(s.intn(8))})
+		}
+		list = append(list, tuple)
+	}
+	return list
+}
+
+// program generates random contract code, built from snippets exercising the
+// storage, logs, calls, contract creation and destruction, and the environment.
+// Unsupported opcodes of older rulesets just abort the execution.
+func (s *source) program(targets []common.Address) []byte {
+	var code []byte
+
+	push := func(v []byte) {
+		code = append(code, byte(vm.PUSH1)+byte(len(v)-1))
+		code = append(code, v...)
+	}
+	pushInt := func(v int) {
+		push([]byte{byte(v)})
+	}
+	op := func(ops ...vm.OpCode) {
+		for _, op := range ops {
+			code = append(code, byte(op))
+		}
+	}
+	target := func() {
+		push(targets[s.intn(len(targets))].Bytes())
+	}
+	store := func() {
+		pushInt(8 + s.intn(8))
+		op(vm.SSTORE)
+	}
+	for i := s.intn(12); i > 0; i-- {
+		switch s.intn(12) {
+		case 0: // write a slot
+			push(s.bytes(1 + s.intn(32)))
+			pushInt(s.intn(8))
+			op(vm.SSTORE)
+
+		case 1: // copy a slot
+			pushInt(s.intn(8))
+			op(vm.SLOAD)
+			store()
+
+		case 2: // write memory
+			push(s.bytes(1 + s.intn(32)))
+			pushInt(s.intn(64))
+			op(vm.MSTORE)
+
+		case 3: // emit a log
+			topics := s.intn(5)
+			for j := 0; j < topics; j++ {
+				push(s.bytes(1 + s.intn(32)))
+			}
+			pushInt(s.intn(64))
+			pushInt(s.intn(64))
+			op(vm.LOG0 + vm.OpCode(topics))
+
+		case 4: // call another account, storing the result
+			pushInt(s.intn(64))
+			pushInt(s.intn(64))
+			pushInt(s.intn(64))
+			pushInt(s.intn(64))
+			call := []vm.OpCode{vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL}[s.intn(4)]
+			if call == vm.CALL || call == vm.CALLCODE {
+				pushInt(s.intn(3))
+			}
+			target()
+			push(s.bytes(3))
+			op(call)
+			store()
+
+		case 5: // create a contract, storing its address
+			init := []byte{byte(vm.PUSH1), byte(s.intn(8)), byte(vm.PUSH1), 0, byte(vm.RETURN)}
+			push(init)
+			pushInt(0)
+			op(vm.MSTORE)
+			create := vm.CREATE
+			if s.bool() {
+				create = vm.CREATE2
+				push(s.bytes(1 + s.intn(32)))
+			}
+			pushInt(len(init))
+			pushInt(32 - len(init))
+			pushInt(s.intn(2))
+			op(create)
+			store()
+
+		case 6: // read the environment
+			switch s.intn(4) {
+			case 0:
+				target()
+				op([]vm.OpCode{vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODEHASH}[s.intn(3)])
+			case 1:
+				pushInt(0)
+				op(vm.BLOCKHASH)
+			default:
+				op([]vm.OpCode{
+					vm.ADDRESS, vm.ORIGIN, vm.CALLER, vm.CALLVALUE, vm.GASPRICE, vm.COINBASE,
+					vm.TIMESTAMP, vm.NUMBER, vm.DIFFICULTY, vm.GASLIMIT, vm.CHAINID,
+					vm.SELFBALANCE, vm.BASEFEE, vm.CODESIZE, vm.CALLDATASIZE, vm.RETURNDATASIZE,
+				}[s.intn(16)])
+			}
+			store()
+
+		case 7: // hash memory
+			pushInt(s.intn(64))
+			pushInt(s.intn(64))
+			op(vm.KECCAK256)
+			store()
+
+		case 8: // load the call data
+			pushInt(s.intn(32))
+			op(vm.CALLDATALOAD)
+			store()
+
+		case 9: // self destruct
+			target()
+			op(vm.SELFDESTRUCT)
+
+		case 10: // return or revert
+			pushInt(s.intn(64))
+			pushInt(s.intn(64))
+			op([]vm.OpCode{vm.RETURN, vm.REVERT}[s.intn(2)])
+
+		default: // arbitrary instruction
+			code = append(code, s.byte())
+		}
+	}
+	return append(code, byte(vm.STOP))
+}
+
+// initcode wraps the given runtime code into init code deploying it.
+func initcode(code []byte) []byte {
+	init := []byte{
+		byte(vm.PUSH2), byte(len(code) >> 8), byte(len(code)), byte(vm.DUP1),
+		byte(vm.PUSH1), 12, byte(vm.PUSH1), 0, byte(vm.CODECOPY), // the runtime code follows these 12 bytes
+		byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	return append(init, code...)
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package difftest
+
+// Minimize reduces a diverging test case to a smaller one that still diverges,
+// by greedily dropping transactions, withdrawals, accounts and storage slots as
+// long as the given check keeps reporting a divergence.
+//
+// Transactions are not modified, as that would require re-signing them, so the
+// senders of the remaining transactions are usually kept.
+func Minimize(c *Case, diverges func(*Case) bool) *Case {
+	c = c.Copy()
+
+	// try applies a reduction on a copy of the case, keeping it if the copy still
+	// diverges
+	try := func(reduce func(*Case)) bool {
+		cpy := c.Copy()
+		reduce(cpy)
+		cpy.setBlockHashes()
+		if !diverges(cpy) {
+			return false
+		}
+		c = cpy
+		return true
+	}
+	for i := len(c.Txs) - 1; i >= 0; i-- {
+		try(func(c *Case) {
+			c.Txs = append(c.Txs[:i:i], c.Txs[i+1:]...)
+		})
+	}
+	for i := len(c.Pre.Env.Withdrawals) - 1; i >= 0; i-- {
+		try(func(c *Case) {
+			c.Pre.Env.Withdrawals = append(c.Pre.Env.Withdrawals[:i:i], c.Pre.Env.Withdrawals[i+1:]...)
+		})
+	}
+	for _, addr := range sortedAddresses(c.Pre.Pre) {
+		if try(func(c *Case) { delete(c.Pre.Pre, addr) }) {
+			continue
+		}
+		// The account is needed, try to strip its content instead
+		try(func(c *Case) {
+			account := c.Pre.Pre[addr]
+			account.Code = nil
+			c.Pre.Pre[addr] = account
+		})
+		for _, key := range sortedKeys(c.Pre.Pre[addr].Storage) {
+			try(func(c *Case) { delete(c.Pre.Pre[addr].Storage, key) })
+		}
+	}
+	return c
+}
+
+// Reproduce returns a check for minimization, reporting whether a case diverges
+// in the same implementation as the given divergence. The diverging item itself
+// is not required to match, as indices shift while the case is reduced.
+func Reproduce(d *Divergence, external []string) func(*Case) bool {
+	return func(c *Case) bool {
+		found, err := Run(c, external)
+		return err == nil && found != nil && found.Impl == d.Impl
+	}
+}
@@ -0,0 +1,465 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package difftest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Divergence describes the first difference found between the results of the
+// block processor and another implementation of the state transition.
+type Divergence struct {
+	Impl  string // Name of the implementation diverging from the block processor
+	Field string // Description of the diverging item
+	Have  string // Value produced by the implementation
+	Want  string // Value produced by the block processor
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("%s mismatch: %s has %s, processor has %s", d.Field, d.Impl, d.Have, d.Want)
+}
+
+// outcome is the result of applying a test case by one implementation.
+type outcome struct {
+	rejected    []int          // Indices of the transactions not included
+	receipts    types.Receipts // Receipts of the included transactions, if available
+	gasUsed     uint64
+	logsHash    common.Hash
+	receiptRoot common.Hash
+	stateRoot   common.Hash
+	alloc       core.GenesisAlloc // Post-state accounts
+	invalid     error             // Reason the block was rejected, if it was (processor only)
+}
+
+// Run applies the test case through the state transition tool and the block
+// processor, and optionally an external t8n command, returning the first
+// divergence found, if any. The external command is given as the program and
+// arguments to invoke, to which the t8n input and output flags are appended.
+//
+// The block processor only accepts valid blocks, so it is fed the transactions
+// included by the state transition tool. An error is returned if the test case
+// could not be applied at all.
+func Run(c *Case, external []string) (*Divergence, error) {
+	t8n, err := runT8n(c)
+	if err != nil {
+		return nil, err
+	}
+	var txs types.Transactions
+	for i, tx := range c.Txs {
+		if !contains(t8n.rejected, i) {
+			txs = append(txs, tx)
+		}
+	}
+	processor, err := runProcessor(c, txs)
+	if err != nil {
+		return nil, err
+	}
+	if processor.invalid != nil {
+		// Transactions accepted by t8n but not the processor are a divergence
+		// too, just not one with results to compare
+		return &Divergence{Impl: "t8n", Field: "block validity", Have: "valid", Want: strconv.Quote(processor.invalid.Error())}, nil
+	}
+	if d := compare("t8n", t8n, processor); d != nil {
+		return d, nil
+	}
+	if len(external) == 0 {
+		return nil, nil
+	}
+	ext, err := runExternal(c, external)
+	if err != nil {
+		return nil, err
+	}
+	if d := compare("external t8n", ext, processor); d != nil {
+		return d, nil
+	}
+	// The processor was only fed the transactions t8n included, check them too
+	if fmt.Sprint(ext.rejected) != fmt.Sprint(t8n.rejected) {
+		return &Divergence{Impl: "external t8n", Field: "rejected transactions", Have: fmt.Sprint(ext.rejected), Want: fmt.Sprint(t8n.rejected)}, nil
+	}
+	return nil, nil
+}
+
+// runT8n applies the test case through the state transition tool.
+func runT8n(c *Case) (*outcome, error) {
+	// Apply may update the environment, operate on a copy
+	pre := c.Copy().Pre
+
+	noTracer := func(int, common.Hash) (vm.EVMLogger, error) { return nil, nil }
+	statedb, result, err := pre.Apply(vm.Config{}, c.Config, c.Txs, c.Reward(), noTracer)
+	if err != nil {
+		return nil, err
+	}
+	out := &outcome{
+		receipts:    result.Receipts,
+		gasUsed:     uint64(result.GasUsed),
+		logsHash:    result.LogsHash,
+		receiptRoot: result.ReceiptRoot,
+		stateRoot:   result.StateRoot,
+	}
+	for _, rejected := range result.Rejected {
+		out.rejected = append(out.rejected, rejected.Index)
+	}
+	alloc := make(t8ntool.Alloc)
+	statedb.DumpToCollector(alloc, nil)
+	out.alloc = core.GenesisAlloc(alloc)
+
+	return out, nil
+}
+
+// runProcessor applies the test case through the block processor, on top of a
+// genesis block with the pre-state. If the processor rejects the block, only the
+// reason is set in the outcome.
+func runProcessor(c *Case, txs types.Transactions) (*outcome, error) {
+	var (
+		env         = c.Pre.Env
+		cacheConfig = &core.CacheConfig{
+			TrieCleanLimit: 16,
+			TrieDirtyLimit: 16,
+			TrieTimeLimit:  time.Minute,
+			Preimages:      true, // needed to dump the post-state
+		}
+	)
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), cacheConfig, c.genesis(), nil, beacon.New(ethash.NewFaker()), vm.Config{}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	header := &types.Header{
+		ParentHash: chain.Genesis().Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Coinbase:   env.Coinbase,
+		Number:     c.number(),
+		GasLimit:   env.GasLimit,
+		Time:       env.Timestamp,
+		Difficulty: env.Difficulty,
+		BaseFee:    env.BaseFee,
+	}
+	if c.merged() {
+		header.Difficulty = new(big.Int)
+		header.MixDigest = common.BigToHash(env.Random)
+	}
+	var block *types.Block
+	if env.Withdrawals != nil {
+		block = types.NewBlockWithWithdrawals(header, txs, nil, nil, env.Withdrawals, trie.NewStackTrie(nil))
+	} else {
+		block = types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+	}
+	statedb, err := chain.StateAt(chain.Genesis().Root())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open genesis state: %v", err)
+	}
+	receipts, logs, gasUsed, err := chain.Processor().Process(block, statedb, vm.Config{})
+	if err != nil {
+		return &outcome{invalid: err}, nil
+	}
+	root, err := statedb.Commit(c.Config.IsEIP158(header.Number))
+	if err != nil {
+		return nil, err
+	}
+	if statedb, err = state.New(root, statedb.Database(), nil); err != nil {
+		return nil, err
+	}
+	alloc := make(t8ntool.Alloc)
+	statedb.DumpToCollector(alloc, nil)
+
+	return &outcome{
+		receipts:    receipts,
+		gasUsed:     gasUsed,
+		logsHash:    rlpHash(logs),
+		receiptRoot: types.DeriveSha(receipts, trie.NewStackTrie(nil)),
+		stateRoot:   root,
+		alloc:       core.GenesisAlloc(alloc),
+	}, nil
+}
+
+// runExternal applies the test case through an external t8n command.
+func runExternal(c *Case, command []string) (*outcome, error) {
+	dir, err := os.MkdirTemp("", "difftest")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := c.WriteInputs(dir); err != nil {
+		return nil, err
+	}
+	args := append(command[1:],
+		"--input.alloc", filepath.Join(dir, "alloc.json"),
+		"--input.env", filepath.Join(dir, "env.json"),
+		"--input.txs", filepath.Join(dir, "txs.rlp"),
+		"--output.alloc", "stdout",
+		"--output.result", "stdout",
+		"--state.fork", c.Fork,
+		"--state.reward", strconv.FormatInt(c.Reward(), 10),
+		"--state.chainid", c.Config.ChainID.String(),
+	)
+	var stderr bytes.Buffer
+	cmd := exec.Command(command[0], args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("external t8n failed: %v: %s", err, stderr.Bytes())
+	}
+	var output struct {
+		Alloc  core.GenesisAlloc `json:"alloc"`
+		Result struct {
+			StateRoot   common.Hash         `json:"stateRoot"`
+			ReceiptRoot common.Hash         `json:"receiptsRoot"`
+			LogsHash    common.Hash         `json:"logsHash"`
+			GasUsed     math.HexOrDecimal64 `json:"gasUsed"`
+			Rejected    []struct {
+				Index int `json:"index"`
+			} `json:"rejected"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(stdout, &output); err != nil {
+		return nil, fmt.Errorf("invalid external t8n output: %v", err)
+	}
+	out := &outcome{
+		gasUsed:     uint64(output.Result.GasUsed),
+		logsHash:    output.Result.LogsHash,
+		receiptRoot: output.Result.ReceiptRoot,
+		stateRoot:   output.Result.StateRoot,
+		alloc:       output.Alloc,
+	}
+	for _, rejected := range output.Result.Rejected {
+		out.rejected = append(out.rejected, rejected.Index)
+	}
+	return out, nil
+}
+
+// WriteInputs writes the test case into the given directory in the input format
+// of t8n: alloc.json, env.json and txs.rlp.
+func (c *Case) WriteInputs(dir string) error {
+	txs, err := rlp.EncodeToBytes(c.Txs)
+	if err != nil {
+		return err
+	}
+	files := map[string]interface{}{
+		"alloc.json": c.Pre.Pre,
+		"env.json":   c.Pre.Env,
+		"txs.rlp":    hexutil.Bytes(txs),
+	}
+	for name, obj := range files {
+		blob, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), blob, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the test case in the combined input format t8n accepts on
+// its standard input.
+func (c *Case) MarshalJSON() ([]byte, error) {
+	txs, err := rlp.EncodeToBytes(c.Txs)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&struct {
+		Alloc core.GenesisAlloc `json:"alloc"`
+		Env   interface{}       `json:"env"`
+		TxRlp hexutil.Bytes     `json:"txsRlp"`
+	}{c.Pre.Pre, c.Pre.Env, txs})
+}
+
+// compare returns the first difference between the outcome of an implementation
+// and the block processor. Receipts are compared first as they pinpoint the
+// diverging transaction, the post-state last as it is the least specific.
+func compare(impl string, have, want *outcome) *Divergence {
+	diverge := func(field string, have, want interface{}) *Divergence {
+		return &Divergence{Impl: impl, Field: field, Have: fmt.Sprint(have), Want: fmt.Sprint(want)}
+	}
+	if have.receipts != nil {
+		if len(have.receipts) != len(want.receipts) {
+			return diverge("receipt count", len(have.receipts), len(want.receipts))
+		}
+		for i := range have.receipts {
+			if d := compareReceipts(have.receipts[i], want.receipts[i]); d != nil {
+				d.Impl, d.Field = impl, fmt.Sprintf("receipt %d %s", i, d.Field)
+				return d
+			}
+		}
+	}
+	if have.gasUsed != want.gasUsed {
+		return diverge("gas used", have.gasUsed, want.gasUsed)
+	}
+	if have.logsHash != want.logsHash {
+		return diverge("logs hash", have.logsHash, want.logsHash)
+	}
+	if have.receiptRoot != want.receiptRoot {
+		return diverge("receipt root", have.receiptRoot, want.receiptRoot)
+	}
+	if d := compareAllocs(have.alloc, want.alloc); d != nil {
+		d.Impl = impl
+		return d
+	}
+	if have.stateRoot != want.stateRoot {
+		return diverge("state root", have.stateRoot, want.stateRoot)
+	}
+	return nil
+}
+
+// compareReceipts returns the first difference in the consensus fields of two
+// receipts, and the contract address.
+func compareReceipts(have, want *types.Receipt) *Divergence {
+	diverge := func(field string, have, want interface{}) *Divergence {
+		return &Divergence{Field: field, Have: fmt.Sprint(have), Want: fmt.Sprint(want)}
+	}
+	switch {
+	case have.Type != want.Type:
+		return diverge("type", have.Type, want.Type)
+	case have.Status != want.Status:
+		return diverge("status", have.Status, want.Status)
+	case !bytes.Equal(have.PostState, want.PostState):
+		return diverge("post state", hexutil.Bytes(have.PostState), hexutil.Bytes(want.PostState))
+	case have.CumulativeGasUsed != want.CumulativeGasUsed:
+		return diverge("cumulative gas used", have.CumulativeGasUsed, want.CumulativeGasUsed)
+	case have.GasUsed != want.GasUsed:
+		return diverge("gas used", have.GasUsed, want.GasUsed)
+	case have.ContractAddress != want.ContractAddress:
+		return diverge("contract address", have.ContractAddress, want.ContractAddress)
+	case len(have.Logs) != len(want.Logs):
+		return diverge("log count", len(have.Logs), len(want.Logs))
+	}
+	for i := range have.Logs {
+		haveLog, wantLog := have.Logs[i], want.Logs[i]
+		switch {
+		case haveLog.Address != wantLog.Address:
+			return diverge(fmt.Sprintf("log %d address", i), haveLog.Address, wantLog.Address)
+		case fmt.Sprint(haveLog.Topics) != fmt.Sprint(wantLog.Topics):
+			return diverge(fmt.Sprintf("log %d topics", i), haveLog.Topics, wantLog.Topics)
+		case !bytes.Equal(haveLog.Data, wantLog.Data):
+			return diverge(fmt.Sprintf("log %d data", i), hexutil.Bytes(haveLog.Data), hexutil.Bytes(wantLog.Data))
+		}
+	}
+	if have.Bloom != want.Bloom {
+		return diverge("bloom", hexutil.Bytes(have.Bloom[:]), hexutil.Bytes(want.Bloom[:]))
+	}
+	return nil
+}
+
+// compareAllocs returns the first difference between two post-states, in the
+// order of the account addresses.
+func compareAllocs(have, want core.GenesisAlloc) *Divergence {
+	diverge := func(field string, have, want interface{}) *Divergence {
+		return &Divergence{Field: field, Have: fmt.Sprint(have), Want: fmt.Sprint(want)}
+	}
+	for _, addr := range sortedAddresses(have, want) {
+		haveAcc, haveOk := have[addr]
+		wantAcc, wantOk := want[addr]
+		if haveOk != wantOk {
+			return diverge(fmt.Sprintf("account %x existence", addr), haveOk, wantOk)
+		}
+		if balance(haveAcc).Cmp(balance(wantAcc)) != 0 {
+			return diverge(fmt.Sprintf("account %x balance", addr), balance(haveAcc), balance(wantAcc))
+		}
+		if haveAcc.Nonce != wantAcc.Nonce {
+			return diverge(fmt.Sprintf("account %x nonce", addr), haveAcc.Nonce, wantAcc.Nonce)
+		}
+		if !bytes.Equal(haveAcc.Code, wantAcc.Code) {
+			return diverge(fmt.Sprintf("account %x code", addr), hexutil.Bytes(haveAcc.Code), hexutil.Bytes(wantAcc.Code))
+		}
+		for _, key := range sortedKeys(haveAcc.Storage, wantAcc.Storage) {
+			if haveAcc.Storage[key] != wantAcc.Storage[key] {
+				return diverge(fmt.Sprintf("account %x slot %x", addr, key), haveAcc.Storage[key], wantAcc.Storage[key])
+			}
+		}
+	}
+	return nil
+}
+
+func sortedAddresses(allocs ...core.GenesisAlloc) []common.Address {
+	set := make(map[common.Address]struct{})
+	for _, alloc := range allocs {
+		for addr := range alloc {
+			set[addr] = struct{}{}
+		}
+	}
+	addrs := make([]common.Address, 0, len(set))
+	for addr := range set {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
+
+func sortedKeys(storages ...map[common.Hash]common.Hash) []common.Hash {
+	set := make(map[common.Hash]struct{})
+	for _, storage := range storages {
+		for key := range storage {
+			set[key] = struct{}{}
+		}
+	}
+	keys := make([]common.Hash, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	return keys
+}
+
+func contains(list []int, item int) bool {
+	for _, x := range list {
+		if x == item {
+			return true
+		}
+	}
+	return false
+}
+
+func rlpHash(x interface{}) common.Hash {
+	blob, _ := rlp.EncodeToBytes(x)
+	return crypto.Keccak256Hash(blob)
+}
+
+// balance returns the balance of an account, which may be missing from the
+// output of external tools if zero.
+func balance(account core.GenesisAccount) *big.Int {
+	if account.Balance == nil {
+		return new(big.Int)
+	}
+	return account.Balance
+}
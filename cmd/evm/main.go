@@ -214,12 +214,15 @@ func init() {
 	app.Commands = []*cli.Command{
 		compileCommand,
 		disasmCommand,
+		eofParseCommand,
 		runCommand,
 		blockTestCommand,
 		stateTestCommand,
 		stateTransitionCommand,
 		transactionCommand,
 		blockBuilderCommand,
+		statelessCommand,
+		diffTestCommand,
 	}
 }
 
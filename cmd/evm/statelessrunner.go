@@ -0,0 +1,88 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var statelessCommand = &cli.Command{
+	Action:    statelessCmd,
+	Name:      "stateless",
+	Usage:     "verifies a block using only its execution witness",
+	ArgsUsage: "<block.rlp> <witness.json>",
+	Description: `
+The stateless command executes a block on top of the state contained in its
+execution witness, as returned by debug_executionWitness, and checks the post
+state root, receipt root and gas used against the block header. The block is
+given as hex encoded RLP, as returned by debug_getRawBlock.`,
+	Flags: []cli.Flag{
+		t8ntool.ForknameFlag,
+		t8ntool.ChainIDFlag,
+	},
+}
+
+func statelessCmd(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return errors.New("block and witness arguments required")
+	}
+	config, _, err := tests.GetChainConfig(ctx.String(t8ntool.ForknameFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed constructing chain configuration: %v", err)
+	}
+	config.ChainID = big.NewInt(ctx.Int64(t8ntool.ChainIDFlag.Name))
+
+	// Load the block and its witness
+	src, err := os.ReadFile(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(common.FromHex(strings.Trim(strings.TrimSpace(string(src)), `"`)), block); err != nil {
+		return fmt.Errorf("failed to decode block: %v", err)
+	}
+	if src, err = os.ReadFile(ctx.Args().Get(1)); err != nil {
+		return err
+	}
+	witness := new(stateless.Witness)
+	if err := json.Unmarshal(src, witness); err != nil {
+		return fmt.Errorf("failed to decode witness: %v", err)
+	}
+	// Verify the block, both pre and post merge blocks are supported
+	engine := beacon.New(ethash.NewFaker())
+	if err := core.ExecuteStateless(config, engine, block, witness); err != nil {
+		return fmt.Errorf("block %d [%x] invalid: %v", block.NumberU64(), block.Hash(), err)
+	}
+	fmt.Printf("block %d [%x] valid\n", block.NumberU64(), block.Hash())
+	return nil
+}
@@ -93,6 +93,10 @@ if one is set.  Otherwise it prints the genesis from the datadir.`,
 			utils.MetricsInfluxDBTokenFlag,
 			utils.MetricsInfluxDBBucketFlag,
 			utils.MetricsInfluxDBOrganizationFlag,
+			utils.MetricsEnableOTLPFlag,
+			utils.MetricsOTLPEndpointFlag,
+			utils.MetricsOTLPResourceFlag,
+			utils.MetricsPrometheusBucketsFlag,
 			utils.TxLookupLimitFlag,
 		}, utils.DatabasePathFlags),
 		Description: `
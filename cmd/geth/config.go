@@ -161,6 +161,7 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 		cfg.Ethstats.URL = ctx.String(utils.EthStatsURLFlag.Name)
 	}
 	applyMetricConfig(ctx, &cfg)
+	utils.SetMetricsResource(stack, &cfg.Eth)
 
 	return stack, cfg
 }
@@ -286,6 +287,18 @@ func applyMetricConfig(ctx *cli.Context, cfg *gethConfig) {
 	if ctx.IsSet(utils.MetricsInfluxDBOrganizationFlag.Name) {
 		cfg.Metrics.InfluxDBOrganization = ctx.String(utils.MetricsInfluxDBOrganizationFlag.Name)
 	}
+	if ctx.IsSet(utils.MetricsEnableOTLPFlag.Name) {
+		cfg.Metrics.EnableOTLP = ctx.Bool(utils.MetricsEnableOTLPFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsOTLPEndpointFlag.Name) {
+		cfg.Metrics.OTLPEndpoint = ctx.String(utils.MetricsOTLPEndpointFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsOTLPResourceFlag.Name) {
+		cfg.Metrics.OTLPResource = ctx.String(utils.MetricsOTLPResourceFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsPrometheusBucketsFlag.Name) {
+		cfg.Metrics.PrometheusBuckets = ctx.String(utils.MetricsPrometheusBucketsFlag.Name)
+	}
 }
 
 func deprecated(field string) bool {
@@ -70,6 +70,7 @@ var (
 		utils.TxPoolLocalsFlag,
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
+		utils.TxPoolRemoteJournalFlag,
 		utils.TxPoolRejournalFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
@@ -79,11 +80,13 @@ var (
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
 		utils.SyncModeFlag,
+		utils.SyncPivotFlag,
 		utils.SyncTargetFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.HistoryCutoffFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
@@ -105,6 +108,8 @@ var (
 		utils.CacheGCFlag,
 		utils.CacheSnapshotFlag,
 		utils.CacheNoPrefetchFlag,
+		utils.CacheReadSetsFlag,
+		utils.ParallelWorkersFlag,
 		utils.CachePreimagesFlag,
 		utils.CacheLogSizeFlag,
 		utils.FDLimitFlag,
@@ -120,6 +125,7 @@ var (
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNewPayloadTimeout,
+		utils.MinerSequencerKeyFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -129,6 +135,8 @@ var (
 		utils.DNSDiscoveryFlag,
 		utils.DeveloperFlag,
 		utils.DeveloperGasLimitFlag,
+		utils.DeveloperShanghaiTimeFlag,
+		utils.DeveloperCancunTimeFlag,
 		utils.DeveloperPeriodFlag,
 		utils.VMEnableDebugFlag,
 		utils.NetworkIdFlag,
@@ -149,19 +157,35 @@ var (
 		utils.AuthListenFlag,
 		utils.AuthPortFlag,
 		utils.AuthVirtualHostsFlag,
+		utils.AuthMethodsAllowFlag,
+		utils.AuthMethodsDenyFlag,
 		utils.JWTSecretFlag,
 		utils.HTTPVirtualHostsFlag,
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
 		utils.GraphQLVirtualHostsFlag,
 		utils.HTTPApiFlag,
+		utils.HTTPMethodsAllowFlag,
+		utils.HTTPMethodsDenyFlag,
+		utils.HTTPJWTAuthFlag,
 		utils.HTTPPathPrefixFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
 		utils.WSApiFlag,
+		utils.WSMethodsAllowFlag,
+		utils.WSMethodsDenyFlag,
+		utils.WSJWTAuthFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.WSPathPrefixFlag,
+		utils.HTTP2EnabledFlag,
+		utils.HTTP2ListenAddrFlag,
+		utils.HTTP2PortFlag,
+		utils.HTTP2ApiFlag,
+		utils.HTTP2MethodsAllowFlag,
+		utils.HTTP2MethodsDenyFlag,
+		utils.HTTP2JWTAuthFlag,
+		utils.HTTP2PathPrefixFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
@@ -171,6 +195,15 @@ var (
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCRateLimitConnFlag,
+		utils.RPCRateLimitConnBurstFlag,
+		utils.RPCRateLimitClientFlag,
+		utils.RPCRateLimitClientBurstFlag,
+		utils.RPCRateLimitCostsFlag,
+		utils.RPCRateLimitRuntimeCostFlag,
+		utils.RPCTimeoutFlag,
+		utils.RPCTimeoutMethodsFlag,
+		utils.RPCTimeoutMaxFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -188,6 +221,10 @@ var (
 		utils.MetricsInfluxDBTokenFlag,
 		utils.MetricsInfluxDBBucketFlag,
 		utils.MetricsInfluxDBOrganizationFlag,
+		utils.MetricsEnableOTLPFlag,
+		utils.MetricsOTLPEndpointFlag,
+		utils.MetricsOTLPResourceFlag,
+		utils.MetricsPrometheusBucketsFlag,
 	}
 )
 
@@ -66,6 +66,8 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
 	"github.com/ethereum/go-ethereum/metrics/influxdb"
+	"github.com/ethereum/go-ethereum/metrics/otlp"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -171,6 +173,16 @@ var (
 		Value:    11500000,
 		Category: flags.DevCategory,
 	}
+	DeveloperShanghaiTimeFlag = &cli.Uint64Flag{
+		Name:     "dev.shanghaitime",
+		Usage:    "Timestamp to schedule the Shanghai fork at in developer mode (default = active from genesis)",
+		Category: flags.DevCategory,
+	}
+	DeveloperCancunTimeFlag = &cli.Uint64Flag{
+		Name:     "dev.cancuntime",
+		Usage:    "Timestamp to schedule the Cancun fork at in developer mode (default = not scheduled)",
+		Category: flags.DevCategory,
+	}
 
 	IdentityFlag = &cli.StringFlag{
 		Name:     "identity",
@@ -225,6 +237,11 @@ var (
 		Value:    &defaultSyncMode,
 		Category: flags.EthCategory,
 	}
+	SyncPivotFlag = &cli.Uint64Flag{
+		Name:     "syncmode.pivot",
+		Usage:    "Block number to pin the snap sync pivot to, syncing the state of a historical block (requires archive peers, 0 = track the head)",
+		Category: flags.EthCategory,
+	}
 	GCModeFlag = &cli.StringFlag{
 		Name:     "gcmode",
 		Usage:    `Blockchain garbage collection mode ("full", "archive")`,
@@ -243,6 +260,11 @@ var (
 		Value:    ethconfig.Defaults.TxLookupLimit,
 		Category: flags.EthCategory,
 	}
+	HistoryCutoffFlag = &cli.Uint64Flag{
+		Name:     "history.cutoff",
+		Usage:    "Block number below which block bodies and receipts are expired from the ancient store (e.g. the merge block, 0 = keep all)",
+		Category: flags.EthCategory,
+	}
 	LightKDFFlag = &cli.BoolFlag{
 		Name:     "lightkdf",
 		Usage:    "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -343,9 +365,14 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Journal,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolRemoteJournalFlag = &cli.StringFlag{
+		Name:     "txpool.remotejournal",
+		Usage:    "Disk journal for remote transactions to survive node restarts (persists the entire pool)",
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolRejournalFlag = &cli.DurationFlag{
 		Name:     "txpool.rejournal",
-		Usage:    "Time interval to regenerate the local transaction journal",
+		Usage:    "Time interval to regenerate the transaction journals",
 		Value:    ethconfig.Defaults.TxPool.Rejournal,
 		Category: flags.TxPoolCategory,
 	}
@@ -427,6 +454,16 @@ var (
 		Usage:    "Disable heuristic state prefetch during block import (less CPU and disk IO, more time waiting for data)",
 		Category: flags.PerfCategory,
 	}
+	CacheReadSetsFlag = &cli.BoolFlag{
+		Name:     "cache.readsets",
+		Usage:    "Record the state read by each imported block, to prefetch it in parallel when re-executing the block (tracing, reprocessing)",
+		Category: flags.PerfCategory,
+	}
+	ParallelWorkersFlag = &cli.IntFlag{
+		Name:     "parallel.workers",
+		Usage:    "Number of goroutines speculatively executing block transactions in parallel during import (0 = sequential)",
+		Category: flags.PerfCategory,
+	}
 	CachePreimagesFlag = &cli.BoolFlag{
 		Name:     "cache.preimages",
 		Usage:    "Enable recording the SHA3/keccak preimages of trie keys",
@@ -490,6 +527,11 @@ var (
 		Value:    ethconfig.Defaults.Miner.NewPayloadTimeout,
 		Category: flags.MinerCategory,
 	}
+	MinerSequencerKeyFlag = &cli.StringFlag{
+		Name:     "miner.sequencerkey",
+		Usage:    "Key file to sign transaction inclusion preconfirmations with, enabling first-come-first-served sequencing (single block producer chains only)",
+		Category: flags.MinerCategory,
+	}
 
 	// Account settings
 	UnlockedAccountFlag = &cli.StringFlag{
@@ -561,6 +603,16 @@ var (
 		Value:    strings.Join(node.DefaultConfig.AuthVirtualHosts, ","),
 		Category: flags.APICategory,
 	}
+	AuthMethodsAllowFlag = &cli.StringFlag{
+		Name:     "authrpc.methods.allow",
+		Usage:    "Comma separated list of methods allowed over the authenticated APIs (e.g. engine_*)",
+		Category: flags.APICategory,
+	}
+	AuthMethodsDenyFlag = &cli.StringFlag{
+		Name:     "authrpc.methods.deny",
+		Usage:    "Comma separated list of methods denied over the authenticated APIs",
+		Category: flags.APICategory,
+	}
 	JWTSecretFlag = &flags.DirectoryFlag{
 		Name:     "authrpc.jwtsecret",
 		Usage:    "Path to a JWT secret to use for authenticated RPC endpoints",
@@ -633,6 +685,21 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	HTTPMethodsAllowFlag = &cli.StringFlag{
+		Name:     "http.methods.allow",
+		Usage:    "Comma separated list of methods allowed over the HTTP-RPC interface (e.g. debug_traceTransaction or debug_*)",
+		Category: flags.APICategory,
+	}
+	HTTPMethodsDenyFlag = &cli.StringFlag{
+		Name:     "http.methods.deny",
+		Usage:    "Comma separated list of methods denied over the HTTP-RPC interface (e.g. debug_setHead)",
+		Category: flags.APICategory,
+	}
+	HTTPJWTAuthFlag = &cli.BoolFlag{
+		Name:     "http.jwtauth",
+		Usage:    "Require clients of the HTTP-RPC interface to authenticate using the JWT secret (see --authrpc.jwtsecret)",
+		Category: flags.APICategory,
+	}
 	HTTPPathPrefixFlag = &cli.StringFlag{
 		Name:     "http.rpcprefix",
 		Usage:    "HTTP path path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
@@ -679,6 +746,21 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	WSMethodsAllowFlag = &cli.StringFlag{
+		Name:     "ws.methods.allow",
+		Usage:    "Comma separated list of methods allowed over the WS-RPC interface (e.g. debug_traceTransaction or debug_*)",
+		Category: flags.APICategory,
+	}
+	WSMethodsDenyFlag = &cli.StringFlag{
+		Name:     "ws.methods.deny",
+		Usage:    "Comma separated list of methods denied over the WS-RPC interface (e.g. debug_setHead)",
+		Category: flags.APICategory,
+	}
+	WSJWTAuthFlag = &cli.BoolFlag{
+		Name:     "ws.jwtauth",
+		Usage:    "Require clients of the WS-RPC interface to authenticate using the JWT secret (see --authrpc.jwtsecret)",
+		Category: flags.APICategory,
+	}
 	WSAllowedOriginsFlag = &cli.StringFlag{
 		Name:     "ws.origins",
 		Usage:    "Origins from which to accept websockets requests",
@@ -691,6 +773,50 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	HTTP2EnabledFlag = &cli.BoolFlag{
+		Name:     "http2",
+		Usage:    "Enable the HTTP/2 streaming RPC server",
+		Category: flags.APICategory,
+	}
+	HTTP2ListenAddrFlag = &cli.StringFlag{
+		Name:     "http2.addr",
+		Usage:    "HTTP/2 streaming RPC server listening interface",
+		Value:    node.DefaultHTTP2Host,
+		Category: flags.APICategory,
+	}
+	HTTP2PortFlag = &cli.IntFlag{
+		Name:     "http2.port",
+		Usage:    "HTTP/2 streaming RPC server listening port",
+		Value:    node.DefaultHTTP2Port,
+		Category: flags.APICategory,
+	}
+	HTTP2ApiFlag = &cli.StringFlag{
+		Name:     "http2.api",
+		Usage:    "API's offered over the HTTP/2 streaming RPC interface",
+		Value:    "",
+		Category: flags.APICategory,
+	}
+	HTTP2MethodsAllowFlag = &cli.StringFlag{
+		Name:     "http2.methods.allow",
+		Usage:    "Comma separated list of methods allowed over the HTTP/2 streaming RPC interface (e.g. debug_traceTransaction or debug_*)",
+		Category: flags.APICategory,
+	}
+	HTTP2MethodsDenyFlag = &cli.StringFlag{
+		Name:     "http2.methods.deny",
+		Usage:    "Comma separated list of methods denied over the HTTP/2 streaming RPC interface (e.g. debug_setHead)",
+		Category: flags.APICategory,
+	}
+	HTTP2JWTAuthFlag = &cli.BoolFlag{
+		Name:     "http2.jwtauth",
+		Usage:    "Require clients of the HTTP/2 streaming RPC interface to authenticate using the JWT secret (see --authrpc.jwtsecret)",
+		Category: flags.APICategory,
+	}
+	HTTP2PathPrefixFlag = &cli.StringFlag{
+		Name:     "http2.rpcprefix",
+		Usage:    "HTTP path prefix on which JSON-RPC streams are served. Use '/' to serve on all paths.",
+		Value:    "",
+		Category: flags.APICategory,
+	}
 	ExecFlag = &cli.StringFlag{
 		Name:     "exec",
 		Usage:    "Execute JavaScript statement",
@@ -718,6 +844,51 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCRateLimitConnFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.conn",
+		Usage:    "Call cost per second allowed on a single HTTP or WebSocket connection (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitConnBurstFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.conn-burst",
+		Usage:    "Maximum call cost a single connection may accumulate for bursts",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitClientFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.client",
+		Usage:    "Call cost per second allowed for a client, identified by IP address or JWT subject (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitClientBurstFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.client-burst",
+		Usage:    "Maximum call cost a client may accumulate for bursts",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitCostsFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.costs",
+		Usage:    "Comma separated list of method=cost pairs overriding the cost of 1 charged per call (e.g. eth_getLogs=20)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitRuntimeCostFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.runtime-cost",
+		Usage:    "Additional cost charged per second of call execution time",
+		Category: flags.APICategory,
+	}
+	RPCTimeoutFlag = &cli.DurationFlag{
+		Name:     "rpc.timeout",
+		Usage:    "Execution timeout of HTTP, WebSocket and HTTP/2 method calls (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCTimeoutMethodsFlag = &cli.StringFlag{
+		Name:     "rpc.timeout.methods",
+		Usage:    "Comma separated list of method=duration pairs overriding the execution timeout (e.g. debug_traceBlockByNumber=1m)",
+		Category: flags.APICategory,
+	}
+	RPCTimeoutMaxFlag = &cli.DurationFlag{
+		Name:     "rpc.timeout.max",
+		Usage:    "Longest execution timeout clients may request via the Rpc-Timeout header (0 = only shorter than configured)",
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -926,6 +1097,29 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Value:    metrics.DefaultConfig.InfluxDBOrganization,
 		Category: flags.MetricsCategory,
 	}
+
+	MetricsEnableOTLPFlag = &cli.BoolFlag{
+		Name:     "metrics.otlp",
+		Usage:    "Enable metrics export/push to an OpenTelemetry collector using OTLP/HTTP",
+		Category: flags.MetricsCategory,
+	}
+	MetricsOTLPEndpointFlag = &cli.StringFlag{
+		Name:     "metrics.otlp.endpoint",
+		Usage:    "OTLP/HTTP endpoint of the collector to report metrics to",
+		Value:    metrics.DefaultConfig.OTLPEndpoint,
+		Category: flags.MetricsCategory,
+	}
+	MetricsOTLPResourceFlag = &cli.StringFlag{
+		Name:     "metrics.otlp.resource",
+		Usage:    "Comma-separated resource attributes (key=value) reported along with the node's network, ID and version",
+		Category: flags.MetricsCategory,
+	}
+
+	MetricsPrometheusBucketsFlag = &cli.StringFlag{
+		Name:     "metrics.prometheus.buckets",
+		Usage:    "Histogram bucket bounds of metric families in the OpenMetrics output, rounded up to exponential bucket boundaries (e.g. \"rpc/duration=100,1000,10000;chain=1e6,1e7,1e8\")",
+		Category: flags.MetricsCategory,
+	}
 )
 
 var (
@@ -1116,6 +1310,12 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(AuthVirtualHostsFlag.Name) {
 		cfg.AuthVirtualHosts = SplitAndTrim(ctx.String(AuthVirtualHostsFlag.Name))
 	}
+	if ctx.IsSet(AuthMethodsAllowFlag.Name) {
+		cfg.AuthAccessControl.Allow = SplitAndTrim(ctx.String(AuthMethodsAllowFlag.Name))
+	}
+	if ctx.IsSet(AuthMethodsDenyFlag.Name) {
+		cfg.AuthAccessControl.Deny = SplitAndTrim(ctx.String(AuthMethodsDenyFlag.Name))
+	}
 
 	if ctx.IsSet(HTTPCORSDomainFlag.Name) {
 		cfg.HTTPCors = SplitAndTrim(ctx.String(HTTPCORSDomainFlag.Name))
@@ -1124,6 +1324,15 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(HTTPApiFlag.Name) {
 		cfg.HTTPModules = SplitAndTrim(ctx.String(HTTPApiFlag.Name))
 	}
+	if ctx.IsSet(HTTPMethodsAllowFlag.Name) {
+		cfg.HTTPAccessControl.Allow = SplitAndTrim(ctx.String(HTTPMethodsAllowFlag.Name))
+	}
+	if ctx.IsSet(HTTPMethodsDenyFlag.Name) {
+		cfg.HTTPAccessControl.Deny = SplitAndTrim(ctx.String(HTTPMethodsDenyFlag.Name))
+	}
+	if ctx.IsSet(HTTPJWTAuthFlag.Name) {
+		cfg.HTTPJWTAuth = ctx.Bool(HTTPJWTAuthFlag.Name)
+	}
 
 	if ctx.IsSet(HTTPVirtualHostsFlag.Name) {
 		cfg.HTTPVirtualHosts = SplitAndTrim(ctx.String(HTTPVirtualHostsFlag.Name))
@@ -1145,6 +1354,69 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setRPCRateLimit configures the rate limits of the HTTP and WebSocket RPC endpoints
+// from the set command line flags.
+func setRPCRateLimit(ctx *cli.Context, cfg *node.Config) {
+	if ctx.IsSet(RPCRateLimitConnFlag.Name) {
+		cfg.RPCRateLimit.ConnRate = ctx.Float64(RPCRateLimitConnFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitConnBurstFlag.Name) {
+		cfg.RPCRateLimit.ConnBurst = ctx.Float64(RPCRateLimitConnBurstFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitClientFlag.Name) {
+		cfg.RPCRateLimit.ClientRate = ctx.Float64(RPCRateLimitClientFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitClientBurstFlag.Name) {
+		cfg.RPCRateLimit.ClientBurst = ctx.Float64(RPCRateLimitClientBurstFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitRuntimeCostFlag.Name) {
+		cfg.RPCRateLimit.RuntimeCost = ctx.Float64(RPCRateLimitRuntimeCostFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitCostsFlag.Name) {
+		if cfg.RPCRateLimit.MethodCosts == nil {
+			cfg.RPCRateLimit.MethodCosts = make(map[string]float64)
+		}
+		for _, entry := range SplitAndTrim(ctx.String(RPCRateLimitCostsFlag.Name)) {
+			method, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				Fatalf("Invalid --%s entry %q, expected method=cost", RPCRateLimitCostsFlag.Name, entry)
+			}
+			cost, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || cost < 0 {
+				Fatalf("Invalid --%s cost for %s: %q", RPCRateLimitCostsFlag.Name, method, value)
+			}
+			cfg.RPCRateLimit.MethodCosts[strings.TrimSpace(method)] = cost
+		}
+	}
+}
+
+// setRPCTimeouts configures the execution timeouts of method calls on the HTTP,
+// WebSocket and HTTP/2 RPC endpoints from the command line flags.
+func setRPCTimeouts(ctx *cli.Context, cfg *node.Config) {
+	if ctx.IsSet(RPCTimeoutFlag.Name) {
+		cfg.RPCExecutionTimeouts.Default = ctx.Duration(RPCTimeoutFlag.Name)
+	}
+	if ctx.IsSet(RPCTimeoutMaxFlag.Name) {
+		cfg.RPCExecutionTimeouts.Max = ctx.Duration(RPCTimeoutMaxFlag.Name)
+	}
+	if ctx.IsSet(RPCTimeoutMethodsFlag.Name) {
+		if cfg.RPCExecutionTimeouts.Methods == nil {
+			cfg.RPCExecutionTimeouts.Methods = make(map[string]time.Duration)
+		}
+		for _, entry := range SplitAndTrim(ctx.String(RPCTimeoutMethodsFlag.Name)) {
+			method, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				Fatalf("Invalid --%s entry %q, expected method=duration", RPCTimeoutMethodsFlag.Name, entry)
+			}
+			timeout, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || timeout < 0 {
+				Fatalf("Invalid --%s timeout for %s: %q", RPCTimeoutMethodsFlag.Name, method, value)
+			}
+			cfg.RPCExecutionTimeouts.Methods[strings.TrimSpace(method)] = timeout
+		}
+	}
+}
+
 // setGraphQL creates the GraphQL listener interface string from the set
 // command line flags, returning empty if the GraphQL endpoint is disabled.
 func setGraphQL(ctx *cli.Context, cfg *node.Config) {
@@ -1176,12 +1448,52 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(WSApiFlag.Name) {
 		cfg.WSModules = SplitAndTrim(ctx.String(WSApiFlag.Name))
 	}
+	if ctx.IsSet(WSMethodsAllowFlag.Name) {
+		cfg.WSAccessControl.Allow = SplitAndTrim(ctx.String(WSMethodsAllowFlag.Name))
+	}
+	if ctx.IsSet(WSMethodsDenyFlag.Name) {
+		cfg.WSAccessControl.Deny = SplitAndTrim(ctx.String(WSMethodsDenyFlag.Name))
+	}
+	if ctx.IsSet(WSJWTAuthFlag.Name) {
+		cfg.WSJWTAuth = ctx.Bool(WSJWTAuthFlag.Name)
+	}
 
 	if ctx.IsSet(WSPathPrefixFlag.Name) {
 		cfg.WSPathPrefix = ctx.String(WSPathPrefixFlag.Name)
 	}
 }
 
+// setHTTP2 creates the HTTP/2 streaming RPC listener interface string from the set
+// command line flags, returning empty if the HTTP/2 endpoint is disabled.
+func setHTTP2(ctx *cli.Context, cfg *node.Config) {
+	if ctx.Bool(HTTP2EnabledFlag.Name) && cfg.HTTP2Host == "" {
+		cfg.HTTP2Host = "127.0.0.1"
+		if ctx.IsSet(HTTP2ListenAddrFlag.Name) {
+			cfg.HTTP2Host = ctx.String(HTTP2ListenAddrFlag.Name)
+		}
+	}
+	if ctx.IsSet(HTTP2PortFlag.Name) {
+		cfg.HTTP2Port = ctx.Int(HTTP2PortFlag.Name)
+	}
+
+	if ctx.IsSet(HTTP2ApiFlag.Name) {
+		cfg.HTTP2Modules = SplitAndTrim(ctx.String(HTTP2ApiFlag.Name))
+	}
+	if ctx.IsSet(HTTP2MethodsAllowFlag.Name) {
+		cfg.HTTP2AccessControl.Allow = SplitAndTrim(ctx.String(HTTP2MethodsAllowFlag.Name))
+	}
+	if ctx.IsSet(HTTP2MethodsDenyFlag.Name) {
+		cfg.HTTP2AccessControl.Deny = SplitAndTrim(ctx.String(HTTP2MethodsDenyFlag.Name))
+	}
+	if ctx.IsSet(HTTP2JWTAuthFlag.Name) {
+		cfg.HTTP2JWTAuth = ctx.Bool(HTTP2JWTAuthFlag.Name)
+	}
+
+	if ctx.IsSet(HTTP2PathPrefixFlag.Name) {
+		cfg.HTTP2PathPrefix = ctx.String(HTTP2PathPrefixFlag.Name)
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -1396,6 +1708,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
+	setHTTP2(ctx, cfg)
+	setRPCRateLimit(ctx, cfg)
+	setRPCTimeouts(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	SetDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
@@ -1510,6 +1825,9 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolJournalFlag.Name) {
 		cfg.Journal = ctx.String(TxPoolJournalFlag.Name)
 	}
+	if ctx.IsSet(TxPoolRemoteJournalFlag.Name) {
+		cfg.RemoteJournal = ctx.String(TxPoolRemoteJournalFlag.Name)
+	}
 	if ctx.IsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.Duration(TxPoolRejournalFlag.Name)
 	}
@@ -1552,6 +1870,13 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerNewPayloadTimeout.Name) {
 		cfg.NewPayloadTimeout = ctx.Duration(MinerNewPayloadTimeout.Name)
 	}
+	if file := ctx.String(MinerSequencerKeyFlag.Name); file != "" {
+		key, err := crypto.LoadECDSA(file)
+		if err != nil {
+			Fatalf("Option %q: %v", MinerSequencerKeyFlag.Name, err)
+		}
+		cfg.SequencerKey = key
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1666,6 +1991,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *flags.GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
 	}
+	if ctx.IsSet(SyncPivotFlag.Name) {
+		cfg.SyncPivot = ctx.Uint64(SyncPivotFlag.Name)
+	}
 	if ctx.IsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.Uint64(NetworkIdFlag.Name)
 	}
@@ -1686,6 +2014,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = ctx.Bool(CacheNoPrefetchFlag.Name)
 	}
+	if ctx.IsSet(CacheReadSetsFlag.Name) {
+		cfg.RecordReadSets = ctx.Bool(CacheReadSetsFlag.Name)
+	}
+	if ctx.IsSet(ParallelWorkersFlag.Name) {
+		cfg.ParallelWorkers = ctx.Int(ParallelWorkersFlag.Name)
+	}
 	// Read the value from the flag no matter if it's set or not.
 	cfg.Preimages = ctx.Bool(CachePreimagesFlag.Name)
 	if cfg.NoPruning && !cfg.Preimages {
@@ -1695,6 +2029,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.Uint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.IsSet(HistoryCutoffFlag.Name) {
+		cfg.HistoryCutoff = ctx.Uint64(HistoryCutoffFlag.Name)
+	}
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheTrieFlag.Name) / 100
 	}
@@ -1818,6 +2155,14 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 
 		// Create a new developer genesis block or reuse existing one
 		cfg.Genesis = core.DeveloperGenesisBlock(ctx.Uint64(DeveloperGasLimitFlag.Name), developer.Address)
+		if ctx.IsSet(DeveloperShanghaiTimeFlag.Name) {
+			shanghai := ctx.Uint64(DeveloperShanghaiTimeFlag.Name)
+			cfg.Genesis.Config.ShanghaiTime = &shanghai
+		}
+		if ctx.IsSet(DeveloperCancunTimeFlag.Name) {
+			cancun := ctx.Uint64(DeveloperCancunTimeFlag.Name)
+			cfg.Genesis.Config.CancunTime = &cancun
+		}
 		if ctx.IsSet(DataDirFlag.Name) {
 			// If datadir doesn't exist we need to open db in write-mode
 			// so leveldb can create files.
@@ -1993,6 +2338,28 @@ func SetupMetrics(ctx *cli.Context) {
 			go influxdb.InfluxDBV2WithTags(metrics.DefaultRegistry, 10*time.Second, endpoint, token, bucket, organization, "geth.", tagsMap)
 		}
 
+		if ctx.Bool(MetricsEnableOTLPFlag.Name) {
+			resource := SplitTagsFlag(ctx.String(MetricsOTLPResourceFlag.Name))
+			otlp.SetResourceAttribute(otlp.AttrServiceName, "geth")
+			otlp.SetResourceAttribute(otlp.AttrServiceVersion, params.VersionWithMeta)
+
+			log.Info("Enabling metrics export to OTLP collector", "endpoint", ctx.String(MetricsOTLPEndpointFlag.Name))
+
+			go otlp.OTLP(metrics.DefaultRegistry, 10*time.Second, ctx.String(MetricsOTLPEndpointFlag.Name), "geth.", resource)
+		}
+
+		if ctx.IsSet(MetricsPrometheusBucketsFlag.Name) {
+			layouts, err := prometheus.ParseBuckets(ctx.String(MetricsPrometheusBucketsFlag.Name))
+			if err != nil {
+				Fatalf("Invalid --%s: %v", MetricsPrometheusBucketsFlag.Name, err)
+			}
+			for family, bounds := range layouts {
+				if err := prometheus.SetBuckets(family, bounds); err != nil {
+					Fatalf("Invalid --%s: %v", MetricsPrometheusBucketsFlag.Name, err)
+				}
+			}
+		}
+
 		if ctx.IsSet(MetricsHTTPFlag.Name) {
 			address := net.JoinHostPort(ctx.String(MetricsHTTPFlag.Name), fmt.Sprintf("%d", ctx.Int(MetricsPortFlag.Name)))
 			log.Info("Enabling stand-alone metrics HTTP endpoint", "address", address)
@@ -2003,6 +2370,15 @@ func SetupMetrics(ctx *cli.Context) {
 	}
 }
 
+// SetMetricsResource sets the network and node ID reported along with metrics
+// exported to an OpenTelemetry collector.
+func SetMetricsResource(stack *node.Node, cfg *ethconfig.Config) {
+	otlp.SetResourceAttribute(otlp.AttrNetworkID, strconv.FormatUint(cfg.NetworkId, 10))
+	if key := stack.Server().PrivateKey; key != nil {
+		otlp.SetResourceAttribute(otlp.AttrNodeID, enode.PubkeyToIDV4(&key.PublicKey).String())
+	}
+}
+
 func SplitTagsFlag(tagsFlag string) map[string]string {
 	tags := strings.Split(tagsFlag, ",")
 	tagsMap := map[string]string{}
@@ -2122,6 +2498,8 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		TrieTimeLimit:       ethconfig.Defaults.TrieTimeout,
 		SnapshotLimit:       ethconfig.Defaults.SnapshotCache,
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
+		RecordReadSets:      ctx.Bool(CacheReadSetsFlag.Name),
+		ParallelWorkers:     ctx.Int(ParallelWorkersFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
@@ -2141,7 +2519,9 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheGCFlag.Name) {
 		cache.TrieDirtyLimit = ctx.Int(CacheFlag.Name) * ctx.Int(CacheGCFlag.Name) / 100
 	}
-	vmcfg := vm.Config{EnablePreimageRecording: ctx.Bool(VMEnableDebugFlag.Name)}
+	vmcfg := vm.Config{
+		EnablePreimageRecording: ctx.Bool(VMEnableDebugFlag.Name),
+	}
 
 	// Disable transaction indexing/unindexing by default.
 	chain, err := core.NewBlockChain(chainDb, cache, gspec, nil, engine, vmcfg, nil, nil)
@@ -18,6 +18,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -41,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/syncx"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/internal/version"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -100,6 +102,10 @@ const (
 	maxTimeFutureBlocks = 30
 	TriesInMemory       = 128
 
+	// historyExpiryInterval is the frequency to check whether newly frozen blocks
+	// have bodies and receipts to expire below the history cutoff.
+	historyExpiryInterval = time.Minute
+
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
 	// Changelog:
@@ -139,6 +145,11 @@ type CacheConfig struct {
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
+
+	HistoryCutoff uint64 // Block number below which bodies and receipts are expired from the ancients (0 = keep all)
+
+	RecordReadSets  bool // Whether to record the state read by each block, to prefetch it on re-execution
+	ParallelWorkers int  // Number of goroutines speculatively executing block transactions (0 = sequential)
 }
 
 // defaultCacheConfig are the default caching values if none are specified by the
@@ -184,6 +195,7 @@ type BlockChain struct {
 	//  * N:   means N block limit [HEAD-N+1, HEAD] and delete extra indexes
 	//  * nil: disable tx reindexer/deleter, but still index new blocks
 	txLookupLimit uint64
+	txIndexLock   sync.Mutex // Serializes transaction (un)indexing with history expiry
 
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
@@ -425,6 +437,11 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 		bc.wg.Add(1)
 		go bc.maintainTxIndex()
 	}
+	// Start history expirer if required.
+	if bc.cacheConfig.HistoryCutoff > 0 {
+		bc.wg.Add(1)
+		go bc.maintainHistory()
+	}
 	return bc, nil
 }
 
@@ -1332,6 +1349,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	if reads := state.ReadSet(); reads != nil {
+		rawdb.WriteBlockReadSet(blockBatch, block.Hash(), block.NumberU64(), reads)
+	}
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
@@ -1698,15 +1718,29 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 
 		// Retrieve the parent block and it's state to execute on top
 		start := time.Now()
+		ctx, span := telemetry.Start(context.Background(), "core.insertBlock", telemetry.WithStartTime(it.validateStart), telemetry.WithAttributes(
+			telemetry.Uint64("block.number", block.NumberU64()),
+			telemetry.String("block.hash", block.Hash().Hex()),
+			telemetry.Int("block.transactions", len(block.Transactions())),
+		))
+		span.RecordChild("core.validateBlock", it.validateStart, it.validateEnd)
+
 		parent := it.previous()
 		if parent == nil {
 			parent = bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
 		}
 		statedb, err := state.New(parent.Root, bc.stateCache, bc.snaps)
 		if err != nil {
+			span.EndWithError(err)
 			return it.index, err
 		}
-
+		// If the block was executed before and its read set recorded, preload
+		// it in parallel. Otherwise record it if requested.
+		if reads := rawdb.ReadBlockReadSet(bc.db, block.Hash(), block.NumberU64()); reads != nil {
+			statedb.Prefetch(reads, runtime.NumCPU())
+		} else if bc.cacheConfig.RecordReadSets {
+			statedb.StartReadRecording()
+		}
 		// Enable prefetching to pull in trie node paths while processing transactions
 		statedb.StartPrefetcher("chain")
 		activeState = statedb
@@ -1731,10 +1765,15 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 
 		// Process block using the parent state as reference point
 		pstart := time.Now()
+		_, pspan := telemetry.Start(ctx, "core.processBlock")
+		statedb.SetReadSpan(pspan)
 		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		statedb.SetReadSpan(nil)
+		pspan.EndWithError(err)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			followupInterrupt.Store(true)
+			span.EndWithError(err)
 			return it.index, err
 		}
 		ptime := time.Since(pstart)
@@ -1743,9 +1782,11 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 		if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
 			bc.reportBlock(block, receipts, err)
 			followupInterrupt.Store(true)
+			span.EndWithError(err)
 			return it.index, err
 		}
 		vtime := time.Since(vstart)
+		span.RecordChild("core.validateState", vstart, vstart.Add(vtime))
 		proctime := time.Since(start) // processing + validation
 
 		// Update the metrics touched during block processing and validation
@@ -1777,8 +1818,12 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 		}
 		followupInterrupt.Store(true)
 		if err != nil {
+			span.EndWithError(err)
 			return it.index, err
 		}
+		span.RecordChild("core.writeBlock", wstart, time.Now())
+		span.End()
+
 		// Update the metrics touched during block commit
 		accountCommitTimer.Update(statedb.AccountCommits)   // Account commits are complete, we can mark them
 		storageCommitTimer.Update(statedb.StorageCommits)   // Storage commits are complete, we can mark them
@@ -2331,6 +2376,9 @@ func (bc *BlockChain) skipBlock(err error, it *insertIterator) bool {
 func (bc *BlockChain) indexBlocks(tail *uint64, head uint64, done chan struct{}) {
 	defer func() { close(done) }()
 
+	// Blocks with expired bodies cannot be indexed, never go below them.
+	floor := bc.HistoryTail()
+
 	// The tail flag is not existent, it means the node is just initialized
 	// and all blocks(may from ancient store) are not indexed yet.
 	if tail == nil {
@@ -2338,7 +2386,12 @@ func (bc *BlockChain) indexBlocks(tail *uint64, head uint64, done chan struct{})
 		if bc.txLookupLimit != 0 && head >= bc.txLookupLimit {
 			from = head - bc.txLookupLimit + 1
 		}
-		rawdb.IndexTransactions(bc.db, from, head+1, bc.quit)
+		if from < floor {
+			from = floor
+		}
+		if from <= head {
+			rawdb.IndexTransactions(bc.db, from, head+1, bc.quit)
+		}
 		return
 	}
 	// The tail flag is existent, but the whole chain is required to be indexed.
@@ -2351,17 +2404,30 @@ func (bc *BlockChain) indexBlocks(tail *uint64, head uint64, done chan struct{})
 			if end > head+1 {
 				end = head + 1
 			}
-			rawdb.IndexTransactions(bc.db, 0, end, bc.quit)
+			if floor < end {
+				rawdb.IndexTransactions(bc.db, floor, end, bc.quit)
+			}
 		}
 		return
 	}
 	// Update the transaction index to the new chain state
 	if head-bc.txLookupLimit+1 < *tail {
 		// Reindex a part of missing indices and rewind index tail to HEAD-limit
-		rawdb.IndexTransactions(bc.db, head-bc.txLookupLimit+1, *tail, bc.quit)
+		from := head - bc.txLookupLimit + 1
+		if from < floor {
+			from = floor
+		}
+		if from < *tail {
+			rawdb.IndexTransactions(bc.db, from, *tail, bc.quit)
+		}
 	} else {
-		// Unindex a part of stale indices and forward index tail to HEAD-limit
-		rawdb.UnindexTransactions(bc.db, *tail, head-bc.txLookupLimit+1, bc.quit)
+		// Unindex a part of stale indices and forward index tail to HEAD-limit.
+		// The indices of expired blocks were already deleted along with them.
+		from := *tail
+		if from < floor {
+			from = floor
+		}
+		rawdb.UnindexTransactions(bc.db, from, head-bc.txLookupLimit+1, bc.quit)
 	}
 }
 
@@ -2394,7 +2460,12 @@ func (bc *BlockChain) maintainTxIndex() {
 		case head := <-headCh:
 			if done == nil {
 				done = make(chan struct{})
-				go bc.indexBlocks(rawdb.ReadTxIndexTail(bc.db), head.Block.NumberU64(), done)
+				go func(head uint64) {
+					bc.txIndexLock.Lock()
+					defer bc.txIndexLock.Unlock()
+
+					bc.indexBlocks(rawdb.ReadTxIndexTail(bc.db), head, done)
+				}(head.Block.NumberU64())
 			}
 		case <-done:
 			done = nil
@@ -2408,6 +2479,74 @@ func (bc *BlockChain) maintainTxIndex() {
 	}
 }
 
+// HistoryTail returns the number of the first block whose body and receipts are
+// still retained. Anything below it was expired from the ancient store.
+func (bc *BlockChain) HistoryTail() uint64 {
+	tail, err := bc.db.Tail()
+	if err != nil {
+		return 0
+	}
+	return tail
+}
+
+// expireHistory drops the block bodies and receipts below the history cutoff
+// from the ancient store. Only frozen blocks are expired, the remainder follows
+// as soon as it becomes old enough to be moved into the freezer.
+func (bc *BlockChain) expireHistory() {
+	frozen, err := bc.db.Ancients()
+	if err != nil || frozen == 0 {
+		return
+	}
+	cutoff := bc.cacheConfig.HistoryCutoff
+	if cutoff > frozen {
+		cutoff = frozen
+	}
+	tail := bc.HistoryTail()
+	if tail >= cutoff {
+		return
+	}
+	// Delete the transaction indices of the blocks to be expired first, they
+	// cannot be unindexed anymore once the bodies are gone.
+	bc.txIndexLock.Lock()
+	defer bc.txIndexLock.Unlock()
+
+	start := time.Now()
+	if indexTail := rawdb.ReadTxIndexTail(bc.db); indexTail != nil && *indexTail < cutoff {
+		from := *indexTail
+		if from < tail {
+			from = tail
+		}
+		rawdb.UnindexTransactions(bc.db, from, cutoff, bc.quit)
+		if indexTail := rawdb.ReadTxIndexTail(bc.db); indexTail == nil || *indexTail < cutoff {
+			return // Interrupted, retry on the next run
+		}
+	}
+	if err := bc.db.TruncateTail(cutoff); err != nil {
+		log.Error("Failed to expire chain history", "cutoff", cutoff, "err", err)
+		return
+	}
+	log.Info("Expired chain history", "tail", cutoff, "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// maintainHistory is responsible for periodically expiring the block bodies and
+// receipts below the configured history cutoff as they get frozen.
+func (bc *BlockChain) maintainHistory() {
+	defer bc.wg.Done()
+
+	ticker := time.NewTicker(historyExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		bc.expireHistory()
+
+		select {
+		case <-ticker.C:
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
 // reportBlock logs a bad block error.
 func (bc *BlockChain) reportBlock(block *types.Block, receipts types.Receipts, err error) {
 	rawdb.WriteBadBlock(bc.db, block)
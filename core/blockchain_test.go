@@ -2058,9 +2058,9 @@ func testSideImport(t *testing.T, numCanonBlocksInSidechain, blocksBetweenCommon
 //	[ Cn, Cn+1, Cc, Sn+3 ... Sm]
 //	^    ^    ^  pruned
 func TestPrunedImportSide(t *testing.T) {
-	//glogger := log.NewGlogHandler(log.StreamHandler(os.Stdout, log.TerminalFormat(false)))
-	//glogger.Verbosity(3)
-	//log.Root().SetHandler(log.Handler(glogger))
+	//glogger := log.NewGlogHandler(log.NewTerminalHandler(os.Stdout, false))
+	//glogger.Verbosity(log.LevelInfo)
+	//log.SetDefault(log.NewLogger(glogger))
 	testSideImport(t, 3, 3, -1)
 	testSideImport(t, 3, -3, -1)
 	testSideImport(t, 10, 0, -1)
@@ -2069,9 +2069,9 @@ func TestPrunedImportSide(t *testing.T) {
 }
 
 func TestPrunedImportSideWithMerging(t *testing.T) {
-	//glogger := log.NewGlogHandler(log.StreamHandler(os.Stdout, log.TerminalFormat(false)))
-	//glogger.Verbosity(3)
-	//log.Root().SetHandler(log.Handler(glogger))
+	//glogger := log.NewGlogHandler(log.NewTerminalHandler(os.Stdout, false))
+	//glogger.Verbosity(log.LevelInfo)
+	//log.SetDefault(log.NewLogger(glogger))
 	testSideImport(t, 3, 3, 0)
 	testSideImport(t, 3, -3, 0)
 	testSideImport(t, 10, 0, 0)
@@ -2623,6 +2623,146 @@ func TestTransactionIndices(t *testing.T) {
 	}
 }
 
+// Tests that block bodies and receipts below the history cutoff are expired from
+// the ancient store, while the headers and the genesis block are retained.
+func TestHistoryExpiry(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: big.NewInt(100000000000000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 128, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x00}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	ancientDb, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create temp freezer db: %v", err)
+	}
+	defer ancientDb.Close()
+	rawdb.WriteAncientBlocks(ancientDb, append([]*types.Block{gspec.ToBlock()}, blocks...), append([]types.Receipts{{}}, receipts...), big.NewInt(0))
+	rawdb.IndexTransactions(ancientDb, 0, uint64(len(blocks))+1, nil)
+
+	config := *defaultCacheConfig
+	config.HistoryCutoff = 64
+
+	chain, err := NewBlockChain(ancientDb, &config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	chain.expireHistory()
+	if tail := chain.HistoryTail(); tail != 64 {
+		t.Fatalf("history tail mismatch: have %d, want %d", tail, 64)
+	}
+	for _, block := range append([]*types.Block{chain.Genesis()}, blocks...) {
+		number, hash := block.NumberU64(), block.Hash()
+		if chain.GetHeaderByHash(hash) == nil {
+			t.Errorf("block %d: header missing", number)
+		}
+		expired := number > 0 && number < 64
+		if body := chain.GetBodyRLP(hash); (len(body) == 0) != expired {
+			t.Errorf("block %d: body availability mismatch: have %v, want %v", number, len(body) != 0, !expired)
+		}
+		if has := rawdb.HasBody(ancientDb, hash, number); has == expired {
+			t.Errorf("block %d: body existence mismatch: have %v, want %v", number, has, !expired)
+		}
+		if has := rawdb.HasReceipts(ancientDb, hash, number); has == expired {
+			t.Errorf("block %d: receipts existence mismatch: have %v, want %v", number, has, !expired)
+		}
+		if number > 0 {
+			if receipts := chain.GetReceiptsByHash(hash); (receipts == nil) != expired {
+				t.Errorf("block %d: receipt availability mismatch: have %v, want %v", number, receipts != nil, !expired)
+			}
+		}
+		for _, tx := range block.Transactions() {
+			if lookup := rawdb.ReadTxLookupEntry(ancientDb, tx.Hash()); (lookup == nil) != expired {
+				t.Errorf("block %d: tx index availability mismatch: have %v, want %v", number, lookup != nil, !expired)
+			}
+		}
+	}
+	if tail := rawdb.ReadTxIndexTail(ancientDb); tail == nil || *tail != 64 {
+		t.Errorf("tx index tail mismatch: have %v, want %d", tail, 64)
+	}
+}
+
+// Tests that the state read by imported blocks is recorded if requested, and that
+// re-importing the blocks with the recorded read sets preloaded works.
+func TestBlockReadSets(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: big.NewInt(100000000000000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 8, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	config := *defaultCacheConfig
+	config.RecordReadSets = true
+
+	db := rawdb.NewMemoryDatabase()
+	chain, err := NewBlockChain(db, &config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	for i, block := range blocks {
+		reads := rawdb.ReadBlockReadSet(db, block.Hash(), block.NumberU64())
+		want := map[common.Address]bool{address: false, common.Address{byte(i)}: false, block.Coinbase(): false}
+		for _, tuple := range reads {
+			if _, ok := want[tuple.Address]; ok {
+				want[tuple.Address] = true
+			}
+		}
+		for addr, found := range want {
+			if !found {
+				t.Errorf("block %d: account %x missing from read set", block.NumberU64(), addr)
+			}
+		}
+	}
+	chain.Stop()
+
+	// Rewind the chain and import the blocks again, prefetching the read sets
+	chain, err = NewBlockChain(db, &config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to recreate tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if err := chain.SetHead(0); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if rawdb.ReadBlockReadSet(db, blocks[0].Hash(), blocks[0].NumberU64()) == nil {
+		t.Fatalf("read set deleted by rewind")
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to reimport chain: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", head.Hash(), blocks[len(blocks)-1].Hash())
+	}
+}
+
 func TestSkipStaleTxIndicesInSnapSync(t *testing.T) {
 	// Configure and generate a sample block chain
 	var (
@@ -3656,7 +3796,7 @@ func TestEIP1559Transition(t *testing.T) {
 // Tests the scenario the chain is requested to another point with the missing state.
 // It expects the state is recovered and all relevant chain markers are set correctly.
 func TestSetCanonical(t *testing.T) {
-	//log.Root().SetHandler(log.LvlFilterHandler(log.LvlDebug, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	//log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LvlDebug, true)))
 
 	var (
 		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
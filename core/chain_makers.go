@@ -310,6 +310,9 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(b.header.Number) == 0 {
 			misc.ApplyDAOHardFork(statedb)
 		}
+		if config.IsPrague(b.header.Number, b.header.Time) {
+			ProcessParentBlockHash(b.header.ParentHash, b.header.Number.Uint64()-1, statedb)
+		}
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
@@ -434,6 +434,13 @@ func isCanon(reader ethdb.AncientReaderOp, number uint64, hash common.Hash) bool
 	return bytes.Equal(h, hash[:])
 }
 
+// isExpired reports whether the body and receipts of the given ancient block were
+// removed from the freezer by history expiry.
+func isExpired(db ethdb.AncientReader, number uint64) bool {
+	tail, err := db.Tail()
+	return err == nil && number < tail
+}
+
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue {
 	// First try to look up the data in ancient database. Extra hash
@@ -444,9 +451,12 @@ func ReadBodyRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue
 		// Check if the data is in ancients
 		if isCanon(reader, number, hash) {
 			data, _ = reader.Ancient(ChainFreezerBodiesTable, number)
-			return nil
+			if len(data) > 0 {
+				return nil
+			}
 		}
-		// If not, try reading from leveldb
+		// If not, try reading from leveldb. The genesis is always retained there,
+		// even if the history in the ancients was expired.
 		data, _ = db.Get(blockBodyKey(number, hash))
 		return nil
 	})
@@ -481,7 +491,7 @@ func WriteBodyRLP(db ethdb.KeyValueWriter, hash common.Hash, number uint64, rlp
 
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db ethdb.Reader, hash common.Hash, number uint64) bool {
-	if isCanon(db, number, hash) {
+	if isCanon(db, number, hash) && !isExpired(db, number) {
 		return true
 	}
 	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
@@ -571,7 +581,7 @@ func DeleteTd(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 // HasReceipts verifies the existence of all the transaction receipts belonging
 // to a block.
 func HasReceipts(db ethdb.Reader, hash common.Hash, number uint64) bool {
-	if isCanon(db, number, hash) {
+	if isCanon(db, number, hash) && !isExpired(db, number) {
 		return true
 	}
 	if has, err := db.Has(blockReceiptsKey(number, hash)); !has || err != nil {
@@ -587,9 +597,12 @@ func ReadReceiptsRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawVa
 		// Check if the data is in ancients
 		if isCanon(reader, number, hash) {
 			data, _ = reader.Ancient(ChainFreezerReceiptTable, number)
-			return nil
+			if len(data) > 0 {
+				return nil
+			}
 		}
-		// If not, try reading from leveldb
+		// If not, try reading from leveldb. The genesis is always retained there,
+		// even if the history in the ancients was expired.
 		data, _ = db.Get(blockReceiptsKey(number, hash))
 		return nil
 	})
@@ -674,6 +687,40 @@ func DeleteReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadBlockReadSet retrieves the accounts and storage slots recorded as read by
+// the execution of a block, or nil if none were recorded.
+func ReadBlockReadSet(db ethdb.KeyValueReader, hash common.Hash, number uint64) types.AccessList {
+	data, _ := db.Get(blockReadSetKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var reads types.AccessList
+	if err := rlp.DecodeBytes(data, &reads); err != nil {
+		log.Error("Invalid block read set RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return reads
+}
+
+// WriteBlockReadSet stores the accounts and storage slots read by the execution
+// of a block.
+func WriteBlockReadSet(db ethdb.KeyValueWriter, hash common.Hash, number uint64, reads types.AccessList) {
+	data, err := rlp.EncodeToBytes(reads)
+	if err != nil {
+		log.Crit("Failed to encode block read set", "err", err)
+	}
+	if err := db.Put(blockReadSetKey(number, hash), data); err != nil {
+		log.Crit("Failed to store block read set", "err", err)
+	}
+}
+
+// DeleteBlockReadSet removes the read set recorded for a block.
+func DeleteBlockReadSet(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockReadSetKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block read set", "err", err)
+	}
+}
+
 // storedReceiptRLP is the storage encoding of a receipt.
 // Re-definition in core/types/receipt.go.
 // TODO: Re-use the existing definition.
@@ -815,6 +862,7 @@ func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *type
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteBlockReadSet(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -432,6 +432,28 @@ func checkReceiptsRLP(have, want types.Receipts) error {
 	return nil
 }
 
+// Tests block read set storage and retrieval operations.
+func TestBlockReadSetStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	hash := common.Hash{0x01}
+	if reads := ReadBlockReadSet(db, hash, 1); reads != nil {
+		t.Fatalf("non existent read set returned: %v", reads)
+	}
+	reads := types.AccessList{
+		{Address: common.Address{0x01}, StorageKeys: []common.Hash{}},
+		{Address: common.Address{0x02}, StorageKeys: []common.Hash{{0x01}, {0x02}}},
+	}
+	WriteBlockReadSet(db, hash, 1, reads)
+	if have := ReadBlockReadSet(db, hash, 1); !reflect.DeepEqual(have, reads) {
+		t.Fatalf("read set mismatch: have %v, want %v", have, reads)
+	}
+	DeleteBlock(db, hash, 1)
+	if reads := ReadBlockReadSet(db, hash, 1); reads != nil {
+		t.Fatalf("deleted read set returned: %v", reads)
+	}
+}
+
 func TestAncientStorage(t *testing.T) {
 	// Freezer style fast import the chain.
 	frdir := t.TempDir()
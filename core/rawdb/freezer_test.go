@@ -429,3 +429,63 @@ func TestFreezerCloseSync(t *testing.T) {
 		t.Fatalf("want %v, have %v", have, want)
 	}
 }
+
+// Tests that truncating the tail of a freezer with only a subset of prunable
+// tables leaves the rest intact, also across restarts.
+func TestFreezerPrunableTail(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir      = t.TempDir()
+		tables   = map[string]bool{"keep": true, "prune": true}
+		prunable = map[string]bool{"prune": true}
+	)
+	f, err := newFreezer(dir, "", false, 2049, tables, prunable)
+	if err != nil {
+		t.Fatal("can't open freezer", err)
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := 0; i < 100; i++ {
+			if err := op.AppendRaw("keep", uint64(i), getChunk(256, i)); err != nil {
+				return err
+			}
+			if err := op.AppendRaw("prune", uint64(i), getChunk(256, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("ModifyAncients failed:", err)
+	}
+	check := func(f *Freezer) {
+		t.Helper()
+
+		if tail, _ := f.Tail(); tail != 50 {
+			t.Fatalf("tail mismatch: have %d, want %d", tail, 50)
+		}
+		for i := uint64(0); i < 100; i++ {
+			if ok, _ := f.HasAncient("keep", i); !ok {
+				t.Fatalf("retained item %d missing", i)
+			}
+			if ok, _ := f.HasAncient("prune", i); ok != (i >= 50) {
+				t.Fatalf("pruned item %d availability mismatch: have %v, want %v", i, ok, i >= 50)
+			}
+		}
+	}
+	if err := f.TruncateTail(50); err != nil {
+		t.Fatal("TruncateTail failed:", err)
+	}
+	check(f)
+	f.Close()
+
+	// Reopen the freezer, both in read-write and read-only mode
+	for _, readonly := range []bool{false, true} {
+		f, err = newFreezer(dir, "", readonly, 2049, tables, prunable)
+		if err != nil {
+			t.Fatalf("can't reopen freezer (readonly %v): %v", readonly, err)
+		}
+		check(f)
+		f.Close()
+	}
+}
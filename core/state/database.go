@@ -109,6 +109,10 @@ type Trie interface {
 	// can be used even if the trie doesn't have one.
 	Hash() common.Hash
 
+	// Witness returns the set of trie nodes resolved from the database since the
+	// trie was opened or last committed, keyed by their RLP encoding.
+	Witness() map[string]struct{}
+
 	// Commit collects all dirty nodes in the trie and replace them with the
 	// corresponding node hash. All collected nodes(including dirty leaves if
 	// collectLeaf is true) will be encapsulated into a nodeset for return.
@@ -0,0 +1,206 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// StartReadRecording starts recording the accounts and storage slots read from
+// the state, which can be retrieved with ReadSet.
+func (s *StateDB) StartReadRecording() {
+	s.tracker = &accessTracker{
+		accounts: make(map[common.Address]struct{}),
+		slots:    make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+// ReadSet returns the accounts and storage slots read from the state since the
+// recording was started, sorted. Nil is returned if no recording is in progress.
+//
+// Note, resolving the recorded accounts and slots touches the exact same trie
+// nodes as the recorded execution, so there is no need to track those separately.
+func (s *StateDB) ReadSet() types.AccessList {
+	if s.tracker == nil || s.tracker.speculative {
+		return nil
+	}
+	reads := make(types.AccessList, 0, len(s.tracker.accounts))
+	for addr := range s.tracker.accounts {
+		tuple := types.AccessTuple{Address: addr, StorageKeys: []common.Hash{}}
+		for key := range s.tracker.slots[addr] {
+			tuple.StorageKeys = append(tuple.StorageKeys, key)
+		}
+		sort.Slice(tuple.StorageKeys, func(i, j int) bool {
+			return bytes.Compare(tuple.StorageKeys[i][:], tuple.StorageKeys[j][:]) < 0
+		})
+		reads = append(reads, tuple)
+	}
+	sort.Slice(reads, func(i, j int) bool {
+		return bytes.Compare(reads[i].Address[:], reads[j].Address[:]) < 0
+	})
+	return reads
+}
+
+// prefetchedAccount is an account with its code and storage slots loaded by
+// Prefetch, waiting to be inserted into the live objects.
+type prefetchedAccount struct {
+	data    *types.StateAccount
+	code    []byte
+	storage map[common.Hash]common.Hash
+}
+
+// Prefetch loads the given accounts along with their code and storage slots into
+// the state, using the given number of goroutines. It is meant to preload a read
+// set recorded during an earlier execution of a block, so executing it again does
+// not need to wait for the database sequentially.
+//
+// Prefetch must be called on a fresh state, before it is modified. Any failure is
+// ignored, leaving the account to be loaded on demand during execution.
+func (s *StateDB) Prefetch(reads types.AccessList, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		loaded = make([]*prefetchedAccount, len(reads))
+		next   atomic.Int32
+		wg     sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		tr := s.db.CopyTrie(s.trie)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(reads) {
+					return
+				}
+				loaded[i] = s.prefetchAccount(tr, reads[i].Address, reads[i].StorageKeys)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, acc := range loaded {
+		if acc == nil {
+			continue
+		}
+		addr := reads[i].Address
+		if _, ok := s.stateObjects[addr]; ok {
+			continue
+		}
+		obj := newObject(s, addr, *acc.data)
+		obj.code = acc.code
+		for key, value := range acc.storage {
+			obj.originStorage[key] = value
+		}
+		s.setStateObject(obj)
+	}
+}
+
+// prefetchAccount loads an account with its code and the requested storage slots
+// from the snapshot, or the database if unavailable. The same sources are used
+// as for loading the account on demand, but the method is safe for concurrent
+// use. Nil is returned if the account does not exist or loading it failed.
+func (s *StateDB) prefetchAccount(tr Trie, addr common.Address, keys []common.Hash) *prefetchedAccount {
+	var (
+		addrHash = crypto.Keccak256Hash(addr[:])
+		data     *types.StateAccount
+	)
+	if s.snap != nil {
+		if acc, err := s.snap.Account(addrHash); err == nil {
+			if acc == nil {
+				return nil
+			}
+			data = &types.StateAccount{
+				Nonce:    acc.Nonce,
+				Balance:  acc.Balance,
+				CodeHash: acc.CodeHash,
+				Root:     common.BytesToHash(acc.Root),
+			}
+			if len(data.CodeHash) == 0 {
+				data.CodeHash = types.EmptyCodeHash.Bytes()
+			}
+			if data.Root == (common.Hash{}) {
+				data.Root = types.EmptyRootHash
+			}
+		}
+	}
+	if data == nil {
+		var err error
+		if data, err = tr.GetAccount(addr); err != nil || data == nil {
+			return nil
+		}
+	}
+	acc := &prefetchedAccount{
+		data:    data,
+		storage: make(map[common.Hash]common.Hash, len(keys)),
+	}
+	if codeHash := common.BytesToHash(data.CodeHash); codeHash != types.EmptyCodeHash {
+		if code, err := s.db.ContractCode(addr, codeHash); err == nil {
+			acc.code = code
+		}
+	}
+	// Storage of accounts without any is known to be empty
+	if data.Root == types.EmptyRootHash {
+		for _, key := range keys {
+			acc.storage[key] = common.Hash{}
+		}
+		return acc
+	}
+	var storageTrie Trie
+	for _, key := range keys {
+		var value common.Hash
+		if s.snap != nil {
+			enc, err := s.snap.Storage(addrHash, crypto.Keccak256Hash(key[:]))
+			if err == nil {
+				if len(enc) > 0 {
+					_, content, _, err := rlp.Split(enc)
+					if err != nil {
+						continue
+					}
+					value.SetBytes(content)
+				}
+				acc.storage[key] = value
+				continue
+			}
+		}
+		if storageTrie == nil {
+			var err error
+			if storageTrie, err = s.db.OpenStorageTrie(s.originalRoot, addr, data.Root); err != nil {
+				return acc
+			}
+		}
+		val, err := storageTrie.GetStorage(addr, key[:])
+		if err != nil {
+			continue
+		}
+		value.SetBytes(val)
+		acc.storage[key] = value
+	}
+	return acc
+}
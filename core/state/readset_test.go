@@ -0,0 +1,111 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that the recorded read set contains exactly the accounts and slots read,
+// and that prefetching it loads the same data as reading it on demand.
+func TestReadSetPrefetch(t *testing.T) {
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	state, _ := New(types.EmptyRootHash, db, nil)
+	for i := byte(0); i < 16; i++ {
+		addr := common.Address{i}
+		state.SetBalance(addr, big.NewInt(int64(i)+1))
+		state.SetCode(addr, []byte{i, i})
+		for j := byte(0); j < 4; j++ {
+			state.SetState(addr, common.Hash{j}, common.Hash{i, j + 1})
+		}
+	}
+	root, _ := state.Commit(false)
+
+	// Read a subset of the state, including missing accounts and slots
+	state, _ = New(root, db, nil)
+	state.StartReadRecording()
+
+	var (
+		accounts = []common.Address{{0x01}, {0x05}, {0x0a}, {0xff}}
+		slots    = []common.Hash{{0x00}, {0x03}, {0xff}}
+	)
+	for _, addr := range accounts {
+		state.GetBalance(addr)
+		for _, key := range slots {
+			state.GetState(addr, key)
+		}
+	}
+	reads := state.ReadSet()
+	if len(reads) != len(accounts) {
+		t.Fatalf("read account count mismatch: have %d, want %d", len(reads), len(accounts))
+	}
+	for i, tuple := range reads {
+		if tuple.Address != accounts[i] {
+			t.Errorf("read account %d mismatch: have %x, want %x", i, tuple.Address, accounts[i])
+		}
+		// Slots of missing accounts are never read
+		want := len(slots)
+		if tuple.Address == (common.Address{0xff}) {
+			want = 0
+		}
+		if len(tuple.StorageKeys) != want {
+			t.Errorf("read account %x slot count mismatch: have %d, want %d", tuple.Address, len(tuple.StorageKeys), want)
+		}
+	}
+	// Prefetch the read set into a fresh state and check that it is loaded
+	prefetched, _ := New(root, db, nil)
+	prefetched.Prefetch(reads, 3)
+
+	for _, tuple := range reads {
+		obj := prefetched.stateObjects[tuple.Address]
+		if tuple.Address == (common.Address{0xff}) {
+			if obj != nil {
+				t.Errorf("missing account %x prefetched", tuple.Address)
+			}
+			continue
+		}
+		if obj == nil {
+			t.Fatalf("account %x not prefetched", tuple.Address)
+		}
+		if obj.code == nil {
+			t.Errorf("account %x code not prefetched", tuple.Address)
+		}
+		for _, key := range tuple.StorageKeys {
+			if _, ok := obj.originStorage[key]; !ok {
+				t.Errorf("account %x slot %x not prefetched", tuple.Address, key)
+			}
+		}
+	}
+	for _, addr := range accounts {
+		if have, want := prefetched.GetBalance(addr), state.GetBalance(addr); have.Cmp(want) != 0 {
+			t.Errorf("account %x balance mismatch: have %v, want %v", addr, have, want)
+		}
+		if have, want := prefetched.GetCode(addr), state.GetCode(addr); string(have) != string(want) {
+			t.Errorf("account %x code mismatch: have %x, want %x", addr, have, want)
+		}
+		for _, key := range slots {
+			if have, want := prefetched.GetState(addr, key), state.GetState(addr, key); have != want {
+				t.Errorf("account %x slot %x mismatch: have %x, want %x", addr, key, have, want)
+			}
+		}
+	}
+}
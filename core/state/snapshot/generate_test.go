@@ -544,7 +544,7 @@ func TestGenerateWithExtraAccounts(t *testing.T) {
 }
 
 func enableLogging() {
-	log.Root().SetHandler(log.LvlFilterHandler(log.LvlTrace, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LvlTrace, true)))
 }
 
 // Tests that snapshot generation when an extra account with storage exists in the snap state.
@@ -0,0 +1,381 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// accessTracker records the accounts and storage slots read during the
+// speculative execution of a transaction, or during the processing of a block
+// if its read set is recorded.
+//
+// In speculative executions, balance additions to the block's coinbase are not
+// applied to the state but accumulated separately, otherwise the fee payment would
+// make every transaction of a block conflict with all the previous ones.
+type accessTracker struct {
+	revision int                                         // Snapshot to revert to when the speculation ends
+	accounts map[common.Address]struct{}                 // Accounts read by the transaction
+	slots    map[common.Address]map[common.Hash]struct{} // Storage slots read by the transaction
+
+	speculative   bool           // Whether the state is a speculative copy
+	coinbase      common.Address // Account whose balance additions are deferred
+	coinbaseRead  bool           // Whether the coinbase was accessed other than by an addition
+	coinbaseDelta *big.Int       // Deferred balance addition to the coinbase, nil if none
+}
+
+// trackAccount marks an account read by the speculative execution.
+func (t *accessTracker) trackAccount(addr common.Address) {
+	if addr == t.coinbase {
+		t.coinbaseRead = true
+	}
+	t.accounts[addr] = struct{}{}
+}
+
+// trackSlot marks a storage slot read by the speculative execution.
+func (t *accessTracker) trackSlot(addr common.Address, key common.Hash) {
+	slots, ok := t.slots[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		t.slots[addr] = slots
+	}
+	slots[key] = struct{}{}
+}
+
+// coinbaseChange is the journal entry of a deferred coinbase balance addition,
+// needed to drop the addition if the call frame making it is reverted.
+type coinbaseChange struct {
+	prev *big.Int
+}
+
+func (ch coinbaseChange) revert(s *StateDB) {
+	s.tracker.coinbaseDelta = ch.prev
+}
+
+func (ch coinbaseChange) dirtied() *common.Address {
+	return nil
+}
+
+// speculativeAccount is the modification a speculatively executed transaction
+// made to a single account.
+type speculativeAccount struct {
+	created        bool // The account was (re)created, wiping any previous storage
+	suicided       bool // The account self destructed
+	balanceChanged bool // The balance was modified
+	nonceChanged   bool // The nonce was modified
+	codeChanged    bool // The code was modified
+
+	data    types.StateAccount          // Account fields after the transaction
+	code    []byte                      // Contract code after the transaction, if modified
+	storage map[common.Hash]common.Hash // Storage slots modified by the transaction
+}
+
+// Speculation is the outcome of executing a transaction speculatively on a
+// private copy of the state: the accounts and storage slots it read, and the
+// modifications it made. If none of the reads were written by the transactions
+// preceding it in the block, the modifications are exactly what executing the
+// transaction on the canonical state would produce, and can be merged into it.
+type Speculation struct {
+	reads    map[common.Address]struct{}
+	slots    map[common.Address]map[common.Hash]struct{}
+	accounts map[common.Address]*speculativeAccount
+
+	logs      []*types.Log
+	preimages map[common.Hash][]byte
+
+	coinbase     common.Address
+	coinbaseRead bool
+	coinbaseAdd  *big.Int
+
+	readStats [readKinds]readStat // Database reads done during the speculation
+	err       error               // Database failure during the speculation, invalidating it
+}
+
+// Valid reports whether the speculation can be merged into a state already
+// containing the given modifications of the preceding transactions.
+func (sp *Speculation) Valid(writes *WriteSet) bool {
+	if sp.err != nil || sp.coinbaseRead {
+		return false
+	}
+	for addr := range sp.reads {
+		if _, ok := writes.accounts[addr]; ok {
+			return false
+		}
+	}
+	for addr, slots := range sp.slots {
+		written := writes.slots[addr]
+		if len(written) == 0 {
+			continue
+		}
+		for key := range slots {
+			if _, ok := written[key]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// WriteSet is the set of accounts and storage slots modified by a sequence of
+// transactions, against which speculations of later transactions are validated.
+type WriteSet struct {
+	accounts map[common.Address]struct{}                 // Accounts with modified fields, or (re)created or destructed
+	slots    map[common.Address]map[common.Hash]struct{} // Modified storage slots
+}
+
+// NewWriteSet creates an empty write set.
+func NewWriteSet() *WriteSet {
+	return &WriteSet{
+		accounts: make(map[common.Address]struct{}),
+		slots:    make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+// addSlot marks a storage slot modified.
+func (w *WriteSet) addSlot(addr common.Address, key common.Hash) {
+	slots, ok := w.slots[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		w.slots[addr] = slots
+	}
+	slots[key] = struct{}{}
+}
+
+// SpeculativeCopy creates a copy of the state to execute transactions on
+// speculatively. The returned state is independent and may be used concurrently
+// with the original, but it should only be used via BeginSpeculation and
+// EndSpeculation. Balance additions to the given coinbase are deferred.
+func (s *StateDB) SpeculativeCopy(coinbase common.Address) *StateDB {
+	// The prefetcher is not needed for the copy as it never hashes the tries,
+	// avoid the overhead of copying it
+	prefetcher := s.prefetcher
+	s.prefetcher = nil
+	state := s.Copy()
+	s.prefetcher = prefetcher
+
+	// Copy already loaded clean objects too (e.g. preloaded read sets), to avoid
+	// every copy hitting the database for them again
+	for addr, obj := range s.stateObjects {
+		if _, exist := state.stateObjects[addr]; !exist {
+			state.stateObjects[addr] = obj.deepCopy(state)
+		}
+	}
+
+	// Account the database reads of the copy on the read span of the state too,
+	// they are merged back along with the speculations
+	state.readSpan = s.readSpan
+
+	state.tracker = &accessTracker{speculative: true, coinbase: coinbase}
+	return state
+}
+
+// BeginSpeculation starts tracking the state accesses of a transaction to be
+// executed speculatively.
+func (s *StateDB) BeginSpeculation() {
+	s.tracker.revision = s.Snapshot()
+	s.tracker.accounts = make(map[common.Address]struct{})
+	s.tracker.slots = make(map[common.Address]map[common.Hash]struct{})
+	s.tracker.coinbaseRead = false
+	s.tracker.coinbaseDelta = nil
+	s.readStats = [readKinds]readStat{}
+}
+
+// EndSpeculation collects the accesses and modifications of the speculatively
+// executed transaction, and reverts the state to before its execution.
+func (s *StateDB) EndSpeculation() *Speculation {
+	sp := &Speculation{
+		reads:        s.tracker.accounts,
+		slots:        s.tracker.slots,
+		accounts:     make(map[common.Address]*speculativeAccount),
+		preimages:    make(map[common.Hash][]byte),
+		coinbase:     s.tracker.coinbase,
+		coinbaseRead: s.tracker.coinbaseRead,
+		readStats:    s.readStats,
+		err:          s.dbErr,
+	}
+	if s.tracker.coinbaseDelta != nil {
+		sp.coinbaseAdd = new(big.Int).Set(s.tracker.coinbaseDelta)
+	}
+	account := func(addr common.Address) *speculativeAccount {
+		acc, ok := sp.accounts[addr]
+		if !ok {
+			acc = &speculativeAccount{storage: make(map[common.Hash]common.Hash)}
+			sp.accounts[addr] = acc
+		}
+		return acc
+	}
+	for _, entry := range s.journal.entries {
+		switch ch := entry.(type) {
+		case createObjectChange:
+			account(*ch.account).created = true
+		case resetObjectChange:
+			account(*ch.account).created = true
+		case suicideChange:
+			account(*ch.account).suicided = true
+		case balanceChange:
+			account(*ch.account).balanceChanged = true
+		case nonceChange:
+			account(*ch.account).nonceChanged = true
+		case codeChange:
+			account(*ch.account).codeChanged = true
+		case storageChange:
+			account(*ch.account).storage[ch.key] = common.Hash{}
+		case addPreimageChange:
+			sp.preimages[ch.hash] = s.preimages[ch.hash]
+		}
+	}
+	// Touched accounts are only tracked in the dirty set, which may also contain
+	// accounts with no entries left (ripemd, see Finalise)
+	for addr := range s.journal.dirties {
+		account(addr)
+	}
+	for addr, acc := range sp.accounts {
+		obj := s.stateObjects[addr]
+		if obj == nil {
+			continue
+		}
+		acc.data = obj.data
+		acc.data.Balance = new(big.Int).Set(obj.data.Balance)
+		if acc.codeChanged || acc.created {
+			acc.code = obj.code
+		}
+		if acc.created {
+			for key, value := range obj.dirtyStorage {
+				acc.storage[key] = value
+			}
+		} else {
+			for key := range acc.storage {
+				acc.storage[key] = obj.dirtyStorage[key]
+			}
+		}
+	}
+	for _, l := range s.logs[s.thash] {
+		cpy := new(types.Log)
+		*cpy = *l
+		sp.logs = append(sp.logs, cpy)
+	}
+	// Revert the modifications and start the next speculation with a clean
+	// journal, so stale dirty markers don't leak into it
+	s.RevertToSnapshot(s.tracker.revision)
+	s.journal = newJournal()
+	s.validRevisions = s.validRevisions[:0]
+
+	return sp
+}
+
+// ApplySpeculation merges the modifications of a speculatively executed
+// transaction into the state. The speculation must have been validated against
+// the modifications of the preceding transactions. The modifications are added
+// to the journal the same way executing the transaction would, so Finalise and
+// IntermediateRoot produce the same results.
+func (s *StateDB) ApplySpeculation(sp *Speculation) {
+	if s.readSpan.IsRecording() {
+		for kind, stat := range sp.readStats {
+			s.readStats[kind].count += stat.count
+			s.readStats[kind].time += stat.time
+		}
+	}
+	// If the read set of the state is recorded, carry over the speculative reads
+	if s.tracker != nil {
+		for addr := range sp.reads {
+			s.tracker.trackAccount(addr)
+		}
+		for addr, slots := range sp.slots {
+			for key := range slots {
+				s.tracker.trackSlot(addr, key)
+			}
+		}
+	}
+	for addr, acc := range sp.accounts {
+		if acc.created {
+			obj, _ := s.createObject(addr)
+			obj.setNonce(acc.data.Nonce)
+			obj.setBalance(new(big.Int).Set(acc.data.Balance))
+			if acc.code != nil {
+				obj.setCode(common.BytesToHash(acc.data.CodeHash), acc.code)
+			}
+			for key, value := range acc.storage {
+				obj.setState(key, value)
+			}
+			if acc.suicided {
+				s.Suicide(addr)
+				obj.SetBalance(new(big.Int).Set(acc.data.Balance))
+			}
+			continue
+		}
+		obj := s.getStateObject(addr)
+		if obj == nil {
+			// Touched but non-existent accounts have no effect beyond the
+			// dirty marker, which is ignored by Finalise anyway
+			s.journal.dirty(addr)
+			continue
+		}
+		if acc.suicided {
+			s.Suicide(addr)
+		}
+		if acc.balanceChanged || acc.suicided {
+			obj.SetBalance(new(big.Int).Set(acc.data.Balance))
+		}
+		if acc.nonceChanged {
+			obj.SetNonce(acc.data.Nonce)
+		}
+		if acc.codeChanged {
+			obj.SetCode(common.BytesToHash(acc.data.CodeHash), acc.code)
+		}
+		for key, value := range acc.storage {
+			obj.SetState(s.db, key, value)
+		}
+		s.journal.dirty(addr)
+	}
+	for _, l := range sp.logs {
+		cpy := new(types.Log)
+		*cpy = *l
+		s.AddLog(cpy)
+	}
+	for hash, preimage := range sp.preimages {
+		s.AddPreimage(hash, preimage)
+	}
+	if sp.coinbaseAdd != nil {
+		s.AddBalance(sp.coinbase, sp.coinbaseAdd)
+	}
+}
+
+// RecordWrites adds the accounts and storage slots modified since the last
+// Finalise to the write set. It must be called before finalising the state.
+func (s *StateDB) RecordWrites(writes *WriteSet) {
+	journaled := make(map[common.Address]struct{})
+	for _, entry := range s.journal.entries {
+		switch ch := entry.(type) {
+		case createObjectChange, resetObjectChange, suicideChange, balanceChange, nonceChange, codeChange, touchChange:
+			writes.accounts[*ch.dirtied()] = struct{}{}
+		case storageChange:
+			writes.addSlot(*ch.account, ch.key)
+		}
+		if addr := entry.dirtied(); addr != nil {
+			journaled[*addr] = struct{}{}
+		}
+	}
+	// Accounts only present in the dirty set were touched, which may delete them
+	for addr := range s.journal.dirties {
+		if _, ok := journaled[addr]; !ok {
+			writes.accounts[addr] = struct{}{}
+		}
+	}
+}
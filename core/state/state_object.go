@@ -148,12 +148,18 @@ func (s *stateObject) getTrie(db Database) (Trie, error) {
 			}
 			s.trie = tr
 		}
+		if s.db.witness != nil {
+			s.db.witnessTries = append(s.db.witnessTries, s.trie)
+		}
 	}
 	return s.trie, nil
 }
 
 // GetState retrieves a value from the account storage trie.
 func (s *stateObject) GetState(db Database, key common.Hash) common.Hash {
+	if s.db.tracker != nil {
+		s.db.tracker.trackSlot(s.address, key)
+	}
 	// If we have a dirty value for this state entry, return it
 	value, dirty := s.dirtyStorage[key]
 	if dirty {
@@ -165,6 +171,9 @@ func (s *stateObject) GetState(db Database, key common.Hash) common.Hash {
 
 // GetCommittedState retrieves a value from the committed account storage trie.
 func (s *stateObject) GetCommittedState(db Database, key common.Hash) common.Hash {
+	if s.db.tracker != nil {
+		s.db.tracker.trackSlot(s.address, key)
+	}
 	// If we have a pending write or clean cached, return that
 	if value, pending := s.pendingStorage[key]; pending {
 		return value
@@ -193,6 +202,7 @@ func (s *stateObject) GetCommittedState(db Database, key common.Hash) common.Has
 		if metrics.EnabledExpensive {
 			s.db.SnapshotStorageReads += time.Since(start)
 		}
+		s.db.traceRead(readSnapshotStorage, start)
 		if len(enc) > 0 {
 			_, content, _, err := rlp.Split(enc)
 			if err != nil {
@@ -213,6 +223,7 @@ func (s *stateObject) GetCommittedState(db Database, key common.Hash) common.Has
 		if metrics.EnabledExpensive {
 			s.db.StorageReads += time.Since(start)
 		}
+		s.db.traceRead(readTrieStorage, start)
 		if err != nil {
 			s.db.setError(err)
 			return common.Hash{}
@@ -445,6 +456,9 @@ func (s *stateObject) Code(db Database) []byte {
 	if err != nil {
 		s.db.setError(fmt.Errorf("can't load code hash %x: %v", s.CodeHash(), err))
 	}
+	if s.db.witness != nil {
+		s.db.witness.AddCode(code)
+	}
 	s.code = code
 	return code
 }
@@ -459,6 +473,10 @@ func (s *stateObject) CodeSize(db Database) int {
 	if bytes.Equal(s.CodeHash(), types.EmptyCodeHash.Bytes()) {
 		return 0
 	}
+	// Stateless execution needs the entire code to derive its size
+	if s.db.witness != nil {
+		return len(s.Code(db))
+	}
 	size, err := db.ContractCodeSize(s.address, common.BytesToHash(s.CodeHash()))
 	if err != nil {
 		s.db.setError(fmt.Errorf("can't load code size %x: %v", s.CodeHash(), err))
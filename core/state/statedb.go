@@ -27,8 +27,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
@@ -110,6 +112,18 @@ type StateDB struct {
 	// Transient storage
 	transientStorage transientStorage
 
+	// Access tracker of speculative executions or read set recordings, nil otherwise
+	tracker *accessTracker
+
+	// Trace span under which database reads are recorded, if any, and the read
+	// counts and durations accumulated since it was set
+	readSpan  *telemetry.Span
+	readStats [readKinds]readStat
+
+	// Execution witness being collected and the storage tries opened for it
+	witness      *stateless.Witness
+	witnessTries []Trie
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -189,6 +203,65 @@ func (s *StateDB) StopPrefetcher() {
 	}
 }
 
+// SetReadSpan sets the trace span under which the account and storage reads from
+// the snapshot and the tries are recorded. The number and total duration of the
+// reads of each kind are set as attributes of the previous span, if any, so it
+// must be replaced or cleared before being ended. Passing nil stops recording
+// them. The span is only inherited by speculative copies of the state, whose
+// reads are accounted once their speculations are applied.
+func (s *StateDB) SetReadSpan(span *telemetry.Span) {
+	if s.readSpan.IsRecording() {
+		attrs := make([]telemetry.Attribute, 0, 2*readKinds)
+		for kind, stat := range s.readStats {
+			if stat.count == 0 {
+				continue
+			}
+			attrs = append(attrs,
+				telemetry.Int(readKindNames[kind]+".count", stat.count),
+				telemetry.Int64(readKindNames[kind]+".time_us", stat.time.Microseconds()),
+			)
+		}
+		s.readSpan.SetAttributes(attrs...)
+	}
+	s.readSpan = span
+	s.readStats = [readKinds]readStat{}
+}
+
+// readKind enumerates the database reads accounted for on the read span.
+type readKind int
+
+const (
+	readSnapshotAccount readKind = iota
+	readSnapshotStorage
+	readTrieAccount
+	readTrieStorage
+	readKinds
+)
+
+// readKindNames are the attribute name prefixes of the read kinds.
+var readKindNames = [readKinds]string{
+	readSnapshotAccount: "state.snapshot.account",
+	readSnapshotStorage: "state.snapshot.storage",
+	readTrieAccount:     "state.trie.account",
+	readTrieStorage:     "state.trie.storage",
+}
+
+// readStat is the number and total duration of the reads of a kind.
+type readStat struct {
+	count int
+	time  time.Duration
+}
+
+// traceRead accounts a database read started at the given time, if a read span
+// is being recorded.
+func (s *StateDB) traceRead(kind readKind, start time.Time) {
+	if !s.readSpan.IsRecording() {
+		return
+	}
+	s.readStats[kind].count++
+	s.readStats[kind].time += time.Since(start)
+}
+
 // setError remembers the first non-nil error it is called with.
 func (s *StateDB) setError(err error) {
 	if s.dbErr == nil {
@@ -402,6 +475,16 @@ func (s *StateDB) HasSuicided(addr common.Address) bool {
 
 // AddBalance adds amount to the account associated with addr.
 func (s *StateDB) AddBalance(addr common.Address, amount *big.Int) {
+	// Defer the coinbase additions of speculative executions, see accessTracker
+	if s.tracker != nil && s.tracker.speculative && addr == s.tracker.coinbase {
+		s.journal.append(coinbaseChange{prev: s.tracker.coinbaseDelta})
+		delta := new(big.Int).Set(amount)
+		if s.tracker.coinbaseDelta != nil {
+			delta.Add(delta, s.tracker.coinbaseDelta)
+		}
+		s.tracker.coinbaseDelta = delta
+		return
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.AddBalance(amount)
@@ -560,6 +643,9 @@ func (s *StateDB) getStateObject(addr common.Address) *stateObject {
 // flag set. This is needed by the state journal to revert to the correct s-
 // destructed object instead of wiping all knowledge about the state object.
 func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
+	if s.tracker != nil {
+		s.tracker.trackAccount(addr)
+	}
 	// Prefer live objects if any is available
 	if obj := s.stateObjects[addr]; obj != nil {
 		return obj
@@ -572,6 +658,7 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 		if metrics.EnabledExpensive {
 			s.SnapshotAccountReads += time.Since(start)
 		}
+		s.traceRead(readSnapshotAccount, start)
 		if err == nil {
 			if acc == nil {
 				return nil
@@ -598,6 +685,7 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 		if metrics.EnabledExpensive {
 			s.AccountReads += time.Since(start)
 		}
+		s.traceRead(readTrieAccount, start)
 		if err != nil {
 			s.setError(fmt.Errorf("getDeleteStateObject (%x) error: %w", addr.Bytes(), err))
 			return nil
@@ -0,0 +1,42 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import "github.com/ethereum/go-ethereum/core/stateless"
+
+// StartWitness starts collecting the trie nodes and contract codes accessed
+// through the state into the given witness. It must be called on a fresh state
+// opened without snapshots or prefetching, as data retrieved through those
+// bypasses the tries and would be missing from the witness.
+func (s *StateDB) StartWitness(witness *stateless.Witness) {
+	s.witness = witness
+	s.witnessTries = nil
+}
+
+// Witness adds the trie nodes resolved so far to the witness being collected
+// and returns it, or nil if no witness is being collected. To capture the nodes
+// needed for hashing too, it should be called after IntermediateRoot.
+func (s *StateDB) Witness() *stateless.Witness {
+	if s.witness == nil {
+		return nil
+	}
+	s.witness.AddState(s.trie.Witness())
+	for _, tr := range s.witnessTries {
+		s.witness.AddState(tr.Witness())
+	}
+	return s.witness
+}
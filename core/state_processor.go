@@ -37,16 +37,26 @@ import (
 // StateProcessor implements Processor.
 type StateProcessor struct {
 	config *params.ChainConfig // Chain configuration options
-	bc     *BlockChain         // Canonical block chain
+	bc     processorChain      // Canonical block chain, or the witness of a stateless block
 	engine consensus.Engine    // Consensus engine used for block rewards
+
+	workers int // Number of goroutines speculatively executing transactions (0 = sequential)
+}
+
+// processorChain is the chain access needed to process a block: the ancestor
+// headers for the BLOCKHASH opcode and the ones needed to finalize the block.
+type processorChain interface {
+	ChainContext
+	consensus.ChainHeaderReader
 }
 
 // NewStateProcessor initialises a new StateProcessor.
 func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consensus.Engine) *StateProcessor {
 	return &StateProcessor{
-		config: config,
-		bc:     bc,
-		engine: engine,
+		config:  config,
+		bc:      bc,
+		engine:  engine,
+		workers: bc.cacheConfig.ParallelWorkers,
 	}
 }
 
@@ -71,24 +81,36 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	if p.config.IsPrague(block.Number(), block.Time()) {
+		ProcessParentBlockHash(block.ParentHash(), block.NumberU64()-1, statedb)
+	}
 	var (
 		context = NewEVMBlockContext(header, p.bc, nil)
 		vmenv   = vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
 		signer  = types.MakeSigner(p.config, header.Number, header.Time)
 	)
-	// Iterate over and process the individual transactions
-	for i, tx := range block.Transactions() {
-		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+	// Iterate over and process the individual transactions, speculatively in
+	// parallel if enabled. Tracers need to observe the execution in order, so
+	// they always run sequentially.
+	if p.workers > 1 && cfg.Tracer == nil && len(block.Transactions()) > 1 {
+		var err error
+		if receipts, allLogs, err = p.processParallel(block, statedb, cfg, gp, usedGas, vmenv, signer); err != nil {
+			return nil, nil, 0, err
 		}
-		statedb.SetTxContext(tx.Hash(), i)
-		receipt, err := applyTransaction(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+	} else {
+		for i, tx := range block.Transactions() {
+			msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			statedb.SetTxContext(tx.Hash(), i)
+			receipt, err := applyTransaction(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			receipts = append(receipts, receipt)
+			allLogs = append(allLogs, receipt.Logs...)
 		}
-		receipts = append(receipts, receipt)
-		allLogs = append(allLogs, receipt.Logs...)
 	}
 	// Fail if Shanghai not enabled and len(withdrawals) is non-zero.
 	withdrawals := block.Withdrawals()
@@ -101,6 +123,16 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	return receipts, allLogs, *usedGas, nil
 }
 
+// ProcessParentBlockHash stores the parent block hash in the history storage
+// before the transactions of a block are executed, as per EIP-2935. The history
+// account is given a nonce so the storage is not cleared as an empty account.
+func ProcessParentBlockHash(prevHash common.Hash, prevNumber uint64, statedb vm.StateDB) {
+	if statedb.Empty(params.HistoryStorageAddress) {
+		statedb.SetNonce(params.HistoryStorageAddress, 1)
+	}
+	statedb.SetState(params.HistoryStorageAddress, vm.HistoryStorageSlot(prevNumber), prevHash)
+}
+
 func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
 	// Create a new context to be used in the EVM environment.
 	txContext := NewEVMTxContext(msg)
@@ -111,7 +143,12 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	if err != nil {
 		return nil, err
 	}
+	return makeReceipt(msg, result, config, statedb, blockNumber, blockHash, tx, usedGas), nil
+}
 
+// makeReceipt finalises the state changes of an executed transaction and creates
+// its receipt.
+func makeReceipt(msg *Message, result *ExecutionResult, config *params.ChainConfig, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64) *types.Receipt {
 	// Update the state with pending changes.
 	var root []byte
 	if config.IsByzantium(blockNumber) {
@@ -134,7 +171,7 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 
 	// If the transaction created a contract, store the creation address in the receipt.
 	if msg.To == nil {
-		receipt.ContractAddress = crypto.CreateAddress(evm.TxContext.Origin, tx.Nonce())
+		receipt.ContractAddress = crypto.CreateAddress(msg.From, tx.Nonce())
 	}
 
 	// Set the receipt logs and create the bloom filter.
@@ -143,7 +180,7 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	receipt.BlockHash = blockHash
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
-	return receipt, err
+	return receipt
 }
 
 // ApplyTransaction attempts to apply a transaction to the given state database
@@ -0,0 +1,139 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	parallelMergedMeter     = metrics.NewRegisteredMeter("chain/parallel/merged", nil)
+	parallelReexecutedMeter = metrics.NewRegisteredMeter("chain/parallel/reexecuted", nil)
+)
+
+// speculativeTx is the outcome of the speculative execution of a transaction.
+type speculativeTx struct {
+	spec   *state.Speculation
+	result *ExecutionResult
+	err    error
+	done   chan struct{} // Closed when the speculation finished
+}
+
+// processParallel applies the transactions of a block using optimistic
+// concurrency: every transaction is executed speculatively on a private copy of
+// the pre-block state by a pool of workers, tracking the accounts and storage
+// slots it reads. The speculations are then merged into the canonical state in
+// block order. Any transaction that read something modified by a preceding one
+// is executed again on the canonical state, so the resulting state and receipts
+// are identical to sequential processing.
+func (p *StateProcessor) processParallel(block *types.Block, statedb *state.StateDB, cfg vm.Config, gp *GasPool, usedGas *uint64, vmenv *vm.EVM, signer types.Signer) (types.Receipts, []*types.Log, error) {
+	var (
+		header = block.Header()
+		txs    = block.Transactions()
+		msgs   = make([]*Message, len(txs))
+		specs  = make([]*speculativeTx, len(txs))
+	)
+	for i, tx := range txs {
+		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		msgs[i] = msg
+		specs[i] = &speculativeTx{done: make(chan struct{})}
+	}
+	// Start the workers speculatively executing the transactions in block order,
+	// so the ones merged first are also ready first
+	var (
+		next    atomic.Int32
+		abort   atomic.Bool
+		pending sync.WaitGroup
+		workers = p.workers
+	)
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	defer func() {
+		abort.Store(true)
+		pending.Wait()
+	}()
+	for w := 0; w < workers; w++ {
+		// The block context is created per worker, as its hash cache is not
+		// thread safe
+		var (
+			specdb  = statedb.SpeculativeCopy(vmenv.Context.Coinbase)
+			context = NewEVMBlockContext(header, p.bc, nil)
+			evm     = vm.NewEVM(context, vm.TxContext{}, specdb, p.config, cfg)
+		)
+		pending.Add(1)
+		go func() {
+			defer pending.Done()
+
+			for !abort.Load() {
+				i := int(next.Add(1)) - 1
+				if i >= len(txs) {
+					return
+				}
+				specdb.SetTxContext(txs[i].Hash(), i)
+				specdb.BeginSpeculation()
+
+				evm.Reset(NewEVMTxContext(msgs[i]), specdb)
+				specs[i].result, specs[i].err = ApplyMessage(evm, msgs[i], new(GasPool).AddGas(header.GasLimit))
+				specs[i].spec = specdb.EndSpeculation()
+				close(specs[i].done)
+			}
+		}()
+	}
+	// Merge the speculations in block order, executing the invalidated ones again
+	var (
+		receipts = make(types.Receipts, 0, len(txs))
+		allLogs  []*types.Log
+		writes   = state.NewWriteSet()
+	)
+	for i, tx := range txs {
+		<-specs[i].done
+
+		statedb.SetTxContext(tx.Hash(), i)
+
+		result, err := specs[i].result, specs[i].err
+		if err == nil && gp.Gas() >= msgs[i].GasLimit && specs[i].spec.Valid(writes) {
+			statedb.ApplySpeculation(specs[i].spec)
+			if err := gp.SubGas(result.UsedGas); err != nil {
+				return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			parallelMergedMeter.Mark(1)
+		} else {
+			vmenv.Reset(NewEVMTxContext(msgs[i]), statedb)
+			if result, err = ApplyMessage(vmenv, msgs[i], gp); err != nil {
+				return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			parallelReexecutedMeter.Mark(1)
+		}
+		statedb.RecordWrites(writes)
+
+		receipt := makeReceipt(msgs[i], result, p.config, statedb, header.Number, block.Hash(), tx, usedGas)
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	return receipts, allLogs, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that processing blocks with speculative parallel execution produces the
+// same state and receipts as sequential processing, both for transactions that
+// can be merged and ones that conflict and need to be executed again.
+func TestParallelStateProcessor(t *testing.T) {
+	t.Parallel()
+
+	// Run the test both with per transaction state roots and without
+	preByzantium := &params.ChainConfig{
+		ChainID:        big.NewInt(1),
+		HomesteadBlock: big.NewInt(0),
+		EIP150Block:    big.NewInt(0),
+		EIP155Block:    big.NewInt(0),
+		EIP158Block:    big.NewInt(0),
+		Ethash:         new(params.EthashConfig),
+	}
+	for _, config := range []*params.ChainConfig{params.TestChainConfig, preByzantium} {
+		testParallelStateProcessor(t, config)
+	}
+}
+
+func testParallelStateProcessor(t *testing.T, config *params.ChainConfig) {
+	var (
+		keys    = make([]*ecdsa.PrivateKey, 8)
+		senders = make([]common.Address, len(keys))
+		counter = common.HexToAddress("0xc0") // Increments slot 0, conflicting
+		writer  = common.HexToAddress("0xc1") // Stores the value at the caller's slot and logs it, independent
+		suicide = common.HexToAddress("0xc2") // Self destructs to the caller
+		alloc   = GenesisAlloc{
+			counter: {Balance: common.Big0, Code: common.FromHex("0x60005460010160005500")},
+			writer:  {Balance: common.Big0, Code: common.FromHex("0x3433553360006000a100")},
+			suicide: {Balance: big.NewInt(params.Ether), Code: common.FromHex("0x33ff")},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		senders[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[senders[i]] = GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	var (
+		gspec  = &Genesis{Config: config, Alloc: alloc}
+		signer = types.LatestSigner(config)
+		engine = ethash.NewFaker()
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 3, func(n int, block *BlockGen) {
+		gasPrice := big.NewInt(params.GWei)
+		if fee := block.header.BaseFee; fee != nil {
+			gasPrice = fee
+		}
+		send := func(sender int, to *common.Address, value int64, data []byte) {
+			var tx *types.Transaction
+			if to == nil {
+				tx = types.NewContractCreation(block.TxNonce(senders[sender]), big.NewInt(value), 100000, gasPrice, data)
+			} else {
+				tx = types.NewTransaction(block.TxNonce(senders[sender]), *to, big.NewInt(value), 100000, gasPrice, data)
+			}
+			tx, _ = types.SignTx(tx, signer, keys[sender])
+			block.AddTx(tx)
+		}
+		recipient := common.Address{0xaa, byte(n)}
+		coinbase := block.header.Coinbase
+
+		send(0, &writer, int64(n+1), nil)               // independent storage write and log
+		send(1, &writer, int64(n+1), nil)               // independent storage write and log
+		send(0, &recipient, 1, nil)                     // same sender, conflicting nonce
+		send(2, &counter, 0, nil)                       // shared slot, first access
+		send(3, &counter, 0, nil)                       // shared slot, conflicting
+		send(4, &recipient, 1, nil)                     // recipient created by an earlier transaction
+		send(5, &coinbase, 1, nil)                      // coinbase read, conflicting
+		send(6, nil, 0, common.FromHex("0x60016000f3")) // contract creation
+		if n == 1 {
+			send(7, &suicide, 0, nil) // self destruct
+		}
+		send(7, &writer, int64(n+1), nil) // independent of the self destruct
+	})
+	// Import the chain in parallel mode, which validates the state roots, receipt
+	// roots and gas used against the blocks generated sequentially
+	db := rawdb.NewMemoryDatabase()
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.ParallelWorkers = 4
+	chain, err := NewBlockChain(db, &cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// Process every block in both modes first, comparing the derived fields of the
+	// receipts too, which are not covered by the consensus checks
+	parent := chain.Genesis()
+	for i, block := range blocks {
+		var (
+			receipts = make([]types.Receipts, 2)
+			logs     = make([][]*types.Log, 2)
+			roots    = make([]common.Hash, 2)
+		)
+		for j, workers := range []int{0, 4} {
+			statedb, err := state.New(parent.Root(), chain.StateCache(), nil)
+			if err != nil {
+				t.Fatalf("block %d: failed to open state: %v", i, err)
+			}
+			processor := *chain.processor.(*StateProcessor)
+			processor.workers = workers
+			receipts[j], logs[j], _, err = processor.Process(block, statedb, vm.Config{})
+			if err != nil {
+				t.Fatalf("block %d, workers %d: failed to process: %v", i, workers, err)
+			}
+			roots[j] = statedb.IntermediateRoot(config.IsEIP158(block.Number()))
+		}
+		if roots[0] != roots[1] || roots[0] != block.Root() {
+			t.Fatalf("block %d: root mismatch: sequential %x, parallel %x, want %x", i, roots[0], roots[1], block.Root())
+		}
+		if !reflect.DeepEqual(receipts[0], receipts[1]) {
+			t.Fatalf("block %d: receipt mismatch", i)
+		}
+		if !reflect.DeepEqual(logs[0], logs[1]) {
+			t.Fatalf("block %d: log mismatch", i)
+		}
+		if _, err := chain.InsertChain(blocks[i : i+1]); err != nil {
+			t.Fatalf("block %d: failed to import: %v", i, err)
+		}
+		parent = block
+	}
+}
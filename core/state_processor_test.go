@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"math/big"
 	"testing"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -428,3 +430,149 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	}
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
 }
+
+// Tests that from Prague on the parent block hash is stored in the history
+// storage, and that BLOCKHASH serves it from there up to the configured window.
+func TestBlockHashHistory(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		reader   = common.HexToAddress("0xc0") // Stores the hash of the block 290 back
+		distance = uint64(290)
+		count    = 300
+	)
+	run := func(window *uint64) (*BlockChain, []*types.Block) {
+		config := *params.AllEthashProtocolChanges
+		config.TerminalTotalDifficulty = common.Big0
+		config.TerminalTotalDifficultyPassed = true
+		config.ShanghaiTime = u64(0)
+		config.PragueTime = u64(0)
+		config.BlockHashWindow = window
+
+		gspec := &Genesis{
+			Config: &config,
+			Alloc: GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				reader: {Balance: common.Big0, Code: common.FromHex("0x610122430340435500")},
+			},
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+			Difficulty: common.Big0,
+		}
+		signer := types.LatestSigner(gspec.Config)
+		_, chain, _ := GenerateChainWithGenesis(gspec, beacon.NewFaker(), count, func(i int, gen *BlockGen) {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(sender), reader, common.Big0, 100000, gen.BaseFee(), nil), signer, key)
+			gen.AddTx(tx)
+		})
+		blockchain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, beacon.NewFaker(), vm.Config{}, nil, nil)
+		if n, err := blockchain.InsertChain(chain); err != nil {
+			t.Fatalf("block %d: failed to import: %v", n, err)
+		}
+		return blockchain, chain
+	}
+	// With the default window, the old hashes are stored but not accessible
+	chain, blocks := run(nil)
+	defer chain.Stop()
+
+	statedb, _ := chain.State()
+	for i := len(blocks) - int(params.HistoryServeWindow); i < len(blocks)-1; i++ {
+		if i < 0 {
+			continue
+		}
+		want := blocks[i].Hash()
+		if have := statedb.GetState(params.HistoryStorageAddress, vm.HistoryStorageSlot(blocks[i].NumberU64())); have != want {
+			t.Fatalf("block %d: history hash mismatch: have %x, want %x", blocks[i].NumberU64(), have, want)
+		}
+	}
+	for _, block := range blocks {
+		if have := statedb.GetState(reader, common.BigToHash(block.Number())); have != (common.Hash{}) {
+			t.Fatalf("block %d: hash beyond default window accessible: %x", block.NumberU64(), have)
+		}
+	}
+	// With an extended window, they are accessible as well
+	window := uint64(512)
+	chain, blocks = run(&window)
+	defer chain.Stop()
+
+	statedb, _ = chain.State()
+	for _, block := range blocks {
+		var want common.Hash
+		if n := block.NumberU64(); n >= distance {
+			want = chain.GetHeaderByNumber(n - distance).Hash()
+		}
+		if have := statedb.GetState(reader, common.BigToHash(block.Number())); have != want {
+			t.Fatalf("block %d: stored hash mismatch: have %x, want %x", block.NumberU64(), have, want)
+		}
+	}
+}
+
+func TestSetCodeTransaction(t *testing.T) {
+	var (
+		key1, _  = crypto.GenerateKey()
+		key2, _  = crypto.GenerateKey()
+		key3, _  = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key1.PublicKey)
+		authAddr = crypto.PubkeyToAddress(key2.PublicKey)
+		badAddr  = crypto.PubkeyToAddress(key3.PublicKey)
+		target   = common.HexToAddress("0xbb") // Stores ADDRESS in slot 0
+	)
+	config := *params.AllEthashProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+	config.TerminalTotalDifficultyPassed = true
+	config.ShanghaiTime = u64(0)
+	config.PragueTime = u64(0)
+
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			sender:   {Balance: big.NewInt(params.Ether)},
+			authAddr: {Balance: big.NewInt(params.Ether)},
+			target:   {Balance: common.Big0, Code: common.FromHex("0x30600055")},
+		},
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: common.Big0,
+	}
+	auth, _ := types.SignAuth(types.Authorization{ChainID: config.ChainID, Address: target, Nonce: 0}, key2)
+	badAuth, _ := types.SignAuth(types.Authorization{ChainID: new(big.Int).Add(config.ChainID, common.Big1), Address: target, Nonce: 0}, key3)
+
+	signer := types.LatestSigner(gspec.Config)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, beacon.NewFaker(), 2, func(i int, gen *BlockGen) {
+		switch i {
+		case 0:
+			tx := types.MustSignNewTx(key1, signer, &types.SetCodeTx{
+				ChainID:   uint256.MustFromBig(config.ChainID),
+				Nonce:     gen.TxNonce(sender),
+				To:        authAddr,
+				Gas:       500000,
+				GasTipCap: uint256.NewInt(1),
+				GasFeeCap: uint256.MustFromBig(new(big.Int).Mul(gen.BaseFee(), big.NewInt(2))),
+				Value:     uint256.NewInt(0),
+				AuthList:  []types.Authorization{auth, badAuth},
+			})
+			gen.AddTx(tx)
+		case 1:
+			// The delegating account can still originate transactions.
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(authAddr), authAddr, common.Big0, 100000, gen.BaseFee(), nil), signer, key2)
+			gen.AddTx(tx)
+		}
+	})
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, beacon.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to import: %v", n, err)
+	}
+	statedb, _ := chain.State()
+	if have, want := statedb.GetCode(authAddr), types.AddressToDelegation(target); !bytes.Equal(have, want) {
+		t.Fatalf("delegation code mismatch: have %x, want %x", have, want)
+	}
+	if have, want := statedb.GetNonce(authAddr), uint64(2); have != want {
+		t.Fatalf("authority nonce mismatch: have %d, want %d", have, want)
+	}
+	// The delegated code runs in the context of the authority
+	if have, want := statedb.GetState(authAddr, common.Hash{}), common.BytesToHash(authAddr.Bytes()); have != want {
+		t.Fatalf("storage mismatch: have %x, want %x", have, want)
+	}
+	// Authorizations for another chain are skipped
+	if code := statedb.GetCode(badAddr); len(code) != 0 {
+		t.Fatalf("invalid authorization applied: %x", code)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// ExecutionWitness executes the given block on top of its parent and collects
+// all the state, code and ancestor headers accessed, which is sufficient to
+// execute the block again statelessly with ExecuteStateless. The parent state
+// needs to be available.
+func (bc *BlockChain) ExecutionWitness(block *types.Block) (*stateless.Witness, error) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	// Open the parent state without snapshots, so every access goes through the
+	// tries and gets recorded
+	statedb, err := state.New(parent.Root, bc.stateCache, nil)
+	if err != nil {
+		return nil, err
+	}
+	witness := stateless.NewWitness(parent)
+	statedb.StartWitness(witness)
+
+	processor := &StateProcessor{
+		config: bc.chainConfig,
+		bc:     &headerRecorder{BlockChain: bc, witness: witness},
+		engine: bc.engine,
+	}
+	if _, _, _, err := processor.Process(block, statedb, vm.Config{}); err != nil {
+		return nil, err
+	}
+	// Hash the post state too, which may resolve further nodes when collapsing
+	// the tries after deletions
+	if root := statedb.IntermediateRoot(bc.chainConfig.IsEIP158(block.Number())); root != block.Root() {
+		return nil, fmt.Errorf("invalid merkle root (remote: %x local: %x) dberr: %w", block.Root(), root, statedb.Error())
+	}
+	return statedb.Witness(), nil
+}
+
+// headerRecorder is a chain wrapper adding all the ancestor headers accessed to
+// an execution witness.
+type headerRecorder struct {
+	*BlockChain
+	witness *stateless.Witness
+}
+
+// GetHeader retrieves a block header from the chain, adding it to the witness.
+func (r *headerRecorder) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header := r.BlockChain.GetHeader(hash, number)
+	if header != nil {
+		r.witness.AddHeader(header)
+	}
+	return header
+}
+
+// ExecuteStateless verifies a block using only its execution witness, without
+// access to any chain or state database. The block body is checked against the
+// header, then the block is executed on top of the witness state and the post
+// state, receipts and gas used are checked against the header.
+//
+// The header itself is not verified, that is left to the consensus layer.
+func ExecuteStateless(config *params.ChainConfig, engine consensus.Engine, block *types.Block, witness *stateless.Witness) error {
+	if err := witness.Verify(block); err != nil {
+		return err
+	}
+	header := block.Header()
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return fmt.Errorf("uncle root hash mismatch (header value %x, calculated %x)", header.UncleHash, hash)
+	}
+	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
+		return fmt.Errorf("transaction root hash mismatch (header value %x, calculated %x)", header.TxHash, hash)
+	}
+	if header.WithdrawalsHash != nil {
+		if block.Withdrawals() == nil {
+			return errors.New("missing withdrawals in block body")
+		}
+		if hash := types.DeriveSha(block.Withdrawals(), trie.NewStackTrie(nil)); hash != *header.WithdrawalsHash {
+			return fmt.Errorf("withdrawals root hash mismatch (header value %x, calculated %x)", *header.WithdrawalsHash, hash)
+		}
+	} else if block.Withdrawals() != nil {
+		return errors.New("withdrawals present in block body")
+	}
+	// Execute the block on top of the witness state. Any access to state missing
+	// from the witness fails the execution or leads to a state root mismatch.
+	statedb, err := state.New(witness.Root(), state.NewDatabase(witness.MakeHashDB()), nil)
+	if err != nil {
+		return err
+	}
+	processor := &StateProcessor{
+		config: config,
+		bc:     newWitnessChain(config, engine, witness),
+		engine: engine,
+	}
+	receipts, _, usedGas, err := processor.Process(block, statedb, vm.Config{})
+	if err != nil {
+		return err
+	}
+	validator := &BlockValidator{config: config, engine: engine}
+	if err := validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
+		return err
+	}
+	// Missing witness data is silently treated as empty during execution, make
+	// sure it did not go unnoticed
+	return statedb.Error()
+}
+
+// witnessChain is a chain reader serving the ancestor headers contained in an
+// execution witness.
+type witnessChain struct {
+	config  *params.ChainConfig
+	engine  consensus.Engine
+	witness *stateless.Witness
+	headers map[common.Hash]*types.Header
+}
+
+func newWitnessChain(config *params.ChainConfig, engine consensus.Engine, witness *stateless.Witness) *witnessChain {
+	headers := make(map[common.Hash]*types.Header, len(witness.Headers))
+	for _, header := range witness.Headers {
+		headers[header.Hash()] = header
+	}
+	return &witnessChain{
+		config:  config,
+		engine:  engine,
+		witness: witness,
+		headers: headers,
+	}
+}
+
+// Config retrieves the chain configuration.
+func (c *witnessChain) Config() *params.ChainConfig { return c.config }
+
+// Engine retrieves the consensus engine.
+func (c *witnessChain) Engine() consensus.Engine { return c.engine }
+
+// CurrentHeader returns the parent of the block being executed.
+func (c *witnessChain) CurrentHeader() *types.Header { return c.witness.Parent() }
+
+// GetHeader retrieves a header from the witness by hash and number.
+func (c *witnessChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+// GetHeaderByNumber retrieves a header from the witness by number.
+func (c *witnessChain) GetHeaderByNumber(number uint64) *types.Header {
+	for _, header := range c.witness.Headers {
+		if header.Number.Uint64() == number {
+			return header
+		}
+	}
+	return nil
+}
+
+// GetHeaderByHash retrieves a header from the witness by hash.
+func (c *witnessChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.headers[hash]
+}
+
+// GetTd is not available for stateless execution, the total difficulty is not
+// part of the witness.
+func (c *witnessChain) GetTd(hash common.Hash, number uint64) *big.Int {
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// extWitness is the witness format used for RLP and JSON encoding, with the sets
+// flattened into sorted lists so the encoding is deterministic.
+type extWitness struct {
+	Headers []*types.Header `json:"headers"`
+	Codes   []hexutil.Bytes `json:"codes"`
+	State   []hexutil.Bytes `json:"state"`
+}
+
+// toExtWitness converts the witness into its flattened encoding format.
+func (w *Witness) toExtWitness() *extWitness {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	flatten := func(set map[string]struct{}) []hexutil.Bytes {
+		list := make([]hexutil.Bytes, 0, len(set))
+		for item := range set {
+			list = append(list, hexutil.Bytes(item))
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return bytes.Compare(list[i], list[j]) < 0
+		})
+		return list
+	}
+	return &extWitness{
+		Headers: w.Headers,
+		Codes:   flatten(w.Codes),
+		State:   flatten(w.State),
+	}
+}
+
+// fromExtWitness populates the witness from its flattened encoding format.
+func (w *Witness) fromExtWitness(ext *extWitness) error {
+	if len(ext.Headers) == 0 {
+		return errors.New("witness missing parent header")
+	}
+	w.Headers = ext.Headers
+	w.Codes = make(map[string]struct{}, len(ext.Codes))
+	for _, code := range ext.Codes {
+		w.Codes[string(code)] = struct{}{}
+	}
+	w.State = make(map[string]struct{}, len(ext.State))
+	for _, node := range ext.State {
+		w.State[string(node)] = struct{}{}
+	}
+	return nil
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (w *Witness) EncodeRLP(wr io.Writer) error {
+	return rlp.Encode(wr, w.toExtWitness())
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (w *Witness) DecodeRLP(s *rlp.Stream) error {
+	var ext extWitness
+	if err := s.Decode(&ext); err != nil {
+		return err
+	}
+	return w.fromExtWitness(&ext)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (w *Witness) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.toExtWitness())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (w *Witness) UnmarshalJSON(input []byte) error {
+	var ext extWitness
+	if err := json.Unmarshal(input, &ext); err != nil {
+		return err
+	}
+	return w.fromExtWitness(&ext)
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that witnesses survive both the RLP and JSON encoding, and that the
+// encodings are deterministic.
+func TestWitnessEncoding(t *testing.T) {
+	grandparent := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	parent := &types.Header{Number: big.NewInt(2), Difficulty: big.NewInt(1), ParentHash: grandparent.Hash()}
+
+	witness := NewWitness(parent)
+	witness.AddHeader(&types.Header{Number: big.NewInt(7)}) // not linked, ignored
+	witness.AddHeader(grandparent)
+	witness.AddCode([]byte{0x60, 0x00})
+	witness.AddCode([]byte{0x60, 0x01})
+	witness.AddState(map[string]struct{}{"\xc2\x01\x02": {}, "\xc2\x03\x04": {}})
+
+	if len(witness.Headers) != 2 {
+		t.Fatalf("header count mismatch: have %d, want %d", len(witness.Headers), 2)
+	}
+	// Round trip through RLP
+	blob, err := rlp.EncodeToBytes(witness)
+	if err != nil {
+		t.Fatalf("failed to encode RLP: %v", err)
+	}
+	decoded := new(Witness)
+	if err := rlp.DecodeBytes(blob, decoded); err != nil {
+		t.Fatalf("failed to decode RLP: %v", err)
+	}
+	checkWitness(t, decoded, witness)
+
+	again, _ := rlp.EncodeToBytes(decoded)
+	if string(again) != string(blob) {
+		t.Errorf("RLP encoding not deterministic")
+	}
+	// Round trip through JSON
+	blob, err = json.Marshal(witness)
+	if err != nil {
+		t.Fatalf("failed to encode JSON: %v", err)
+	}
+	decoded = new(Witness)
+	if err := json.Unmarshal(blob, decoded); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+	checkWitness(t, decoded, witness)
+
+	// Witnesses without a parent header are rejected
+	if err := json.Unmarshal([]byte(`{"headers":[],"codes":[],"state":[]}`), new(Witness)); err == nil {
+		t.Errorf("witness without headers accepted")
+	}
+}
+
+func checkWitness(t *testing.T, have, want *Witness) {
+	t.Helper()
+
+	if len(have.Headers) != len(want.Headers) {
+		t.Fatalf("header count mismatch: have %d, want %d", len(have.Headers), len(want.Headers))
+	}
+	for i := range have.Headers {
+		if have.Headers[i].Hash() != want.Headers[i].Hash() {
+			t.Errorf("header %d mismatch: have %x, want %x", i, have.Headers[i].Hash(), want.Headers[i].Hash())
+		}
+	}
+	if !reflect.DeepEqual(have.Codes, want.Codes) {
+		t.Errorf("codes mismatch: have %x, want %x", have.Codes, want.Codes)
+	}
+	if !reflect.DeepEqual(have.State, want.State) {
+		t.Errorf("state mismatch: have %x, want %x", have.State, want.State)
+	}
+}
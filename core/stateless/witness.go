@@ -0,0 +1,131 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stateless implements the execution witnesses, containing everything
+// needed to execute a block without access to a state database.
+package stateless
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Witness encompasses the state required to apply a block on top of its parent
+// and derive the post state and receipt roots: the trie nodes and contract codes
+// touched during execution, and the headers needed to serve the BLOCKHASH opcode.
+type Witness struct {
+	Headers []*types.Header     // Parent header first, followed by its ancestors in reverse order
+	Codes   map[string]struct{} // Contract codes executed or otherwise accessed
+	State   map[string]struct{} // Account and storage trie nodes resolved during execution and hashing
+
+	lock sync.Mutex // Lock protecting concurrent additions
+}
+
+// NewWitness creates an empty witness for executing a block on top of the given
+// parent header.
+func NewWitness(parent *types.Header) *Witness {
+	return &Witness{
+		Headers: []*types.Header{parent},
+		Codes:   make(map[string]struct{}),
+		State:   make(map[string]struct{}),
+	}
+}
+
+// Parent returns the header of the parent of the block the witness is for.
+func (w *Witness) Parent() *types.Header {
+	return w.Headers[0]
+}
+
+// Root returns the state root the witness is rooted at, i.e. the parent's.
+func (w *Witness) Root() common.Hash {
+	return w.Headers[0].Root
+}
+
+// AddHeader adds an ancestor header accessed during execution. Headers need to
+// be added in reverse order, each being the parent of the previous one, otherwise
+// they are ignored.
+func (w *Witness) AddHeader(header *types.Header) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if last := w.Headers[len(w.Headers)-1]; header.Hash() == last.ParentHash {
+		w.Headers = append(w.Headers, header)
+	}
+}
+
+// AddCode adds a contract code accessed during execution.
+func (w *Witness) AddCode(code []byte) {
+	if len(code) == 0 {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.Codes[string(code)] = struct{}{}
+}
+
+// AddState adds a set of trie nodes resolved during execution.
+func (w *Witness) AddState(nodes map[string]struct{}) {
+	if len(nodes) == 0 {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for node := range nodes {
+		w.State[node] = struct{}{}
+	}
+}
+
+// Verify checks that the headers in the witness form a chain leading up to the
+// given block, which is required for them to be used to serve block hashes.
+func (w *Witness) Verify(block *types.Block) error {
+	if len(w.Headers) == 0 {
+		return errors.New("witness missing parent header")
+	}
+	if hash := w.Headers[0].Hash(); hash != block.ParentHash() {
+		return fmt.Errorf("witness parent mismatch: have %x, want %x", hash, block.ParentHash())
+	}
+	for i := 1; i < len(w.Headers); i++ {
+		if hash := w.Headers[i].Hash(); hash != w.Headers[i-1].ParentHash {
+			return fmt.Errorf("witness header %d not linked: have %x, want %x", i, hash, w.Headers[i-1].ParentHash)
+		}
+	}
+	return nil
+}
+
+// MakeHashDB imports the trie nodes and contract codes of the witness into an
+// in-memory database in the hash based scheme, so that the state can be accessed
+// through the usual state database.
+func (w *Witness) MakeHashDB() ethdb.Database {
+	db := rawdb.NewMemoryDatabase()
+	for code := range w.Codes {
+		blob := []byte(code)
+		rawdb.WriteCode(db, crypto.Keccak256Hash(blob), blob)
+	}
+	for node := range w.State {
+		blob := []byte(node)
+		rawdb.WriteLegacyTrieNode(db, crypto.Keccak256Hash(blob), blob)
+	}
+	return db
+}
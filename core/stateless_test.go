@@ -0,0 +1,145 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that the execution witness of a block is sufficient to execute it again
+// statelessly, and that every item in it is actually needed to do so.
+func TestStatelessExecution(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _  = crypto.GenerateKey()
+		sender  = crypto.PubkeyToAddress(key.PublicKey)
+		hasher  = common.HexToAddress("0xc0") // Stores the hash of the block 3 back
+		sizer   = common.HexToAddress("0xc1") // Stores the code size of the hasher
+		clearer = common.HexToAddress("0xc2") // Clears the slot given as call value
+		alloc   = GenesisAlloc{
+			sender:  {Balance: big.NewInt(params.Ether)},
+			hasher:  {Balance: common.Big0, Code: common.FromHex("0x43600390034060005500")},
+			sizer:   {Balance: common.Big0, Code: append(append([]byte{0x73}, hasher.Bytes()...), common.FromHex("0x3b60005500")...)},
+			clearer: {Balance: common.Big0, Code: common.FromHex("0x60003455"), Storage: make(map[common.Hash]common.Hash)},
+		}
+	)
+	for i := 0; i < 16; i++ {
+		alloc[clearer].Storage[common.BigToHash(big.NewInt(int64(i)))] = common.Hash{0x01}
+	}
+	for i := 0; i < 64; i++ {
+		alloc[common.Address{0xaa, byte(i)}] = GenesisAccount{Balance: common.Big1}
+	}
+	var (
+		gspec  = &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+	)
+	// Run an archive chain, so the blocks accessing earlier block hashes can be
+	// generated one by one on top of it
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.TrieDirtyDisabled = true
+
+	chain, err := NewBlockChain(db, &cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	var blocks []*types.Block
+	for i := 0; i < 5; i++ {
+		parent := chain.GetBlockByHash(chain.CurrentBlock().Hash())
+		generated, _ := GenerateChain(gspec.Config, parent, engine, db, 1, func(_ int, block *BlockGen) {
+			send := func(to common.Address, value int64) {
+				tx := types.NewTransaction(block.TxNonce(sender), to, big.NewInt(value), 100000, block.header.BaseFee, nil)
+				tx, _ = types.SignTx(tx, signer, key)
+				block.AddTxWithChain(chain, tx)
+			}
+			send(hasher, 0)
+			send(sizer, 0)
+			send(clearer, int64(i))
+			send(common.Address{0xaa, byte(i)}, 1)
+		})
+		if _, err := chain.InsertChain(generated); err != nil {
+			t.Fatalf("block %d: failed to import: %v", i, err)
+		}
+		blocks = append(blocks, generated...)
+	}
+	for i, block := range blocks {
+		witness, err := chain.ExecutionWitness(block)
+		if err != nil {
+			t.Fatalf("block %d: failed to create witness: %v", i, err)
+		}
+		// From the third block on, the hash of the block 3 back is accessed, which
+		// is the parent hash of the grandparent
+		want := 1
+		if i >= 2 {
+			want = 2
+		}
+		if len(witness.Headers) != want {
+			t.Errorf("block %d: header count mismatch: have %d, want %d", i, len(witness.Headers), want)
+		}
+		if len(witness.Codes) != 3 {
+			t.Errorf("block %d: code count mismatch: have %d, want %d", i, len(witness.Codes), 3)
+		}
+		// Send the witness through the wire encoding and execute statelessly
+		blob, err := rlp.EncodeToBytes(witness)
+		if err != nil {
+			t.Fatalf("block %d: failed to encode witness: %v", i, err)
+		}
+		decoded := new(stateless.Witness)
+		if err := rlp.DecodeBytes(blob, decoded); err != nil {
+			t.Fatalf("block %d: failed to decode witness: %v", i, err)
+		}
+		if err := ExecuteStateless(gspec.Config, engine, block, decoded); err != nil {
+			t.Fatalf("block %d: failed to execute statelessly: %v", i, err)
+		}
+		// Drop every state node and code one by one, and check the execution fails
+		for node := range witness.State {
+			delete(decoded.State, node)
+			if err := ExecuteStateless(gspec.Config, engine, block, decoded); err == nil {
+				t.Errorf("block %d: executed without state node %x", i, crypto.Keccak256([]byte(node)))
+			}
+			decoded.State[node] = struct{}{}
+		}
+		for code := range witness.Codes {
+			delete(decoded.Codes, code)
+			if err := ExecuteStateless(gspec.Config, engine, block, decoded); err == nil {
+				t.Errorf("block %d: executed without code %x", i, crypto.Keccak256([]byte(code)))
+			}
+			decoded.Codes[code] = struct{}{}
+		}
+		if len(decoded.Headers) > 1 {
+			decoded.Headers = decoded.Headers[:len(decoded.Headers)-1]
+			if err := ExecuteStateless(gspec.Config, engine, block, decoded); err == nil {
+				t.Errorf("block %d: executed without ancestor header", i)
+			}
+		}
+	}
+}
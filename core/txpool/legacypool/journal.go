@@ -42,13 +42,16 @@ func (*devNull) Write(p []byte) (n int, err error) { return len(p), nil }
 func (*devNull) Close() error                      { return nil }
 
 // journal is a rotating log of transactions with the aim of storing locally
-// created transactions to allow non-executed ones to survive node restarts.
+// created transactions to allow non-executed ones to survive node restarts. It
+// is also used to periodically snapshot the remote transactions of the pool if
+// the node operator requested the entire pool to be persisted.
 type journal struct {
 	path   string         // Filesystem path to store the transactions at
 	writer io.WriteCloser // Output stream to write new transactions into
 }
 
-// newTxJournal creates a new transaction journal to
+// newTxJournal creates a new transaction journal to store transactions at the
+// given filesystem path.
 func newTxJournal(path string) *journal {
 	return &journal{
 		path: path,
@@ -112,7 +115,7 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 			batch = batch[:0]
 		}
 	}
-	log.Info("Loaded local transaction journal", "transactions", total, "dropped", dropped)
+	log.Info("Loaded transaction journal", "path", journal.path, "transactions", total, "dropped", dropped)
 
 	return failure
 }
@@ -139,6 +142,33 @@ func (journal *journal) rotate(all map[common.Address]types.Transactions) error
 		journal.writer = nil
 	}
 	// Generate a new journal with the contents of the current pool
+	if err := journal.dump(all); err != nil {
+		return err
+	}
+	sink, err := os.OpenFile(journal.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	journal.writer = sink
+	return nil
+}
+
+// snapshot overwrites the transaction journal with the current contents of the
+// transaction pool, without keeping the file open for subsequent insertions.
+// It is used for journals that are only ever regenerated wholesale.
+func (journal *journal) snapshot(all map[common.Address]types.Transactions) error {
+	if journal.writer != nil {
+		if err := journal.writer.Close(); err != nil {
+			return err
+		}
+		journal.writer = nil
+	}
+	return journal.dump(all)
+}
+
+// dump writes the given transactions into a temporary file and atomically moves
+// it in place of the live journal.
+func (journal *journal) dump(all map[common.Address]types.Transactions) error {
 	replacement, err := os.OpenFile(journal.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
@@ -159,13 +189,7 @@ func (journal *journal) rotate(all map[common.Address]types.Transactions) error
 	if err = os.Rename(journal.path+".new", journal.path); err != nil {
 		return err
 	}
-	sink, err := os.OpenFile(journal.path, os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return err
-	}
-	journal.writer = sink
-	log.Info("Regenerated local transaction journal", "transactions", journaled, "accounts", len(all))
-
+	log.Info("Regenerated transaction journal", "path", journal.path, "transactions", journaled, "accounts", len(all))
 	return nil
 }
 
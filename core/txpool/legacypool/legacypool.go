@@ -104,6 +104,19 @@ var (
 	slotsGauge   = metrics.NewRegisteredGauge("txpool/slots", nil)
 
 	reheapTimer = metrics.NewRegisteredTimer("txpool/reheap", nil)
+
+	// dropMeters counts the transactions leaving the pool without inclusion,
+	// split by the reason of their removal.
+	dropMeters = map[txpool.DropReason]metrics.Meter{
+		txpool.DropReplaced:     metrics.NewRegisteredMeter("txpool/dropped/replaced", nil),
+		txpool.DropUnderpriced:  metrics.NewRegisteredMeter("txpool/dropped/underpriced", nil),
+		txpool.DropGasTip:       metrics.NewRegisteredMeter("txpool/dropped/gastip", nil),
+		txpool.DropStale:        metrics.NewRegisteredMeter("txpool/dropped/stale", nil),
+		txpool.DropNoFunds:      metrics.NewRegisteredMeter("txpool/dropped/nofunds", nil),
+		txpool.DropLifetime:     metrics.NewRegisteredMeter("txpool/dropped/lifetime", nil),
+		txpool.DropAccountLimit: metrics.NewRegisteredMeter("txpool/dropped/accountlimit", nil),
+		txpool.DropPoolLimit:    metrics.NewRegisteredMeter("txpool/dropped/poollimit", nil),
+	}
 )
 
 // BlockChain defines the minimal set of methods needed to back a tx pool with
@@ -127,7 +140,9 @@ type Config struct {
 	Locals    []common.Address // Addresses that should be treated by default as local
 	NoLocals  bool             // Whether local transaction handling should be disabled
 	Journal   string           // Journal of local transactions to survive node restarts
-	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+	Rejournal time.Duration    // Time interval to regenerate the transaction journals
+
+	RemoteJournal string // Journal of remote transactions to survive node restarts (empty = disabled)
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -208,6 +223,7 @@ type LegacyPool struct {
 	chain       BlockChain
 	gasTip      atomic.Pointer[big.Int]
 	txFeed      event.Feed
+	dropFeed    event.Feed
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
@@ -216,8 +232,9 @@ type LegacyPool struct {
 	currentState  *state.StateDB               // Current state in the blockchain head
 	pendingNonces *noncer                      // Pending state tracking virtual nonces
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *journal    // Journal of local transaction to back up to disk
+	locals        *accountSet // Set of local transaction to exempt from eviction rules
+	journal       *journal    // Journal of local transaction to back up to disk
+	remoteJournal *journal    // Journal of remote transactions to snapshot to disk
 
 	pending map[common.Address]*list     // All currently processable transactions
 	queue   map[common.Address]*list     // Queued but non-processable transactions
@@ -233,7 +250,10 @@ type LegacyPool struct {
 	wg              sync.WaitGroup // tracks loop, scheduleReorgLoop
 	initDoneCh      chan struct{}  // is closed once the pool is initialized (for tests)
 
-	changesSinceReorg int // A counter for how many drops we've performed in-between reorg.
+	changesSinceReorg int                 // A counter for how many drops we've performed in-between reorg.
+	drops             []*txpool.DroppedTx // Dropped transactions waiting to be announced
+
+	included map[common.Hash]struct{} // Transactions included by the chain during a reset, nil if unknown
 }
 
 type txpoolResetRequest struct {
@@ -273,6 +293,9 @@ func New(config Config, chain BlockChain) *LegacyPool {
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 	}
+	if config.RemoteJournal != "" {
+		pool.remoteJournal = newTxJournal(config.RemoteJournal)
+	}
 	return pool
 }
 
@@ -310,6 +333,14 @@ func (pool *LegacyPool) Init(gasTip *big.Int, head *types.Header) error {
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If the entire pool is persisted, load the remote transactions too. These
+	// are revalidated against the current head and only the ones still viable
+	// are retained.
+	if pool.remoteJournal != nil {
+		if err := pool.remoteJournal.load(pool.addRemotesSync); err != nil {
+			log.Warn("Failed to load remote transaction journal", "err", err)
+		}
+	}
 	pool.wg.Add(1)
 	go pool.loop()
 	return nil
@@ -366,11 +397,13 @@ func (pool *LegacyPool) loop() {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
 						pool.removeTx(tx.Hash(), true)
+						pool.markDropped(tx, txpool.DropLifetime, nil)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
 			pool.mu.Unlock()
+			pool.announceDrops()
 
 		// Handle local transaction journal rotation
 		case <-journal.C:
@@ -381,6 +414,17 @@ func (pool *LegacyPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			if pool.remoteJournal != nil {
+				// Only gather the transactions under the lock, writing them out
+				// to disk doesn't need to block the pool.
+				pool.mu.RLock()
+				remotes := pool.remote()
+				pool.mu.RUnlock()
+
+				if err := pool.remoteJournal.snapshot(remotes); err != nil {
+					log.Warn("Failed to snapshot remote tx journal", "err", err)
+				}
+			}
 		}
 	}
 }
@@ -397,6 +441,17 @@ func (pool *LegacyPool) Close() error {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	// Persist the remote transactions one last time, the pool is not changing
+	// anymore so this is the most up-to-date view that can be saved.
+	if pool.remoteJournal != nil {
+		pool.mu.RLock()
+		remotes := pool.remote()
+		pool.mu.RUnlock()
+
+		if err := pool.remoteJournal.snapshot(remotes); err != nil {
+			log.Warn("Failed to snapshot remote tx journal", "err", err)
+		}
+	}
 	log.Info("Transaction pool stopped")
 	return nil
 }
@@ -414,9 +469,17 @@ func (pool *LegacyPool) SubscribeTransactions(ch chan<- core.NewTxsEvent) event.
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeDropTransactions registers a subscription of DropTxsEvent and
+// starts sending event to the given channel.
+func (pool *LegacyPool) SubscribeDropTransactions(ch chan<- txpool.DropTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.dropFeed.Subscribe(ch))
+}
+
 // SetGasTip updates the minimum gas tip required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (pool *LegacyPool) SetGasTip(tip *big.Int) {
+	defer pool.announceDrops()
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
@@ -429,6 +492,7 @@ func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 		drop := pool.all.RemotesBelowTip(tip)
 		for _, tx := range drop {
 			pool.removeTx(tx.Hash(), false)
+			pool.markDropped(tx, txpool.DropGasTip, nil)
 		}
 		pool.priced.Removed(len(drop))
 	}
@@ -556,6 +620,24 @@ func (pool *LegacyPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// remote retrieves all currently known remote transactions, grouped by origin
+// account and sorted by nonce. The returned transaction set is a copy and can be
+// freely modified by calling code.
+func (pool *LegacyPool) remote() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, pending := range pool.pending {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], pending.Flatten()...)
+		}
+	}
+	for addr, queued := range pool.queue {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], queued.Flatten()...)
+		}
+	}
+	return txs
+}
+
 // validateTxBasics checks whether a transaction is valid according to the consensus
 // rules, but does not check state-dependent validation such as sufficient balance.
 // This check is meant as an early check which only needs to be performed once,
@@ -566,7 +648,8 @@ func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) erro
 		Accept: 0 |
 			1<<types.LegacyTxType |
 			1<<types.AccessListTxType |
-			1<<types.DynamicFeeTxType,
+			1<<types.DynamicFeeTxType |
+			1<<types.SetCodeTxType,
 		MaxSize: txMaxSize,
 		MinTip:  pool.gasTip.Load(),
 	}
@@ -692,6 +775,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			underpricedTxMeter.Mark(1)
 			dropped := pool.removeTx(tx.Hash(), false)
 			pool.changesSinceReorg += dropped
+			pool.markDropped(tx, txpool.DropUnderpriced, nil)
 		}
 	}
 
@@ -708,6 +792,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.markDropped(old, txpool.DropReplaced, tx)
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
@@ -783,6 +868,7 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.markDropped(old, txpool.DropReplaced, tx)
 	} else {
 		// Nothing was replaced, bump the queued counter
 		queuedGauge.Inc(1)
@@ -832,6 +918,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+		pool.markDropped(tx, txpool.DropReplaced, list.txs.Get(tx.Nonce()))
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -839,6 +926,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.markDropped(old, txpool.DropReplaced, tx)
 	} else {
 		// Nothing was replaced, bump the pending counter
 		pendingGauge.Inc(1)
@@ -1094,6 +1182,46 @@ func (pool *LegacyPool) queueTxEvent(tx *types.Transaction) {
 	}
 }
 
+// markDropped records a transaction removed from the pool to be announced to
+// any subscribers of drop events.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) markDropped(tx *types.Transaction, reason txpool.DropReason, replacement *types.Transaction) {
+	if meter, ok := dropMeters[reason]; ok {
+		meter.Mark(1)
+	}
+	pool.drops = append(pool.drops, &txpool.DroppedTx{Tx: tx, Reason: reason, Replacement: replacement})
+}
+
+// markStale records a transaction removed because its nonce was already used on
+// chain. Transactions removed because the chain included them did not leave the
+// pool by being dropped, so only transactions superseded by another one with the
+// same nonce are announced. If the included transactions are unknown (e.g. after
+// a deep reorg), nothing is announced.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) markStale(tx *types.Transaction) {
+	if pool.included == nil {
+		return
+	}
+	if _, ok := pool.included[tx.Hash()]; !ok {
+		pool.markDropped(tx, txpool.DropStale, nil)
+	}
+}
+
+// announceDrops sends out all the transactions dropped since the last call to
+// the subscribers of drop events.
+func (pool *LegacyPool) announceDrops() {
+	pool.mu.Lock()
+	drops := pool.drops
+	pool.drops = nil
+	pool.mu.Unlock()
+
+	if len(drops) > 0 {
+		pool.dropFeed.Send(txpool.DropTxsEvent{Txs: drops})
+	}
+}
+
 // scheduleReorgLoop schedules runs of reset and promoteExecutables. Code above should not
 // call those methods directly, but request them being run using requestReset and
 // requestPromoteExecutables instead.
@@ -1222,6 +1350,8 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 		}
 		pool.pendingNonces.setAll(nonces)
 	}
+	pool.included = nil
+
 	// Ensure pool.queue and pool.pending sizes stay within the configured limits.
 	pool.truncatePending()
 	pool.truncateQueue()
@@ -1230,6 +1360,9 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 	pool.changesSinceReorg = 0 // Reset change counter
 	pool.mu.Unlock()
 
+	// Notify subsystems of any transactions dropped in the meantime
+	pool.announceDrops()
+
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
 		addr, _ := types.Sender(pool.signer, tx)
@@ -1253,6 +1386,14 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	// If we're reorging an old state, reinject all dropped transactions
 	var reinject types.Transactions
 
+	// Track the transactions the new chain included, to tell them apart from the
+	// ones invalidated by another transaction with the same nonce
+	pool.included = nil
+	if oldHead != nil && oldHead.Hash() == newHead.ParentHash {
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			pool.included = includedSet(block.Transactions())
+		}
+	}
 	if oldHead != nil && oldHead.Hash() != newHead.ParentHash {
 		// If the reorg is too deep, avoid doing it (will happen during fast sync)
 		oldNum := oldHead.Number.Uint64()
@@ -1318,6 +1459,7 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 					}
 				}
 				reinject = types.TxDifference(discarded, included)
+				pool.included = includedSet(included)
 			}
 		}
 	}
@@ -1340,6 +1482,15 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	pool.addTxsLocked(reinject, false)
 }
 
+// includedSet returns the set of hashes of the given transactions.
+func includedSet(txs types.Transactions) map[common.Hash]struct{} {
+	set := make(map[common.Hash]struct{}, len(txs))
+	for _, tx := range txs {
+		set[tx.Hash()] = struct{}{}
+	}
+	return set
+}
+
 // promoteExecutables moves transactions that have become processable from the
 // future queue to the set of pending transactions. During this process, all
 // invalidated transactions (low nonce, low balance) are deleted.
@@ -1359,6 +1510,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range forwards {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.markStale(tx)
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
@@ -1366,6 +1518,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.markDropped(tx, txpool.DropNoFunds, nil)
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
@@ -1388,6 +1541,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			for _, tx := range caps {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
+				pool.markDropped(tx, txpool.DropAccountLimit, nil)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 			queuedRateLimitMeter.Mark(int64(len(caps)))
@@ -1450,6 +1604,7 @@ func (pool *LegacyPool) truncatePending() {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
 						pool.all.Remove(hash)
+						pool.markDropped(tx, txpool.DropPoolLimit, nil)
 
 						// Update the account nonce to the dropped transaction
 						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
@@ -1477,6 +1632,7 @@ func (pool *LegacyPool) truncatePending() {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
 					pool.all.Remove(hash)
+					pool.markDropped(tx, txpool.DropPoolLimit, nil)
 
 					// Update the account nonce to the dropped transaction
 					pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1524,6 +1680,7 @@ func (pool *LegacyPool) truncateQueue() {
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
 				pool.removeTx(tx.Hash(), true)
+				pool.markDropped(tx, txpool.DropPoolLimit, nil)
 			}
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
@@ -1533,6 +1690,7 @@ func (pool *LegacyPool) truncateQueue() {
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 			pool.removeTx(txs[i].Hash(), true)
+			pool.markDropped(txs[i], txpool.DropPoolLimit, nil)
 			drop--
 			queuedRateLimitMeter.Mark(1)
 		}
@@ -1557,6 +1715,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		for _, tx := range olds {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.markStale(tx)
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
@@ -1565,6 +1724,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
+			pool.markDropped(tx, txpool.DropNoFunds, nil)
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -36,8 +37,10 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
 )
 
 var (
@@ -64,6 +67,8 @@ type testBlockChain struct {
 	gasLimit      atomic.Uint64
 	statedb       *state.StateDB
 	chainHeadFeed *event.Feed
+
+	blockTxs types.Transactions // Transactions contained in the blocks returned by GetBlock
 }
 
 func newTestBlockChain(config *params.ChainConfig, gasLimit uint64, statedb *state.StateDB, chainHeadFeed *event.Feed) *testBlockChain {
@@ -84,7 +89,7 @@ func (bc *testBlockChain) CurrentBlock() *types.Header {
 }
 
 func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
-	return types.NewBlock(bc.CurrentBlock(), nil, nil, nil, trie.NewStackTrie(nil))
+	return types.NewBlock(bc.CurrentBlock(), bc.blockTxs, nil, nil, trie.NewStackTrie(nil))
 }
 
 func (bc *testBlockChain) StateAt(common.Hash) (*state.StateDB, error) {
@@ -415,6 +420,30 @@ func TestTipAboveFeeCap(t *testing.T) {
 	}
 }
 
+// Tests that set code transactions without authorizations are rejected.
+func TestSetCodeTxEmptyAuthList(t *testing.T) {
+	t.Parallel()
+
+	config := *eip1559Config
+	config.PragueTime = new(uint64)
+	pool, key := setupPoolWithConfig(&config)
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+	tx, _ := types.SignNewTx(key, types.LatestSignerForChainID(config.ChainID), &types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(config.ChainID),
+		Nonce:     0,
+		To:        common.Address{0x01},
+		Gas:       100000,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(1),
+		Value:     uint256.NewInt(0),
+	})
+	if err := pool.addRemote(tx); !errors.Is(err, core.ErrEmptyAuthList) {
+		t.Error("expected", core.ErrEmptyAuthList, "got", err)
+	}
+}
+
 func TestVeryHighValues(t *testing.T) {
 	t.Parallel()
 
@@ -2163,6 +2192,166 @@ func TestReplacement(t *testing.T) {
 	}
 }
 
+// Tests that transactions leaving the pool are announced along with the reason
+// of their removal and their replacement, if any.
+func TestDropEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	events := make(chan txpool.DropTxsEvent, 32)
+	sub := pool.SubscribeDropTransactions(events)
+	defer sub.Unsubscribe()
+
+	other, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(other.PublicKey), big.NewInt(1000000000))
+
+	// Replace a pending transaction and ensure the replacement is announced
+	original := pricedTransaction(0, 100000, big.NewInt(1), key)
+	replacement := pricedTransaction(0, 100000, big.NewInt(2), key)
+
+	if err := pool.addRemoteSync(original); err != nil {
+		t.Fatalf("failed to add original transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(replacement); err != nil {
+		t.Fatalf("failed to add replacement transaction: %v", err)
+	}
+	drops, err := collectDrops(events, 1)
+	if err != nil {
+		t.Fatalf("replacement drop event failed: %v", err)
+	}
+	if drops[0].Tx.Hash() != original.Hash() || drops[0].Reason != txpool.DropReplaced {
+		t.Fatalf("replacement drop mismatch: have %x (%v), want %x (%v)", drops[0].Tx.Hash(), drops[0].Reason, original.Hash(), txpool.DropReplaced)
+	}
+	if drops[0].Replacement == nil || drops[0].Replacement.Hash() != replacement.Hash() {
+		t.Fatalf("replacement transaction mismatch: have %v, want %x", drops[0].Replacement, replacement.Hash())
+	}
+	// Raise the minimum tip and ensure the cheap remote transaction is announced
+	cheap := pricedTransaction(0, 100000, big.NewInt(1), other)
+	if err := pool.addRemoteSync(cheap); err != nil {
+		t.Fatalf("failed to add cheap transaction: %v", err)
+	}
+	pool.SetGasTip(big.NewInt(2))
+
+	if drops, err = collectDrops(events, 1); err != nil {
+		t.Fatalf("gas tip drop event failed: %v", err)
+	}
+	if drops[0].Tx.Hash() != cheap.Hash() || drops[0].Reason != txpool.DropGasTip {
+		t.Fatalf("gas tip drop mismatch: have %x (%v), want %x (%v)", drops[0].Tx.Hash(), drops[0].Reason, cheap.Hash(), txpool.DropGasTip)
+	}
+	// Include the replacement in a block and ensure it is not announced as dropped
+	testMineBlock(pool, key, 1, types.Transactions{replacement})
+
+	if _, err = collectDrops(events, 0); err != nil {
+		t.Fatalf("included transaction announced: %v", err)
+	}
+	// Include a different transaction with the nonce of a pooled one and ensure
+	// the superseded transaction is announced as stale
+	pooled := pricedTransaction(1, 100000, big.NewInt(2), key)
+	if err := pool.addRemoteSync(pooled); err != nil {
+		t.Fatalf("failed to add pooled transaction: %v", err)
+	}
+	testMineBlock(pool, key, 2, types.Transactions{pricedTransaction(1, 21000, big.NewInt(1), key)})
+
+	if drops, err = collectDrops(events, 1); err != nil {
+		t.Fatalf("stale drop event failed: %v", err)
+	}
+	if drops[0].Tx.Hash() != pooled.Hash() || drops[0].Reason != txpool.DropStale {
+		t.Fatalf("stale drop mismatch: have %x (%v), want %x (%v)", drops[0].Tx.Hash(), drops[0].Reason, pooled.Hash(), txpool.DropStale)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that transactions included on chain are neither announced as dropped nor
+// counted by the drop meters, which back the droppedTransactions subscription
+// and the txpool/dropped/* metrics.
+func TestDropIgnoresIncluded(t *testing.T) {
+	// Swap in a live meter as the registered ones are no-ops with metrics disabled.
+	// The meters are shared, so this test can't run in parallel.
+	stale := metrics.NewMeterForced()
+	defer stale.Stop()
+
+	old := dropMeters[txpool.DropStale]
+	dropMeters[txpool.DropStale] = stale
+	defer func() { dropMeters[txpool.DropStale] = old }()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	events := make(chan txpool.DropTxsEvent, 32)
+	sub := pool.SubscribeDropTransactions(events)
+	defer sub.Unsubscribe()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	txs := types.Transactions{
+		transaction(0, 100000, key),
+		transaction(1, 100000, key),
+	}
+	for _, tx := range txs {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	testMineBlock(pool, key, 2, txs)
+
+	if _, err := collectDrops(events, 0); err != nil {
+		t.Fatalf("included transactions announced: %v", err)
+	}
+	if n := stale.Count(); n != 0 {
+		t.Fatalf("included transactions counted as stale: %d", n)
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("included transactions not removed: pending %d, queued %d", pending, queued)
+	}
+}
+
+// testMineBlock resets the pool onto a new head block containing the given
+// transactions, setting the nonce of the given account as if they were executed.
+func testMineBlock(pool *LegacyPool, key *ecdsa.PrivateKey, nonce uint64, txs types.Transactions) {
+	testSetNonce(pool, crypto.PubkeyToAddress(key.PublicKey), nonce)
+
+	chain := pool.chain.(*testBlockChain)
+	chain.blockTxs = txs
+
+	parent := pool.currentHead.Load()
+	head := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   chain.gasLimit.Load(),
+		BaseFee:    common.Big1,
+	}
+	<-pool.requestReset(parent, head)
+}
+
+// collectDrops waits for the given number of dropped transactions to be announced
+// on the pool's drop event feed, ensuring no more are fired afterwards.
+func collectDrops(events chan txpool.DropTxsEvent, count int) ([]*txpool.DroppedTx, error) {
+	var received []*txpool.DroppedTx
+
+	for len(received) < count {
+		select {
+		case ev := <-events:
+			received = append(received, ev.Txs...)
+		case <-time.After(time.Second):
+			return nil, fmt.Errorf("drop event #%d not fired", len(received))
+		}
+	}
+	if len(received) > count {
+		return nil, fmt.Errorf("more than %d drop events fired: %v", count, received[count:])
+	}
+	select {
+	case ev := <-events:
+		return nil, fmt.Errorf("more than %d drop events fired: %v", count, ev.Txs)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return received, nil
+}
+
 // Tests that the pool rejects replacement dynamic fee transactions that don't
 // meet the minimum price bump required.
 func TestReplacementDynamicFee(t *testing.T) {
@@ -2389,6 +2578,75 @@ func testJournaling(t *testing.T, nolocals bool) {
 	pool.Close()
 }
 
+// Tests that if the remote journal is enabled, the entire pool content (pending
+// and queued remote transactions) survives a restart and is revalidated against
+// the chain state on load.
+func TestRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	journal := filepath.Join(t.TempDir(), "remotes.rlp")
+
+	// Create the original pool to inject transaction into the journal
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.RemoteJournal = journal
+	config.Rejournal = time.Second
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock())
+
+	// Create two remote accounts, one with executable and one with gapped transactions
+	first, _ := crypto.GenerateKey()
+	second, _ := crypto.GenerateKey()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(first.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(second.PublicKey), big.NewInt(1000000000))
+
+	errs := pool.addRemotesSync([]*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), first),
+		pricedTransaction(1, 100000, big.NewInt(1), first),
+		pricedTransaction(2, 100000, big.NewInt(1), first),
+		pricedTransaction(1, 100000, big.NewInt(1), second),
+	})
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	pending, queued := pool.Stats()
+	if pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	if queued != 1 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 1)
+	}
+	// Terminate the old pool, bump the nonce of the first account, create a new
+	// pool and ensure the still valid transactions survive
+	pool.Close()
+	statedb.SetNonce(crypto.PubkeyToAddress(first.PublicKey), 1)
+	blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	pool = New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock())
+	defer pool.Close()
+
+	pending, queued = pool.Stats()
+	if pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 2)
+	}
+	if queued != 1 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 1)
+	}
+	if locals := pool.all.LocalCount(); locals != 0 {
+		t.Fatalf("local transactions mismatched: have %d, want %d", locals, 0)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestStatusCheck(t *testing.T) {
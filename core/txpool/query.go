@@ -0,0 +1,207 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// QuerySort is the ordering in which the results of a pool query are returned.
+type QuerySort uint8
+
+const (
+	QuerySortNonce QuerySort = iota // Grouped by sender and ordered by nonce
+	QuerySortTip                    // Highest gas tip cap first
+	QuerySortAge                    // Oldest transaction first
+)
+
+// Query is a set of filtering, sorting and pagination criteria to select a
+// subset of the transactions tracked by the pool. Empty criteria match all
+// transactions.
+type Query struct {
+	Senders    []common.Address // Accounts the transactions should originate from
+	Recipients []common.Address // Accounts the transactions should be sent to
+	Selectors  [][4]byte        // Method selectors the call data should start with
+	MinTip     *big.Int         // Minimum gas tip cap the transactions should pay
+	Types      []uint8          // Transaction types to accept
+	MinAge     time.Duration    // Minimum time since the transactions were first seen
+	MaxAge     time.Duration    // Maximum time since the transactions were first seen
+	Status     TxStatus         // Pending or queued only (unknown matches both)
+
+	Sort   QuerySort // Ordering of the returned results
+	Offset int       // Number of matching transactions to skip (negative = none)
+	Limit  int       // Maximum number of transactions to return (0 = unlimited)
+}
+
+// QueryResult is a single transaction matched by a pool query.
+type QueryResult struct {
+	Tx     *types.Transaction // Transaction matching the query
+	From   common.Address     // Sender of the transaction
+	Status TxStatus           // Pending or queued status of the transaction
+}
+
+// Match checks whether a transaction originating from the given sender satisfies
+// the filtering criteria of the query. The status and pagination criteria are
+// not considered.
+func (q *Query) Match(from common.Address, tx *types.Transaction) bool {
+	if len(q.Senders) > 0 && !containsAddress(q.Senders, from) {
+		return false
+	}
+	if len(q.Recipients) > 0 {
+		if to := tx.To(); to == nil || !containsAddress(q.Recipients, *to) {
+			return false
+		}
+	}
+	if len(q.Selectors) > 0 {
+		data := tx.Data()
+		if len(data) < 4 {
+			return false
+		}
+		var found bool
+		for _, selector := range q.Selectors {
+			if bytes.Equal(data[:4], selector[:]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.MinTip != nil && tx.GasTipCapIntCmp(q.MinTip) < 0 {
+		return false
+	}
+	if len(q.Types) > 0 {
+		var found bool
+		for _, typ := range q.Types {
+			if tx.Type() == typ {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.MinAge > 0 || q.MaxAge > 0 {
+		age := time.Since(tx.Time())
+		if q.MinAge > 0 && age < q.MinAge {
+			return false
+		}
+		if q.MaxAge > 0 && age > q.MaxAge {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAddress checks whether an address is contained in a small list.
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Query retrieves the transactions from the pool matching the given criteria,
+// sorted and paginated as requested. The total number of matches before the
+// pagination was applied is also returned.
+func (p *TxPool) Query(q *Query) ([]*QueryResult, int) {
+	// If the query is restricted to a few senders, avoid flattening the entire
+	// pool and only retrieve the relevant accounts
+	if len(q.Senders) == 0 {
+		return q.Apply(p.Content())
+	}
+	var (
+		pending = make(map[common.Address][]*types.Transaction)
+		queued  = make(map[common.Address][]*types.Transaction)
+	)
+	for _, addr := range q.Senders {
+		if _, ok := pending[addr]; ok {
+			continue
+		}
+		pending[addr], queued[addr] = p.ContentFrom(addr)
+	}
+	return q.Apply(pending, queued)
+}
+
+// Apply filters the given pending and queued transaction sets (grouped by sender)
+// according to the query, sorting and paginating the matches as requested. The
+// total number of matches before the pagination was applied is also returned.
+func (q *Query) Apply(pending, queued map[common.Address][]*types.Transaction) ([]*QueryResult, int) {
+	var matches []*QueryResult
+
+	collect := func(set map[common.Address][]*types.Transaction, status TxStatus) {
+		if q.Status != TxStatusUnknown && q.Status != status {
+			return
+		}
+		for addr, txs := range set {
+			for _, tx := range txs {
+				if q.Match(addr, tx) {
+					matches = append(matches, &QueryResult{Tx: tx, From: addr, Status: status})
+				}
+			}
+		}
+	}
+	collect(pending, TxStatusPending)
+	collect(queued, TxStatusQueued)
+
+	// Order the matches, always falling back to the hash to keep the pagination
+	// stable between subsequent calls
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch q.Sort {
+		case QuerySortTip:
+			if cmp := a.Tx.GasTipCapCmp(b.Tx); cmp != 0 {
+				return cmp > 0
+			}
+		case QuerySortAge:
+			if !a.Tx.Time().Equal(b.Tx.Time()) {
+				return a.Tx.Time().Before(b.Tx.Time())
+			}
+		default:
+			if cmp := bytes.Compare(a.From[:], b.From[:]); cmp != 0 {
+				return cmp < 0
+			}
+			if a.Tx.Nonce() != b.Tx.Nonce() {
+				return a.Tx.Nonce() < b.Tx.Nonce()
+			}
+		}
+		return bytes.Compare(a.Tx.Hash().Bytes(), b.Tx.Hash().Bytes()) < 0
+	})
+	// Cut out the requested page of results
+	total, offset := len(matches), q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total {
+		return []*QueryResult{}, total
+	}
+	matches = matches[offset:]
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+	}
+	return matches, total
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that pool queries filter, sort and paginate transactions as requested.
+func TestQueryApply(t *testing.T) {
+	var (
+		alice = common.Address{0x01}
+		bob   = common.Address{0x02}
+		token = common.Address{0xaa}
+		other = common.Address{0xbb}
+
+		transfer = []byte{0xa9, 0x05, 0x9c, 0xbb, 0x00}
+	)
+	newTx := func(nonce uint64, to common.Address, tip int64, data []byte) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       21000,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(100),
+			Data:      data,
+		})
+	}
+	pending := map[common.Address][]*types.Transaction{
+		alice: {newTx(0, token, 1, transfer), newTx(1, other, 5, nil)},
+		bob:   {newTx(0, token, 3, transfer)},
+	}
+	queued := map[common.Address][]*types.Transaction{
+		alice: {newTx(3, token, 4, transfer)},
+	}
+	tests := []struct {
+		query  Query
+		total  int
+		result []*types.Transaction
+	}{
+		// Empty query should return everything ordered by sender and nonce
+		{
+			query:  Query{},
+			total:  4,
+			result: []*types.Transaction{pending[alice][0], pending[alice][1], queued[alice][0], pending[bob][0]},
+		},
+		// Sender filter and status filter
+		{
+			query:  Query{Senders: []common.Address{alice}, Status: TxStatusPending},
+			total:  2,
+			result: []*types.Transaction{pending[alice][0], pending[alice][1]},
+		},
+		// Recipient and selector filters
+		{
+			query:  Query{Recipients: []common.Address{token}, Selectors: [][4]byte{{0xa9, 0x05, 0x9c, 0xbb}}},
+			total:  3,
+			result: []*types.Transaction{pending[alice][0], queued[alice][0], pending[bob][0]},
+		},
+		// Minimum tip filter sorted by tip
+		{
+			query:  Query{MinTip: big.NewInt(3), Sort: QuerySortTip},
+			total:  3,
+			result: []*types.Transaction{pending[alice][1], queued[alice][0], pending[bob][0]},
+		},
+		// Pagination
+		{
+			query:  Query{Sort: QuerySortTip, Offset: 1, Limit: 2},
+			total:  4,
+			result: []*types.Transaction{queued[alice][0], pending[bob][0]},
+		},
+		{
+			query:  Query{Offset: 10},
+			total:  4,
+			result: []*types.Transaction{},
+		},
+		// Type filter
+		{
+			query:  Query{Types: []uint8{types.LegacyTxType}},
+			total:  0,
+			result: []*types.Transaction{},
+		},
+	}
+	for i, tt := range tests {
+		results, total := tt.query.Apply(pending, queued)
+		if total != tt.total {
+			t.Errorf("test %d: total mismatch: have %d, want %d", i, total, tt.total)
+		}
+		if len(results) != len(tt.result) {
+			t.Errorf("test %d: result count mismatch: have %d, want %d", i, len(results), len(tt.result))
+			continue
+		}
+		for j, res := range results {
+			if res.Tx.Hash() != tt.result[j].Hash() {
+				t.Errorf("test %d, result %d: transaction mismatch: have %x, want %x", i, j, res.Tx.Hash(), tt.result[j].Hash())
+			}
+		}
+	}
+}
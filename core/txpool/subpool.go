@@ -36,6 +36,59 @@ type Transaction struct {
 	BlobTxProofs  []kzg4844.Proof      // Proofs needed by the blob pool
 }
 
+// DropReason is the cause of a transaction being removed from the pool without
+// being included in a block.
+type DropReason uint8
+
+const (
+	DropUnknown      DropReason = iota // Transaction dropped for an unspecified reason
+	DropReplaced                       // Replaced by a transaction with the same nonce and a higher fee
+	DropUnderpriced                    // Evicted to make room for better paying transactions
+	DropGasTip                         // Tip fell below the pool's minimum gas tip threshold
+	DropStale                          // Superseded by another transaction with the same nonce included on chain
+	DropNoFunds                        // Sender can no longer pay for it, or it exceeds the block gas limit
+	DropLifetime                       // Stayed non-executable for longer than the allowed lifetime
+	DropAccountLimit                   // Exceeded the per-account slot limits
+	DropPoolLimit                      // Exceeded the global pool slot limits
+)
+
+// String implements fmt.Stringer, returning the textual representation of the
+// drop reason as used in the APIs.
+func (r DropReason) String() string {
+	switch r {
+	case DropReplaced:
+		return "replaced"
+	case DropUnderpriced:
+		return "underpriced"
+	case DropGasTip:
+		return "gastip"
+	case DropStale:
+		return "stale"
+	case DropNoFunds:
+		return "nofunds"
+	case DropLifetime:
+		return "lifetime"
+	case DropAccountLimit:
+		return "accountlimit"
+	case DropPoolLimit:
+		return "poollimit"
+	default:
+		return "unknown"
+	}
+}
+
+// DroppedTx is a transaction that was removed from the pool, along with the
+// reason of its removal and the replacement transaction, if any.
+type DroppedTx struct {
+	Tx          *types.Transaction // Transaction that was dropped
+	Reason      DropReason         // Cause of the transaction being dropped
+	Replacement *types.Transaction // Transaction taking its place (only for DropReplaced)
+}
+
+// DropTxsEvent is posted when a batch of transactions leave the pool for any
+// other reason than being included in a block.
+type DropTxsEvent struct{ Txs []*DroppedTx }
+
 // SubPool represents a specialized transaction pool that lives on its own (e.g.
 // blob pool). Since independent of how many specialized pools we have, they do
 // need to be updated in lockstep and assemble into one coherent view for block
@@ -86,6 +139,10 @@ type SubPool interface {
 	// SubscribeTransactions subscribes to new transaction events.
 	SubscribeTransactions(ch chan<- core.NewTxsEvent) event.Subscription
 
+	// SubscribeDropTransactions subscribes to dropped and replaced transaction
+	// events.
+	SubscribeDropTransactions(ch chan<- DropTxsEvent) event.Subscription
+
 	// Nonce returns the next nonce of an account, with all transactions executable
 	// by the pool already applied on top.
 	Nonce(addr common.Address) uint64
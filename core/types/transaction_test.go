@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
 )
 
 // The values in those tests are from the Transaction Tests
@@ -599,3 +600,66 @@ func TestTransactionSizes(t *testing.T) {
 		}
 	}
 }
+
+func TestSetCodeTransaction(t *testing.T) {
+	var (
+		signer     = NewPragueSigner(big.NewInt(1))
+		key, _     = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		authKey, _ = crypto.GenerateKey()
+		authAddr   = crypto.PubkeyToAddress(authKey.PublicKey)
+		target     = common.HexToAddress("0x0000000000000000000000000000000000000aaa")
+	)
+	auth, err := SignAuth(Authorization{ChainID: big.NewInt(1), Address: target, Nonce: 7}, authKey)
+	if err != nil {
+		t.Fatalf("could not sign authorization: %v", err)
+	}
+	if authority, err := auth.Authority(); err != nil {
+		t.Fatalf("could not recover authority: %v", err)
+	} else if authority != authAddr {
+		t.Fatalf("wrong authority: have %x want %x", authority, authAddr)
+	}
+	tx, err := SignNewTx(key, signer, &SetCodeTx{
+		ChainID:   uint256.NewInt(1),
+		Nonce:     3,
+		To:        common.HexToAddress("0x095e7baea6a6c7c4c2dfeb977efac326af552d87"),
+		Gas:       100000,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(10),
+		Value:     uint256.NewInt(0),
+		AuthList:  []Authorization{auth},
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	for _, codec := range []func(*Transaction) (*Transaction, error){encodeDecodeBinary, encodeDecodeJSON} {
+		parsed, err := codec(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := assertEqual(parsed, tx); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed.AuthList(), tx.AuthList()) {
+			t.Fatalf("auth list mismatch: have %v want %v", parsed.AuthList(), tx.AuthList())
+		}
+		from, err := Sender(signer, parsed)
+		if err != nil {
+			t.Fatalf("could not recover sender: %v", err)
+		}
+		if want := crypto.PubkeyToAddress(key.PublicKey); from != want {
+			t.Fatalf("wrong sender: have %x want %x", from, want)
+		}
+	}
+	// Set code transactions are not accepted before Prague.
+	if _, err := Sender(NewCancunSigner(big.NewInt(1)), tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("expected %v from cancun signer, got %v", ErrTxTypeNotSupported, err)
+	}
+	// Check the delegation designator helpers.
+	code := AddressToDelegation(target)
+	if addr, ok := ParseDelegation(code); !ok || addr != target {
+		t.Fatalf("failed to parse delegation %x: have %x, %v", code, addr, ok)
+	}
+	if _, ok := ParseDelegation(append(code, 0x00)); ok {
+		t.Fatal("parsed delegation with trailing data")
+	}
+}
@@ -53,10 +53,14 @@ type Contract struct {
 	jumpdests map[common.Hash]bitvec // Aggregated result of JUMPDEST analysis.
 	analysis  bitvec                 // Locally cached result of JUMPDEST analysis
 
-	Code     []byte
-	CodeHash common.Hash
-	CodeAddr *common.Address
-	Input    []byte
+	Code      []byte
+	CodeHash  common.Hash
+	CodeAddr  *common.Address
+	Input     []byte
+	Container *Container // Parsed EOF container, nil for legacy code
+
+	codeSection uint64           // EOF code section being executed
+	returnStack []*returnContext // EOF return stack of CALLF
 
 	Gas   uint64
 	value *big.Int
@@ -141,6 +145,19 @@ func (c *Contract) AsDelegate() *Contract {
 	return c
 }
 
+// returnContext is the state saved by CALLF to resume the calling EOF code
+// section on RETF.
+type returnContext struct {
+	section     uint64
+	pc          uint64
+	stackHeight int
+}
+
+// CodeSection returns the index of the EOF code section being executed.
+func (c *Contract) CodeSection() uint64 {
+	return c.codeSection
+}
+
 // GetOp returns the n'th element in the contract's byte array
 func (c *Contract) GetOp(n uint64) OpCode {
 	if n < uint64(len(c.Code)) {
@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"encoding/binary"
 	"fmt"
 	"sort"
 
@@ -291,3 +292,143 @@ func enable4844(jt *JumpTable) {
 		maxStack:    maxStack(1, 1),
 	}
 }
+
+// enable7702 applies the EIP-7702 changes to support delegation designators.
+func enable7702(jt *JumpTable) {
+	jt[CALL].dynamicGas = gasCallEIP7702
+	jt[CALLCODE].dynamicGas = gasCallCodeEIP7702
+	jt[STATICCALL].dynamicGas = gasStaticCallEIP7702
+	jt[DELEGATECALL].dynamicGas = gasDelegateCallEIP7702
+}
+
+// enableEOF applies the EOF v1 instruction changes to the given jump table:
+// - Removes CALLCODE, SELFDESTRUCT, JUMP, JUMPI and PC (EIP-3670, EIP-4200)
+// - Adds the static relative jumps RJUMP, RJUMPI and RJUMPV (EIP-4200)
+// - Adds the function instructions CALLF and RETF (EIP-4750)
+func enableEOF(jt *JumpTable) {
+	undefined := &operation{
+		execute:   opUndefined,
+		maxStack:  maxStack(0, 0),
+		undefined: true,
+	}
+	jt[CALLCODE] = undefined
+	jt[SELFDESTRUCT] = undefined
+	jt[JUMP] = undefined
+	jt[JUMPI] = undefined
+	jt[PC] = undefined
+
+	jt[RJUMP] = &operation{
+		execute:     opRjump,
+		constantGas: GasQuickStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+	jt[RJUMPI] = &operation{
+		execute:     opRjumpi,
+		constantGas: params.RjumpiGas,
+		minStack:    minStack(1, 0),
+		maxStack:    maxStack(1, 0),
+	}
+	jt[RJUMPV] = &operation{
+		execute:     opRjumpv,
+		constantGas: params.RjumpiGas,
+		minStack:    minStack(1, 0),
+		maxStack:    maxStack(1, 0),
+	}
+	// The stack effects of CALLF and RETF depend on the type of the code
+	// section, they are checked by the ops themselves and during validation.
+	jt[CALLF] = &operation{
+		execute:     opCallf,
+		constantGas: GasFastStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+	jt[RETF] = &operation{
+		execute:     opRetf,
+		constantGas: GasFastestStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+}
+
+// opRjump implements the RJUMP opcode. Jump offsets are relative to the end
+// of the immediate, which validation ensures is in bounds.
+func opRjump(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.evm.abort.Load() {
+		return nil, errStopToken
+	}
+	offset := parseInt16(scope.Contract.Code[*pc+1:])
+	*pc = uint64(int64(*pc+3)+int64(offset)) - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
+
+// opRjumpi implements the RJUMPI opcode.
+func opRjumpi(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	cond := scope.Stack.pop()
+	if cond.IsZero() {
+		*pc += 2 // skip the immediate
+		return nil, nil
+	}
+	return opRjump(pc, interpreter, scope)
+}
+
+// opRjumpv implements the RJUMPV opcode, jumping to the offset selected by the
+// top of the stack, or falling through if it is out of range.
+func opRjumpv(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.evm.abort.Load() {
+		return nil, errStopToken
+	}
+	var (
+		code  = scope.Contract.Code
+		count = uint64(code[*pc+1])
+		idx   = scope.Stack.pop()
+		next  = *pc + 2 + 2*count
+	)
+	if !idx.LtUint64(count) {
+		*pc = next - 1 // fall through
+		return nil, nil
+	}
+	offset := parseInt16(code[*pc+2+2*idx.Uint64():])
+	*pc = uint64(int64(next)+int64(offset)) - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
+
+// opCallf implements the CALLF opcode, calling into another code section.
+func opCallf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		container = scope.Contract.Container
+		section   = uint64(binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:]))
+		typ       = container.Types[section]
+	)
+	if height := scope.Stack.len() + int(typ.MaxStackHeight) - int(typ.Input); height > int(params.StackLimit) {
+		return nil, &ErrStackOverflow{stackLen: height, limit: int(params.StackLimit)}
+	}
+	contract := scope.Contract
+	if len(contract.returnStack) >= maxReturnStackHeight {
+		return nil, ErrReturnStackExceeded
+	}
+	contract.returnStack = append(contract.returnStack, &returnContext{
+		section:     contract.codeSection,
+		pc:          *pc + 3,
+		stackHeight: scope.Stack.len() - int(typ.Input),
+	})
+	contract.codeSection = section
+	*pc = container.offsets[section] - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
+
+// opRetf implements the RETF opcode, returning to the calling code section.
+// Returning from the first code section halts execution like STOP.
+func opRetf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	contract := scope.Contract
+	if len(contract.returnStack) == 0 {
+		return nil, errStopToken
+	}
+	last := len(contract.returnStack) - 1
+	retCtx := contract.returnStack[last]
+	contract.returnStack = contract.returnStack[:last]
+
+	contract.codeSection = retCtx.section
+	*pc = retCtx.pc - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
@@ -0,0 +1,298 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	offsetVersion   = 2
+	offsetTypesKind = 3
+	offsetCodeKind  = 6
+
+	kindTypes = 1
+	kindCode  = 2
+	kindData  = 3
+
+	eofFormatByte = 0xef
+	eof1Version   = 1
+
+	maxInputItems        = 127
+	maxOutputItems       = 127
+	maxStackHeight       = 1023
+	maxCodeSections      = 1024
+	maxReturnStackHeight = 1024
+)
+
+var (
+	eofMagic = []byte{0xef, 0x00}
+
+	errInvalidMagic           = errors.New("invalid magic")
+	errInvalidVersion         = errors.New("invalid version")
+	errMissingTypeHeader      = errors.New("missing type header")
+	errInvalidTypeSize        = errors.New("invalid type section size")
+	errMissingCodeHeader      = errors.New("missing code header")
+	errInvalidCodeHeader      = errors.New("invalid code header")
+	errInvalidCodeSize        = errors.New("invalid code size")
+	errMissingDataHeader      = errors.New("missing data header")
+	errMissingTerminator      = errors.New("missing header terminator")
+	errTooManyInputs          = errors.New("invalid type content, too many inputs")
+	errTooManyOutputs         = errors.New("invalid type content, too many outputs")
+	errInvalidSection0Type    = errors.New("invalid section 0 type, input and output should be zero")
+	errTooLargeMaxStackHeight = errors.New("invalid type content, max stack height exceeds limit")
+	errInvalidContainerSize   = errors.New("invalid container size")
+)
+
+// FunctionMetadata is the type of a code section: the number of stack items
+// it consumes and produces, and the stack height it reaches at most.
+type FunctionMetadata struct {
+	Input          uint8
+	Output         uint8
+	MaxStackHeight uint16
+}
+
+// Container is an EOF v1 container (EIP-3540), consisting of the type and code
+// sections of the functions and a trailing data section.
+type Container struct {
+	Types []*FunctionMetadata
+	Code  [][]byte
+	Data  []byte
+
+	// offsets holds the position of each code section within the container
+	// it was unmarshalled from. The interpreter runs EOF code on the raw
+	// container, so program counters are container offsets.
+	offsets []uint64
+}
+
+// hasEOFMagic reports whether the code begins with the EOF magic.
+func hasEOFMagic(code []byte) bool {
+	return len(code) >= len(eofMagic) && bytes.Equal(eofMagic, code[:len(eofMagic)])
+}
+
+// isEOFVersion1 reports whether the code is an EOF container of version 1.
+func isEOFVersion1(code []byte) bool {
+	return hasEOFMagic(code) && len(code) > offsetVersion && code[offsetVersion] == eof1Version
+}
+
+// MarshalBinary encodes an EOF container into binary format.
+func (c *Container) MarshalBinary() []byte {
+	b := make([]byte, 0, 2+1+3+3+2*len(c.Code)+3+1)
+	b = append(b, eofMagic...)
+	b = append(b, eof1Version)
+
+	// Write section headers.
+	b = append(b, kindTypes)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.Types)*4))
+	b = append(b, kindCode)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.Code)))
+	for _, code := range c.Code {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(code)))
+	}
+	b = append(b, kindData)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.Data)))
+	b = append(b, 0) // terminator
+
+	// Write section contents.
+	for _, ty := range c.Types {
+		b = append(b, ty.Input, ty.Output)
+		b = binary.BigEndian.AppendUint16(b, ty.MaxStackHeight)
+	}
+	for _, code := range c.Code {
+		b = append(b, code...)
+	}
+	b = append(b, c.Data...)
+	return b
+}
+
+// UnmarshalBinary decodes an EOF container, checking that its structure is
+// well-formed. The code itself is not validated, see ValidateCode.
+func (c *Container) UnmarshalBinary(b []byte) error {
+	if !hasEOFMagic(b) {
+		return fmt.Errorf("%w: want %x", errInvalidMagic, eofMagic)
+	}
+	if len(b) < 14 {
+		return errInvalidContainerSize
+	}
+	if !isEOFVersion1(b) {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidVersion, b[offsetVersion], eof1Version)
+	}
+	// Parse the type section header.
+	kind, typesSize, err := parseSection(b, offsetTypesKind)
+	if err != nil {
+		return err
+	}
+	if kind != kindTypes {
+		return fmt.Errorf("%w: found section kind %x instead", errMissingTypeHeader, kind)
+	}
+	if typesSize < 4 || typesSize%4 != 0 {
+		return fmt.Errorf("%w: type section size must be divisible by 4, have %d", errInvalidTypeSize, typesSize)
+	}
+	if typesSize/4 > maxCodeSections {
+		return fmt.Errorf("%w: type section must not exceed 4*1024, have %d", errInvalidTypeSize, typesSize)
+	}
+	// Parse the code section header.
+	kind, codeSizes, err := parseSectionList(b, offsetCodeKind)
+	if err != nil {
+		return err
+	}
+	if kind != kindCode {
+		return fmt.Errorf("%w: found section kind %x instead", errMissingCodeHeader, kind)
+	}
+	if len(codeSizes) != typesSize/4 {
+		return fmt.Errorf("%w: mismatch of code sections count and type signatures, types %d, code %d", errInvalidCodeSize, typesSize/4, len(codeSizes))
+	}
+	// Parse the data section header.
+	offsetDataKind := offsetCodeKind + 2 + 2*len(codeSizes) + 1
+	kind, dataSize, err := parseSection(b, offsetDataKind)
+	if err != nil {
+		return err
+	}
+	if kind != kindData {
+		return fmt.Errorf("%w: found section kind %x instead", errMissingDataHeader, kind)
+	}
+	// Check for the terminator and the expected container size.
+	offsetTerminator := offsetDataKind + 3
+	if len(b) <= offsetTerminator {
+		return fmt.Errorf("%w: invalid offset terminator", errInvalidContainerSize)
+	}
+	if b[offsetTerminator] != 0 {
+		return fmt.Errorf("%w: have %x", errMissingTerminator, b[offsetTerminator])
+	}
+	expectedSize := offsetTerminator + 1 + typesSize + dataSize
+	for _, size := range codeSizes {
+		expectedSize += size
+	}
+	if len(b) != expectedSize {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidContainerSize, len(b), expectedSize)
+	}
+	// Parse the types section.
+	idx := offsetTerminator + 1
+	types := make([]*FunctionMetadata, 0, typesSize/4)
+	for i := 0; i < typesSize/4; i++ {
+		sig := &FunctionMetadata{
+			Input:          b[idx+i*4],
+			Output:         b[idx+i*4+1],
+			MaxStackHeight: binary.BigEndian.Uint16(b[idx+i*4+2:]),
+		}
+		if sig.Input > maxInputItems {
+			return fmt.Errorf("%w for section %d: have %d", errTooManyInputs, i, sig.Input)
+		}
+		if sig.Output > maxOutputItems {
+			return fmt.Errorf("%w for section %d: have %d", errTooManyOutputs, i, sig.Output)
+		}
+		if sig.MaxStackHeight > maxStackHeight {
+			return fmt.Errorf("%w for section %d: have %d", errTooLargeMaxStackHeight, i, sig.MaxStackHeight)
+		}
+		types = append(types, sig)
+	}
+	if types[0].Input != 0 || types[0].Output != 0 {
+		return fmt.Errorf("%w: have %d, %d", errInvalidSection0Type, types[0].Input, types[0].Output)
+	}
+	c.Types = types
+
+	// Parse the code sections.
+	idx += typesSize
+	code := make([][]byte, len(codeSizes))
+	offsets := make([]uint64, len(codeSizes))
+	for i, size := range codeSizes {
+		if size == 0 {
+			return fmt.Errorf("%w for section %d: size must not be 0", errInvalidCodeSize, i)
+		}
+		code[i] = b[idx : idx+size]
+		offsets[i] = uint64(idx)
+		idx += size
+	}
+	c.Code = code
+	c.offsets = offsets
+
+	// Parse the data section.
+	c.Data = b[idx : idx+dataSize]
+
+	return nil
+}
+
+// ValidateCode validates each code section of the container against the EOF
+// v1 rules, using the given jump table for the instruction definitions.
+func (c *Container) ValidateCode(jt *JumpTable) error {
+	for i, code := range c.Code {
+		if err := validateCode(code, i, c.Types, jt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateEOF checks that the code is a well-formed EOF container whose code
+// sections are valid under the given jump table.
+func validateEOF(code []byte, jt *JumpTable) error {
+	var c Container
+	if err := c.UnmarshalBinary(code); err != nil {
+		return err
+	}
+	return c.ValidateCode(jt)
+}
+
+// parseInt16 decodes a big-endian signed 16 bit integer, as used for relative
+// jump offsets.
+func parseInt16(b []byte) int {
+	return int(int16(binary.BigEndian.Uint16(b)))
+}
+
+// parseSection decodes a (kind, size) pair from an EOF header.
+func parseSection(b []byte, idx int) (kind, size int, err error) {
+	if idx+3 > len(b) {
+		return 0, 0, errInvalidContainerSize
+	}
+	return int(b[idx]), int(binary.BigEndian.Uint16(b[idx+1:])), nil
+}
+
+// parseSectionList decodes a (kind, len, []codeSize) section list from an EOF
+// header.
+func parseSectionList(b []byte, idx int) (kind int, list []int, err error) {
+	if idx >= len(b) {
+		return 0, nil, errInvalidContainerSize
+	}
+	kind = int(b[idx])
+	list, err = parseList(b, idx+1)
+	if err != nil {
+		return 0, nil, err
+	}
+	return kind, list, nil
+}
+
+// parseList decodes a list of uint16.
+func parseList(b []byte, idx int) ([]int, error) {
+	if len(b) < idx+2 {
+		return nil, errInvalidContainerSize
+	}
+	count := binary.BigEndian.Uint16(b[idx:])
+	if count == 0 || count > maxCodeSections {
+		return nil, fmt.Errorf("%w: have %d sections", errInvalidCodeHeader, count)
+	}
+	if len(b) < idx+2+int(count)*2 {
+		return nil, errInvalidContainerSize
+	}
+	list := make([]int, count)
+	for i := 0; i < int(count); i++ {
+		list[i] = int(binary.BigEndian.Uint16(b[idx+2+2*i:]))
+	}
+	return list, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestEOFMarshaling(t *testing.T) {
+	for i, test := range []struct {
+		want Container
+		err  error
+	}{
+		{
+			want: Container{
+				Types: []*FunctionMetadata{{Input: 0, Output: 0, MaxStackHeight: 1}},
+				Code:  [][]byte{common.Hex2Bytes("604200")},
+				Data:  []byte{},
+			},
+		},
+		{
+			want: Container{
+				Types: []*FunctionMetadata{{Input: 0, Output: 0, MaxStackHeight: 1}},
+				Code:  [][]byte{common.Hex2Bytes("604200")},
+				Data:  []byte{0x01, 0x02, 0x03},
+			},
+		},
+		{
+			want: Container{
+				Types: []*FunctionMetadata{
+					{Input: 0, Output: 0, MaxStackHeight: 1},
+					{Input: 2, Output: 3, MaxStackHeight: 4},
+					{Input: 1, Output: 1, MaxStackHeight: 1},
+				},
+				Code: [][]byte{
+					common.Hex2Bytes("604200"),
+					common.Hex2Bytes("6042604200"),
+					common.Hex2Bytes("00"),
+				},
+				Data: []byte{},
+			},
+		},
+	} {
+		var (
+			b   = test.want.MarshalBinary()
+			got Container
+		)
+		if err := got.UnmarshalBinary(b); err != nil && err != test.err {
+			t.Fatalf("test %d: got error \"%v\", want \"%v\"", i, err, test.err)
+		}
+		got.offsets = nil // not part of the encoding
+		if !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("test %d: objects are not equal\nhave: %#v\nwant: %#v", i, got, test.want)
+		}
+		if have := got.MarshalBinary(); !bytes.Equal(have, b) {
+			t.Fatalf("test %d: re-encoding mismatch\nhave: %x\nwant: %x", i, have, b)
+		}
+	}
+}
+
+func TestEOFUnmarshalErrors(t *testing.T) {
+	for i, test := range []struct {
+		code string
+		err  error
+	}{
+		{"ef", errInvalidMagic},
+		{"ef010101000402000100030300000000000001604200", errInvalidMagic},
+		{"ef0001", errInvalidContainerSize},
+		{"ef000201000402000100030300000000000001604200", errInvalidVersion},
+		{"ef000102000402000100030300000000000001604200", errMissingTypeHeader},
+		{"ef000101000302000100030300000000000001604200", errInvalidTypeSize},
+		{"ef000101000403000100030300000000000001604200", errMissingCodeHeader},
+		{"ef000101000402000000030300000000000001604200", errInvalidCodeHeader},
+		{"ef000101000402000100030400000000000001604200", errMissingDataHeader},
+		{"ef000101000402000100030300000100000001604200", errMissingTerminator},
+		{"ef00010100040200010003030000000000000160420000", errInvalidContainerSize},
+		{"ef0001010004020001000303000000000000016042", errInvalidContainerSize},
+		{"ef000101000802000100030300000000000001604200", errInvalidCodeSize},
+		{"ef000101000402000100000300000000000000", errInvalidCodeSize},
+		{"ef000101000402000100030300000000010001604200", errInvalidSection0Type},
+		{"ef00010100080200020003000103000000000000018000000160420000", errTooManyInputs},
+		{"ef00010100080200020003000103000000000000010080000160420000", errTooManyOutputs},
+		{"ef000101000402000100030300000000000400604200", errTooLargeMaxStackHeight},
+	} {
+		var c Container
+		err := c.UnmarshalBinary(common.Hex2Bytes(test.code))
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: have error %v, want %v", i, err, test.err)
+		}
+	}
+}
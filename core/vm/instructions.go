@@ -17,6 +17,8 @@
 package vm
 
 import (
+	"encoding/binary"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -437,21 +439,48 @@ func opBlockhash(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) (
 		num.Clear()
 		return nil, nil
 	}
+	// From Prague on, the accessible range is configurable as the hashes are
+	// served from the history storage (EIP-2935).
+	window := params.BlockHashServeWindow
+	if interpreter.evm.chainRules.IsPrague {
+		window = interpreter.evm.chainConfig.BlockHashServeWindow()
+	}
 	var upper, lower uint64
 	upper = interpreter.evm.Context.BlockNumber.Uint64()
-	if upper < 257 {
+	if upper <= window {
 		lower = 0
 	} else {
-		lower = upper - 256
+		lower = upper - window
 	}
-	if num64 >= lower && num64 < upper {
-		num.SetBytes(interpreter.evm.Context.GetHash(num64).Bytes())
-	} else {
+	if num64 < lower || num64 >= upper {
 		num.Clear()
+		return nil, nil
 	}
+	if interpreter.evm.chainRules.IsPrague {
+		hash := interpreter.evm.StateDB.GetState(params.HistoryStorageAddress, HistoryStorageSlot(num64))
+		if hash != (common.Hash{}) {
+			num.SetBytes(hash.Bytes())
+			return nil, nil
+		}
+		// Blocks from before the fork are not in the history storage, only
+		// the legacy range is accessible for those.
+		if upper-num64 > params.BlockHashServeWindow {
+			num.Clear()
+			return nil, nil
+		}
+	}
+	num.SetBytes(interpreter.evm.Context.GetHash(num64).Bytes())
 	return nil, nil
 }
 
+// HistoryStorageSlot returns the slot of the history storage holding the hash
+// of the given block (EIP-2935).
+func HistoryStorageSlot(number uint64) common.Hash {
+	var slot common.Hash
+	binary.BigEndian.PutUint64(slot[common.HashLength-8:], number%params.HistoryServeWindow)
+	return slot
+}
+
 func opCoinbase(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
 	scope.Stack.push(new(uint256.Int).SetBytes(interpreter.evm.Context.Coinbase.Bytes()))
 	return nil, nil
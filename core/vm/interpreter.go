@@ -17,6 +17,8 @@
 package vm
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -41,8 +43,11 @@ type ScopeContext struct {
 
 // EVMInterpreter represents an EVM interpreter
 type EVMInterpreter struct {
-	evm   *EVM
-	table *JumpTable
+	evm      *EVM
+	table    *JumpTable
+	eofTable *JumpTable // Instructions for EOF code, nil before Prague
+
+	containers map[common.Hash]*Container // Validated EOF containers by code hash
 
 	hasher    crypto.KeccakState // Keccak256 hasher instance shared across opcodes
 	hasherBuf common.Hash        // Keccak256 hasher result array shared aross opcodes
@@ -56,6 +61,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	// If jump table was not initialised we set the default one.
 	var table *JumpTable
 	switch {
+	case evm.chainRules.IsPrague:
+		table = &pragueInstructionSet
 	case evm.chainRules.IsCancun:
 		table = &cancunInstructionSet
 	case evm.chainRules.IsShanghai:
@@ -95,7 +102,34 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		}
 	}
 	evm.Config.ExtraEips = extraEips
-	return &EVMInterpreter{evm: evm, table: table}
+
+	interpreter := &EVMInterpreter{evm: evm, table: table}
+	if evm.chainRules.IsPrague {
+		interpreter.eofTable = &pragueEOFInstructionSet
+		interpreter.containers = make(map[common.Hash]*Container)
+	}
+	return interpreter
+}
+
+// loadContainer parses and validates the EOF container of the contract code,
+// caching the result for code that is known by hash.
+func (in *EVMInterpreter) loadContainer(contract *Contract) (*Container, error) {
+	if contract.CodeHash != (common.Hash{}) {
+		if c, ok := in.containers[contract.CodeHash]; ok {
+			return c, nil
+		}
+	}
+	c := new(Container)
+	if err := c.UnmarshalBinary(contract.Code); err != nil {
+		return nil, err
+	}
+	if err := c.ValidateCode(in.eofTable); err != nil {
+		return nil, err
+	}
+	if contract.CodeHash != (common.Hash{}) {
+		in.containers[contract.CodeHash] = c
+	}
+	return c, nil
 }
 
 // Run loops and evaluates the contract's code with the given input data and returns
@@ -124,6 +158,27 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	if len(contract.Code) == 0 {
 		return nil, nil
 	}
+	// EOF code runs with its own instruction set, starting at the first code
+	// section. Program counters remain offsets into the whole container.
+	//
+	// For optimisation reason we're using uint64 as the program counter.
+	// It's theoretically possible to go above 2^64. The YP defines the PC
+	// to be uint256. Practically much less so feasible.
+	var (
+		table = in.table
+		pc    = uint64(0) // program counter
+	)
+	if in.eofTable != nil && hasEOFMagic(contract.Code) {
+		if contract.Container == nil {
+			container, err := in.loadContainer(contract)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidEOFCode, err)
+			}
+			contract.Container = container
+		}
+		table = in.eofTable
+		pc = contract.Container.offsets[0]
+	}
 
 	var (
 		op          OpCode        // current opcode
@@ -134,10 +189,6 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 			Stack:    stack,
 			Contract: contract,
 		}
-		// For optimisation reason we're using uint64 as the program counter.
-		// It's theoretically possible to go above 2^64. The YP defines the PC
-		// to be uint256. Practically much less so feasible.
-		pc   = uint64(0) // program counter
 		cost uint64
 		// copies used by tracer
 		pcCopy  uint64 // needed for the deferred EVMLogger
@@ -177,7 +228,7 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
 		op = contract.GetOp(pc)
-		operation := in.table[op]
+		operation := table[op]
 		cost = operation.constantGas // For tracing
 		// Validate stack
 		if sLen := stack.len(); sLen < operation.minStack {
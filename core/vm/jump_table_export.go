@@ -29,7 +29,8 @@ func LookupInstructionSet(rules params.Rules) (JumpTable, error) {
 	case rules.IsVerkle:
 		return newCancunInstructionSet(), errors.New("verkle-fork not defined yet")
 	case rules.IsPrague:
-		return newCancunInstructionSet(), errors.New("prague-fork not defined yet")
+		// See LookupEOFInstructionSet for the instructions available to EOF code.
+		return newPragueInstructionSet(), nil
 	case rules.IsCancun:
 		return newCancunInstructionSet(), nil
 	case rules.IsShanghai:
@@ -56,6 +57,15 @@ func LookupInstructionSet(rules params.Rules) (JumpTable, error) {
 	return newFrontierInstructionSet(), nil
 }
 
+// LookupEOFInstructionSet returns the instructionset for EOF code at the fork
+// configured by the rules, or an error if EOF is not enabled yet.
+func LookupEOFInstructionSet(rules params.Rules) (JumpTable, error) {
+	if !rules.IsPrague {
+		return JumpTable{}, errors.New("eof not enabled before prague")
+	}
+	return newPragueEOFInstructionSet(), nil
+}
+
 // Stack returns the mininum and maximum stack requirements.
 func (op *operation) Stack() (int, int) {
 	return op.minStack, op.maxStack
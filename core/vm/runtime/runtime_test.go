@@ -17,6 +17,8 @@
 package runtime
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -912,3 +914,104 @@ func BenchmarkTracerStepVsCallFrame(b *testing.B) {
 	benchmarkNonModifyingCode(10000000, code, "tracer-step-10M", stepTracer, b)
 	benchmarkNonModifyingCode(10000000, code, "tracer-call-frame-10M", callFrameTracer, b)
 }
+
+// pragueConfig returns a runtime config with EOF enabled.
+func pragueConfig() *Config {
+	config := *params.TestChainConfig
+	config.ShanghaiTime = new(uint64)
+	config.CancunTime = new(uint64)
+	config.PragueTime = new(uint64)
+	return &Config{ChainConfig: &config}
+}
+
+func TestEOFExecution(t *testing.T) {
+	container := &vm.Container{
+		Types: []*vm.FunctionMetadata{
+			{Input: 0, Output: 0, MaxStackHeight: 2},
+			{Input: 1, Output: 1, MaxStackHeight: 2},
+		},
+		Code: [][]byte{
+			{
+				byte(vm.PUSH1), 5,
+				byte(vm.CALLF), 0x00, 0x01,
+				byte(vm.PUSH0),
+				byte(vm.MSTORE),
+				byte(vm.PUSH1), 32,
+				byte(vm.PUSH0),
+				byte(vm.RETURN),
+			},
+			{
+				byte(vm.DUP1),
+				byte(vm.ADD),
+				byte(vm.RETF),
+			},
+		},
+		Data: []byte{0xde, 0xad},
+	}
+	ret, _, err := Execute(container.MarshalBinary(), nil, pragueConfig())
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if num := new(big.Int).SetBytes(ret); num.Cmp(big.NewInt(10)) != 0 {
+		t.Error("Expected 10, got", num)
+	}
+	// EOF instructions are not available to legacy code
+	_, _, err = Execute([]byte{byte(vm.RJUMP), 0x00, 0x00, byte(vm.STOP)}, nil, pragueConfig())
+	if _, ok := err.(*vm.ErrInvalidOpCode); !ok {
+		t.Errorf("expected invalid opcode error for legacy code, got %v", err)
+	}
+	// Invalid containers can't be executed
+	code := container.MarshalBinary()
+	code[len(code)-len(container.Data)-1] = byte(vm.ADD) // RETF -> ADD
+	if _, _, err = Execute(code, nil, pragueConfig()); !errors.Is(err, vm.ErrInvalidEOFCode) {
+		t.Errorf("expected invalid eof code error, got %v", err)
+	}
+}
+
+func TestEOFCreate(t *testing.T) {
+	// initcode returns a container that copies its data section as the code
+	// to deploy
+	initcode := func(deployed []byte) []byte {
+		c := &vm.Container{
+			Types: []*vm.FunctionMetadata{{Input: 0, Output: 0, MaxStackHeight: 3}},
+			Code: [][]byte{{
+				byte(vm.PUSH1), byte(len(deployed)),
+				byte(vm.PUSH1), 0x00, // data offset, patched below
+				byte(vm.PUSH0),
+				byte(vm.CODECOPY),
+				byte(vm.PUSH1), byte(len(deployed)),
+				byte(vm.PUSH0),
+				byte(vm.RETURN),
+			}},
+			Data: deployed,
+		}
+		c.Code[0][3] = byte(len(c.MarshalBinary()) - len(deployed))
+		return c.MarshalBinary()
+	}
+	deployed := (&vm.Container{
+		Types: []*vm.FunctionMetadata{{Input: 0, Output: 0, MaxStackHeight: 0}},
+		Code:  [][]byte{{byte(vm.STOP)}},
+		Data:  []byte{},
+	}).MarshalBinary()
+
+	cfg := pragueConfig()
+	_, addr, _, err := Create(initcode(deployed), cfg)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if code := cfg.State.GetCode(addr); !bytes.Equal(code, deployed) {
+		t.Errorf("deployed code mismatch: have %x, want %x", code, deployed)
+	}
+	// EOF initcode may only deploy valid EOF code
+	if _, _, _, err = Create(initcode([]byte{byte(vm.STOP)}), pragueConfig()); !errors.Is(err, vm.ErrInvalidEOFCode) {
+		t.Errorf("expected invalid eof code error, got %v", err)
+	}
+	// Invalid EOF initcode fails the creation
+	if _, _, _, err = Create(deployed[:len(deployed)-1], pragueConfig()); !errors.Is(err, vm.ErrInvalidEOFInitcode) {
+		t.Errorf("expected invalid eof initcode error, got %v", err)
+	}
+	// Before Prague, EOF initcode is legacy code and fails on the 0xEF opcode
+	if _, _, _, err = Create(initcode(deployed), nil); err == nil {
+		t.Error("expected error before prague")
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	errUndefinedInstruction   = errors.New("undefined instruction")
+	errTruncatedImmediate     = errors.New("truncated immediate")
+	errInvalidSectionArgument = errors.New("invalid section argument")
+	errInvalidJumpDest        = errors.New("invalid jump destination")
+	errConflictingStack       = errors.New("conflicting stack height")
+	errInvalidBranchCount     = errors.New("invalid number of branches in jump table")
+	errInvalidOutputs         = errors.New("invalid number of outputs")
+	errInvalidMaxStackHeight  = errors.New("invalid max stack height")
+	errInvalidCodeTermination = errors.New("invalid code termination")
+	errUnreachableCode        = errors.New("unreachable code")
+	errStackUnderflow         = errors.New("stack underflow")
+	errStackOverflow          = errors.New("stack overflow")
+)
+
+// validateCode validates a code section of an EOF container against the rules
+// of EIP-3670, EIP-4200, EIP-4750 and EIP-5450:
+//
+//   - all instructions are defined and their immediates are not truncated,
+//   - relative jumps target instructions within the section,
+//   - CALLF only targets existing code sections,
+//   - the code ends with a terminating instruction or an unconditional jump,
+//   - every instruction is reachable with a consistent stack height, which
+//     never underflows and peaks at the declared max stack height.
+func validateCode(code []byte, section int, metadata []*FunctionMetadata, jt *JumpTable) error {
+	var (
+		i        = 0
+		op       OpCode
+		analysis = make(bitvec, len(code)/8+1+4)
+	)
+	// First pass: check the instructions and their immediates, marking the
+	// positions of immediate data so jump targets can be checked.
+	for i < len(code) {
+		op = OpCode(code[i])
+		if jt[op].undefined && op != INVALID {
+			return fmt.Errorf("%w: op %s, pos %d", errUndefinedInstruction, op, i)
+		}
+		size := immediateSize(op, code, i)
+		if i+size >= len(code) && size > 0 {
+			return fmt.Errorf("%w: op %s, pos %d", errTruncatedImmediate, op, i)
+		}
+		if op == RJUMPV {
+			if code[i+1] == 0 {
+				return fmt.Errorf("%w: pos %d", errInvalidBranchCount, i)
+			}
+		}
+		if op == CALLF {
+			arg := binary.BigEndian.Uint16(code[i+1:])
+			if int(arg) >= len(metadata) {
+				return fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(metadata), i)
+			}
+		}
+		for j := 1; j <= size; j++ {
+			analysis.set1(uint64(i + j))
+		}
+		i += size + 1
+	}
+	// Second pass: check the jump targets now that all immediates are known.
+	for i = 0; i < len(code); i += immediateSize(OpCode(code[i]), code, i) + 1 {
+		for _, dest := range jumpTargets(OpCode(code[i]), code, i) {
+			if dest < 0 || dest >= len(code) {
+				return fmt.Errorf("%w: out-of-bounds offset: offset %d, dest %d, pos %d", errInvalidJumpDest, dest-i, dest, i)
+			}
+			if !analysis.codeSegment(uint64(dest)) {
+				return fmt.Errorf("%w: offset into immediate: offset %d, dest %d, pos %d", errInvalidJumpDest, dest-i, dest, i)
+			}
+		}
+	}
+	return validateControlFlow(code, section, metadata, jt)
+}
+
+// validateControlFlow walks all execution paths of a code section and checks
+// that the stack height at each instruction is the same on all of them.
+func validateControlFlow(code []byte, section int, metadata []*FunctionMetadata, jt *JumpTable) error {
+	type item struct {
+		pos    int
+		height int
+	}
+	var (
+		heights   = make(map[int]int)
+		worklist  = []item{{0, int(metadata[section].Input)}}
+		maxHeight = int(metadata[section].Input)
+	)
+	for len(worklist) > 0 {
+		var (
+			idx    = len(worklist) - 1
+			pos    = worklist[idx].pos
+			height = worklist[idx].height
+		)
+		worklist = worklist[:idx]
+
+	outer:
+		for pos < len(code) {
+			op := OpCode(code[pos])
+
+			// Check if the stack height at this position was already
+			// established by another path.
+			if want, ok := heights[pos]; ok {
+				if height != want {
+					return fmt.Errorf("%w: have %d, want %d", errConflictingStack, height, want)
+				}
+				break
+			}
+			heights[pos] = height
+
+			// Apply the stack effect of the instruction.
+			switch op {
+			case CALLF:
+				arg := binary.BigEndian.Uint16(code[pos+1:])
+				callee := metadata[arg]
+				if height < int(callee.Input) {
+					return fmt.Errorf("%w: at pos %d", errStackUnderflow, pos)
+				}
+				height += int(callee.Output) - int(callee.Input)
+			case RETF:
+				if int(metadata[section].Output) != height {
+					return fmt.Errorf("%w: have %d, want %d, at pos %d", errInvalidOutputs, metadata[section].Output, height, pos)
+				}
+				break outer
+			default:
+				var (
+					pops   = jt[op].minStack
+					pushes = int(params.StackLimit) + pops - jt[op].maxStack
+				)
+				if height < pops {
+					return fmt.Errorf("%w: at pos %d", errStackUnderflow, pos)
+				}
+				height += pushes - pops
+			}
+			if height > int(params.StackLimit) {
+				return fmt.Errorf("%w: at pos %d", errStackOverflow, pos)
+			}
+			if height > maxHeight {
+				maxHeight = height
+			}
+			// Queue up the jump destinations and move on to the next instruction.
+			for _, dest := range jumpTargets(op, code, pos) {
+				worklist = append(worklist, item{dest, height})
+			}
+			switch op {
+			case RJUMP, STOP, RETURN, REVERT, INVALID:
+				break outer
+			}
+			pos += immediateSize(op, code, pos) + 1
+		}
+		if pos >= len(code) {
+			return errInvalidCodeTermination
+		}
+	}
+	if maxHeight != int(metadata[section].MaxStackHeight) {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidMaxStackHeight, maxHeight, metadata[section].MaxStackHeight)
+	}
+	// Every instruction must be visited, otherwise there is dead code.
+	count := 0
+	for i := 0; i < len(code); i += immediateSize(OpCode(code[i]), code, i) + 1 {
+		count++
+	}
+	if count != len(heights) {
+		return fmt.Errorf("%w: reached %d of %d instructions", errUnreachableCode, len(heights), count)
+	}
+	return nil
+}
+
+// immediateSize returns the number of immediate bytes following the op at the
+// given position.
+func immediateSize(op OpCode, code []byte, pos int) int {
+	switch {
+	case op >= PUSH1 && op <= PUSH32:
+		return int(op - PUSH0)
+	case op == RJUMP, op == RJUMPI, op == CALLF:
+		return 2
+	case op == RJUMPV:
+		if pos+1 >= len(code) {
+			return 1
+		}
+		return 1 + 2*int(code[pos+1])
+	}
+	return 0
+}
+
+// jumpTargets returns the destinations of a relative jump at the given position,
+// which are relative to the position after the immediates. The immediates are
+// expected to be in bounds.
+func jumpTargets(op OpCode, code []byte, pos int) []int {
+	switch op {
+	case RJUMP, RJUMPI:
+		offset := int16(binary.BigEndian.Uint16(code[pos+1:]))
+		return []int{pos + 3 + int(offset)}
+	case RJUMPV:
+		var (
+			count   = int(code[pos+1])
+			targets = make([]int, count)
+			next    = pos + 2 + 2*count
+		)
+		for i := 0; i < count; i++ {
+			offset := int16(binary.BigEndian.Uint16(code[pos+2+2*i:]))
+			targets[i] = next + int(offset)
+		}
+		return targets
+	}
+	return nil
+}
@@ -334,6 +334,10 @@ func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transac
 	return b.eth.txPool.ContentFrom(addr)
 }
 
+func (b *EthAPIBackend) TxPoolQuery(q *txpool.Query) ([]*txpool.QueryResult, int) {
+	return b.eth.txPool.Query(q)
+}
+
 func (b *EthAPIBackend) TxPool() *txpool.TxPool {
 	return b.eth.txPool
 }
@@ -342,6 +346,10 @@ func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeDropTxsEvent(ch chan<- txpool.DropTxsEvent) event.Subscription {
+	return b.eth.txPool.SubscribeDropTxsEvent(ch)
+}
+
 func (b *EthAPIBackend) SyncProgress() ethereum.SyncProgress {
 	return b.eth.Downloader().Progress()
 }
@@ -0,0 +1,71 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SequencerAPI provides an API to submit transactions for sequenced inclusion
+// on chains where the local node produces all the blocks.
+type SequencerAPI struct {
+	e *Ethereum
+}
+
+// NewSequencerAPI creates a new SequencerAPI instance.
+func NewSequencerAPI(e *Ethereum) *SequencerAPI {
+	return &SequencerAPI{e}
+}
+
+// RPCPreconfirmation is the signed promise of the sequencer to include a
+// transaction at a specific slot, as returned over RPC.
+type RPCPreconfirmation struct {
+	ChainID     *hexutil.Big   `json:"chainId"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Index       hexutil.Uint64 `json:"transactionIndex"`
+	Sequencer   common.Address `json:"sequencer"`
+	Signature   hexutil.Bytes  `json:"signature"`
+}
+
+// SendRawTransaction sequences a signed transaction first-come-first-served and
+// returns the signed promise of including it in a specific block at a specific
+// position. The transaction is injected into the pool as a local one.
+func (api *SequencerAPI) SendRawTransaction(input hexutil.Bytes) (*RPCPreconfirmation, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return nil, err
+	}
+	preconf, err := api.e.Miner().Sequence(tx)
+	if err != nil {
+		return nil, err
+	}
+	sequencer, err := preconf.Sequencer()
+	if err != nil {
+		return nil, err
+	}
+	return &RPCPreconfirmation{
+		ChainID:     (*hexutil.Big)(preconf.ChainID),
+		TxHash:      preconf.TxHash,
+		BlockNumber: hexutil.Uint64(preconf.BlockNumber),
+		Index:       hexutil.Uint64(preconf.Index),
+		Sequencer:   sequencer,
+		Signature:   preconf.Signature,
+	}, nil
+}
@@ -19,6 +19,7 @@ package catalyst
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -26,8 +27,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -77,6 +80,15 @@ type SimulatedBeacon struct {
 	engineAPI          *ConsensusAPI
 	curForkchoiceState engine.ForkchoiceStateV1
 	lastBlockTime      uint64
+
+	// Scripted scenario settings, applied to the upcoming blocks
+	skipSlots   uint64                // Number of slots to leave empty before the next block
+	scheduled   [][]*types.Withdrawal // Withdrawal lists to include in the upcoming blocks
+	deferred    []*types.Withdrawal   // Queued withdrawals not included yet, held until Shanghai
+	safeLag     uint64                // Number of blocks the safe head lags behind the head
+	finalLag    uint64                // Number of blocks the finalized head lags behind the head
+	reorgs      uint64                // Number of competing branches produced, to diversify them
+	sealingLock sync.Mutex            // lock serializes block production and scenario changes
 }
 
 func NewSimulatedBeacon(period uint64, eth *eth.Ethereum) (*SimulatedBeacon, error) {
@@ -131,18 +143,57 @@ func (c *SimulatedBeacon) Stop() error {
 	return nil
 }
 
+// slotTime returns the number of seconds a slot lasts, used to leave gaps in the
+// block timestamps when slots are skipped.
+func (c *SimulatedBeacon) slotTime() uint64 {
+	if c.period == 0 {
+		return 1
+	}
+	return c.period
+}
+
 // sealBlock initiates payload building for a new block and creates a new block
 // with the completed payload.
 func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal) error {
+	c.sealingLock.Lock()
+	defer c.sealingLock.Unlock()
+
 	tstamp := uint64(time.Now().Unix())
 	if tstamp <= c.lastBlockTime {
 		tstamp = c.lastBlockTime + 1
 	}
+	// Leave the requested number of slots empty, as if their proposers were offline
+	if c.skipSlots > 0 {
+		tstamp += c.skipSlots * c.slotTime()
+		c.skipSlots = 0
+	}
 	c.feeRecipientLock.Lock()
 	feeRecipient := c.feeRecipient
 	c.feeRecipientLock.Unlock()
 
-	fcResponse, err := c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, &engine.PayloadAttributes{
+	// The withdrawals taken from the queue are held until they make it into a
+	// block, which is only possible after the Shanghai fork.
+	c.deferred = append(c.deferred, withdrawals...)
+
+	head := c.eth.BlockChain().GetHeaderByHash(c.curForkchoiceState.HeadBlockHash)
+	if head == nil {
+		return errors.New("head block not found")
+	}
+	// Scripted lists take precedence over the queued withdrawals.
+	var (
+		number    = new(big.Int).Add(head.Number, common.Big1)
+		shanghai  = c.eth.BlockChain().Config().IsShanghai(number, tstamp)
+		scheduled = shanghai && len(c.scheduled) > 0
+	)
+	withdrawals = nil
+	if shanghai {
+		withdrawals = []*types.Withdrawal{}
+		if scheduled {
+			withdrawals = append(withdrawals, c.scheduled[0]...)
+		}
+		withdrawals = append(withdrawals, c.deferred...)
+	}
+	fcResponse, err := c.forkchoiceUpdated(c.curForkchoiceState, &engine.PayloadAttributes{
 		Timestamp:             tstamp,
 		SuggestedFeeRecipient: feeRecipient,
 		Withdrawals:           withdrawals,
@@ -158,19 +209,177 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal) error {
 	payload := envelope.ExecutionPayload
 
 	// mark the payload as canon
-	if _, err = c.engineAPI.NewPayloadV2(*payload); err != nil {
+	status, err := c.newPayload(*payload)
+	if err != nil {
 		return fmt.Errorf("failed to mark payload as canonical: %v", err)
 	}
-	c.curForkchoiceState = engine.ForkchoiceStateV1{
-		HeadBlockHash:      payload.BlockHash,
-		SafeBlockHash:      payload.BlockHash,
-		FinalizedBlockHash: payload.BlockHash,
+	if status.Status != engine.VALID {
+		return fmt.Errorf("payload rejected: %s", status.Status)
+	}
+	// The payload was accepted, the included withdrawals can be dropped
+	if shanghai {
+		if scheduled {
+			c.scheduled = c.scheduled[1:]
+		}
+		c.deferred = nil
 	}
 	// mark the block containing the payload as canonical
-	if _, err = c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, nil); err != nil {
+	return c.setHead(payload.BlockHash)
+}
+
+// forkchoiceUpdated updates the forkchoice state, and starts building a payload
+// if attributes are given. The engine API version is picked according to the fork
+// active at the timestamp of the payload.
+func (c *SimulatedBeacon) forkchoiceUpdated(state engine.ForkchoiceStateV1, attrs *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	if attrs != nil && c.engineAPI.isCancun(attrs.Timestamp) {
+		return c.engineAPI.ForkchoiceUpdatedV3(state, attrs)
+	}
+	return c.engineAPI.ForkchoiceUpdatedV2(state, attrs)
+}
+
+// newPayload imports a payload using the engine API version matching the fork
+// active at its timestamp.
+func (c *SimulatedBeacon) newPayload(payload engine.ExecutableData) (engine.PayloadStatusV1, error) {
+	if c.engineAPI.isCancun(payload.Timestamp) {
+		return c.engineAPI.NewPayloadV3(payload)
+	}
+	return c.engineAPI.NewPayloadV2(payload)
+}
+
+// setHead marks the given block as the canonical head, moving the safe and the
+// finalized blocks along with it according to the configured lags.
+func (c *SimulatedBeacon) setHead(hash common.Hash) error {
+	head := c.eth.BlockChain().GetHeaderByHash(hash)
+	if head == nil {
+		return fmt.Errorf("unknown head block %x", hash)
+	}
+	state := engine.ForkchoiceStateV1{
+		HeadBlockHash:      hash,
+		SafeBlockHash:      c.lagging(head, c.safeLag, c.curForkchoiceState.SafeBlockHash),
+		FinalizedBlockHash: c.lagging(head, c.finalLag, c.curForkchoiceState.FinalizedBlockHash),
+	}
+	if _, err := c.engineAPI.ForkchoiceUpdatedV2(state, nil); err != nil {
 		return fmt.Errorf("failed to mark block as canonical: %v", err)
 	}
-	c.lastBlockTime = payload.Timestamp
+	c.curForkchoiceState = state
+	c.lastBlockTime = head.Time
+	return nil
+}
+
+// ancestor retrieves the ancestor of the given block at the given height,
+// following the parent links to also support non-canonical branches.
+func (c *SimulatedBeacon) ancestor(header *types.Header, number uint64) *types.Header {
+	for header != nil && header.Number.Uint64() > number {
+		header = c.eth.BlockChain().GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	return header
+}
+
+// lagging returns the ancestor of the given block lagging the requested number
+// of blocks behind it. The previously picked block is kept if it's newer and
+// still on the branch of the block, as neither the safe nor the finalized block
+// are expected to move backwards.
+func (c *SimulatedBeacon) lagging(head *types.Header, lag uint64, prev common.Hash) common.Hash {
+	var number uint64
+	if head.Number.Uint64() > lag {
+		number = head.Number.Uint64() - lag
+	}
+	if prevHeader := c.eth.BlockChain().GetHeaderByHash(prev); prevHeader != nil && prevHeader.Number.Uint64() > number {
+		if anc := c.ancestor(head, prevHeader.Number.Uint64()); anc != nil && anc.Hash() == prev {
+			return prev
+		}
+	}
+	if anc := c.ancestor(head, number); anc != nil {
+		return anc.Hash()
+	}
+	return head.Hash()
+}
+
+// reorg rewinds the chain by depth blocks and replaces them with a competing
+// branch of the given length, which is subsequently made canonical.
+func (c *SimulatedBeacon) reorg(depth, length uint64) error {
+	c.sealingLock.Lock()
+	defer c.sealingLock.Unlock()
+
+	chain := c.eth.BlockChain()
+	head := chain.GetHeaderByHash(c.curForkchoiceState.HeadBlockHash)
+	if head == nil {
+		return errors.New("head block not found")
+	}
+	if depth == 0 || depth > head.Number.Uint64() {
+		return fmt.Errorf("invalid reorg depth %d with head at %d", depth, head.Number)
+	}
+	if length == 0 {
+		return errors.New("empty competing branch")
+	}
+	if final := chain.GetHeaderByHash(c.curForkchoiceState.FinalizedBlockHash); final != nil && head.Number.Uint64()-depth < final.Number.Uint64() {
+		return fmt.Errorf("reorg depth %d reaches beyond finalized block %d", depth, final.Number)
+	}
+	c.feeRecipientLock.Lock()
+	feeRecipient := c.feeRecipient
+	c.feeRecipientLock.Unlock()
+
+	// Build the competing branch directly on top of the common ancestor. The
+	// randomness field is varied to never reproduce the rewound blocks.
+	c.reorgs++
+	parent := c.ancestor(head, head.Number.Uint64()-depth)
+	for i := uint64(0); i < length; i++ {
+		tstamp := uint64(time.Now().Unix())
+		if tstamp <= parent.Time {
+			tstamp = parent.Time + 1
+		}
+		args := &miner.BuildPayloadArgs{
+			Parent:       parent.Hash(),
+			Timestamp:    tstamp,
+			FeeRecipient: feeRecipient,
+			Random:       crypto.Keccak256Hash(parent.Hash().Bytes(), new(big.Int).SetUint64(c.reorgs).Bytes()),
+		}
+		if chain.Config().IsShanghai(new(big.Int).Add(parent.Number, common.Big1), tstamp) {
+			args.Withdrawals = types.Withdrawals{}
+		}
+		payload, err := c.eth.Miner().BuildPayload(args)
+		if err != nil {
+			return fmt.Errorf("error building competing block: %v", err)
+		}
+		block := payload.ResolveFull().ExecutionPayload
+		if _, err := c.newPayload(*block); err != nil {
+			return fmt.Errorf("failed to import competing block: %v", err)
+		}
+		if parent = chain.GetHeaderByHash(block.BlockHash); parent == nil {
+			return fmt.Errorf("competing block %x not imported", block.BlockHash)
+		}
+	}
+	log.Info("Simulating chain reorg", "depth", depth, "length", length, "head", parent.Number, "hash", parent.Hash())
+	return c.setHead(parent.Hash())
+}
+
+// setSkipSlots leaves the given number of slots empty before the next block.
+func (c *SimulatedBeacon) setSkipSlots(slots uint64) {
+	c.sealingLock.Lock()
+	defer c.sealingLock.Unlock()
+
+	c.skipSlots += slots
+}
+
+// scheduleWithdrawals queues lists of withdrawals to include in the upcoming
+// blocks, one list per block.
+func (c *SimulatedBeacon) scheduleWithdrawals(lists [][]*types.Withdrawal) {
+	c.sealingLock.Lock()
+	defer c.sealingLock.Unlock()
+
+	c.scheduled = append(c.scheduled, lists...)
+}
+
+// setFinalityLag configures the number of blocks the safe and the finalized
+// blocks lag behind the head.
+func (c *SimulatedBeacon) setFinalityLag(safe, finalized uint64) error {
+	if safe > finalized {
+		return fmt.Errorf("safe lag %d exceeds finalized lag %d", safe, finalized)
+	}
+	c.sealingLock.Lock()
+	defer c.sealingLock.Unlock()
+
+	c.safeLag, c.finalLag = safe, finalized
 	return nil
 }
 
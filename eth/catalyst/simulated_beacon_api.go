@@ -34,3 +34,28 @@ func (a *api) AddWithdrawal(ctx context.Context, withdrawal *types.Withdrawal) e
 func (a *api) SetFeeRecipient(ctx context.Context, feeRecipient common.Address) {
 	a.simBeacon.setFeeRecipient(feeRecipient)
 }
+
+// Reorg rewinds the chain by the given number of blocks and replaces them with
+// a competing branch of the given length. Reorgs reaching beyond the finalized
+// block are refused, so a finality lag needs to be configured first.
+func (a *api) Reorg(ctx context.Context, depth, length uint64) error {
+	return a.simBeacon.reorg(depth, length)
+}
+
+// SkipSlots leaves the given number of slots empty before the next block, as
+// if their proposers were offline.
+func (a *api) SkipSlots(ctx context.Context, slots uint64) {
+	a.simBeacon.setSkipSlots(slots)
+}
+
+// ScheduleWithdrawals sets the lists of withdrawals to include in the upcoming
+// blocks, one list per block, ahead of any withdrawals queued individually.
+func (a *api) ScheduleWithdrawals(ctx context.Context, lists [][]*types.Withdrawal) {
+	a.simBeacon.scheduleWithdrawals(lists)
+}
+
+// SetFinalityLag configures the number of blocks the safe and the finalized
+// blocks lag behind the chain head.
+func (a *api) SetFinalityLag(ctx context.Context, safe, finalized uint64) error {
+	return a.simBeacon.setFinalityLag(safe, finalized)
+}
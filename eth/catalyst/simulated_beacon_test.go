@@ -18,6 +18,7 @@ package catalyst
 
 import (
 	"context"
+	"math"
 	"math/big"
 	"testing"
 	"time"
@@ -34,7 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 )
 
-func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.Node, *eth.Ethereum, *SimulatedBeacon) {
+func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis, period uint64) (*node.Node, *eth.Ethereum, *SimulatedBeacon) {
 	t.Helper()
 
 	n, err := node.New(&node.Config{
@@ -54,7 +55,7 @@ func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.
 		t.Fatal("can't create eth service:", err)
 	}
 
-	simBeacon, err := NewSimulatedBeacon(1, ethservice)
+	simBeacon, err := NewSimulatedBeacon(period, ethservice)
 	if err != nil {
 		t.Fatal("can't create simulated beacon:", err)
 	}
@@ -86,7 +87,7 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 	// short period (1 second) for testing purposes
 	var gasLimit uint64 = 10_000_000
 	genesis := core.DeveloperGenesisBlock(gasLimit, testAddr)
-	node, ethService, mock := startSimulatedBeaconEthService(t, genesis)
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 1)
 	_ = mock
 	defer node.Close()
 
@@ -139,3 +140,164 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the simulated beacon can rewind the chain onto a competing branch,
+// skip slots, include scripted withdrawals and lag the safe and finalized blocks.
+func TestSimulatedBeaconScenarios(t *testing.T) {
+	genesis := core.DeveloperGenesisBlock(10_000_000, common.Address{0xaa})
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 0)
+	defer node.Close()
+
+	chain := ethService.BlockChain()
+	if err := mock.setFinalityLag(2, 1); err == nil {
+		t.Fatalf("safe lag beyond finalized lag accepted")
+	}
+	if err := mock.setFinalityLag(1, 2); err != nil {
+		t.Fatalf("failed to set finality lag: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := mock.sealBlock(nil); err != nil {
+			t.Fatalf("failed to seal block %d: %v", i, err)
+		}
+	}
+	checkHeads := func(head, safe, final uint64) {
+		t.Helper()
+
+		if have := chain.CurrentBlock().Number.Uint64(); have != head {
+			t.Errorf("head mismatch: have %d, want %d", have, head)
+		}
+		if have := chain.CurrentSafeBlock().Number.Uint64(); have != safe {
+			t.Errorf("safe block mismatch: have %d, want %d", have, safe)
+		}
+		if have := chain.CurrentFinalBlock().Number.Uint64(); have != final {
+			t.Errorf("finalized block mismatch: have %d, want %d", have, final)
+		}
+	}
+	checkHeads(5, 4, 3)
+
+	// Reorg the two blocks above the finalized one with a longer branch
+	rewound := chain.GetHeaderByNumber(4).Hash()
+	if err := mock.reorg(3, 1); err == nil {
+		t.Fatalf("reorg beyond finalized block accepted")
+	}
+	if err := mock.reorg(2, 3); err != nil {
+		t.Fatalf("failed to reorg: %v", err)
+	}
+	checkHeads(6, 5, 4)
+	if chain.GetHeaderByNumber(4).Hash() == rewound {
+		t.Errorf("rewound block still canonical")
+	}
+	// Skip a few slots and ensure the gap shows up in the timestamps
+	last := chain.CurrentBlock().Time
+	mock.setSkipSlots(3)
+	if err := mock.sealBlock(nil); err != nil {
+		t.Fatalf("failed to seal block after skipped slots: %v", err)
+	}
+	if have := chain.CurrentBlock().Time; have < last+4 {
+		t.Errorf("skipped slots missing: have time %d, want at least %d", have, last+4)
+	}
+	// Schedule a withdrawal list and ensure it gets included in order
+	mock.scheduleWithdrawals([][]*types.Withdrawal{{{Index: 7}, {Index: 8}}})
+	if err := mock.sealBlock([]*types.Withdrawal{{Index: 9}}); err != nil {
+		t.Fatalf("failed to seal block with withdrawals: %v", err)
+	}
+	head := chain.GetBlockByHash(chain.CurrentBlock().Hash())
+	if have := len(head.Withdrawals()); have != 3 {
+		t.Fatalf("withdrawal count mismatch: have %d, want 3", have)
+	}
+	for i, w := range head.Withdrawals() {
+		if w.Index != uint64(7+i) {
+			t.Errorf("withdrawal %d: index mismatch: have %d, want %d", i, w.Index, 7+i)
+		}
+	}
+}
+
+// Tests that withdrawals are held back until they can be included in a block, both
+// before the Shanghai fork and when sealing fails.
+func TestSimulatedBeaconDeferredWithdrawals(t *testing.T) {
+	genesis := core.DeveloperGenesisBlock(10_000_000, common.Address{0xaa})
+	config := *genesis.Config
+	config.ShanghaiTime = new(uint64)
+	*config.ShanghaiTime = math.MaxUint64
+	genesis.Config = &config
+
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 0)
+	defer node.Close()
+	chain := ethService.BlockChain()
+
+	// Withdrawals queued before Shanghai are kept for later
+	mock.scheduleWithdrawals([][]*types.Withdrawal{{{Index: 0}}})
+	if err := mock.sealBlock([]*types.Withdrawal{{Index: 1}}); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	if head := chain.GetBlockByHash(chain.CurrentBlock().Hash()); head.Withdrawals() != nil {
+		t.Fatalf("withdrawals included before Shanghai: %v", head.Withdrawals())
+	}
+	if len(mock.scheduled) != 1 || len(mock.deferred) != 1 {
+		t.Fatalf("withdrawals discarded: %d scheduled, %d deferred", len(mock.scheduled), len(mock.deferred))
+	}
+	// Activate Shanghai, withdrawals taken for a failed seal must be kept too
+	*chain.Config().ShanghaiTime = 0
+	current := mock.curForkchoiceState
+	mock.curForkchoiceState.HeadBlockHash = common.Hash{0x01}
+	if err := mock.sealBlock([]*types.Withdrawal{{Index: 2}}); err == nil {
+		t.Fatalf("sealing on unknown head succeeded")
+	}
+	mock.curForkchoiceState = current
+
+	if err := mock.sealBlock(nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	head := chain.GetBlockByHash(chain.CurrentBlock().Hash())
+	if have := len(head.Withdrawals()); have != 3 {
+		t.Fatalf("withdrawal count mismatch: have %d, want 3", have)
+	}
+	for i, w := range head.Withdrawals() {
+		if w.Index != uint64(i) {
+			t.Errorf("withdrawal %d: index mismatch: have %d, want %d", i, w.Index, i)
+		}
+	}
+	if len(mock.scheduled) != 0 || len(mock.deferred) != 0 {
+		t.Fatalf("included withdrawals retained: %d scheduled, %d deferred", len(mock.scheduled), len(mock.deferred))
+	}
+}
+
+// Tests that the simulated beacon switches to the Cancun engine API once the fork
+// activates, producing blocks with the data gas fields set.
+func TestSimulatedBeaconCancun(t *testing.T) {
+	genesis := core.DeveloperGenesisBlock(10_000_000, common.Address{0xaa})
+	config := *genesis.Config
+	config.ShanghaiTime = new(uint64)
+	config.CancunTime = new(uint64)
+	*config.CancunTime = math.MaxUint64
+	genesis.Config = &config
+
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 0)
+	defer node.Close()
+	chain := ethService.BlockChain()
+
+	if err := mock.sealBlock(nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	head := chain.CurrentBlock()
+	if head.ExcessDataGas != nil || head.DataGasUsed != nil {
+		t.Fatalf("data gas fields set before Cancun: excess %v, used %v", head.ExcessDataGas, head.DataGasUsed)
+	}
+	// Activate Cancun, the next block must be built and imported through V3
+	*chain.Config().CancunTime = 0
+	for i := 0; i < 2; i++ {
+		if err := mock.sealBlock(nil); err != nil {
+			t.Fatalf("failed to seal block %d: %v", i, err)
+		}
+		head = chain.CurrentBlock()
+		if head.ExcessDataGas == nil || head.DataGasUsed == nil {
+			t.Fatalf("block %d: data gas fields missing after Cancun", head.Number)
+		}
+		if *head.ExcessDataGas != 0 || *head.DataGasUsed != 0 {
+			t.Fatalf("block %d: data gas mismatch: excess %d, used %d", head.Number, *head.ExcessDataGas, *head.DataGasUsed)
+		}
+		if head.WithdrawalsHash == nil {
+			t.Fatalf("block %d: withdrawals missing after Cancun", head.Number)
+		}
+	}
+}
@@ -133,6 +133,7 @@ type Downloader struct {
 	// State sync
 	pivotHeader *types.Header // Pivot block header to dynamically push the syncing state root
 	pivotLock   sync.RWMutex  // Lock protecting pivot header reads from updates
+	pivotPinned atomic.Uint64 // Block number to pin the snap sync pivot to (0 = track the chain head)
 
 	SnapSyncer     *snap.Syncer // TODO(karalabe): make private! hack for now
 	stateSyncStart chan *stateSync
@@ -241,6 +242,15 @@ func New(stateDb ethdb.Database, mux *event.TypeMux, chain BlockChain, lightchai
 	return dl
 }
 
+// PinPivot pins the pivot block of snap sync to the given block number, syncing
+// the state at that historical block instead of continuously moving the pivot
+// along with the chain head. This requires peers retaining and serving the state
+// at the given block (e.g. archive nodes). Pinning is only supported in beacon
+// mode.
+func (d *Downloader) PinPivot(number uint64) {
+	d.pivotPinned.Store(number)
+}
+
 // Progress retrieves the synchronisation boundaries, specifically the origin
 // block where synchronisation started at (may have failed/suspended); the block
 // or header sync is currently at; and the latest known block which the sync targets.
@@ -489,9 +499,24 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td, ttd *
 		if err != nil {
 			return err
 		}
+		var number uint64
 		if latest.Number.Uint64() > uint64(fsMinFullBlocks) {
-			number := latest.Number.Uint64() - uint64(fsMinFullBlocks)
-
+			number = latest.Number.Uint64() - uint64(fsMinFullBlocks)
+		}
+		// If the user pinned the pivot to a historical block, sync the state of
+		// that one instead of tracking the head, as long as it's still ahead of
+		// the local chain
+		if pinned := d.pivotPinned.Load(); pinned != 0 && mode == SnapSync {
+			switch {
+			case pinned > latest.Number.Uint64():
+				log.Warn("Pinned pivot ahead of sync target, ignoring", "pinned", pinned, "latest", latest.Number)
+			case pinned <= d.blockchain.CurrentSnapBlock().Number.Uint64():
+				log.Warn("Pinned pivot behind local chain, ignoring", "pinned", pinned, "local", d.blockchain.CurrentSnapBlock().Number)
+			default:
+				number = pinned
+			}
+		}
+		if number > 0 {
 			// Retrieve the pivot header from the skeleton chain segment but
 			// fallback to local chain if it's not found in skeleton space.
 			if pivot = d.skeleton.Header(number); pivot == nil {
@@ -1650,7 +1675,7 @@ func (d *Downloader) processSnapSyncContent() error {
 			results = append(append([]*fetchResult{oldPivot}, oldTail...), results...)
 		}
 		// Split around the pivot block and process the two sides via snap/full sync
-		if !d.committed.Load() {
+		if !d.committed.Load() && pivot.Number.Uint64() != d.pivotPinned.Load() {
 			latest := results[len(results)-1].Header
 			// If the height is above the pivot block by 2 sets, it means the pivot
 			// become stale in the network and it was garbage collected, move to a
@@ -328,6 +328,11 @@ func (dlp *downloadTesterPeer) RequestReceipts(hashes []common.Hash, sink chan *
 	return req, nil
 }
 
+// HistoryTail retrieves the first block the peer serves bodies and receipts for.
+func (dlp *downloadTesterPeer) HistoryTail() (uint64, bool) {
+	return dlp.chain.HistoryTail(), true
+}
+
 // ID retrieves the peer's unique identifier.
 func (dlp *downloadTesterPeer) ID() string {
 	return dlp.id
@@ -1414,7 +1419,7 @@ func TestBeaconSync66Full(t *testing.T) { testBeaconSync(t, eth.ETH66, FullSync)
 func TestBeaconSync66Snap(t *testing.T) { testBeaconSync(t, eth.ETH66, SnapSync) }
 
 func testBeaconSync(t *testing.T, protocol uint, mode SyncMode) {
-	//log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	//log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LvlInfo, true)))
 
 	var cases = []struct {
 		name  string // The name of testing scenario
@@ -1455,3 +1460,39 @@ func testBeaconSync(t *testing.T, protocol uint, mode SyncMode) {
 		})
 	}
 }
+
+// Tests that snap sync with a pinned pivot syncs the state of the requested block
+// instead of tracking the chain head.
+func TestBeaconSyncPinnedPivot66(t *testing.T) { testBeaconSyncPinnedPivot(t, eth.ETH66) }
+
+func testBeaconSyncPinnedPivot(t *testing.T, protocol uint) {
+	success := make(chan struct{})
+	tester := newTesterWithNotification(t, func() {
+		close(success)
+	})
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", protocol, chain.blocks[1:])
+
+	pinned := uint64(len(chain.blocks) - 1 - 2*fsMinFullBlocks + 8)
+	tester.downloader.PinPivot(pinned)
+
+	if err := tester.downloader.BeaconSync(SnapSync, chain.blocks[len(chain.blocks)-1].Header(), nil); err != nil {
+		t.Fatalf("Failed to beacon sync chain: %v", err)
+	}
+	select {
+	case <-success:
+		if bs := int(tester.chain.CurrentBlock().Number.Uint64()) + 1; bs != len(chain.blocks) {
+			t.Fatalf("synchronised blocks mismatch: have %v, want %v", bs, len(chain.blocks))
+		}
+	case <-time.NewTimer(time.Second * 3).C:
+		t.Fatalf("Failed to sync chain in three seconds")
+	}
+	if pivot := rawdb.ReadLastPivotNumber(tester.chain.StateCache().DiskDB()); pivot == nil || *pivot != pinned {
+		t.Fatalf("pivot mismatch: have %v, want %d", pivot, pinned)
+	}
+	if !tester.chain.HasState(chain.blocks[pinned].Root()) {
+		t.Fatalf("state of pinned pivot %d missing", pinned)
+	}
+}
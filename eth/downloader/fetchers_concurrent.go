@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 )
 
 // timeoutGracePeriod is the amount of time to allow for a peer to deliver a
@@ -311,6 +312,10 @@ func (d *Downloader) concurrentFetch(queue typedQueue, beaconMode bool) error {
 				log.Error("Delivery timeout from unknown peer", "peer", req.Peer)
 				continue
 			}
+			// Let the timeout count against the reputation of the remote node
+			if r, ok := peer.peer.(interface{ Report(p2p.ScoreEvent) }); ok {
+				r.Report(p2p.ScoreTimeout)
+			}
 			if fails > 2 {
 				queue.updateCapacity(peer, 0, 0)
 			} else {
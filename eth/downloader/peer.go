@@ -48,6 +48,9 @@ type peerConnection struct {
 	rates   *msgrate.Tracker         // Tracker to hone in on the number of items retrievable per second
 	lacking map[common.Hash]struct{} // Set of hashes not to request (didn't have previously)
 
+	historyServed uint64 // Highest block the peer delivered a body or receipts for
+	historyTail   uint64 // First block the peer is assumed to retain history for, if not advertised
+
 	peer Peer
 
 	version uint       // Eth protocol version number to switch strategies
@@ -67,6 +70,7 @@ type Peer interface {
 	LightPeer
 	RequestBodies([]common.Hash, chan *eth.Response) (*eth.Request, error)
 	RequestReceipts([]common.Hash, chan *eth.Response) (*eth.Request, error)
+	HistoryTail() (uint64, bool)
 }
 
 // lightPeerWrapper wraps a LightPeer struct, stubbing out the Peer-only methods.
@@ -87,6 +91,7 @@ func (w *lightPeerWrapper) RequestBodies([]common.Hash, chan *eth.Response) (*et
 func (w *lightPeerWrapper) RequestReceipts([]common.Hash, chan *eth.Response) (*eth.Request, error) {
 	panic("RequestReceipts not supported in light client mode sync")
 }
+func (w *lightPeerWrapper) HistoryTail() (uint64, bool) { return 0, false }
 
 // newPeerConnection creates a new downloader peer.
 func newPeerConnection(id string, version uint, peer Peer, logger log.Logger) *peerConnection {
@@ -181,6 +186,43 @@ func (p *peerConnection) Lacks(hash common.Hash) bool {
 	return ok
 }
 
+// MarkHistoryServed records that the peer delivered the body or receipts of the
+// given block.
+func (p *peerConnection) MarkHistoryServed(number uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if number > p.historyServed {
+		p.historyServed = number
+	}
+}
+
+// MarkHistoryLacking records that the peer failed to deliver the body or receipts
+// of the given block. If the peer did not advertise its history tail but already
+// served a later block, it most likely expired its history up to and including
+// this block.
+func (p *peerConnection) MarkHistoryLacking(number uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if number < p.historyServed && number >= p.historyTail {
+		p.historyTail = number + 1
+	}
+}
+
+// LacksHistory retrieves whether the peer is known to have expired the body and
+// receipts of the given block from its history. If the peer does not advertise
+// its history tail, it is derived from failed requests.
+func (p *peerConnection) LacksHistory(number uint64) bool {
+	if tail, ok := p.peer.HistoryTail(); ok {
+		return number < tail
+	}
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return number < p.historyTail
+}
+
 // peeringEvent is sent on the peer event feed when a remote peer connects or
 // disconnects.
 type peeringEvent struct {
@@ -548,7 +548,7 @@ func (q *queue) reserveHeaders(p *peerConnection, count int, taskPool map[common
 		// Remove it from the task queue
 		taskQueue.PopItem()
 		// Otherwise unless the peer is known not to have the data, add to the retrieve list
-		if p.Lacks(header.Hash()) {
+		if p.Lacks(header.Hash()) || p.LacksHistory(header.Number.Uint64()) {
 			skip = append(skip, header)
 		} else {
 			send = append(send, header)
@@ -884,6 +884,7 @@ func (q *queue) deliver(id string, taskPool map[common.Hash]*types.Header,
 	if results == 0 {
 		for _, header := range request.Headers {
 			request.Peer.MarkLacking(header.Hash())
+			request.Peer.MarkHistoryLacking(header.Number.Uint64())
 		}
 	}
 	// Assemble each of the results with their headers and retrieved data parts
@@ -918,6 +919,7 @@ func (q *queue) deliver(id string, taskPool map[common.Hash]*types.Header,
 			failure = errStaleDelivery
 		}
 		// Clean up a successful fetch
+		request.Peer.MarkHistoryServed(header.Number.Uint64())
 		delete(taskPool, hashes[accepted])
 		accepted++
 	}
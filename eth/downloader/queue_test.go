@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -87,10 +88,34 @@ func dummyPeer(id string) *peerConnection {
 	p := &peerConnection{
 		id:      id,
 		lacking: make(map[common.Hash]struct{}),
+		peer:    &lightPeerWrapper{},
 	}
 	return p
 }
 
+// Tests that the history tail of peers not advertising it is derived from failed
+// deliveries, but only once the peer has proven to serve later blocks.
+func TestPeerHistoryTail(t *testing.T) {
+	p := dummyPeer("peer")
+	if p.LacksHistory(0) {
+		t.Fatal("peer with unknown tail assumed to lack history")
+	}
+	// A failure without any prior delivery may simply mean the peer is behind
+	p.MarkHistoryLacking(100)
+	if p.LacksHistory(50) {
+		t.Fatal("history tail derived without any delivery")
+	}
+	// A failure below a delivered block marks everything up to it as expired
+	p.MarkHistoryServed(200)
+	p.MarkHistoryLacking(100)
+	if !p.LacksHistory(100) || !p.LacksHistory(50) {
+		t.Fatal("history tail not derived from failed delivery")
+	}
+	if p.LacksHistory(101) {
+		t.Fatal("history tail derived too high")
+	}
+}
+
 func TestBasics(t *testing.T) {
 	numOfBlocks := len(emptyChain.blocks)
 	numOfReceipts := len(emptyChain.blocks) / 2
@@ -271,7 +296,7 @@ func XTestDelivery(t *testing.T) {
 	world.chain = blo
 	world.progress(10)
 	if false {
-		log.Root().SetHandler(log.StdoutHandler)
+		log.SetDefault(log.NewLogger(log.LogfmtHandler(os.Stdout)))
 	}
 	q := newQueue(10, 10)
 	var wg sync.WaitGroup
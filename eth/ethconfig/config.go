@@ -87,6 +87,7 @@ type Config struct {
 	// Protocol options
 	NetworkId uint64 // Network ID to use for selecting peers to connect to
 	SyncMode  downloader.SyncMode
+	SyncPivot uint64 `toml:",omitempty"` // Block number to pin the snap sync pivot to (0 = track the head)
 
 	// This can be set to list of enrtree:// URLs which will be queried for
 	// for nodes to connect to.
@@ -96,7 +97,12 @@ type Config struct {
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
+	RecordReadSets bool `toml:",omitempty"` // Whether to record the state read by each block, to prefetch it on re-execution
+
+	ParallelWorkers int `toml:",omitempty"` // Number of goroutines speculatively executing block transactions (0 = sequential)
+
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
+	HistoryCutoff uint64 `toml:",omitempty"` // Block number below which bodies and receipts are expired (0 = keep all)
 
 	// RequiredBlocks is a set of block number -> hash mappings which must be in the
 	// canonical chain of all remote peers. Setting the option makes geth verify the
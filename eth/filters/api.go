@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -183,6 +184,46 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 	return rpcSub, nil
 }
 
+// DroppedTransactions creates a subscription that is triggered each time a
+// transaction leaves the transaction pool without being included in a block,
+// notifying the reason of its removal and its replacement, if any.
+func (api *FilterAPI) DroppedTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		drops := make(chan []*txpool.DroppedTx, 128)
+		droppedTxSub := api.events.SubscribeDroppedTxs(drops)
+		chainConfig := api.sys.backend.ChainConfig()
+
+		for {
+			select {
+			case drops := <-drops:
+				latest := api.sys.backend.CurrentHeader()
+				for _, drop := range drops {
+					event := ethapi.NewRPCPoolDropEvent(drop)
+					if fullTx != nil && *fullTx {
+						event.Transaction = ethapi.NewRPCPendingTransaction(drop.Tx, latest, chainConfig)
+					}
+					notifier.Notify(rpcSub.ID, event)
+				}
+			case <-rpcSub.Err():
+				droppedTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				droppedTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 func (api *FilterAPI) NewBlockFilter() rpc.ID {
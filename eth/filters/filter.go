@@ -269,6 +269,11 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
 	for ; f.begin <= int64(end); f.begin++ {
+		// Blocks without matches never block on the log channel, so check for
+		// cancellation explicitly to avoid scanning the whole range needlessly.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(f.begin))
 		if header == nil || err != nil {
 			return err
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -46,6 +47,7 @@ type testBackend struct {
 	db              ethdb.Database
 	sections        uint64
 	txFeed          event.Feed
+	dropFeed        event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
@@ -133,6 +135,10 @@ func (b *testBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Sub
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeDropTxsEvent(ch chan<- txpool.DropTxsEvent) event.Subscription {
+	return b.dropFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -301,6 +307,46 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestDroppedTxSubscription tests whether dropped transaction subscriptions
+// retrieve all the dropped transactions posted by the transaction pool.
+func TestDroppedTxSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys, false)
+
+		original    = types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(1), nil)
+		replacement = types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(2), nil)
+		stale       = types.NewTransaction(1, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(1), nil)
+
+		drops = []*txpool.DroppedTx{
+			{Tx: original, Reason: txpool.DropReplaced, Replacement: replacement},
+			{Tx: stale, Reason: txpool.DropStale},
+		}
+	)
+	ch := make(chan []*txpool.DroppedTx)
+	sub := api.events.SubscribeDroppedTxs(ch)
+	defer sub.Unsubscribe()
+
+	backend.dropFeed.Send(txpool.DropTxsEvent{Txs: drops})
+
+	select {
+	case received := <-ch:
+		if len(received) != len(drops) {
+			t.Fatalf("invalid number of dropped transactions, want %d, got %d", len(drops), len(received))
+		}
+		for i := range received {
+			if received[i].Tx.Hash() != drops[i].Tx.Hash() || received[i].Reason != drops[i].Reason {
+				t.Errorf("drop %d invalid, want %x (%v), got %x (%v)", i, drops[i].Tx.Hash(), drops[i].Reason, received[i].Tx.Hash(), received[i].Reason)
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dropped transactions not delivered")
+	}
+}
+
 // TestPendingTxFilterFullTx tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilterFullTx(t *testing.T) {
 	t.Parallel()
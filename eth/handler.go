@@ -89,6 +89,7 @@ type handlerConfig struct {
 	Merger         *consensus.Merger      // The manager for eth1/2 transition
 	Network        uint64                 // Network identifier to adfvertise
 	Sync           downloader.SyncMode    // Whether to snap or full sync
+	SyncPivot      uint64                 // Block number to pin the snap sync pivot to (0 = track the head)
 	BloomCache     uint64                 // Megabytes to alloc for snap sync bloom
 	EventMux       *event.TypeMux         // Legacy event mux, deprecate for `feed`
 	RequiredBlocks map[uint64]common.Hash // Hard coded map of required block hashes for sync challenges
@@ -187,6 +188,10 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	}
 	// Construct the downloader (long sync)
 	h.downloader = downloader.New(config.Database, h.eventMux, h.chain, nil, h.removePeer, success)
+	if config.SyncPivot != 0 {
+		log.Info("Pinning snap sync pivot", "number", config.SyncPivot)
+		h.downloader.PinPivot(config.SyncPivot)
+	}
 	if ttd := h.chain.Config().TerminalTotalDifficulty; ttd != nil {
 		if h.chain.Config().TerminalTotalDifficultyPassed {
 			log.Info("Chain post-merge, sync via beacon client")
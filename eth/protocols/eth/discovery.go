@@ -24,6 +24,10 @@ import (
 )
 
 // enrEntry is the ENR entry which advertises `eth` protocol on the discovery.
+//
+// Nodes which expired part of their block history append the number of their
+// first retained block body as the first optional field. Older nodes ignore it,
+// keeping the entry compatible both ways.
 type enrEntry struct {
 	ForkID forkid.ID // Fork identifier per EIP-2124
 
@@ -31,6 +35,19 @@ type enrEntry struct {
 	Rest []rlp.RawValue `rlp:"tail"`
 }
 
+// historyTail returns the first block number the advertising node still serves
+// bodies and receipts for, or 0 if it retains its entire history.
+func (e *enrEntry) historyTail() uint64 {
+	if len(e.Rest) == 0 {
+		return 0
+	}
+	var tail uint64
+	if err := rlp.DecodeBytes(e.Rest[0], &tail); err != nil {
+		return 0
+	}
+	return tail
+}
+
 // ENRKey implements enr.Entry.
 func (e enrEntry) ENRKey() string {
 	return "eth"
@@ -60,7 +77,12 @@ func StartENRUpdater(chain *core.BlockChain, ln *enode.LocalNode) {
 // currentENREntry constructs an `eth` ENR entry based on the current state of the chain.
 func currentENREntry(chain *core.BlockChain) *enrEntry {
 	head := chain.CurrentHeader()
-	return &enrEntry{
+	entry := &enrEntry{
 		ForkID: forkid.NewID(chain.Config(), chain.Genesis().Hash(), head.Number.Uint64(), head.Time),
 	}
+	if tail := chain.HistoryTail(); tail > 0 {
+		blob, _ := rlp.EncodeToBytes(tail)
+		entry.Rest = []rlp.RawValue{blob}
+	}
+	return entry
 }
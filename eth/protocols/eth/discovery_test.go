@@ -0,0 +1,68 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that the history tail advertised in the `eth` ENR entry survives a round
+// trip through a node record and that entries without it decode as full history.
+func TestENRHistoryTail(t *testing.T) {
+	tail, _ := rlp.EncodeToBytes(uint64(15537394))
+
+	tests := []struct {
+		entry *enrEntry
+		want  uint64
+	}{
+		{&enrEntry{ForkID: forkid.ID{Hash: [4]byte{1}}}, 0},
+		{&enrEntry{ForkID: forkid.ID{Hash: [4]byte{1}}, Rest: []rlp.RawValue{tail}}, 15537394},
+		{&enrEntry{ForkID: forkid.ID{Hash: [4]byte{1}}, Rest: []rlp.RawValue{{0xc0}}}, 0},
+	}
+	for i, tt := range tests {
+		var r enr.Record
+		r.Set(tt.entry)
+
+		var entry enrEntry
+		if err := r.Load(&entry); err != nil {
+			t.Fatalf("test %d: failed to load entry: %v", i, err)
+		}
+		if entry.ForkID != tt.entry.ForkID {
+			t.Errorf("test %d: fork id mismatch: have %v, want %v", i, entry.ForkID, tt.entry.ForkID)
+		}
+		if have := entry.historyTail(); have != tt.want {
+			t.Errorf("test %d: history tail mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that peers without an `eth` entry in their node record, such as inbound
+// connections, report their history tail as unknown.
+func TestPeerHistoryTailUnknown(t *testing.T) {
+	peer := NewPeer(ETH68, p2p.NewPeer(enode.ID{1}, "", nil), nil, nil)
+	defer peer.Close()
+
+	if tail, ok := peer.HistoryTail(); ok {
+		t.Fatalf("history tail reported as known: %d", tail)
+	}
+}
@@ -99,6 +99,7 @@ type Response struct {
 	id   uint64    // Request ID to match up this reply to
 	recv time.Time // Timestamp when the request was received
 	code uint64    // Response packet type to cross validate with request
+	size int       // Size of the response packet, credited to the peer if accepted
 
 	Req  *Request      // Original request to cross-reference with
 	Res  interface{}   // Remote response for the request query
@@ -150,6 +151,7 @@ func (p *Peer) dispatchResponse(res *Response, metadata func() interface{}) erro
 	case p.resDispatch <- resOp:
 		// Ensure the response is accepted by the dispatcher
 		if err := <-resOp.fail; err != nil {
+			p.Report(p2p.ScoreInvalid)
 			return nil
 		}
 		// Request was accepted, run any postprocessing step to generate metadata
@@ -171,7 +173,15 @@ func (p *Peer) dispatchResponse(res *Response, metadata func() interface{}) erro
 			// for fresh cancellations too
 			select {
 			case res.Req.sink <- res:
-				return <-res.Done // Response delivered, return any errors
+				// Response delivered, return any errors
+				err := <-res.Done
+				if err != nil {
+					p.Report(p2p.ScoreInvalid)
+				} else {
+					p.Report(p2p.ScoreUseful)
+					p.ReportServed(res.size)
+				}
+				return err
 			case <-res.Req.cancel:
 				return nil // Request cancelled, silently discard response
 			}
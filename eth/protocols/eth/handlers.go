@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 )
@@ -367,6 +368,7 @@ func handleBlockHeaders66(backend Backend, msg Decoder, peer *Peer) error {
 	return peer.dispatchResponse(&Response{
 		id:   res.RequestId,
 		code: BlockHeadersMsg,
+		size: msgSize(msg),
 		Res:  &res.BlockHeadersPacket,
 	}, metadata)
 }
@@ -396,6 +398,7 @@ func handleBlockBodies66(backend Backend, msg Decoder, peer *Peer) error {
 	return peer.dispatchResponse(&Response{
 		id:   res.RequestId,
 		code: BlockBodiesMsg,
+		size: msgSize(msg),
 		Res:  &res.BlockBodiesPacket,
 	}, metadata)
 }
@@ -409,6 +412,7 @@ func handleNodeData66(backend Backend, msg Decoder, peer *Peer) error {
 	return peer.dispatchResponse(&Response{
 		id:   res.RequestId,
 		code: NodeDataMsg,
+		size: msgSize(msg),
 		Res:  &res.NodeDataPacket,
 	}, nil) // No post-processing, we're not using this packet anymore
 }
@@ -430,6 +434,7 @@ func handleReceipts66(backend Backend, msg Decoder, peer *Peer) error {
 	return peer.dispatchResponse(&Response{
 		id:   res.RequestId,
 		code: ReceiptsMsg,
+		size: msgSize(msg),
 		Res:  &res.ReceiptsPacket,
 	}, metadata)
 }
@@ -550,3 +555,11 @@ func handlePooledTransactions66(backend Backend, msg Decoder, peer *Peer) error
 
 	return backend.Handle(peer, &txs.PooledTransactionsPacket)
 }
+
+// msgSize returns the size of a received message, or zero if it is unknown.
+func msgSize(msg Decoder) int {
+	if m, ok := msg.(p2p.Msg); ok {
+		return int(m.Size)
+	}
+	return 0
+}
@@ -156,6 +156,22 @@ func (p *Peer) SetHead(hash common.Hash, td *big.Int) {
 	p.td.Set(td)
 }
 
+// HistoryTail returns the first block number the peer advertised to serve bodies
+// and receipts for in its node record. Peers advertising an `eth` entry without a
+// tail retain their entire history. The tail is unknown if the peer's record has
+// no `eth` entry, e.g. for inbound connections.
+func (p *Peer) HistoryTail() (uint64, bool) {
+	node := p.Node()
+	if node == nil {
+		return 0, false
+	}
+	var entry enrEntry
+	if err := node.Load(&entry); err != nil {
+		return 0, false
+	}
+	return entry.historyTail(), true
+}
+
 // KnownBlock returns whether peer is known to already have a block.
 func (p *Peer) KnownBlock(hash common.Hash) bool {
 	return p.knownBlocks.Contains(hash)
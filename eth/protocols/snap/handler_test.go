@@ -0,0 +1,110 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Tests that account and storage ranges can be served at historical roots not
+// covered by the snapshot, as long as the tries are still retained (archive).
+func TestServeHistoricalRanges(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.Address{0xc0, 0xde}
+		gspec    = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				sender:   {Balance: big.NewInt(params.Ether)},
+				contract: {Balance: common.Big1, Code: []byte{0x00}, Storage: map[common.Hash]common.Hash{{0x01}: {0x01}, {0x02}: {0x02}}},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sender), common.Address{byte(i + 1)}, big.NewInt(1000), params.TxGas, block.BaseFee(), nil), signer, key)
+		block.AddTx(tx)
+	})
+	// Import the chain into an archive node without snapshots, so all the ranges
+	// need to be served from the tries
+	cache := &core.CacheConfig{
+		TrieCleanLimit:    256,
+		TrieDirtyDisabled: true,
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), cache, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	var (
+		limit   = common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+		headers = []*types.Header{chain.Genesis().Header(), blocks[0].Header(), blocks[2].Header()}
+		counts  = []int{2, 4, 6} // sender, contract, coinbase and the recipients
+	)
+	for i, header := range headers {
+		root := header.Root
+
+		accounts, proofs := ServiceGetAccountRangeQuery(chain, &GetAccountRangePacket{Root: root, Limit: limit, Bytes: softResponseLimit})
+		if want := counts[i]; len(accounts) != want {
+			t.Fatalf("root %d: account count mismatch: have %d, want %d", i, len(accounts), want)
+		}
+		var (
+			keys    = make([][]byte, len(accounts))
+			vals    = make([][]byte, len(accounts))
+			proofdb = memorydb.New()
+		)
+		for j, account := range accounts {
+			keys[j] = common.CopyBytes(account.Hash[:])
+			if vals[j], err = types.FullAccountRLP(account.Body); err != nil {
+				t.Fatalf("root %d: invalid account %d: %v", i, j, err)
+			}
+		}
+		for _, node := range proofs {
+			proofdb.Put(crypto.Keccak256(node), node)
+		}
+		if _, err := trie.VerifyRangeProof(root, common.Hash{}.Bytes(), keys[len(keys)-1], keys, vals, proofdb); err != nil {
+			t.Fatalf("root %d: failed to verify account range: %v", i, err)
+		}
+		// Request the contract storage at the same root
+		slots, proofs := ServiceGetStorageRangesQuery(chain, &GetStorageRangesPacket{Root: root, Accounts: []common.Hash{crypto.Keccak256Hash(contract[:])}, Bytes: softResponseLimit})
+		if len(slots) != 1 || len(slots[0]) != 2 || len(proofs) != 0 {
+			t.Fatalf("root %d: storage range mismatch: have %d accounts, %d proofs", i, len(slots), len(proofs))
+		}
+	}
+	// Roots not retained by the node should not be served
+	if accounts, _ := ServiceGetAccountRangeQuery(chain, &GetAccountRangePacket{Root: common.Hash{0x01}, Limit: limit, Bytes: softResponseLimit}); len(accounts) != 0 {
+		t.Fatalf("served unknown root: %d accounts", len(accounts))
+	}
+}
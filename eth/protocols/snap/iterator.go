@@ -0,0 +1,154 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// accountIterator creates an iterator over the accounts of the requested state
+// root, starting at the given origin. The snapshot is used if it still covers the
+// root, otherwise the account trie is iterated directly, allowing nodes retaining
+// historical tries (e.g. archive nodes) to serve roots beyond the diff layers.
+func accountIterator(chain *core.BlockChain, root common.Hash, origin common.Hash) (snapshot.AccountIterator, error) {
+	if snaps := chain.Snapshots(); snaps != nil {
+		if it, err := snaps.AccountIterator(root, origin); err == nil {
+			return it, nil
+		}
+	}
+	tr, err := trie.New(trie.StateTrieID(root), chain.StateCache().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	nodeIt, err := tr.NodeIterator(origin[:])
+	if err != nil {
+		return nil, err
+	}
+	historicalAccountServeMeter.Mark(1)
+	return &trieAccountIterator{it: trie.NewIterator(nodeIt)}, nil
+}
+
+// storageIterator creates an iterator over the storage slots of an account in the
+// requested state root, starting at the given origin. Similarly to accounts, the
+// snapshot is preferred, falling back to iterating the storage trie directly.
+func storageIterator(chain *core.BlockChain, root common.Hash, account common.Hash, origin common.Hash) (snapshot.StorageIterator, error) {
+	if snaps := chain.Snapshots(); snaps != nil {
+		if it, err := snaps.StorageIterator(root, account, origin); err == nil {
+			return it, nil
+		}
+	}
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(root), chain.StateCache().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	acc, err := accTrie.GetAccountByHash(account)
+	if err != nil {
+		return nil, err
+	}
+	// Non-existent accounts are served as empty storage, same as the snapshot
+	storageRoot := types.EmptyRootHash
+	if acc != nil {
+		storageRoot = acc.Root
+	}
+	tr, err := trie.New(trie.StorageTrieID(root, account, storageRoot), chain.StateCache().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	nodeIt, err := tr.NodeIterator(origin[:])
+	if err != nil {
+		return nil, err
+	}
+	historicalStorageServeMeter.Mark(1)
+	return &trieStorageIterator{it: trie.NewIterator(nodeIt)}, nil
+}
+
+// trieAccountIterator is an account iterator walking the leaves of an account
+// trie, converting the accounts into the slim format used by the snapshots.
+type trieAccountIterator struct {
+	it      *trie.Iterator
+	account []byte
+	err     error
+}
+
+// Next steps the iterator forward one account, returning false if exhausted.
+func (it *trieAccountIterator) Next() bool {
+	if it.err != nil || !it.it.Next() {
+		return false
+	}
+	account, err := types.FullAccount(it.it.Value)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.account = types.SlimAccountRLP(*account)
+	return true
+}
+
+// Error returns any failure that occurred during iteration.
+func (it *trieAccountIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Err
+}
+
+// Hash returns the hash of the account the iterator is currently at.
+func (it *trieAccountIterator) Hash() common.Hash {
+	return common.BytesToHash(it.it.Key)
+}
+
+// Account returns the RLP encoded slim account the iterator is currently at.
+func (it *trieAccountIterator) Account() []byte {
+	return it.account
+}
+
+// Release is a noop for trie iterators as there are no held resources.
+func (it *trieAccountIterator) Release() {}
+
+// trieStorageIterator is a storage iterator walking the leaves of a storage trie.
+// The slots are stored in the trie in the same RLP encoded form as in the
+// snapshot, so no conversion is needed.
+type trieStorageIterator struct {
+	it *trie.Iterator
+}
+
+// Next steps the iterator forward one storage slot, returning false if exhausted.
+func (it *trieStorageIterator) Next() bool {
+	return it.it.Next()
+}
+
+// Error returns any failure that occurred during iteration.
+func (it *trieStorageIterator) Error() error {
+	return it.it.Err
+}
+
+// Hash returns the hash of the storage slot the iterator is currently at.
+func (it *trieStorageIterator) Hash() common.Hash {
+	return common.BytesToHash(it.it.Key)
+}
+
+// Slot returns the RLP encoded storage slot the iterator is currently at.
+func (it *trieStorageIterator) Slot() []byte {
+	return it.it.Value
+}
+
+// Release is a noop for trie iterators as there are no held resources.
+func (it *trieStorageIterator) Release() {}
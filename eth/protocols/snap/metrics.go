@@ -26,4 +26,9 @@ var (
 
 	IngressRegistrationErrorMeter = metrics.NewRegisteredMeter(ingressRegistrationErrorName, nil)
 	EgressRegistrationErrorMeter  = metrics.NewRegisteredMeter(egressRegistrationErrorName, nil)
+
+	// historicalAccountServeMeter and historicalStorageServeMeter count the range
+	// requests served from the tries directly, for roots not covered by the snapshot.
+	historicalAccountServeMeter = metrics.NewRegisteredMeter("eth/protocols/snap/serve/historical/accounts", nil)
+	historicalStorageServeMeter = metrics.NewRegisteredMeter("eth/protocols/snap/serve/historical/storage", nil)
 )
@@ -0,0 +1,259 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Phases of the snap sync reported in the sync status.
+const (
+	SyncPhaseIdle     = "idle"     // No sync cycle was run yet
+	SyncPhaseSnapping = "snapping" // Downloading the account and storage ranges
+	SyncPhaseHealing  = "healing"  // Fixing up the trie inconsistencies
+	SyncPhaseDone     = "done"     // State fully reconstructed
+)
+
+var (
+	// Gauges tracking the estimated progress (in per mille) and remaining time (in
+	// seconds) of the two main sync phases.
+	snapProgressGauge = metrics.NewRegisteredGauge("eth/protocols/snap/sync/snapping/progress", nil)
+	snapETAGauge      = metrics.NewRegisteredGauge("eth/protocols/snap/sync/snapping/eta", nil)
+	healProgressGauge = metrics.NewRegisteredGauge("eth/protocols/snap/sync/healing/progress", nil)
+	healETAGauge      = metrics.NewRegisteredGauge("eth/protocols/snap/sync/healing/eta", nil)
+
+	// deliveredMeter tracks the total amount of state data delivered by all peers.
+	deliveredMeter = metrics.NewRegisteredMeter("eth/protocols/snap/sync/delivered", nil)
+)
+
+// SyncStatus is a detailed report of the snap sync progress, broken down by the
+// different data types retrieved, with rates and completion estimates.
+type SyncStatus struct {
+	Phase   string      `json:"phase"`   // Current phase of the sync
+	Root    common.Hash `json:"root"`    // State root being synced
+	Elapsed uint64      `json:"elapsed"` // Seconds spent syncing, across restarts
+
+	Accounts  SyncPhaseStatus `json:"accounts"`  // Account range retrieval, progress by covered hash space
+	Storage   SyncPhaseStatus `json:"storage"`   // Storage range retrieval, progress only reported on completion
+	Bytecodes SyncPhaseStatus `json:"bytecodes"` // Contract code retrieval, progress by the codes known pending
+	Healing   SyncPhaseStatus `json:"healing"`   // Trie node and code healing
+
+	Peers map[string]*SyncPeerStatus `json:"peers"` // Data contributed by the connected peers
+}
+
+// SyncPhaseStatus is the progress report of a single data type or phase.
+type SyncPhaseStatus struct {
+	Items    uint64             `json:"items"`    // Number of items retrieved
+	Bytes    common.StorageSize `json:"bytes"`    // Number of bytes persisted
+	Pending  uint64             `json:"pending"`  // Number of items known to be pending (bytecodes and healing only)
+	Progress float64            `json:"progress"` // Estimated fraction completed, between 0 and 1
+	Rate     float64            `json:"rate"`     // Average bytes persisted per second
+	ETA      uint64             `json:"eta"`      // Estimated seconds until completion (0 if done or unknown)
+}
+
+// SyncPeerStatus is the amount of state data delivered by a single peer.
+type SyncPeerStatus struct {
+	Accounts  common.StorageSize `json:"accounts"`  // Bytes of account ranges delivered
+	Storage   common.StorageSize `json:"storage"`   // Bytes of storage ranges delivered
+	Bytecodes common.StorageSize `json:"bytecodes"` // Bytes of contract codes delivered
+	Trienodes common.StorageSize `json:"trienodes"` // Bytes of healing trie nodes delivered
+	Rate      float64            `json:"rate"`      // Average bytes delivered per second since connecting
+
+	connected time.Time // Time the peer was registered to compute the rate with
+}
+
+// total returns the total number of bytes delivered by the peer.
+func (p *SyncPeerStatus) total() common.StorageSize {
+	return p.Accounts + p.Storage + p.Bytecodes + p.Trienodes
+}
+
+// trackPhase accumulates the time spent in the current phase since the last
+// call. The method assumes the lock is held.
+func (s *Syncer) trackPhase() {
+	now := time.Now()
+	if !s.phaseTick.IsZero() {
+		if s.snapped {
+			s.healElapsed += now.Sub(s.phaseTick)
+		} else {
+			s.snapElapsed += now.Sub(s.phaseTick)
+		}
+	}
+	s.phaseTick = now
+}
+
+// updateStatus recalculates the externally visible sync status. It must be run
+// on the sync goroutine, as it accesses the tasks. The method assumes the lock
+// is held.
+func (s *Syncer) updateStatus() {
+	s.trackPhase()
+
+	// Estimate the snapping progress by the hash space covered by the accounts
+	var coverage float64
+	if len(s.tasks) == 0 {
+		coverage = 1
+	} else {
+		gaps := new(big.Int)
+		for _, task := range s.tasks {
+			gaps.Add(gaps, new(big.Int).Sub(task.Last.Big(), task.Next.Big()))
+		}
+		fills := new(big.Float).SetInt(new(big.Int).Sub(hashSpace, gaps))
+		coverage, _ = new(big.Float).Quo(fills, new(big.Float).SetInt(hashSpace)).Float64()
+	}
+	var snapETA uint64
+	if coverage > 0 && coverage < 1 {
+		snapETA = uint64(s.snapElapsed.Seconds() * (1 - coverage) / coverage)
+	}
+	// The total amount of storage is not known until it's retrieved, so only its
+	// completion can be reported. The codes are requested as the accounts are
+	// retrieved, so their pending count is a lower bound of the remaining ones.
+	var storageProgress float64
+	if len(s.tasks) == 0 {
+		storageProgress = 1
+	}
+	var codesPending uint64
+	for _, task := range s.tasks {
+		codesPending += uint64(len(task.codeTasks))
+	}
+	var (
+		codeProgress float64
+		codeETA      uint64
+	)
+	switch {
+	case len(s.tasks) == 0:
+		codeProgress = 1
+	case s.bytecodeSynced > 0:
+		codeProgress = float64(s.bytecodeSynced) / float64(s.bytecodeSynced+codesPending)
+		codeETA = uint64(s.snapElapsed.Seconds() * float64(codesPending) / float64(s.bytecodeSynced))
+	}
+	// Estimate the healing progress by the nodes retrieved and known pending. The
+	// pending set keeps growing as the trie is explored, so this is a lower bound.
+	var (
+		healed  = s.trienodeHealSynced + s.bytecodeHealSynced
+		pending uint64
+	)
+	if s.healer != nil {
+		pending = uint64(s.healer.scheduler.Pending() + len(s.healer.trieTasks) + len(s.healer.codeTasks))
+	}
+	var (
+		healProgress float64
+		healETA      uint64
+	)
+	switch {
+	case !s.snapped:
+	case pending == 0:
+		healProgress = 1
+	default:
+		healProgress = float64(healed) / float64(healed+pending)
+		if healed > 0 {
+			healETA = uint64(s.healElapsed.Seconds() * float64(pending) / float64(healed))
+		}
+	}
+	phase := SyncPhaseSnapping
+	switch {
+	case s.root == (common.Hash{}):
+		phase = SyncPhaseIdle
+	case s.snapped && pending == 0:
+		phase = SyncPhaseDone
+	case s.snapped:
+		phase = SyncPhaseHealing
+	}
+	rate := func(bytes common.StorageSize, elapsed time.Duration) float64 {
+		if elapsed < time.Second {
+			return 0
+		}
+		return float64(bytes) / elapsed.Seconds()
+	}
+	healBytes := s.trienodeHealBytes + s.bytecodeHealBytes
+	s.extStatus = &SyncStatus{
+		Phase:   phase,
+		Root:    s.root,
+		Elapsed: uint64((s.snapElapsed + s.healElapsed).Seconds()),
+		Accounts: SyncPhaseStatus{
+			Items:    s.accountSynced,
+			Bytes:    s.accountBytes,
+			Progress: coverage,
+			Rate:     rate(s.accountBytes, s.snapElapsed),
+			ETA:      snapETA,
+		},
+		Storage: SyncPhaseStatus{
+			Items:    s.storageSynced,
+			Bytes:    s.storageBytes,
+			Progress: storageProgress,
+			Rate:     rate(s.storageBytes, s.snapElapsed),
+		},
+		Bytecodes: SyncPhaseStatus{
+			Items:    s.bytecodeSynced,
+			Bytes:    s.bytecodeBytes,
+			Pending:  codesPending,
+			Progress: codeProgress,
+			Rate:     rate(s.bytecodeBytes, s.snapElapsed),
+			ETA:      codeETA,
+		},
+		Healing: SyncPhaseStatus{
+			Items:    healed,
+			Bytes:    healBytes,
+			Pending:  pending,
+			Progress: healProgress,
+			Rate:     rate(healBytes, s.healElapsed),
+			ETA:      healETA,
+		},
+	}
+	snapProgressGauge.Update(int64(coverage * 1000))
+	snapETAGauge.Update(int64(snapETA))
+	healProgressGauge.Update(int64(healProgress * 1000))
+	healETAGauge.Update(int64(healETA))
+}
+
+// trackDelivery accounts a batch of state data delivered by a peer, independent
+// of whether it turns out to be useful or not.
+func (s *Syncer) trackDelivery(id string, update func(stats *SyncPeerStatus)) {
+	s.peerStatsLock.Lock()
+	defer s.peerStatsLock.Unlock()
+
+	stats, ok := s.peerStats[id]
+	if !ok {
+		return
+	}
+	before := stats.total()
+	update(stats)
+	deliveredMeter.Mark(int64(stats.total() - before))
+}
+
+// Status returns a detailed report of the snap sync progress with rates and
+// completion estimates of the individual phases.
+func (s *Syncer) Status() *SyncStatus {
+	s.lock.RLock()
+	status := *s.extStatus
+	s.lock.RUnlock()
+
+	s.peerStatsLock.Lock()
+	defer s.peerStatsLock.Unlock()
+
+	status.Peers = make(map[string]*SyncPeerStatus, len(s.peerStats))
+	for id, stats := range s.peerStats {
+		peer := *stats
+		if elapsed := time.Since(stats.connected); elapsed >= time.Second {
+			peer.Rate = float64(peer.total()) / elapsed.Seconds()
+		}
+		status.Peers[id] = &peer
+	}
+	return &status
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"sync"
+	"testing"
+)
+
+// Tests that the sync status reports the phases, the per peer contributions and
+// that the phase durations are persisted across restarts.
+func TestSyncStatus(t *testing.T) {
+	t.Parallel()
+
+	var (
+		once   sync.Once
+		cancel = make(chan struct{})
+		term   = func() {
+			once.Do(func() {
+				close(cancel)
+			})
+		}
+	)
+	nodeScheme, sourceAccountTrie, elems, storageTries, storageElems := makeAccountTrieWithStorage(3, 3000, true, false)
+
+	source := newTestPeer("source", t, term)
+	source.accountTrie = sourceAccountTrie.Copy()
+	source.accountValues = elems
+	source.setStorageTries(storageTries)
+	source.storageValues = storageElems
+
+	syncer := setupSyncer(nodeScheme, source)
+	if status := syncer.Status(); status.Phase != SyncPhaseIdle {
+		t.Fatalf("initial phase mismatch: have %s, want %s", status.Phase, SyncPhaseIdle)
+	}
+	if err := syncer.Sync(sourceAccountTrie.Hash(), cancel); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	verifyTrie(syncer.db, sourceAccountTrie.Hash(), t)
+
+	status := syncer.Status()
+	if status.Phase != SyncPhaseDone {
+		t.Errorf("final phase mismatch: have %s, want %s", status.Phase, SyncPhaseDone)
+	}
+	if status.Root != sourceAccountTrie.Hash() {
+		t.Errorf("root mismatch: have %x, want %x", status.Root, sourceAccountTrie.Hash())
+	}
+	for name, phase := range map[string]SyncPhaseStatus{"account": status.Accounts, "storage": status.Storage, "bytecode": status.Bytecodes} {
+		if phase.Progress != 1 || phase.ETA != 0 || phase.Pending != 0 {
+			t.Errorf("%s progress mismatch: have %v (eta %d, pending %d), want 1 (eta 0, pending 0)", name, phase.Progress, phase.ETA, phase.Pending)
+		}
+	}
+	if status.Accounts.Items != 3 || status.Storage.Items != 3*3000 || status.Bytecodes.Items != 3 {
+		t.Errorf("item counts mismatch: have %d/%d/%d, want %d/%d/%d", status.Accounts.Items, status.Storage.Items, status.Bytecodes.Items, 3, 3*3000, 3)
+	}
+	peer := status.Peers["source"]
+	if peer == nil {
+		t.Fatalf("missing peer contribution")
+	}
+	if peer.Accounts == 0 || peer.Storage == 0 || peer.Bytecodes == 0 {
+		t.Errorf("peer contribution missing: accounts %v, storage %v, bytecodes %v", peer.Accounts, peer.Storage, peer.Bytecodes)
+	}
+	// Reload the progress in a new syncer and ensure the durations are retained
+	restarted := NewSyncer(syncer.db, nodeScheme)
+	restarted.loadSyncStatus()
+	if restarted.snapElapsed != syncer.snapElapsed || restarted.healElapsed != syncer.healElapsed {
+		t.Errorf("persisted durations mismatch: have %v/%v, want %v/%v", restarted.snapElapsed, restarted.healElapsed, syncer.snapElapsed, syncer.healElapsed)
+	}
+	if syncer.snapElapsed == 0 {
+		t.Errorf("snapping duration not tracked")
+	}
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/msgrate"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
@@ -360,6 +361,10 @@ type SyncProgress struct {
 	TrienodeHealBytes  common.StorageSize // Number of state trie bytes persisted to disk
 	BytecodeHealSynced uint64             // Number of bytecodes downloaded
 	BytecodeHealBytes  common.StorageSize // Number of bytecodes persisted to disk
+
+	// Time spent in the individual phases to estimate rates across restarts
+	SnapElapsed time.Duration // Time spent downloading the account and storage ranges
+	HealElapsed time.Duration // Time spent healing the state trie
 }
 
 // SyncPending is analogous to SyncProgress, but it's used to report on pending
@@ -396,6 +401,22 @@ type SyncPeer interface {
 	Log() log.Logger
 }
 
+// reportPeer feeds a behaviour of the peer into its reputation score, if the peer
+// is backed by a p2p connection.
+func reportPeer(peer SyncPeer, ev p2p.ScoreEvent) {
+	if p, ok := peer.(interface{ Report(p2p.ScoreEvent) }); ok {
+		p.Report(ev)
+	}
+}
+
+// reportServed credits the peer for data it served in response to one of our
+// requests, if the peer is backed by a p2p connection.
+func reportServed(peer SyncPeer, bytes int) {
+	if p, ok := peer.(interface{ ReportServed(int) }); ok {
+		p.ReportServed(bytes)
+	}
+}
+
 // Syncer is an Ethereum account and storage trie syncer based on snapshots and
 // the  snap protocol. It's purpose is to download all the accounts and storage
 // slots from remote peers and reassemble chunks of the state trie, on top of
@@ -440,6 +461,14 @@ type Syncer struct {
 	storageBytes   common.StorageSize // Number of storage trie bytes persisted to disk
 
 	extProgress *SyncProgress // progress that can be exposed to external caller.
+	extStatus   *SyncStatus   // Detailed progress report with estimates for external callers
+
+	snapElapsed time.Duration // Time spent in the snapping phase, across restarts
+	healElapsed time.Duration // Time spent in the healing phase, across restarts
+	phaseTick   time.Time     // Time instance the phase durations were last updated
+
+	peerStats     map[string]*SyncPeerStatus // Data delivered by the individual peers
+	peerStatsLock sync.Mutex                 // Protects the peer stats, updated from peer goroutines
 
 	// Request tracking during healing phase
 	trienodeHealIdlers map[string]struct{} // Peers that aren't serving trie node requests
@@ -505,6 +534,8 @@ func NewSyncer(db ethdb.KeyValueStore, scheme string) *Syncer {
 		stateWriter:          db.NewBatch(),
 
 		extProgress: new(SyncProgress),
+		extStatus:   &SyncStatus{Phase: SyncPhaseIdle},
+		peerStats:   make(map[string]*SyncPeerStatus),
 	}
 }
 
@@ -531,6 +562,10 @@ func (s *Syncer) Register(peer SyncPeer) error {
 	s.bytecodeHealIdlers[id] = struct{}{}
 	s.lock.Unlock()
 
+	s.peerStatsLock.Lock()
+	s.peerStats[id] = &SyncPeerStatus{connected: time.Now()}
+	s.peerStatsLock.Unlock()
+
 	// Notify any active syncs that a new peer can be assigned data
 	s.peerJoin.Send(id)
 	return nil
@@ -559,6 +594,10 @@ func (s *Syncer) Unregister(id string) error {
 	delete(s.bytecodeHealIdlers, id)
 	s.lock.Unlock()
 
+	s.peerStatsLock.Lock()
+	delete(s.peerStats, id)
+	s.peerStatsLock.Unlock()
+
 	// Notify any active syncs that pending requests need to be reverted
 	s.peerDrop.Send(id)
 	return nil
@@ -579,6 +618,7 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 		codeTasks: make(map[common.Hash]struct{}),
 	}
 	s.statelessPeers = make(map[string]struct{})
+	s.phaseTick = time.Now()
 	s.lock.Unlock()
 
 	if s.startTime == (time.Time{}) {
@@ -588,6 +628,9 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 	s.loadSyncStatus()
 	if len(s.tasks) == 0 && s.healer.scheduler.Pending() == 0 {
 		log.Debug("Snapshot sync already completed")
+		s.lock.Lock()
+		s.updateStatus()
+		s.lock.Unlock()
 		return nil
 	}
 	defer func() { // Persist any progress, independent of failure
@@ -595,6 +638,12 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 			s.forwardAccountTask(task)
 		}
 		s.cleanAccountTasks()
+
+		s.lock.Lock()
+		s.updateStatus()
+		s.phaseTick = time.Time{} // Don't account the time between sync cycles
+		s.lock.Unlock()
+
 		s.saveSyncStatus()
 	}()
 
@@ -677,6 +726,7 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 			BytecodeHealSynced: s.bytecodeHealSynced,
 			BytecodeHealBytes:  s.bytecodeHealBytes,
 		}
+		s.updateStatus()
 		s.lock.Unlock()
 		// Wait for something to happen
 		select {
@@ -769,6 +819,9 @@ func (s *Syncer) loadSyncStatus() {
 			s.trienodeHealBytes = progress.TrienodeHealBytes
 			s.bytecodeHealSynced = progress.BytecodeHealSynced
 			s.bytecodeHealBytes = progress.BytecodeHealBytes
+
+			s.snapElapsed = progress.SnapElapsed
+			s.healElapsed = progress.HealElapsed
 			return
 		}
 	}
@@ -781,6 +834,7 @@ func (s *Syncer) loadSyncStatus() {
 	s.storageSynced, s.storageBytes = 0, 0
 	s.trienodeHealSynced, s.trienodeHealBytes = 0, 0
 	s.bytecodeHealSynced, s.bytecodeHealBytes = 0, 0
+	s.snapElapsed, s.healElapsed = 0, 0
 
 	var next common.Hash
 	step := new(big.Int).Sub(
@@ -843,6 +897,8 @@ func (s *Syncer) saveSyncStatus() {
 		TrienodeHealBytes:  s.trienodeHealBytes,
 		BytecodeHealSynced: s.bytecodeHealSynced,
 		BytecodeHealBytes:  s.bytecodeHealBytes,
+		SnapElapsed:        s.snapElapsed,
+		HealElapsed:        s.healElapsed,
 	}
 	status, err := json.Marshal(progress)
 	if err != nil {
@@ -991,6 +1047,7 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Account range request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.ScoreTimeout)
 			s.rates.Update(idle, AccountRangeMsg, 0, 0)
 			s.scheduleRevertAccountRequest(req)
 		})
@@ -1102,6 +1159,7 @@ func (s *Syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.ScoreTimeout)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeRequest(req)
 		})
@@ -1249,6 +1307,7 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Storage request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.ScoreTimeout)
 			s.rates.Update(idle, StorageRangesMsg, 0, 0)
 			s.scheduleRevertStorageRequest(req)
 		})
@@ -1386,6 +1445,7 @@ func (s *Syncer) assignTrienodeHealTasks(success chan *trienodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Trienode heal request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.ScoreTimeout)
 			s.rates.Update(idle, TrieNodesMsg, 0, 0)
 			s.scheduleRevertTrienodeHealRequest(req)
 		})
@@ -1502,6 +1562,7 @@ func (s *Syncer) assignBytecodeHealTasks(success chan *bytecodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode heal request timed out", "reqid", reqid)
+			reportPeer(peer, p2p.ScoreTimeout)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeHealRequest(req)
 		})
@@ -2335,6 +2396,7 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering range of accounts", "hashes", len(hashes), "accounts", len(accounts), "proofs", len(proof), "bytes", size)
+	s.trackDelivery(peer.ID(), func(stats *SyncPeerStatus) { stats.Accounts += size })
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2356,6 +2418,7 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 	if !ok {
 		// Request stale, perhaps the peer timed out but came through in the end
 		logger.Warn("Unexpected account range packet")
+		reportPeer(peer, p2p.ScoreInvalid)
 		s.lock.Unlock()
 		return nil
 	}
@@ -2415,6 +2478,7 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 		}
 		accs[i] = acc
 	}
+	reportServed(peer, int(size))
 	response := &accountResponse{
 		task:     req.task,
 		hashes:   hashes,
@@ -2451,6 +2515,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering set of bytecodes", "bytecodes", len(bytecodes), "bytes", size)
+	s.trackDelivery(peer.ID(), func(stats *SyncPeerStatus) { stats.Bytecodes += size })
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2472,6 +2537,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 	if !ok {
 		// Request stale, perhaps the peer timed out but came through in the end
 		logger.Warn("Unexpected bytecode packet")
+		reportPeer(peer, p2p.ScoreInvalid)
 		s.lock.Unlock()
 		return nil
 	}
@@ -2526,6 +2592,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 		s.scheduleRevertBytecodeRequest(req)
 		return errors.New("unexpected bytecode")
 	}
+	reportServed(peer, int(size))
 	// Response validated, send it to the scheduler for filling
 	response := &bytecodeResponse{
 		task:   req.task,
@@ -2564,6 +2631,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering ranges of storage slots", "accounts", len(hashes), "hashes", hashCount, "slots", slotCount, "proofs", len(proof), "size", size)
+	s.trackDelivery(peer.ID(), func(stats *SyncPeerStatus) { stats.Storage += size })
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2585,6 +2653,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 	if !ok {
 		// Request stale, perhaps the peer timed out but came through in the end
 		logger.Warn("Unexpected storage ranges packet")
+		reportPeer(peer, p2p.ScoreInvalid)
 		s.lock.Unlock()
 		return nil
 	}
@@ -2668,6 +2737,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 			}
 		}
 	}
+	reportServed(peer, int(size))
 	// Partial tries reconstructed, send them to the scheduler for storage filling
 	response := &storageResponse{
 		mainTask: req.mainTask,
@@ -2695,6 +2765,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering set of healing trienodes", "trienodes", len(trienodes), "bytes", size)
+	s.trackDelivery(peer.ID(), func(stats *SyncPeerStatus) { stats.Trienodes += size })
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2716,6 +2787,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 	if !ok {
 		// Request stale, perhaps the peer timed out but came through in the end
 		logger.Warn("Unexpected trienode heal packet")
+		reportPeer(peer, p2p.ScoreInvalid)
 		s.lock.Unlock()
 		return nil
 	}
@@ -2774,6 +2846,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 		s.scheduleRevertTrienodeHealRequest(req)
 		return errors.New("unexpected healing trienode")
 	}
+	reportServed(peer, int(size))
 	// Response validated, send it to the scheduler for filling
 	s.trienodeHealPend.Add(fills)
 	defer func() {
@@ -2802,6 +2875,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering set of healing bytecodes", "bytecodes", len(bytecodes), "bytes", size)
+	s.trackDelivery(peer.ID(), func(stats *SyncPeerStatus) { stats.Bytecodes += size })
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2823,6 +2897,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 	if !ok {
 		// Request stale, perhaps the peer timed out but came through in the end
 		logger.Warn("Unexpected bytecode heal packet")
+		reportPeer(peer, p2p.ScoreInvalid)
 		s.lock.Unlock()
 		return nil
 	}
@@ -2877,6 +2952,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 		s.scheduleRevertBytecodeHealRequest(req)
 		return errors.New("unexpected healing bytecode")
 	}
+	reportServed(peer, int(size))
 	// Response validated, send it to the scheduler for filling
 	response := &bytecodeHealResponse{
 		task:   req.task,
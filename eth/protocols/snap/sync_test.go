@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
@@ -142,6 +144,10 @@ type testPeer struct {
 	nStorageRequests  int
 	nBytecodeRequests int
 	nTrienodeRequests int
+
+	// reputation reports
+	nServedBytes   atomic.Int64
+	nInvalidEvents atomic.Int64
 }
 
 func newTestPeer(id string, t *testing.T, term func()) *testPeer {
@@ -155,8 +161,7 @@ func newTestPeer(id string, t *testing.T, term func()) *testPeer {
 		codeRequestHandler:    defaultCodeRequestHandler,
 		term:                  term,
 	}
-	//stderrHandler := log.StreamHandler(os.Stderr, log.TerminalFormat(true))
-	//peer.logger.SetHandler(stderrHandler)
+	//peer.logger = log.NewLogger(log.NewTerminalHandler(os.Stderr, true)).With("id", id)
 	return peer
 }
 
@@ -170,6 +175,14 @@ func (t *testPeer) setStorageTries(tries map[common.Hash]*trie.Trie) {
 func (t *testPeer) ID() string      { return t.id }
 func (t *testPeer) Log() log.Logger { return t.logger }
 
+func (t *testPeer) Report(ev p2p.ScoreEvent) {
+	if ev == p2p.ScoreInvalid {
+		t.nInvalidEvents.Add(1)
+	}
+}
+
+func (t *testPeer) ReportServed(bytes int) { t.nServedBytes.Add(int64(bytes)) }
+
 func (t *testPeer) Stats() string {
 	return fmt.Sprintf(`Account requests: %d
 Storage requests: %d
@@ -662,6 +675,51 @@ func TestSync(t *testing.T) {
 	verifyTrie(syncer.db, sourceAccountTrie.Hash(), t)
 }
 
+// TestSyncReputation tests that peers are credited for the responses matching
+// requests, and that unsolicited responses are reported as invalid.
+func TestSyncReputation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		once   sync.Once
+		cancel = make(chan struct{})
+		term   = func() {
+			once.Do(func() {
+				close(cancel)
+			})
+		}
+	)
+	nodeScheme, sourceAccountTrie, elems := makeAccountTrieNoStorage(100)
+
+	source := newTestPeer("source", t, term)
+	source.accountTrie = sourceAccountTrie.Copy()
+	source.accountValues = elems
+
+	syncer := setupSyncer(nodeScheme, source)
+	if err := syncer.Sync(sourceAccountTrie.Hash(), cancel); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	verifyTrie(syncer.db, sourceAccountTrie.Hash(), t)
+
+	served := source.nServedBytes.Load()
+	if served == 0 {
+		t.Fatal("peer not credited for served data")
+	}
+	if n := source.nInvalidEvents.Load(); n != 0 {
+		t.Fatalf("peer reported invalid %d times", n)
+	}
+	// A response without a matching request is not credited.
+	if err := syncer.OnAccounts(source, 1<<40, []common.Hash{{1}}, [][]byte{{1}}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if have := source.nServedBytes.Load(); have != served {
+		t.Fatalf("unsolicited response credited: have %d, want %d", have, served)
+	}
+	if n := source.nInvalidEvents.Load(); n != 1 {
+		t.Fatalf("unsolicited response not reported, invalid events %d", n)
+	}
+}
+
 // TestSyncTinyTriePanic tests a basic sync with one peer, and a tiny trie. This caused a
 // panic within the prover
 func TestSyncTinyTriePanic(t *testing.T) {
@@ -148,6 +148,14 @@ func (api *API) blockByNumberAndHash(ctx context.Context, number rpc.BlockNumber
 	return api.blockByHash(ctx, hash)
 }
 
+// prefetchState preloads the state read by a block into the statedb it is about
+// to be executed on, if its read set was recorded when the block was imported.
+func (api *API) prefetchState(block *types.Block, statedb *state.StateDB) {
+	if reads := rawdb.ReadBlockReadSet(api.backend.ChainDb(), block.Hash(), block.NumberU64()); reads != nil {
+		statedb.Prefetch(reads, runtime.NumCPU())
+	}
+}
+
 // TraceConfig holds extra parameters to trace functions.
 type TraceConfig struct {
 	*logger.Config
@@ -268,6 +276,9 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 					signer   = types.MakeSigner(api.backend.ChainConfig(), task.block.Number(), task.block.Time())
 					blockCtx = core.NewEVMBlockContext(task.block.Header(), api.chainContext(ctx), nil)
 				)
+				if api.backend.ChainConfig().IsPrague(task.block.Number(), task.block.Time()) {
+					core.ProcessParentBlockHash(task.block.ParentHash(), task.block.NumberU64()-1, task.statedb)
+				}
 				// Trace all the transactions contained within
 				for i, tx := range task.block.Transactions() {
 					msg, _ := core.TransactionToMessage(tx, signer, task.block.BaseFee())
@@ -518,6 +529,7 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 		return nil, err
 	}
 	defer release()
+	api.prefetchState(block, statedb)
 
 	var (
 		roots              []common.Hash
@@ -526,6 +538,9 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 		vmctx              = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
 		deleteEmptyObjects = chainConfig.IsEIP158(block.Number())
 	)
+	if chainConfig.IsPrague(block.Number(), block.Time()) {
+		core.ProcessParentBlockHash(block.ParentHash(), block.NumberU64()-1, statedb)
+	}
 	for i, tx := range block.Transactions() {
 		if err := ctx.Err(); err != nil {
 			return nil, err
@@ -585,7 +600,11 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 		return nil, err
 	}
 	defer release()
+	api.prefetchState(block, statedb)
 
+	if api.backend.ChainConfig().IsPrague(block.Number(), block.Time()) {
+		core.ProcessParentBlockHash(block.ParentHash(), block.NumberU64()-1, statedb)
+	}
 	// JS tracers have high overhead. In this case run a parallel
 	// process that generates states in one thread and traces txes
 	// in separate worker threads.
@@ -604,6 +623,10 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 		results   = make([]*txTraceResult, len(txs))
 	)
 	for i, tx := range txs {
+		// Stop early if the caller went away or the request ran out of time
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		// Generate the next state snapshot fast without tracing
 		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
 		txctx := &Context{
@@ -727,6 +750,7 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 		return nil, err
 	}
 	defer release()
+	api.prefetchState(block, statedb)
 
 	// Retrieve the tracing configurations, or use default values
 	var (
@@ -756,7 +780,13 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 		// Note: This copies the config, to not screw up the main config
 		chainConfig, canon = overrideConfig(chainConfig, config.Overrides)
 	}
+	if chainConfig.IsPrague(block.Number(), block.Time()) {
+		core.ProcessParentBlockHash(block.ParentHash(), block.NumberU64()-1, statedb)
+	}
 	for i, tx := range block.Transactions() {
+		if err := ctx.Err(); err != nil {
+			return dumps, err
+		}
 		// Prepare the transaction for un-traced execution
 		var (
 			msg, _    = core.TransactionToMessage(tx, signer, block.BaseFee())
@@ -956,6 +986,10 @@ func (api *API) traceTx(ctx context.Context, message *core.Message, txctx *Conte
 			tracer.Stop(errors.New("execution timeout"))
 			// Stop evm execution. Note cancellation is not necessarily immediate.
 			vmenv.Cancel()
+		} else if err := ctx.Err(); err != nil {
+			// The request was abandoned, don't keep tracing for nobody.
+			tracer.Stop(err)
+			vmenv.Cancel()
 		}
 	}()
 	defer cancel()
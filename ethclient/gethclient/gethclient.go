@@ -204,6 +204,21 @@ func (ec *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- co
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions")
 }
 
+// DroppedTransaction is a notification about a transaction leaving the pool
+// without being included in a block.
+type DroppedTransaction struct {
+	Type       string       `json:"type"`   // "dropped" or "replaced"
+	Hash       common.Hash  `json:"hash"`   // Hash of the dropped transaction
+	Reason     string       `json:"reason"` // Reason of the transaction being dropped
+	ReplacedBy *common.Hash `json:"replacedBy,omitempty"`
+}
+
+// SubscribeDroppedTransactions subscribes to transactions leaving the pool
+// without being included, along with the reason of their removal.
+func (ec *Client) SubscribeDroppedTransactions(ctx context.Context, ch chan<- *DroppedTransaction) (*rpc.ClientSubscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "droppedTransactions")
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
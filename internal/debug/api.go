@@ -39,6 +39,8 @@ import (
 	"github.com/hashicorp/go-bexpr"
 )
 
+var errNoLogFile = errors.New("logging to a file is not enabled")
+
 // Handler is the global debugging handler.
 var Handler = new(HandlerT)
 
@@ -56,7 +58,7 @@ type HandlerT struct {
 // Verbosity sets the log verbosity ceiling. The verbosity of individual packages
 // and source files can be raised using Vmodule.
 func (*HandlerT) Verbosity(level int) {
-	glogger.Verbosity(log.Lvl(level))
+	glogger.Verbosity(log.FromLegacyLevel(level))
 }
 
 // Vmodule sets the log verbosity pattern. See package log for details on the
@@ -71,6 +73,41 @@ func (*HandlerT) BacktraceAt(location string) error {
 	return glogger.BacktraceAt(location)
 }
 
+// RotateLog moves the current log file aside and starts writing a new one.
+func (*HandlerT) RotateLog() error {
+	if logOutputFile == nil {
+		return errNoLogFile
+	}
+	return logOutputFile.Rotate()
+}
+
+// LogRotation returns the rotation settings of the log file.
+func (*HandlerT) LogRotation() (log.RotationConfig, error) {
+	if logOutputFile == nil {
+		return log.RotationConfig{}, errNoLogFile
+	}
+	return logOutputFile.Config(), nil
+}
+
+// SetLogRotation changes the rotation settings of the log file. The size is in
+// megabytes and the age in days, zero values disable the respective limit.
+func (*HandlerT) SetLogRotation(maxSize, maxBackups, maxAge int, compress bool) error {
+	if logOutputFile == nil {
+		return errNoLogFile
+	}
+	if maxSize < 0 || maxBackups < 0 || maxAge < 0 {
+		return errors.New("rotation limits must not be negative")
+	}
+	logOutputFile.SetConfig(log.RotationConfig{
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+	log.Info("Log rotation changed", "maxsize", maxSize, "maxbackups", maxBackups, "maxage", maxAge, "compress", compress)
+	return nil
+}
+
 // MemStats returns detailed runtime memory statistics.
 func (*HandlerT) MemStats() *runtime.MemStats {
 	s := new(runtime.MemStats)
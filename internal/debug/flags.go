@@ -27,6 +27,7 @@ import (
 	"runtime"
 
 	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
@@ -34,7 +35,7 @@ import (
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
-	"gopkg.in/natefinch/lumberjack.v2"
+	"golang.org/x/exp/slog"
 )
 
 var Memsize memsizeui.Handler
@@ -87,8 +88,9 @@ var (
 		Category: flags.LoggingCategory,
 	}
 	logRotateFlag = &cli.BoolFlag{
-		Name:  "log.rotate",
-		Usage: "Enables log file rotation",
+		Name:     "log.rotate",
+		Usage:    "Enables log file rotation",
+		Category: flags.LoggingCategory,
 	}
 	logMaxSizeMBsFlag = &cli.IntFlag{
 		Name:     "log.maxsize",
@@ -152,6 +154,22 @@ var (
 		Usage:    "Write execution trace to the given file",
 		Category: flags.LoggingCategory,
 	}
+	tracingEndpointFlag = &cli.StringFlag{
+		Name:     "tracing.endpoint",
+		Usage:    "OTLP/HTTP collector endpoint to export trace spans to (e.g. http://localhost:4318)",
+		Category: flags.LoggingCategory,
+	}
+	tracingFileFlag = &cli.StringFlag{
+		Name:     "tracing.file",
+		Usage:    "Write trace spans to the given file in OTLP/JSON format",
+		Category: flags.LoggingCategory,
+	}
+	tracingSampleRatioFlag = &cli.Float64Flag{
+		Name:     "tracing.sample-ratio",
+		Usage:    "Fraction of traces to record, unless decided by the traceparent of an RPC request",
+		Value:    telemetry.DefaultConfig.SampleRatio,
+		Category: flags.LoggingCategory,
+	}
 )
 
 // Flags holds all command-line flags required for debugging.
@@ -176,52 +194,54 @@ var Flags = []cli.Flag{
 	blockprofilerateFlag,
 	cpuprofileFlag,
 	traceFlag,
+	tracingEndpointFlag,
+	tracingFileFlag,
+	tracingSampleRatioFlag,
 }
 
 var (
-	glogger         *log.GlogHandler
-	logOutputStream log.Handler
+	glogger       *log.GlogHandler
+	logOutputFile *log.RotatingFileWriter
+	stopTracing   func()
 )
 
 func init() {
-	glogger = log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger = log.NewGlogHandler(log.NewTerminalHandler(os.Stderr, false))
 	glogger.Verbosity(log.LvlInfo)
-	log.Root().SetHandler(glogger)
+	log.SetDefault(log.NewLogger(glogger))
 }
 
 // Setup initializes profiling and logging based on the CLI flags.
 // It should be called as early as possible in the program.
 func Setup(ctx *cli.Context) error {
 	var (
-		logfmt     log.Format
+		handler    func(io.Writer) slog.Handler
 		output     = io.Writer(os.Stderr)
 		logFmtFlag = ctx.String(logFormatFlag.Name)
+		logFile    = ctx.String(logFileFlag.Name)
+		rotation   = ctx.Bool(logRotateFlag.Name)
 	)
 	switch {
 	case ctx.Bool(logjsonFlag.Name):
 		// Retain backwards compatibility with `--log.json` flag if `--log.format` not set
 		defer log.Warn("The flag '--log.json' is deprecated, please use '--log.format=json' instead")
-		logfmt = log.JSONFormat()
+		handler = log.JSONHandler
 	case logFmtFlag == "json":
-		logfmt = log.JSONFormat()
+		handler = log.JSONHandler
 	case logFmtFlag == "logfmt":
-		logfmt = log.LogfmtFormat()
+		handler = log.LogfmtHandler
 	case logFmtFlag == "", logFmtFlag == "terminal":
 		useColor := (isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd())) && os.Getenv("TERM") != "dumb"
 		if useColor {
 			output = colorable.NewColorableStderr()
 		}
-		logfmt = log.TerminalFormat(useColor)
+		handler = func(wr io.Writer) slog.Handler {
+			return log.NewTerminalHandler(wr, useColor)
+		}
 	default:
 		// Unknown log format specified
 		return fmt.Errorf("unknown log format: %v", ctx.String(logFormatFlag.Name))
 	}
-	var (
-		stdHandler = log.StreamHandler(output, logfmt)
-		ostream    = stdHandler
-		logFile    = ctx.String(logFileFlag.Name)
-		rotation   = ctx.Bool(logRotateFlag.Name)
-	)
 	if len(logFile) > 0 {
 		if err := validateLogLocation(filepath.Dir(logFile)); err != nil {
 			return fmt.Errorf("failed to initiatilize file logger: %v", err)
@@ -233,34 +253,35 @@ func Setup(ctx *cli.Context) error {
 	} else {
 		context = append(context, "format", "terminal")
 	}
-	if rotation {
-		// Lumberjack uses <processname>-lumberjack.log in is.TempDir() if empty.
-		// so typically /tmp/geth-lumberjack.log on linux
-		if len(logFile) > 0 {
-			context = append(context, "location", logFile)
-		} else {
-			context = append(context, "location", filepath.Join(os.TempDir(), "geth-lumberjack.log"))
+	if rotation || logFile != "" {
+		// Without an explicit location, rotated logs retain the location used
+		// by previous releases, typically /tmp/geth-lumberjack.log on linux.
+		if logFile == "" {
+			logFile = filepath.Join(os.TempDir(), "geth-lumberjack.log")
+		}
+		var cfg log.RotationConfig
+		if rotation {
+			cfg = log.RotationConfig{
+				MaxSize:    ctx.Int(logMaxSizeMBsFlag.Name),
+				MaxBackups: ctx.Int(logMaxBackupsFlag.Name),
+				MaxAge:     ctx.Int(logMaxAgeFlag.Name),
+				Compress:   ctx.Bool(logCompressFlag.Name),
+			}
 		}
-		ostream = log.MultiHandler(log.StreamHandler(&lumberjack.Logger{
-			Filename:   logFile,
-			MaxSize:    ctx.Int(logMaxSizeMBsFlag.Name),
-			MaxBackups: ctx.Int(logMaxBackupsFlag.Name),
-			MaxAge:     ctx.Int(logMaxAgeFlag.Name),
-			Compress:   ctx.Bool(logCompressFlag.Name),
-		}, logfmt), stdHandler)
-	} else if logFile != "" {
-		if logOutputStream, err := log.FileHandler(logFile, logfmt); err != nil {
+		var err error
+		if logOutputFile, err = log.NewRotatingFileWriter(logFile, cfg); err != nil {
 			return err
-		} else {
-			ostream = log.MultiHandler(logOutputStream, stdHandler)
-			context = append(context, "location", logFile)
 		}
+		context = append(context, "location", logFile)
 	}
-	glogger.SetHandler(ostream)
+	if logOutputFile != nil {
+		output = io.MultiWriter(logOutputFile, output)
+	}
+	glogger = log.NewGlogHandler(handler(output))
 
 	// logging
 	verbosity := ctx.Int(verbosityFlag.Name)
-	glogger.Verbosity(log.Lvl(verbosity))
+	glogger.Verbosity(log.FromLegacyLevel(verbosity))
 	vmodule := ctx.String(logVmoduleFlag.Name)
 	if vmodule == "" {
 		// Retain backwards compatibility with `--vmodule` flag if `--log.vmodule` not set
@@ -280,7 +301,7 @@ func Setup(ctx *cli.Context) error {
 	backtrace := ctx.String(backtraceAtFlag.Name)
 	glogger.BacktraceAt(backtrace)
 
-	log.Root().SetHandler(glogger)
+	log.SetDefault(log.NewLogger(glogger))
 
 	// profiling, tracing
 	runtime.MemProfileRate = memprofilerateFlag.Value
@@ -314,6 +335,20 @@ func Setup(ctx *cli.Context) error {
 		// It cannot be imported because it will cause a cyclical dependency.
 		StartPProf(address, !ctx.IsSet("metrics.addr"))
 	}
+
+	// span export
+	tracing := telemetry.DefaultConfig
+	tracing.Endpoint = ctx.String(tracingEndpointFlag.Name)
+	tracing.File = ctx.String(tracingFileFlag.Name)
+	tracing.SampleRatio = ctx.Float64(tracingSampleRatioFlag.Name)
+	if tracing.Enabled() {
+		stop, err := telemetry.Setup(tracing)
+		if err != nil {
+			return fmt.Errorf("failed to set up tracing: %v", err)
+		}
+		stopTracing = stop
+		log.Info("Exporting trace spans", "endpoint", tracing.Endpoint, "file", tracing.File, "ratio", tracing.SampleRatio)
+	}
 	if len(logFile) > 0 || rotation {
 		log.Info("Logging configured", context...)
 	}
@@ -340,8 +375,13 @@ func StartPProf(address string, withMetrics bool) {
 func Exit() {
 	Handler.StopCPUProfile()
 	Handler.StopGoTrace()
-	if closer, ok := logOutputStream.(io.Closer); ok {
-		closer.Close()
+	if stopTracing != nil {
+		stopTracing()
+		stopTracing = nil
+	}
+	if logOutputFile != nil {
+		logOutputFile.Close()
+		logOutputFile = nil
 	}
 }
 
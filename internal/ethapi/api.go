@@ -21,6 +21,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	gomath "math"
 	"math/big"
 	"strings"
 	"time"
@@ -37,10 +38,12 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
@@ -253,6 +256,217 @@ func (s *TxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	return content
 }
 
+// maxTxPoolQueryLimit is the maximum number of transactions returned by a single
+// txpool_query call.
+const maxTxPoolQueryLimit = 1000
+
+// TxPoolQueryArgs represents the filtering, sorting and pagination criteria of
+// the txpool_query and txpool_subscribe calls.
+type TxPoolQueryArgs struct {
+	From      []common.Address `json:"from"`
+	To        []common.Address `json:"to"`
+	Selectors []hexutil.Bytes  `json:"selectors"`
+	MinTip    *hexutil.Big     `json:"minTip"`
+	Types     []hexutil.Uint64 `json:"types"`
+	MinAge    *hexutil.Uint64  `json:"minAge"` // Seconds since first seen
+	MaxAge    *hexutil.Uint64  `json:"maxAge"` // Seconds since first seen
+	Status    string           `json:"status"` // "pending", "queued" or empty for both
+	Sort      string           `json:"sort"`   // "nonce" (default), "tip" or "age"
+	Offset    hexutil.Uint64   `json:"offset"`
+	Limit     *hexutil.Uint64  `json:"limit"`
+}
+
+// query converts the RPC arguments into a transaction pool query, validating
+// and capping them along the way.
+func (args *TxPoolQueryArgs) query() (*txpool.Query, error) {
+	if args.Offset > gomath.MaxInt {
+		return nil, fmt.Errorf("invalid offset %d: must be at most %d", args.Offset, gomath.MaxInt)
+	}
+	q := &txpool.Query{
+		Senders:    args.From,
+		Recipients: args.To,
+		Offset:     int(args.Offset),
+		Limit:      maxTxPoolQueryLimit,
+	}
+	for _, selector := range args.Selectors {
+		if len(selector) != 4 {
+			return nil, fmt.Errorf("invalid method selector %v: must be 4 bytes", selector)
+		}
+		var sel [4]byte
+		copy(sel[:], selector)
+		q.Selectors = append(q.Selectors, sel)
+	}
+	if args.MinTip != nil {
+		q.MinTip = args.MinTip.ToInt()
+	}
+	for _, typ := range args.Types {
+		if typ > math.MaxUint8 {
+			return nil, fmt.Errorf("invalid transaction type %d", typ)
+		}
+		q.Types = append(q.Types, uint8(typ))
+	}
+	if args.MinAge != nil {
+		q.MinAge = time.Duration(*args.MinAge) * time.Second
+	}
+	if args.MaxAge != nil {
+		q.MaxAge = time.Duration(*args.MaxAge) * time.Second
+	}
+	switch args.Status {
+	case "":
+	case "pending":
+		q.Status = txpool.TxStatusPending
+	case "queued":
+		q.Status = txpool.TxStatusQueued
+	default:
+		return nil, fmt.Errorf("invalid status %q", args.Status)
+	}
+	switch args.Sort {
+	case "", "nonce":
+		q.Sort = txpool.QuerySortNonce
+	case "tip":
+		q.Sort = txpool.QuerySortTip
+	case "age":
+		q.Sort = txpool.QuerySortAge
+	default:
+		return nil, fmt.Errorf("invalid sort order %q", args.Sort)
+	}
+	if args.Limit != nil {
+		if *args.Limit == 0 || *args.Limit > maxTxPoolQueryLimit {
+			return nil, fmt.Errorf("invalid limit %d: must be between 1 and %d", *args.Limit, maxTxPoolQueryLimit)
+		}
+		q.Limit = int(*args.Limit)
+	}
+	return q, nil
+}
+
+// RPCPoolTransaction represents a transaction matched by a pool query, along
+// with its pool specific metadata.
+type RPCPoolTransaction struct {
+	*RPCTransaction
+	Status    string         `json:"status"`
+	FirstSeen hexutil.Uint64 `json:"firstSeen"`
+}
+
+// TxPoolQueryResult is a page of transactions matched by a pool query.
+type TxPoolQueryResult struct {
+	Total        hexutil.Uint          `json:"total"`
+	Transactions []*RPCPoolTransaction `json:"transactions"`
+}
+
+// Query returns the transactions from the pool matching the given criteria,
+// sorted and paginated as requested. The total number of matching transactions
+// is also returned to allow iterating over all the pages.
+func (s *TxPoolAPI) Query(args TxPoolQueryArgs) (*TxPoolQueryResult, error) {
+	q, err := args.query()
+	if err != nil {
+		return nil, err
+	}
+	matches, total := s.b.TxPoolQuery(q)
+	curHeader := s.b.CurrentHeader()
+
+	result := &TxPoolQueryResult{
+		Total:        hexutil.Uint(total),
+		Transactions: make([]*RPCPoolTransaction, 0, len(matches)),
+	}
+	for _, match := range matches {
+		status := "queued"
+		if match.Status == txpool.TxStatusPending {
+			status = "pending"
+		}
+		result.Transactions = append(result.Transactions, &RPCPoolTransaction{
+			RPCTransaction: NewRPCPendingTransaction(match.Tx, curHeader, s.b.ChainConfig()),
+			Status:         status,
+			FirstSeen:      hexutil.Uint64(match.Tx.Time().Unix()),
+		})
+	}
+	return result, nil
+}
+
+// RPCPoolEvent is a transaction lifecycle notification delivered to txpool
+// subscribers.
+type RPCPoolEvent struct {
+	Type        string          `json:"type"` // "added", "dropped" or "replaced"
+	Hash        common.Hash     `json:"hash"`
+	Reason      string          `json:"reason,omitempty"`
+	ReplacedBy  *common.Hash    `json:"replacedBy,omitempty"`
+	Transaction *RPCTransaction `json:"transaction,omitempty"`
+}
+
+// NewRPCPoolDropEvent returns a notification about a transaction removed from the
+// pool, along with the reason of its removal and its replacement, if any.
+func NewRPCPoolDropEvent(drop *txpool.DroppedTx) *RPCPoolEvent {
+	event := &RPCPoolEvent{Type: "dropped", Hash: drop.Tx.Hash(), Reason: drop.Reason.String()}
+	if drop.Reason == txpool.DropReplaced {
+		event.Type = "replaced"
+	}
+	if drop.Replacement != nil {
+		hash := drop.Replacement.Hash()
+		event.ReplacedBy = &hash
+	}
+	return event
+}
+
+// Transactions creates a subscription that is notified each time a transaction
+// matching the filtering criteria enters the pool, is replaced or is dropped from
+// it, along with the reason of the removal. The sorting and pagination criteria
+// are ignored for subscriptions.
+func (s *TxPoolAPI) Transactions(ctx context.Context, args TxPoolQueryArgs, fullTx *bool) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	q, err := args.query()
+	if err != nil {
+		return nil, err
+	}
+	q.Status = txpool.TxStatusUnknown
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		var (
+			addCh   = make(chan core.NewTxsEvent, 128)
+			dropCh  = make(chan txpool.DropTxsEvent, 128)
+			addSub  = s.b.SubscribeNewTxsEvent(addCh)
+			dropSub = s.b.SubscribeDropTxsEvent(dropCh)
+			signer  = types.LatestSigner(s.b.ChainConfig())
+		)
+		defer addSub.Unsubscribe()
+		defer dropSub.Unsubscribe()
+
+		notify := func(ev *RPCPoolEvent, tx *types.Transaction) {
+			if fullTx != nil && *fullTx {
+				ev.Transaction = NewRPCPendingTransaction(tx, s.b.CurrentHeader(), s.b.ChainConfig())
+			}
+			notifier.Notify(rpcSub.ID, ev)
+		}
+		for {
+			select {
+			case ev := <-addCh:
+				for _, tx := range ev.Txs {
+					from, _ := types.Sender(signer, tx)
+					if q.Match(from, tx) {
+						notify(&RPCPoolEvent{Type: "added", Hash: tx.Hash()}, tx)
+					}
+				}
+			case ev := <-dropCh:
+				for _, drop := range ev.Txs {
+					from, _ := types.Sender(signer, drop.Tx)
+					if !q.Match(from, drop.Tx) {
+						continue
+					}
+					notify(NewRPCPoolDropEvent(drop), drop.Tx)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
 // EthereumAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type EthereumAccountAPI struct {
@@ -1030,7 +1244,10 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 	}
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
-	var cancel context.CancelFunc
+	var (
+		reqCtx = ctx
+		cancel context.CancelFunc
+	)
 	if timeout > 0 {
 		ctx, cancel = context.WithTimeout(ctx, timeout)
 	} else {
@@ -1058,15 +1275,27 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 		evm.Cancel()
 	}()
 
-	// Execute the message.
+	// Execute the message, recording the state reads separately from the execution
+	// in the trace, if any.
+	_, span := telemetry.Start(ctx, "ethapi.execute")
+	state.SetReadSpan(span)
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
 	result, err := core.ApplyMessage(evm, msg, gp)
+	state.SetReadSpan(nil)
+	if result != nil {
+		span.SetAttributes(telemetry.Uint64("evm.gas_used", result.UsedGas))
+	}
+	span.EndWithError(err)
 	if err := vmError(); err != nil {
 		return nil, err
 	}
 
-	// If the timer caused an abort, return an appropriate error message
+	// If the request itself was canceled or timed out, report that. Otherwise
+	// the timer caused an abort, return an appropriate error message.
 	if evm.Cancelled() {
+		if err := reqCtx.Err(); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
 	}
 	if err != nil {
@@ -1078,7 +1307,9 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
-	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	sctx, span := telemetry.Start(ctx, "ethapi.openState")
+	state, header, err := b.StateAndHeaderByNumberOrHash(sctx, blockNrOrHash)
+	span.EndWithError(err)
 	if state == nil || err != nil {
 		return nil, err
 	}
@@ -1223,6 +1454,9 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 	}
 	// Execute the binary search and hone in on an executable gas limit
 	for lo+1 < hi {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
 		s := state.Copy()
 		mid := (hi + lo) / 2
 		failed, _, err := executable(mid, s, header)
@@ -1356,25 +1590,26 @@ func (s *BlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash        *common.Hash      `json:"blockHash"`
-	BlockNumber      *hexutil.Big      `json:"blockNumber"`
-	From             common.Address    `json:"from"`
-	Gas              hexutil.Uint64    `json:"gas"`
-	GasPrice         *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap        *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap        *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	Hash             common.Hash       `json:"hash"`
-	Input            hexutil.Bytes     `json:"input"`
-	Nonce            hexutil.Uint64    `json:"nonce"`
-	To               *common.Address   `json:"to"`
-	TransactionIndex *hexutil.Uint64   `json:"transactionIndex"`
-	Value            *hexutil.Big      `json:"value"`
-	Type             hexutil.Uint64    `json:"type"`
-	Accesses         *types.AccessList `json:"accessList,omitempty"`
-	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
-	V                *hexutil.Big      `json:"v"`
-	R                *hexutil.Big      `json:"r"`
-	S                *hexutil.Big      `json:"s"`
+	BlockHash         *common.Hash          `json:"blockHash"`
+	BlockNumber       *hexutil.Big          `json:"blockNumber"`
+	From              common.Address        `json:"from"`
+	Gas               hexutil.Uint64        `json:"gas"`
+	GasPrice          *hexutil.Big          `json:"gasPrice"`
+	GasFeeCap         *hexutil.Big          `json:"maxFeePerGas,omitempty"`
+	GasTipCap         *hexutil.Big          `json:"maxPriorityFeePerGas,omitempty"`
+	Hash              common.Hash           `json:"hash"`
+	Input             hexutil.Bytes         `json:"input"`
+	Nonce             hexutil.Uint64        `json:"nonce"`
+	To                *common.Address       `json:"to"`
+	TransactionIndex  *hexutil.Uint64       `json:"transactionIndex"`
+	Value             *hexutil.Big          `json:"value"`
+	Type              hexutil.Uint64        `json:"type"`
+	Accesses          *types.AccessList     `json:"accessList,omitempty"`
+	ChainID           *hexutil.Big          `json:"chainId,omitempty"`
+	AuthorizationList []types.Authorization `json:"authorizationList,omitempty"`
+	V                 *hexutil.Big          `json:"v"`
+	R                 *hexutil.Big          `json:"r"`
+	S                 *hexutil.Big          `json:"s"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1412,12 +1647,13 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
-	case types.DynamicFeeTxType:
+	case types.DynamicFeeTxType, types.SetCodeTxType:
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
 		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
 		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		result.AuthorizationList = tx.AuthList()
 		// if the transaction has been mined, compute the effective gas price
 		if baseFee != nil && blockHash != (common.Hash{}) {
 			// price = min(tip, gasFeeCap - baseFee) + baseFee
@@ -1526,6 +1762,9 @@ func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrH
 		prevTracer = logger.NewAccessListTracer(*args.AccessList, args.from(), to, precompiles)
 	}
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, nil, err
+		}
 		// Retrieve the current access list to expand
 		accessList := prevTracer.AccessList()
 		log.Trace("Creating access list", "input", accessList)
@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -206,6 +207,11 @@ type testBackend struct {
 	db      ethdb.Database
 	chain   *core.BlockChain
 	pending *types.Block
+
+	poolPending map[common.Address][]*types.Transaction
+	poolQueued  map[common.Address][]*types.Transaction
+	txFeed      *event.Feed
+	dropFeed    *event.Feed
 }
 
 func newTestBackend(t *testing.T, n int, gspec *core.Genesis, generator func(i int, b *core.BlockGen)) *testBackend {
@@ -371,8 +377,14 @@ func (b testBackend) TxPoolContent() (map[common.Address][]*types.Transaction, m
 func (b testBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
 	panic("implement me")
 }
+func (b testBackend) TxPoolQuery(q *txpool.Query) ([]*txpool.QueryResult, int) {
+	return q.Apply(b.poolPending, b.poolQueued)
+}
 func (b testBackend) SubscribeNewTxsEvent(events chan<- core.NewTxsEvent) event.Subscription {
-	panic("implement me")
+	return b.txFeed.Subscribe(events)
+}
+func (b testBackend) SubscribeDropTxsEvent(events chan<- txpool.DropTxsEvent) event.Subscription {
+	return b.dropFeed.Subscribe(events)
 }
 func (b testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
 func (b testBackend) Engine() consensus.Engine         { return b.chain.Engine() }
@@ -1016,3 +1028,143 @@ func TestRPCGetBlockOrHeader(t *testing.T) {
 		require.JSONEqf(t, want, have, "test %d: json not match, want: %s, have: %s", i, want, have)
 	}
 }
+
+func TestTxPoolQuery(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		backend  = newTestBackend(t, 0, &core.Genesis{Config: params.TestChainConfig}, nil)
+		signer   = types.LatestSigner(params.TestChainConfig)
+		newTx    = func(key *ecdsa.PrivateKey, nonce uint64) *types.Transaction {
+			tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{Nonce: nonce, To: &common.Address{}, Gas: params.TxGas, GasPrice: big.NewInt(params.InitialBaseFee)}), signer, key)
+			return tx
+		}
+	)
+	backend.poolPending = map[common.Address][]*types.Transaction{
+		accounts[0].addr: {newTx(accounts[0].key, 0), newTx(accounts[0].key, 1), newTx(accounts[0].key, 2)},
+	}
+	backend.poolQueued = map[common.Address][]*types.Transaction{
+		accounts[1].addr: {newTx(accounts[1].key, 5)},
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("txpool", NewTxPoolAPI(backend)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	type result struct {
+		Total        hexutil.Uint
+		Transactions []struct {
+			From   common.Address
+			Nonce  hexutil.Uint64
+			Status string
+		}
+	}
+	var tests = []struct {
+		args      map[string]interface{}
+		total     uint
+		nonces    []uint64
+		expectErr bool
+	}{
+		{args: map[string]interface{}{}, total: 4, nonces: []uint64{0, 1, 2, 5}},
+		{args: map[string]interface{}{"status": "queued"}, total: 1, nonces: []uint64{5}},
+		{args: map[string]interface{}{"from": []common.Address{accounts[0].addr}, "offset": "0x1", "limit": "0x1"}, total: 3, nonces: []uint64{1}},
+		{args: map[string]interface{}{"offset": "0x10"}, total: 4, nonces: []uint64{}},
+		{args: map[string]interface{}{"offset": "0xffffffffffffffff"}, expectErr: true},
+		{args: map[string]interface{}{"limit": "0x0"}, expectErr: true},
+		{args: map[string]interface{}{"sort": "size"}, expectErr: true},
+	}
+	for i, tt := range tests {
+		var res result
+		err := client.Call(&res, "txpool_query", tt.args)
+		if tt.expectErr {
+			if err == nil {
+				t.Errorf("test %d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: query failed: %v", i, err)
+			continue
+		}
+		if uint(res.Total) != tt.total {
+			t.Errorf("test %d: total mismatch: have %d, want %d", i, res.Total, tt.total)
+		}
+		nonces := []uint64{}
+		for _, tx := range res.Transactions {
+			nonces = append(nonces, uint64(tx.Nonce))
+		}
+		if !reflect.DeepEqual(nonces, tt.nonces) {
+			t.Errorf("test %d: nonces mismatch: have %v, want %v", i, nonces, tt.nonces)
+		}
+	}
+}
+
+func TestTxPoolSubscribeDrops(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		backend  = newTestBackend(t, 0, &core.Genesis{Config: params.TestChainConfig}, nil)
+		signer   = types.LatestSigner(params.TestChainConfig)
+		newTx    = func(key *ecdsa.PrivateKey, nonce uint64, price int64) *types.Transaction {
+			tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{Nonce: nonce, To: &common.Address{}, Gas: params.TxGas, GasPrice: big.NewInt(price)}), signer, key)
+			return tx
+		}
+	)
+	backend.txFeed, backend.dropFeed = new(event.Feed), new(event.Feed)
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("txpool", NewTxPoolAPI(backend)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	events := make(chan *RPCPoolEvent, 8)
+	sub, err := client.Subscribe(context.Background(), "txpool", events, "transactions", map[string]interface{}{"from": []common.Address{accounts[0].addr}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	var (
+		old         = newTx(accounts[0].key, 0, 1)
+		replacement = newTx(accounts[0].key, 0, 2)
+		stale       = newTx(accounts[0].key, 1, 1)
+		other       = newTx(accounts[1].key, 0, 1)
+	)
+	// The subscription is set up on the server asynchronously, wait for it
+	drops := txpool.DropTxsEvent{Txs: []*txpool.DroppedTx{
+		{Tx: other, Reason: txpool.DropLifetime},
+		{Tx: old, Reason: txpool.DropReplaced, Replacement: replacement},
+		{Tx: stale, Reason: txpool.DropStale},
+	}}
+	for backend.dropFeed.Send(drops) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	want := []*RPCPoolEvent{
+		{Type: "replaced", Hash: old.Hash(), Reason: "replaced", ReplacedBy: ptrHash(replacement.Hash())},
+		{Type: "dropped", Hash: stale.Hash(), Reason: "stale"},
+	}
+	for i, w := range want {
+		select {
+		case ev := <-events:
+			if !reflect.DeepEqual(ev, w) {
+				t.Errorf("event %d mismatch: have %+v, want %+v", i, ev, w)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %d not delivered", i)
+		}
+	}
+}
+
+func ptrHash(hash common.Hash) *common.Hash {
+	return &hash
+}
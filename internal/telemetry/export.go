@@ -0,0 +1,173 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	spanQueueSize    = 8192            // Maximum number of ended spans waiting for export
+	exportBatchSize  = 512             // Maximum number of spans exported at once
+	exportInterval   = 5 * time.Second // Time after which pending spans are exported
+	exportWarnPeriod = time.Minute     // Minimum time between export failure warnings
+)
+
+// droppedSpanMeter counts the spans discarded because the export queue was full.
+var droppedSpanMeter = metrics.NewRegisteredMeter("telemetry/spans/dropped", nil)
+
+// exporter sends batches of ended spans to their destination.
+type exporter interface {
+	Export(resource []Attribute, spans []*Span) error
+	Close() error
+}
+
+// tracer creates span IDs, samples new traces and exports ended spans in batches.
+type tracer struct {
+	exporters []exporter
+	ratio     float64
+	resource  []Attribute
+
+	queue chan *Span
+	quit  chan struct{}
+	done  chan struct{}
+
+	randLock sync.Mutex
+	rand     *rand.Rand
+}
+
+func newTracer(exporters []exporter, ratio float64, resource []Attribute) *tracer {
+	var seed int64
+	binary.Read(crand.Reader, binary.LittleEndian, &seed)
+
+	t := &tracer{
+		exporters: exporters,
+		ratio:     ratio,
+		resource:  resource,
+		queue:     make(chan *Span, spanQueueSize),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+		rand:      rand.New(rand.NewSource(seed)),
+	}
+	go t.loop()
+	return t
+}
+
+func (t *tracer) newTraceID() (id TraceID) {
+	t.randLock.Lock()
+	defer t.randLock.Unlock()
+
+	for !id.IsValid() {
+		t.rand.Read(id[:])
+	}
+	return id
+}
+
+func (t *tracer) newSpanID() (id SpanID) {
+	t.randLock.Lock()
+	defer t.randLock.Unlock()
+
+	for !id.IsValid() {
+		t.rand.Read(id[:])
+	}
+	return id
+}
+
+// sample decides whether a new trace is recorded. The decision is derived from the
+// random part of the trace ID, like the TraceIDRatioBased sampler of OpenTelemetry.
+func (t *tracer) sample(id TraceID) bool {
+	switch {
+	case t.ratio >= 1:
+		return true
+	case t.ratio <= 0:
+		return false
+	}
+	bound := uint64(t.ratio * (1 << 63))
+	return binary.BigEndian.Uint64(id[8:])>>1 < bound
+}
+
+// export queues an ended span for export, or drops it if the queue is full.
+func (t *tracer) export(s *Span) {
+	select {
+	case t.queue <- s:
+	default:
+		droppedSpanMeter.Mark(1)
+	}
+}
+
+// stop exports the queued spans and shuts down the exporters.
+func (t *tracer) stop() {
+	close(t.quit)
+	<-t.done
+}
+
+func (t *tracer) loop() {
+	defer close(t.done)
+
+	var (
+		batch    = make([]*Span, 0, exportBatchSize)
+		ticker   = time.NewTicker(exportInterval)
+		lastWarn time.Time
+	)
+	defer ticker.Stop()
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		for _, exp := range t.exporters {
+			if err := exp.Export(t.resource, batch); err != nil && time.Since(lastWarn) > exportWarnPeriod {
+				log.Warn("Failed to export trace spans", "spans", len(batch), "err", err)
+				lastWarn = time.Now()
+			}
+		}
+		batch = batch[:0]
+	}
+	add := func(s *Span) {
+		if batch = append(batch, s); len(batch) == exportBatchSize {
+			flush()
+		}
+	}
+	for {
+		select {
+		case s := <-t.queue:
+			add(s)
+		case <-ticker.C:
+			flush()
+		case <-t.quit:
+			for {
+				select {
+				case s := <-t.queue:
+					add(s)
+				default:
+					flush()
+					for _, exp := range t.exporters {
+						exp.Close()
+					}
+					return
+				}
+			}
+		}
+	}
+}
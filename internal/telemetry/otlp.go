@@ -0,0 +1,240 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	otlpTracesPath   = "/v1/traces"     // Default path of the OTLP/HTTP traces endpoint
+	otlpScopeName    = "go-ethereum"    // Instrumentation scope reported with all spans
+	otlpStatusError  = 2                // STATUS_CODE_ERROR
+	otlpHTTPTimeout  = 10 * time.Second // Timeout of a single export request
+	otlpErrorMaxSize = 512              // Maximum number of bytes read from error responses
+)
+
+// The types below mirror the JSON encoding of the OTLP ExportTraceServiceRequest
+// message, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"` // 64 bit integers are encoded as strings
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func encodeAttributes(attrs []Attribute) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]otlpKeyValue, len(attrs))
+	for i, attr := range attrs {
+		kvs[i].Key = attr.Key
+		switch v := attr.Value.(type) {
+		case string:
+			kvs[i].Value.StringValue = &v
+		case bool:
+			kvs[i].Value.BoolValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			kvs[i].Value.IntValue = &s
+		case uint64:
+			// OTLP has no unsigned integers, fall back to a string if it doesn't fit.
+			s := strconv.FormatUint(v, 10)
+			if v > math.MaxInt64 {
+				kvs[i].Value.StringValue = &s
+			} else {
+				kvs[i].Value.IntValue = &s
+			}
+		case float64:
+			kvs[i].Value.DoubleValue = &v
+		default:
+			s := fmt.Sprint(v)
+			kvs[i].Value.StringValue = &s
+		}
+	}
+	return kvs
+}
+
+func encodeSpan(s *Span) otlpSpan {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	enc := otlpSpan{
+		TraceID:           s.sc.TraceID.String(),
+		SpanID:            s.sc.SpanID.String(),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Attributes:        encodeAttributes(s.attrs),
+	}
+	if s.parent.IsValid() {
+		enc.ParentSpanID = s.parent.String()
+	}
+	if s.failed {
+		enc.Status = otlpStatus{Code: otlpStatusError, Message: s.status}
+	}
+	return enc
+}
+
+// encodeTraces encodes the spans as an OTLP/JSON ExportTraceServiceRequest.
+func encodeTraces(resource []Attribute, spans []*Span) ([]byte, error) {
+	encoded := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		encoded[i] = encodeSpan(s)
+	}
+	return json.Marshal(otlpTraces{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: encodeAttributes(resource)},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: otlpScopeName},
+				Spans: encoded,
+			}},
+		}},
+	})
+}
+
+// httpExporter sends spans to an OTLP/HTTP collector endpoint.
+type httpExporter struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPExporter(endpoint string) (*httpExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: scheme must be http or https", endpoint)
+	}
+	// Like the OpenTelemetry SDKs, treat endpoints without a path as the base URL
+	// of the collector.
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpTracesPath
+	}
+	return &httpExporter{
+		url:    u.String(),
+		client: &http.Client{Timeout: otlpHTTPTimeout},
+	}, nil
+}
+
+func (e *httpExporter) Export(resource []Attribute, spans []*Span) error {
+	body, err := encodeTraces(resource, spans)
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, otlpErrorMaxSize))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *httpExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// fileExporter appends spans to a file, one OTLP/JSON request per line. This is
+// the format read by the otlpjsonfile receiver of the OpenTelemetry collector.
+type fileExporter struct {
+	file *os.File
+}
+
+func newFileExporter(path string) (*fileExporter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileExporter{file: file}, nil
+}
+
+func (e *fileExporter) Export(resource []Attribute, spans []*Span) error {
+	line, err := encodeTraces(resource, spans)
+	if err != nil {
+		return err
+	}
+	_, err = e.file.Write(append(line, '\n'))
+	return err
+}
+
+func (e *fileExporter) Close() error {
+	return e.file.Close()
+}
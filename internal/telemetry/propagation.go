@@ -0,0 +1,90 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// TraceparentHeader is the name of the W3C Trace Context header carrying the span
+// context of the caller.
+const TraceparentHeader = "traceparent"
+
+const (
+	traceparentLen = 55 // version-traceid-spanid-flags, e.g. 00-<32 hex>-<16 hex>-01
+	sampledFlag    = 0x01
+	traceparentV0  = "00"
+	invalidVersion = "ff"
+)
+
+var errInvalidTraceparent = errors.New("invalid traceparent")
+
+// ParseTraceparent decodes the span context in the value of a W3C traceparent
+// header. The returned span context is marked as remote.
+func ParseTraceparent(value string) (SpanContext, error) {
+	// Later versions may append fields, but must keep the layout of version 00.
+	if len(value) < traceparentLen {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	version := value[:2]
+	if version == invalidVersion || (version == traceparentV0 && len(value) != traceparentLen) {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	if len(value) > traceparentLen && value[traceparentLen] != '-' {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	var (
+		sc    = SpanContext{Remote: true}
+		flags [1]byte
+	)
+	if !decodeLowerHex(sc.TraceID[:], value[3:35]) || !decodeLowerHex(sc.SpanID[:], value[36:52]) || !decodeLowerHex(flags[:], value[53:55]) || !decodeLowerHex(nil, version) {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	sc.Sampled = flags[0]&sampledFlag != 0
+	return sc, nil
+}
+
+// Traceparent encodes the span context as the value of a W3C traceparent header.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return traceparentV0 + "-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// decodeLowerHex decodes s into dst, accepting only lowercase hex characters as
+// required by the specification. If dst is nil, s is only validated.
+func decodeLowerHex(dst []byte, s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	if dst == nil {
+		return true
+	}
+	n, err := hex.Decode(dst, []byte(s))
+	return err == nil && n == len(dst)
+}
@@ -0,0 +1,262 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace, i.e. all spans of a distributed operation.
+type TraceID [16]byte
+
+// IsValid reports whether the ID is non-zero.
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// String returns the hex encoding of the ID.
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid reports whether the ID is non-zero.
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// String returns the hex encoding of the ID.
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext is the part of a span which is propagated to its children, within the
+// process and across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool // Whether the trace is recorded
+	Remote  bool // Whether the span was created by another process
+}
+
+// IsValid reports whether both IDs of the span context are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind describes the relationship of a span to its parent and children. The
+// values match the OTLP encoding.
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1 // Operation within the process
+	KindServer   SpanKind = 2 // Handling of a remote request
+	KindClient   SpanKind = 3 // Request to a remote service
+)
+
+// Attribute is a key-value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value interface{} // string, bool, int64, uint64 or float64
+}
+
+// String creates a string attribute.
+func String(key, value string) Attribute { return Attribute{key, value} }
+
+// Bool creates a boolean attribute.
+func Bool(key string, value bool) Attribute { return Attribute{key, value} }
+
+// Int creates an integer attribute.
+func Int(key string, value int) Attribute { return Attribute{key, int64(value)} }
+
+// Int64 creates an integer attribute.
+func Int64(key string, value int64) Attribute { return Attribute{key, value} }
+
+// Uint64 creates an unsigned integer attribute.
+func Uint64(key string, value uint64) Attribute { return Attribute{key, value} }
+
+// Float64 creates a floating point attribute.
+func Float64(key string, value float64) Attribute { return Attribute{key, value} }
+
+// SpanOption configures a span when it is started.
+type SpanOption func(*Span)
+
+// WithKind sets the kind of the span. Spans are KindInternal by default.
+func WithKind(kind SpanKind) SpanOption {
+	return func(s *Span) { s.kind = kind }
+}
+
+// WithStartTime sets the start time of the span, which is the current time by
+// default.
+func WithStartTime(start time.Time) SpanOption {
+	return func(s *Span) { s.start = start }
+}
+
+// WithAttributes adds attributes to the span.
+func WithAttributes(attrs ...Attribute) SpanOption {
+	return func(s *Span) { s.attrs = append(s.attrs, attrs...) }
+}
+
+// Span is a timed operation within a trace. Spans of traces which are not sampled
+// are not recorded, and all methods of such spans, as well as those of a nil span,
+// do nothing.
+type Span struct {
+	tracer *tracer // nil if the span isn't recorded
+	sc     SpanContext
+	parent SpanID
+	name   string
+	kind   SpanKind
+	start  time.Time
+
+	lock   sync.Mutex
+	end    time.Time
+	attrs  []Attribute
+	failed bool
+	status string
+	ended  bool
+}
+
+type spanContextKey struct{}
+
+// Start creates a span as the child of the span in ctx, if any, and returns a copy
+// of ctx which carries the new span. If ctx has no span, a new trace is started.
+// The span must be ended by calling End.
+func Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	t := active.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{name: name, kind: KindInternal}
+	if parent := SpanContextFromContext(ctx); parent.IsValid() {
+		span.sc.TraceID = parent.TraceID
+		span.sc.Sampled = parent.Sampled
+		span.parent = parent.SpanID
+	} else {
+		span.sc.TraceID = t.newTraceID()
+		span.sc.Sampled = t.sample(span.sc.TraceID)
+	}
+	span.sc.SpanID = t.newSpanID()
+
+	// Only spans of sampled traces are recorded. The others are still put into the
+	// context, so their children share the sampling decision.
+	if span.sc.Sampled {
+		span.tracer = t
+		for _, opt := range opts {
+			opt(span)
+		}
+		if span.start.IsZero() {
+			span.start = time.Now()
+		}
+	}
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// SpanFromContext returns the span carried by ctx, or nil if there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the context of the span carried by ctx. The result
+// is invalid if ctx doesn't carry a span.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return SpanFromContext(ctx).Context()
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx which carries the given span
+// context of another process, making it the parent of spans started from ctx.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, spanContextKey{}, &Span{sc: sc})
+}
+
+// Context returns the span context of the span.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// IsRecording reports whether the span is recorded.
+func (s *Span) IsRecording() bool {
+	return s != nil && s.tracer != nil
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if !s.IsRecording() {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks the operation of the span as failed with the given error. Nil
+// errors are ignored.
+func (s *Span) SetError(err error) {
+	if err == nil || !s.IsRecording() {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.failed, s.status = true, err.Error()
+}
+
+// End completes the span and queues it for export. Calls after the first one have
+// no effect.
+func (s *Span) End() {
+	if !s.IsRecording() {
+		return
+	}
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended, s.end = true, time.Now()
+	s.lock.Unlock()
+
+	s.tracer.export(s)
+}
+
+// EndWithError marks the span as failed if err is not nil, and ends it.
+func (s *Span) EndWithError(err error) {
+	s.SetError(err)
+	s.End()
+}
+
+// RecordChild records a completed operation as a child of the span. It is meant
+// for operations that are too frequent and short-lived to be traced by starting a
+// span and passing its context, like database reads.
+func (s *Span) RecordChild(name string, start, end time.Time, attrs ...Attribute) {
+	if !s.IsRecording() {
+		return
+	}
+	child := &Span{
+		tracer: s.tracer,
+		sc:     SpanContext{TraceID: s.sc.TraceID, SpanID: s.tracer.newSpanID(), Sampled: true},
+		parent: s.sc.SpanID,
+		name:   name,
+		kind:   KindInternal,
+		start:  start,
+		end:    end,
+		attrs:  attrs,
+		ended:  true,
+	}
+	s.tracer.export(child)
+}
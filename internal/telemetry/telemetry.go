@@ -0,0 +1,98 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package telemetry records OpenTelemetry compatible trace spans and exports them
+// in the OTLP/JSON format, either to a collector over HTTP or to a local file.
+//
+// Recording is disabled until Setup is called. While disabled, starting a span is
+// cheap and all operations on the returned (nil) span are no-ops.
+package telemetry
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Config contains the settings of span recording and export.
+type Config struct {
+	Endpoint    string  // OTLP/HTTP endpoint of a collector, e.g. http://localhost:4318
+	File        string  // File to append spans to, one OTLP/JSON request per line
+	SampleRatio float64 // Fraction of new traces recorded, remote parents decide for their children
+	ServiceName string  // Reported as the service.name resource attribute
+}
+
+// DefaultConfig is the default tracing configuration.
+var DefaultConfig = Config{
+	SampleRatio: 1,
+	ServiceName: "geth",
+}
+
+// Enabled reports whether the configuration has any span exporter configured.
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || c.File != ""
+}
+
+// active is the tracer spans are recorded by, nil if recording is disabled.
+var active atomic.Pointer[tracer]
+
+// Enabled reports whether span recording is enabled.
+func Enabled() bool {
+	return active.Load() != nil
+}
+
+// Setup starts recording spans and exporting them as configured. The returned
+// function stops recording and flushes all pending spans to the exporters.
+func Setup(cfg Config) (func(), error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("no span exporter configured")
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, errors.New("sample ratio must be between 0 and 1")
+	}
+	var exporters []exporter
+	if cfg.Endpoint != "" {
+		exp, err := newHTTPExporter(cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, exp)
+	}
+	if cfg.File != "" {
+		exp, err := newFileExporter(cfg.File)
+		if err != nil {
+			for _, exp := range exporters {
+				exp.Close()
+			}
+			return nil, err
+		}
+		exporters = append(exporters, exp)
+	}
+	service := cfg.ServiceName
+	if service == "" {
+		service = DefaultConfig.ServiceName
+	}
+	t := newTracer(exporters, cfg.SampleRatio, []Attribute{String("service.name", service)})
+	if !active.CompareAndSwap(nil, t) {
+		t.stop()
+		return nil, errors.New("tracing already enabled")
+	}
+	stop := func() {
+		if active.CompareAndSwap(t, nil) {
+			t.stop()
+		}
+	}
+	return stop, nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTraceparent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(valid)
+	if err != nil {
+		t.Fatalf("failed to parse valid traceparent: %v", err)
+	}
+	if !sc.Sampled || !sc.Remote {
+		t.Errorf("wrong flags: sampled %v, remote %v", sc.Sampled, sc.Remote)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("wrong IDs: %v %v", sc.TraceID, sc.SpanID)
+	}
+	if enc := sc.Traceparent(); enc != valid {
+		t.Errorf("wrong encoding: %s", enc)
+	}
+	// Future versions may append fields.
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Errorf("failed to parse future version: %v", err)
+	}
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",          // missing flags
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", // extra fields in version 00
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",       // invalid version
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",       // uppercase
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",       // zero trace ID
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",       // zero span ID
+		"00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",       // wrong separators
+	} {
+		if _, err := ParseTraceparent(value); err == nil {
+			t.Errorf("no error for invalid traceparent %q", value)
+		}
+	}
+}
+
+func TestSampleRatio(t *testing.T) {
+	tracer := &tracer{ratio: 0.25, rand: rand.New(rand.NewSource(1))}
+
+	var sampled int
+	for i := 0; i < 10000; i++ {
+		if tracer.sample(tracer.newTraceID()) {
+			sampled++
+		}
+	}
+	if sampled < 2200 || sampled > 2800 {
+		t.Errorf("sampled %d of 10000 traces at ratio 0.25", sampled)
+	}
+	tracer.ratio = 0
+	if tracer.sample(tracer.newTraceID()) {
+		t.Error("trace sampled at ratio 0")
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "test")
+	if span != nil || ctx != context.Background() {
+		t.Fatal("span started while tracing is disabled")
+	}
+	// Methods of nil spans must not panic.
+	span.SetAttributes(String("key", "value"))
+	span.RecordChild("child", time.Now(), time.Now())
+	span.EndWithError(errors.New("failed"))
+}
+
+func TestFileExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	stop, err := Setup(Config{File: path, SampleRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithRemoteSpanContext(context.Background(), remote)
+
+	ctx, root := Start(ctx, "root", WithKind(KindServer), WithAttributes(String("method", "eth_call")))
+	_, child := Start(ctx, "child")
+	child.RecordChild("read", time.Now(), time.Now(), Uint64("size", 32))
+	child.EndWithError(errors.New("failed"))
+	root.End()
+
+	// Children of unsampled remote parents are not recorded.
+	remote.Sampled = false
+	_, unsampled := Start(ContextWithRemoteSpanContext(context.Background(), remote), "unsampled")
+	if unsampled.IsRecording() {
+		t.Error("span of unsampled trace is recorded")
+	}
+	unsampled.End()
+	stop()
+
+	spans := readSpanFile(t, path)
+	if len(spans) != 3 {
+		t.Fatalf("wrong number of spans exported: %d", len(spans))
+	}
+	for _, s := range spans {
+		if s.TraceID != remote.TraceID.String() {
+			t.Errorf("span %s has wrong trace ID %s", s.Name, s.TraceID)
+		}
+	}
+	read, c, r := spans["read"], spans["child"], spans["root"]
+	if r.ParentSpanID != remote.SpanID.String() || c.ParentSpanID != r.SpanID || read.ParentSpanID != c.SpanID {
+		t.Errorf("wrong span hierarchy: root %s->%s, child %s->%s, read %s->%s", r.SpanID, r.ParentSpanID, c.SpanID, c.ParentSpanID, read.SpanID, read.ParentSpanID)
+	}
+	if r.Kind != KindServer || len(r.Attributes) != 1 || *r.Attributes[0].Value.StringValue != "eth_call" {
+		t.Errorf("wrong root span: %+v", r)
+	}
+	if c.Status.Code != otlpStatusError || c.Status.Message != "failed" {
+		t.Errorf("wrong child status: %+v", c.Status)
+	}
+	if len(read.Attributes) != 1 || *read.Attributes[0].Value.IntValue != "32" {
+		t.Errorf("wrong read attributes: %+v", read.Attributes)
+	}
+	if Enabled() {
+		t.Error("tracing still enabled after stop")
+	}
+}
+
+func readSpanFile(t *testing.T, path string) map[string]otlpSpan {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	spans := make(map[string]otlpSpan)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var req otlpTraces
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			t.Fatalf("invalid line in span file: %v", err)
+		}
+		for _, rs := range req.ResourceSpans {
+			if len(rs.Resource.Attributes) == 0 || *rs.Resource.Attributes[0].Value.StringValue != "geth" {
+				t.Errorf("wrong resource: %+v", rs.Resource)
+			}
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					spans[s.Name] = s
+				}
+			}
+		}
+	}
+	return spans
+}
+
+func TestHTTPExport(t *testing.T) {
+	var (
+		path, contentType string
+		received          otlpTraces
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("content-type")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	exp, err := newHTTPExporter(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exp.Close()
+
+	span := &Span{name: "test", kind: KindInternal, start: time.Now(), end: time.Now()}
+	span.sc.TraceID[0], span.sc.SpanID[0] = 1, 1
+	if err := exp.Export([]Attribute{String("service.name", "geth")}, []*Span{span}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if path != otlpTracesPath || contentType != "application/json" {
+		t.Errorf("wrong request: path %q, content type %q", path, contentType)
+	}
+	if len(received.ResourceSpans) != 1 || len(received.ResourceSpans[0].ScopeSpans[0].Spans) != 1 {
+		t.Fatalf("wrong request body: %+v", received)
+	}
+	if _, err := newHTTPExporter("localhost:4318"); err == nil {
+		t.Error("no error for endpoint without scheme")
+	}
+}
@@ -18,19 +18,20 @@
 package web3ext
 
 var Modules = map[string]string{
-	"admin":    AdminJs,
-	"clique":   CliqueJs,
-	"ethash":   EthashJs,
-	"debug":    DebugJs,
-	"eth":      EthJs,
-	"miner":    MinerJs,
-	"net":      NetJs,
-	"personal": PersonalJs,
-	"rpc":      RpcJs,
-	"txpool":   TxpoolJs,
-	"les":      LESJs,
-	"vflux":    VfluxJs,
-	"dev":      DevJs,
+	"admin":     AdminJs,
+	"clique":    CliqueJs,
+	"ethash":    EthashJs,
+	"debug":     DebugJs,
+	"eth":       EthJs,
+	"miner":     MinerJs,
+	"net":       NetJs,
+	"personal":  PersonalJs,
+	"rpc":       RpcJs,
+	"sequencer": SequencerJs,
+	"txpool":    TxpoolJs,
+	"les":       LESJs,
+	"vflux":     VfluxJs,
+	"dev":       DevJs,
 }
 
 const CliqueJs = `
@@ -285,6 +286,21 @@ web3._extend({
 			call: 'debug_backtraceAt',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'rotateLog',
+			call: 'debug_rotateLog',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'logRotation',
+			call: 'debug_logRotation',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'setLogRotation',
+			call: 'debug_setLogRotation',
+			params: 4,
+		}),
 		new web3._extend.Method({
 			name: 'stacks',
 			call: 'debug_stacks',
@@ -501,6 +517,16 @@ web3._extend({
 			call: 'debug_getTrieFlushInterval',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'snapSyncStatus',
+			call: 'debug_snapSyncStatus',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'executionWitness',
+			call: 'debug_executionWitness',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -771,6 +797,19 @@ web3._extend({
 });
 `
 
+const SequencerJs = `
+web3._extend({
+	property: 'sequencer',
+	methods: [
+		new web3._extend.Method({
+			name: 'sendRawTransaction',
+			call: 'sequencer_sendRawTransaction',
+			params: 1
+		}),
+	]
+});
+`
+
 const TxpoolJs = `
 web3._extend({
 	property: 'txpool',
@@ -799,6 +838,11 @@ web3._extend({
 			call: 'txpool_contentFrom',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'query',
+			call: 'txpool_query',
+			params: 1,
+		}),
 	]
 });
 `
@@ -903,6 +947,26 @@ web3._extend({
 			call: 'dev_setFeeRecipient',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'reorg',
+			call: 'dev_reorg',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'skipSlots',
+			call: 'dev_skipSlots',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'scheduleWithdrawals',
+			call: 'dev_scheduleWithdrawals',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setFinalityLag',
+			call: 'dev_setFinalityLag',
+			params: 2
+		}),
 	],
 });
 `
@@ -359,9 +359,13 @@ func (h *clientHandler) handleMsg(p *serverPeer) error {
 	// Deliver the received response to retriever.
 	if deliverMsg != nil {
 		if err := h.backend.retriever.deliver(p, deliverMsg); err != nil {
+			p.Report(p2p.ScoreInvalid)
 			if val := p.errCount.Add(1, mclock.Now()); val > maxResponseErrors {
 				return err
 			}
+		} else {
+			p.Report(p2p.ScoreUseful)
+			p.ReportServed(int(msg.Size))
 		}
 	}
 	return nil
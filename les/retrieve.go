@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/p2p"
 )
 
 var (
@@ -338,6 +339,7 @@ func (r *sentReq) tryRequest() {
 		pp, ok := p.(*serverPeer)
 		if hrto && ok {
 			pp.Log().Debug("Request timed out hard")
+			pp.Report(p2p.ScoreTimeout)
 			if r.rm.peers != nil {
 				r.rm.peers.unregister(pp.id)
 			}
@@ -149,10 +149,9 @@ func TestSanitation(t *testing.T) {
 		},
 	} {
 		var (
-			logger = New()
 			out    = new(strings.Builder)
+			logger = NewLogger(NewTerminalHandlerWithLevel(out, LevelInfo, false))
 		)
-		logger.SetHandler(LvlFilterHandler(LvlInfo, StreamHandler(out, TerminalFormat(false))))
 		logger.Info(tt.msg, tt.msg, tt.msg)
 		if have := out.String()[24:]; tt.want != have {
 			t.Fatalf("test %d: want / have: \n%v\n%v", i, tt.want, have)
@@ -17,14 +17,18 @@
 package log
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"math"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+
+	"golang.org/x/exp/slog"
 )
 
 // errVmoduleSyntax is returned when a user vmodule pattern is invalid.
@@ -33,46 +37,51 @@ var errVmoduleSyntax = errors.New("expect comma-separated list of filename=N")
 // errTraceSyntax is returned when a user backtrace pattern is invalid.
 var errTraceSyntax = errors.New("expect file.go:234")
 
+// siteDisabled is cached for callsites not matching any vmodule pattern.
+const siteDisabled slog.Level = math.MaxInt
+
 // GlogHandler is a log handler that mimics the filtering features of Google's
 // glog logger: setting global log levels; overriding with callsite pattern
 // matches; and requesting backtraces at certain positions.
 type GlogHandler struct {
-	origin Handler // The origin handler this wraps
+	origin slog.Handler // The origin handler this wraps
+	filter *glogFilter  // Filter settings, shared with handlers derived from this one
+}
 
-	level     uint32 // Current log level, atomically accessible
-	override  uint32 // Flag whether overrides are used, atomically accessible
-	backtrace uint32 // Flag whether backtrace location is set
+// glogFilter contains the filtering configuration of a GlogHandler. It is shared
+// by all handlers derived via WithAttrs and WithGroup, so that changing the
+// verbosity also applies to loggers created before the change.
+type glogFilter struct {
+	level     atomic.Int64 // Current log level
+	override  atomic.Bool  // Flag whether overrides are used
+	backtrace atomic.Bool  // Flag whether backtrace location is set
 
-	patterns  []pattern       // Current list of patterns to override with
-	siteCache map[uintptr]Lvl // Cache of callsite pattern evaluations
-	location  string          // file:line location where to do a stackdump at
-	lock      sync.RWMutex    // Lock protecting the override pattern list
+	patterns  []pattern              // Current list of patterns to override with
+	siteCache map[uintptr]slog.Level // Cache of callsite pattern evaluations
+	location  string                 // file:line location where to do a stackdump at
+	lock      sync.RWMutex           // Lock protecting the override pattern list
 }
 
 // NewGlogHandler creates a new log handler with filtering functionality similar
-// to Google's glog logger. The returned handler implements Handler.
-func NewGlogHandler(h Handler) *GlogHandler {
+// to Google's glog logger. The returned handler implements slog.Handler.
+func NewGlogHandler(h slog.Handler) *GlogHandler {
 	return &GlogHandler{
 		origin: h,
+		filter: new(glogFilter),
 	}
 }
 
-// SetHandler updates the handler to write records to the specified sub-handler.
-func (h *GlogHandler) SetHandler(nh Handler) {
-	h.origin = nh
-}
-
 // pattern contains a filter for the Vmodule option, holding a verbosity level
 // and a file pattern to match.
 type pattern struct {
 	pattern *regexp.Regexp
-	level   Lvl
+	level   slog.Level
 }
 
 // Verbosity sets the glog verbosity ceiling. The verbosity of individual packages
 // and source files can be raised using Vmodule.
-func (h *GlogHandler) Verbosity(level Lvl) {
-	atomic.StoreUint32(&h.level, uint32(level))
+func (h *GlogHandler) Verbosity(level slog.Level) {
+	h.filter.level.Store(int64(level))
 }
 
 // Vmodule sets the glog verbosity pattern.
@@ -130,15 +139,15 @@ func (h *GlogHandler) Vmodule(ruleset string) error {
 		matcher = matcher + "$"
 
 		re, _ := regexp.Compile(matcher)
-		filter = append(filter, pattern{re, Lvl(level)})
+		filter = append(filter, pattern{re, FromLegacyLevel(level)})
 	}
 	// Swap out the vmodule pattern for the new filter system
-	h.lock.Lock()
-	defer h.lock.Unlock()
+	h.filter.lock.Lock()
+	defer h.filter.lock.Unlock()
 
-	h.patterns = filter
-	h.siteCache = make(map[uintptr]Lvl)
-	atomic.StoreUint32(&h.override, uint32(len(filter)))
+	h.filter.patterns = filter
+	h.filter.siteCache = make(map[uintptr]slog.Level)
+	h.filter.override.Store(len(filter) != 0)
 
 	return nil
 }
@@ -167,66 +176,102 @@ func (h *GlogHandler) BacktraceAt(location string) error {
 		return errTraceSyntax
 	}
 	// All seems valid
-	h.lock.Lock()
-	defer h.lock.Unlock()
+	h.filter.lock.Lock()
+	defer h.filter.lock.Unlock()
 
-	h.location = location
-	atomic.StoreUint32(&h.backtrace, uint32(len(location)))
+	h.filter.location = location
+	h.filter.backtrace.Store(len(location) > 0)
 
 	return nil
 }
 
-// Log implements Handler.Log, filtering a log record through the global, local
-// and backtrace filters, finally emitting it if either allow it through.
-func (h *GlogHandler) Log(r *Record) error {
+// Enabled implements slog.Handler, reporting whether the handler handles records
+// at the given level. Records below the global level are let through if vmodule
+// overrides or a backtrace location are configured, as those can only be checked
+// once the callsite is known.
+func (h *GlogHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	if !h.filter.override.Load() && !h.filter.backtrace.Load() && lvl < slog.Level(h.filter.level.Load()) {
+		return false
+	}
+	return h.origin.Enabled(ctx, lvl)
+}
+
+// WithAttrs implements slog.Handler, returning a handler with the given attributes
+// which shares the filter settings of this one.
+func (h *GlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &GlogHandler{
+		origin: h.origin.WithAttrs(attrs),
+		filter: h.filter,
+	}
+}
+
+// WithGroup implements slog.Handler, returning a handler with the given group
+// which shares the filter settings of this one.
+func (h *GlogHandler) WithGroup(name string) slog.Handler {
+	return &GlogHandler{
+		origin: h.origin.WithGroup(name),
+		filter: h.filter,
+	}
+}
+
+// Handle implements slog.Handler, filtering a log record through the global,
+// local and backtrace filters, finally emitting it if either allow it through.
+func (h *GlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	f := h.filter
+
 	// If backtracing is requested, check whether this is the callsite
-	if atomic.LoadUint32(&h.backtrace) > 0 {
+	if f.backtrace.Load() {
 		// Everything below here is slow. Although we could cache the call sites the
 		// same way as for vmodule, backtracing is so rare it's not worth the extra
 		// complexity.
-		h.lock.RLock()
-		match := h.location == r.Call.String()
-		h.lock.RUnlock()
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		location := filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+
+		f.lock.RLock()
+		match := f.location == location
+		f.lock.RUnlock()
 
 		if match {
 			// Callsite matched, raise the log level to info and gather the stacks
-			r.Lvl = LvlInfo
+			r.Level = slog.LevelInfo
 
 			buf := make([]byte, 1024*1024)
 			buf = buf[:runtime.Stack(buf, true)]
-			r.Msg += "\n\n" + string(buf)
+			r.Message += "\n\n" + string(buf)
 		}
 	}
 	// If the global log level allows, fast track logging
-	if atomic.LoadUint32(&h.level) >= uint32(r.Lvl) {
-		return h.origin.Log(r)
+	if slog.Level(f.level.Load()) <= r.Level {
+		return h.origin.Handle(ctx, r)
 	}
 	// If no local overrides are present, fast track skipping
-	if atomic.LoadUint32(&h.override) == 0 {
+	if !f.override.Load() {
 		return nil
 	}
 	// Check callsite cache for previously calculated log levels
-	h.lock.RLock()
-	lvl, ok := h.siteCache[r.Call.Frame().PC]
-	h.lock.RUnlock()
+	f.lock.RLock()
+	lvl, ok := f.siteCache[r.PC]
+	f.lock.RUnlock()
 
 	// If we didn't cache the callsite yet, calculate it
 	if !ok {
-		h.lock.Lock()
-		for _, rule := range h.patterns {
-			if rule.pattern.MatchString(fmt.Sprintf("%+s", r.Call)) {
-				h.siteCache[r.Call.Frame().PC], lvl, ok = rule.level, rule.level, true
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+
+		f.lock.Lock()
+		for _, rule := range f.patterns {
+			if rule.pattern.MatchString(frame.File) {
+				f.siteCache[r.PC], lvl, ok = rule.level, rule.level, true
 				break
 			}
 		}
 		// If no rule matched, remember to drop log the next time
 		if !ok {
-			h.siteCache[r.Call.Frame().PC] = 0
+			f.siteCache[r.PC], lvl = siteDisabled, siteDisabled
 		}
-		h.lock.Unlock()
+		f.lock.Unlock()
 	}
-	if lvl >= r.Lvl {
-		return h.origin.Log(r)
+	if lvl <= r.Level {
+		return h.origin.Handle(ctx, r)
 	}
 	return nil
 }
@@ -0,0 +1,197 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build go1.21
+
+package log
+
+import (
+	"context"
+	stdslog "log/slog"
+
+	"golang.org/x/exp/slog"
+)
+
+// FromStdHandler wraps a handler of the standard library's log/slog package, so
+// that it can be used as the handler of a Logger. This allows applications
+// embedding geth to route its logs into their own logging setup:
+//
+//	log.SetDefault(log.NewLogger(log.FromStdHandler(handler)))
+func FromStdHandler(h stdslog.Handler) slog.Handler {
+	if w, ok := h.(*stdHandler); ok {
+		return w.inner
+	}
+	return &expHandler{h}
+}
+
+// ToStdHandler wraps a handler of this package, e.g. a GlogHandler or terminal
+// handler, so that it can be used with the standard library's log/slog package:
+//
+//	slog.SetDefault(slog.New(log.ToStdHandler(log.Root().Handler())))
+func ToStdHandler(h slog.Handler) stdslog.Handler {
+	if w, ok := h.(*expHandler); ok {
+		return w.inner
+	}
+	return &stdHandler{h}
+}
+
+// expHandler adapts a log/slog handler to the slog.Handler interface.
+type expHandler struct {
+	inner stdslog.Handler
+}
+
+func (h *expHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, stdslog.Level(level))
+}
+
+func (h *expHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := stdslog.NewRecord(r.Time, stdslog.Level(r.Level), r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(attrToStd(a))
+		return true
+	})
+	return h.inner.Handle(ctx, nr)
+}
+
+func (h *expHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	converted := make([]stdslog.Attr, len(attrs))
+	for i, a := range attrs {
+		converted[i] = attrToStd(a)
+	}
+	return &expHandler{h.inner.WithAttrs(converted)}
+}
+
+func (h *expHandler) WithGroup(name string) slog.Handler {
+	return &expHandler{h.inner.WithGroup(name)}
+}
+
+// stdHandler adapts a slog.Handler to the log/slog handler interface.
+type stdHandler struct {
+	inner slog.Handler
+}
+
+func (h *stdHandler) Enabled(ctx context.Context, level stdslog.Level) bool {
+	return h.inner.Enabled(ctx, slog.Level(level))
+}
+
+func (h *stdHandler) Handle(ctx context.Context, r stdslog.Record) error {
+	nr := slog.NewRecord(r.Time, slog.Level(r.Level), r.Message, r.PC)
+	r.Attrs(func(a stdslog.Attr) bool {
+		nr.AddAttrs(attrFromStd(a))
+		return true
+	})
+	return h.inner.Handle(ctx, nr)
+}
+
+func (h *stdHandler) WithAttrs(attrs []stdslog.Attr) stdslog.Handler {
+	converted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		converted[i] = attrFromStd(a)
+	}
+	return &stdHandler{h.inner.WithAttrs(converted)}
+}
+
+func (h *stdHandler) WithGroup(name string) stdslog.Handler {
+	return &stdHandler{h.inner.WithGroup(name)}
+}
+
+func attrToStd(a slog.Attr) stdslog.Attr {
+	return stdslog.Attr{Key: a.Key, Value: valueToStd(a.Value)}
+}
+
+func valueToStd(v slog.Value) stdslog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return stdslog.StringValue(v.String())
+	case slog.KindInt64:
+		return stdslog.Int64Value(v.Int64())
+	case slog.KindUint64:
+		return stdslog.Uint64Value(v.Uint64())
+	case slog.KindFloat64:
+		return stdslog.Float64Value(v.Float64())
+	case slog.KindBool:
+		return stdslog.BoolValue(v.Bool())
+	case slog.KindDuration:
+		return stdslog.DurationValue(v.Duration())
+	case slog.KindTime:
+		return stdslog.TimeValue(v.Time())
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]stdslog.Attr, len(group))
+		for i, a := range group {
+			attrs[i] = attrToStd(a)
+		}
+		return stdslog.GroupValue(attrs...)
+	case slog.KindLogValuer:
+		// Keep the value lazy, it is resolved by the wrapped handler if needed
+		return stdslog.AnyValue(stdValuer{v.LogValuer()})
+	default:
+		return stdslog.AnyValue(v.Any())
+	}
+}
+
+func attrFromStd(a stdslog.Attr) slog.Attr {
+	return slog.Attr{Key: a.Key, Value: valueFromStd(a.Value)}
+}
+
+func valueFromStd(v stdslog.Value) slog.Value {
+	switch v.Kind() {
+	case stdslog.KindString:
+		return slog.StringValue(v.String())
+	case stdslog.KindInt64:
+		return slog.Int64Value(v.Int64())
+	case stdslog.KindUint64:
+		return slog.Uint64Value(v.Uint64())
+	case stdslog.KindFloat64:
+		return slog.Float64Value(v.Float64())
+	case stdslog.KindBool:
+		return slog.BoolValue(v.Bool())
+	case stdslog.KindDuration:
+		return slog.DurationValue(v.Duration())
+	case stdslog.KindTime:
+		return slog.TimeValue(v.Time())
+	case stdslog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, a := range group {
+			attrs[i] = attrFromStd(a)
+		}
+		return slog.GroupValue(attrs...)
+	case stdslog.KindLogValuer:
+		// Keep the value lazy, it is resolved by the wrapped handler if needed
+		return slog.AnyValue(expValuer{v.LogValuer()})
+	default:
+		return slog.AnyValue(v.Any())
+	}
+}
+
+// stdValuer exposes a slog.LogValuer as a log/slog LogValuer.
+type stdValuer struct {
+	inner slog.LogValuer
+}
+
+func (v stdValuer) LogValue() stdslog.Value {
+	return valueToStd(v.inner.LogValue())
+}
+
+// expValuer exposes a log/slog LogValuer as a slog.LogValuer.
+type expValuer struct {
+	inner stdslog.LogValuer
+}
+
+func (v expValuer) LogValue() slog.Value {
+	return valueFromStd(v.inner.LogValue())
+}
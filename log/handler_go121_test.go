@@ -0,0 +1,59 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build go1.21
+
+package log
+
+import (
+	"bytes"
+	stdslog "log/slog"
+	"strings"
+	"testing"
+)
+
+// Tests that records logged through the standard library's log/slog package
+// are emitted by a handler of this package.
+func TestToStdHandler(t *testing.T) {
+	out := new(bytes.Buffer)
+	logger := stdslog.New(ToStdHandler(LogfmtHandler(out))).With("module", "std")
+	logger.Warn("from stdlib", "count", 1000000, stdslog.Group("g", "k", "v"))
+
+	want := ` lvl=warn msg="from stdlib" module=std count=1,000,000 g.k=v` + "\n"
+	if have := out.String(); !strings.HasSuffix(have, want) {
+		t.Fatalf("wrong output: have %q, want suffix %q", have, want)
+	}
+}
+
+// Tests that a handler of the standard library's log/slog package can be used
+// as the handler of a Logger.
+func TestFromStdHandler(t *testing.T) {
+	out := new(bytes.Buffer)
+	std := stdslog.NewTextHandler(out, &stdslog.HandlerOptions{Level: stdslog.LevelDebug})
+	logger := NewLogger(FromStdHandler(std)).With("module", "geth")
+
+	logger.Trace("filtered")
+	logger.Info("to stdlib", "lazy", Lazy{func() string { return "value" }})
+
+	want := `level=INFO msg="to stdlib" module=geth lazy=value` + "\n"
+	if have := out.String(); !strings.HasSuffix(have, want) {
+		t.Fatalf("wrong output: have %q, want suffix %q", have, want)
+	}
+	// Wrapping a wrapped handler must return the original one.
+	if h := LogfmtHandler(out); FromStdHandler(ToStdHandler(h)) != h || ToStdHandler(FromStdHandler(std)) != std {
+		t.Fatal("handler not unwrapped")
+	}
+}
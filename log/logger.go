@@ -1,120 +1,140 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"reflect"
+	"runtime"
 	"time"
 
-	"github.com/go-stack/stack"
+	"golang.org/x/exp/slog"
 )
 
-const timeKey = "t"
-const lvlKey = "lvl"
-const msgKey = "msg"
-const ctxKey = "ctx"
 const errorKey = "LOG15_ERROR"
-const skipLevel = 2
 
-type Lvl int
+const (
+	legacyLevelCrit = iota
+	legacyLevelError
+	legacyLevelWarn
+	legacyLevelInfo
+	legacyLevelDebug
+	legacyLevelTrace
+)
 
 const (
-	LvlCrit Lvl = iota
-	LvlError
-	LvlWarn
-	LvlInfo
-	LvlDebug
-	LvlTrace
+	LevelTrace slog.Level = -8
+	LevelDebug            = slog.LevelDebug
+	LevelInfo             = slog.LevelInfo
+	LevelWarn             = slog.LevelWarn
+	LevelError            = slog.LevelError
+	LevelCrit  slog.Level = 12
+
+	// for backward-compatibility
+	LvlTrace = LevelTrace
+	LvlInfo  = LevelInfo
+	LvlDebug = LevelDebug
+	LvlWarn  = LevelWarn
+	LvlError = LevelError
+	LvlCrit  = LevelCrit
 )
 
-// AlignedString returns a 5-character string containing the name of a Lvl.
-func (l Lvl) AlignedString() string {
+// FromLegacyLevel converts from old Geth verbosity level constants
+// to levels defined by slog. Values outside of the legacy range are
+// clamped to the nearest valid level.
+func FromLegacyLevel(lvl int) slog.Level {
+	switch lvl {
+	case legacyLevelCrit:
+		return LevelCrit
+	case legacyLevelError:
+		return slog.LevelError
+	case legacyLevelWarn:
+		return slog.LevelWarn
+	case legacyLevelInfo:
+		return slog.LevelInfo
+	case legacyLevelDebug:
+		return slog.LevelDebug
+	case legacyLevelTrace:
+		return LevelTrace
+	}
+	if lvl > legacyLevelTrace {
+		return LevelTrace
+	}
+	return LevelCrit
+}
+
+// LevelAlignedString returns a 5-character string containing the name of a Lvl.
+func LevelAlignedString(l slog.Level) string {
 	switch l {
-	case LvlTrace:
+	case LevelTrace:
 		return "TRACE"
-	case LvlDebug:
+	case slog.LevelDebug:
 		return "DEBUG"
-	case LvlInfo:
+	case slog.LevelInfo:
 		return "INFO "
-	case LvlWarn:
+	case slog.LevelWarn:
 		return "WARN "
-	case LvlError:
+	case slog.LevelError:
 		return "ERROR"
-	case LvlCrit:
+	case LevelCrit:
 		return "CRIT "
 	default:
-		panic("bad level")
+		return l.String()
 	}
 }
 
-// String returns the name of a Lvl.
-func (l Lvl) String() string {
+// LevelString returns the name of a Lvl.
+func LevelString(l slog.Level) string {
 	switch l {
-	case LvlTrace:
+	case LevelTrace:
 		return "trce"
-	case LvlDebug:
+	case slog.LevelDebug:
 		return "dbug"
-	case LvlInfo:
+	case slog.LevelInfo:
 		return "info"
-	case LvlWarn:
+	case slog.LevelWarn:
 		return "warn"
-	case LvlError:
+	case slog.LevelError:
 		return "eror"
-	case LvlCrit:
+	case LevelCrit:
 		return "crit"
 	default:
-		panic("bad level")
+		return l.String()
 	}
 }
 
-// LvlFromString returns the appropriate Lvl from a string name.
+// LvlFromString returns the appropriate level from a string name.
 // Useful for parsing command line args and configuration files.
-func LvlFromString(lvlString string) (Lvl, error) {
+func LvlFromString(lvlString string) (slog.Level, error) {
 	switch lvlString {
 	case "trace", "trce":
-		return LvlTrace, nil
+		return LevelTrace, nil
 	case "debug", "dbug":
-		return LvlDebug, nil
+		return LevelDebug, nil
 	case "info":
-		return LvlInfo, nil
+		return LevelInfo, nil
 	case "warn":
-		return LvlWarn, nil
+		return LevelWarn, nil
 	case "error", "eror":
-		return LvlError, nil
+		return LevelError, nil
 	case "crit":
-		return LvlCrit, nil
+		return LevelCrit, nil
 	default:
-		return LvlDebug, fmt.Errorf("unknown level: %v", lvlString)
+		return LevelDebug, fmt.Errorf("unknown level: %v", lvlString)
 	}
 }
 
-// A Record is what a Logger asks its handler to write
-type Record struct {
-	Time     time.Time
-	Lvl      Lvl
-	Msg      string
-	Ctx      []interface{}
-	Call     stack.Call
-	KeyNames RecordKeyNames
-}
-
-// RecordKeyNames gets stored in a Record when the write function is executed.
-type RecordKeyNames struct {
-	Time string
-	Msg  string
-	Lvl  string
-	Ctx  string
-}
-
 // A Logger writes key/value pairs to a Handler
 type Logger interface {
-	// New returns a new Logger that has this logger's context plus the given context
-	New(ctx ...interface{}) Logger
+	// With returns a new Logger that has this logger's attributes plus the given attributes
+	With(ctx ...interface{}) Logger
 
-	// GetHandler gets the handler associated with the logger.
-	GetHandler() Handler
+	// New returns a new Logger that has this logger's attributes plus the given attributes.
+	// Identical to 'With'.
+	New(ctx ...interface{}) Logger
 
-	// SetHandler updates the logger to write records to the specified handler.
-	SetHandler(h Handler)
+	// Log logs a message at the specified level with context key/value pairs
+	Log(level slog.Level, msg string, ctx ...interface{})
 
 	// Log a message at the trace level with context key/value pairs
 	//
@@ -169,123 +189,151 @@ type Logger interface {
 	//	log.Crit("msg", "key1", val1)
 	//	log.Crit("msg", "key1", val1, "key2", val2)
 	Crit(msg string, ctx ...interface{})
+
+	// Write logs a message at the specified level. The call site recorded for
+	// the message is the caller of the function invoking Write, which allows
+	// logger wrappers to report the location of their own callers.
+	Write(level slog.Level, msg string, attrs ...any)
+
+	// Enabled reports whether l emits log records at the given context and level.
+	Enabled(ctx context.Context, level slog.Level) bool
+
+	// Handler returns the underlying handler of the inner logger.
+	Handler() slog.Handler
 }
 
 type logger struct {
-	ctx []interface{}
-	h   *swapHandler
+	inner *slog.Logger
+}
+
+// NewLogger returns a logger with the specified handler set.
+func NewLogger(h slog.Handler) Logger {
+	return &logger{
+		slog.New(h),
+	}
 }
 
-func (l *logger) write(msg string, lvl Lvl, ctx []interface{}, skip int) {
-	l.h.Log(&Record{
-		Time: time.Now(),
-		Lvl:  lvl,
-		Msg:  msg,
-		Ctx:  newContext(l.ctx, ctx),
-		Call: stack.Caller(skip),
-		KeyNames: RecordKeyNames{
-			Time: timeKey,
-			Msg:  msgKey,
-			Lvl:  lvlKey,
-			Ctx:  ctxKey,
-		},
-	})
+func (l *logger) Handler() slog.Handler {
+	return l.inner.Handler()
+}
+
+// Write logs a message at the specified level.
+func (l *logger) Write(level slog.Level, msg string, attrs ...any) {
+	if !l.inner.Enabled(context.Background(), level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(normalize(attrs)...)
+	l.inner.Handler().Handle(context.Background(), r)
+}
+
+func (l *logger) Log(level slog.Level, msg string, ctx ...interface{}) {
+	l.Write(level, msg, ctx...)
+}
+
+func (l *logger) With(ctx ...interface{}) Logger {
+	return &logger{l.inner.With(normalize(ctx)...)}
 }
 
 func (l *logger) New(ctx ...interface{}) Logger {
-	child := &logger{newContext(l.ctx, ctx), new(swapHandler)}
-	child.SetHandler(l.h)
-	return child
+	return l.With(ctx...)
 }
 
-func newContext(prefix []interface{}, suffix []interface{}) []interface{} {
-	normalizedSuffix := normalize(suffix)
-	newCtx := make([]interface{}, len(prefix)+len(normalizedSuffix))
-	n := copy(newCtx, prefix)
-	copy(newCtx[n:], normalizedSuffix)
-	return newCtx
+// Enabled reports whether l emits log records at the given context and level.
+func (l *logger) Enabled(ctx context.Context, level slog.Level) bool {
+	return l.inner.Enabled(ctx, level)
 }
 
 func (l *logger) Trace(msg string, ctx ...interface{}) {
-	l.write(msg, LvlTrace, ctx, skipLevel)
+	l.Write(LevelTrace, msg, ctx...)
 }
 
 func (l *logger) Debug(msg string, ctx ...interface{}) {
-	l.write(msg, LvlDebug, ctx, skipLevel)
+	l.Write(slog.LevelDebug, msg, ctx...)
 }
 
 func (l *logger) Info(msg string, ctx ...interface{}) {
-	l.write(msg, LvlInfo, ctx, skipLevel)
+	l.Write(slog.LevelInfo, msg, ctx...)
 }
 
 func (l *logger) Warn(msg string, ctx ...interface{}) {
-	l.write(msg, LvlWarn, ctx, skipLevel)
+	l.Write(slog.LevelWarn, msg, ctx...)
 }
 
 func (l *logger) Error(msg string, ctx ...interface{}) {
-	l.write(msg, LvlError, ctx, skipLevel)
+	l.Write(slog.LevelError, msg, ctx...)
 }
 
 func (l *logger) Crit(msg string, ctx ...interface{}) {
-	l.write(msg, LvlCrit, ctx, skipLevel)
+	l.Write(LevelCrit, msg, ctx...)
 	os.Exit(1)
 }
 
-func (l *logger) GetHandler() Handler {
-	return l.h.Get()
-}
-
-func (l *logger) SetHandler(h Handler) {
-	l.h.Swap(h)
-}
-
 func normalize(ctx []interface{}) []interface{} {
-	// if the caller passed a Ctx object, then expand it
-	if len(ctx) == 1 {
-		if ctxMap, ok := ctx[0].(Ctx); ok {
-			ctx = ctxMap.toArray()
-		}
-	}
-
 	// ctx needs to be even because it's a series of key/value pairs
 	// no one wants to check for errors on logging functions,
 	// so instead of erroring on bad input, we'll just make sure
 	// that things are the right length and users can fix bugs
 	// when they see the output looks wrong
-	if len(ctx)%2 != 0 {
-		ctx = append(ctx, nil, errorKey, "Normalized odd number of arguments by adding nil")
+	for i := 0; i < len(ctx); i += 2 {
+		// Attributes may be passed directly, they don't need a key
+		if _, ok := ctx[i].(slog.Attr); ok {
+			i--
+			continue
+		}
+		if i == len(ctx)-1 {
+			ctx = append(ctx, nil, errorKey, "Normalized odd number of arguments by adding nil")
+			break
+		}
 	}
-
 	return ctx
 }
 
 // Lazy allows you to defer calculation of a logged value that is expensive
 // to compute until it is certain that it must be evaluated with the given filters.
 //
-// Lazy may also be used in conjunction with a Logger's New() function
-// to generate a child logger which always reports the current value of changing
-// state.
-//
 // You may wrap any function which takes no arguments to Lazy. It may return any
 // number of values of any type.
 type Lazy struct {
 	Fn interface{}
 }
 
-// Ctx is a map of key/value pairs to pass as context to a log function
-// Use this only if you really need greater safety around the arguments you pass
-// to the logging functions.
-type Ctx map[string]interface{}
+// LogValue implements slog.LogValuer, evaluating the wrapped function when
+// a handler resolves the value for output.
+func (l Lazy) LogValue() slog.Value {
+	v, err := evaluateLazy(l)
+	if err != nil {
+		return slog.AnyValue(err)
+	}
+	return slog.AnyValue(v)
+}
 
-func (c Ctx) toArray() []interface{} {
-	arr := make([]interface{}, len(c)*2)
+func evaluateLazy(lz Lazy) (interface{}, error) {
+	t := reflect.TypeOf(lz.Fn)
 
-	i := 0
-	for k, v := range c {
-		arr[i] = k
-		arr[i+1] = v
-		i += 2
+	if t == nil || t.Kind() != reflect.Func {
+		return nil, fmt.Errorf("INVALID_LAZY, not func: %+v", lz.Fn)
 	}
 
-	return arr
+	if t.NumIn() > 0 {
+		return nil, fmt.Errorf("INVALID_LAZY, func takes args: %+v", lz.Fn)
+	}
+
+	if t.NumOut() == 0 {
+		return nil, fmt.Errorf("INVALID_LAZY, no func return val: %+v", lz.Fn)
+	}
+
+	value := reflect.ValueOf(lz.Fn)
+	results := value.Call([]reflect.Value{})
+	if len(results) == 1 {
+		return results[0].Interface(), nil
+	}
+	values := make([]interface{}, len(results))
+	for i, v := range results {
+		values[i] = v.Interface()
+	}
+	return values, nil
 }
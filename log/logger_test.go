@@ -0,0 +1,168 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+func TestTerminalHandlerWithAttrs(t *testing.T) {
+	out := new(bytes.Buffer)
+	logger := NewLogger(NewTerminalHandler(out, false)).With("baz", "bat")
+	logger.Trace("a message", "foo", "bar")
+
+	have := out.String()
+	want := "TRACE[" // timestamp follows
+	if !strings.HasPrefix(have, want) {
+		t.Fatalf("wrong prefix: have %q, want %q", have, want)
+	}
+	if want := "] a message                                baz=bat foo=bar\n"; !strings.HasSuffix(have, want) {
+		t.Fatalf("wrong output: have %q, want suffix %q", have, want)
+	}
+}
+
+func TestTerminalHandlerGroups(t *testing.T) {
+	out := new(bytes.Buffer)
+	logger := NewLogger(NewTerminalHandler(out, false).WithGroup("peer").WithAttrs([]slog.Attr{slog.String("id", "abc")}))
+	logger.Info("message", slog.Group("stats", slog.Int("count", 3)))
+
+	if want := " peer.id=abc peer.stats.count=3\n"; !strings.HasSuffix(out.String(), want) {
+		t.Fatalf("wrong output: have %q, want suffix %q", out.String(), want)
+	}
+}
+
+func TestLogfmtHandler(t *testing.T) {
+	out := new(bytes.Buffer)
+	logger := NewLogger(LogfmtHandlerWithLevel(out, LevelInfo))
+	logger.Debug("filtered")
+	logger.Info("hello world", "number", 1000000, "big", big.NewInt(-123456789), "str", "a b")
+
+	have := out.String()
+	if !strings.HasPrefix(have, "t=") {
+		t.Fatalf("missing timestamp: %q", have)
+	}
+	want := ` lvl=info msg="hello world" number=1,000,000 big=-123,456,789 str="a b"` + "\n"
+	if !strings.HasSuffix(have, want) {
+		t.Fatalf("wrong output: have %q, want suffix %q", have, want)
+	}
+}
+
+func TestJSONHandler(t *testing.T) {
+	out := new(bytes.Buffer)
+	logger := NewLogger(JSONHandler(out))
+	logger.Warn("some message", "num", 5, "big", big.NewInt(100), "dur", time.Second, "err", errTraceSyntax)
+
+	var have map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &have); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out.String(), err)
+	}
+	want := map[string]interface{}{
+		"lvl": "warn",
+		"msg": "some message",
+		"num": float64(5),
+		"big": "100",
+		"dur": "1s",
+		"err": errTraceSyntax.Error(),
+	}
+	for key, val := range want {
+		if have[key] != val {
+			t.Errorf("key %q: have %v, want %v", key, have[key], val)
+		}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, have["t"].(string)); err != nil {
+		t.Errorf("invalid timestamp %v: %v", have["t"], err)
+	}
+}
+
+func TestLazyEvaluatedAtLogTime(t *testing.T) {
+	out := new(bytes.Buffer)
+	counter := 0
+	logger := NewLogger(LogfmtHandler(out)).With("counter", Lazy{func() int { return counter }})
+
+	counter = 42
+	logger.Info("lazy")
+	if want := " counter=42\n"; !strings.HasSuffix(out.String(), want) {
+		t.Fatalf("wrong output: have %q, want suffix %q", out.String(), want)
+	}
+}
+
+func TestGlogHandler(t *testing.T) {
+	out := new(bytes.Buffer)
+	glog := NewGlogHandler(LogfmtHandler(out))
+	glog.Verbosity(LevelWarn)
+
+	// Loggers derived before a verbosity change must observe it.
+	logger := NewLogger(glog).With("sub", "system")
+
+	logger.Info("dropped")
+	if out.Len() != 0 {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+	glog.Verbosity(LevelInfo)
+	logger.Info("emitted")
+	if have := out.String(); !strings.Contains(have, "msg=emitted") || !strings.HasSuffix(have, " sub=system\n") {
+		t.Fatalf("missing output after verbosity change: %q", out.String())
+	}
+	out.Reset()
+
+	// Vmodule raises the verbosity of matching files only.
+	glog.Verbosity(LevelWarn)
+	if err := glog.Vmodule("logger_test.go=5"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Trace("vmodule")
+	if !strings.Contains(out.String(), "lvl=trce msg=vmodule") {
+		t.Fatalf("missing vmodule output: %q", out.String())
+	}
+	out.Reset()
+
+	if err := glog.Vmodule("other.go=5"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Trace("vmodule")
+	if out.Len() != 0 {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestFromLegacyLevel(t *testing.T) {
+	tests := []struct {
+		legacy int
+		level  slog.Level
+	}{
+		{-1, LevelCrit},
+		{0, LevelCrit},
+		{1, LevelError},
+		{2, LevelWarn},
+		{3, LevelInfo},
+		{4, LevelDebug},
+		{5, LevelTrace},
+		{6, LevelTrace},
+	}
+	for _, tt := range tests {
+		if have := FromLegacyLevel(tt.legacy); have != tt.level {
+			t.Errorf("level %d: have %v, want %v", tt.legacy, have, tt.level)
+		}
+	}
+}
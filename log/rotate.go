@@ -0,0 +1,393 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// megabyte is the unit of RotationConfig.MaxSize. It is a variable so that
+// tests can lower it.
+var megabyte int64 = 1024 * 1024
+
+var errWriterClosed = errors.New("log file is closed")
+
+// RotationConfig contains the rotation and retention settings of a log file.
+type RotationConfig struct {
+	MaxSize    int  `json:"maxSize"`    // Maximum size in megabytes before the file is rotated, 0 disables rotation
+	MaxBackups int  `json:"maxBackups"` // Maximum number of rotated files to retain, 0 retains all of them
+	MaxAge     int  `json:"maxAge"`     // Maximum number of days to retain rotated files, 0 retains them regardless of age
+	Compress   bool `json:"compress"`   // Whether rotated files are compressed using gzip
+}
+
+// RotatingFileWriter is an io.Writer appending to a log file, which is moved
+// aside once it grows beyond the configured size. Rotated files are named after
+// the time of rotation, e.g. geth-2006-01-02T15-04-05.000.log, and are compressed
+// and pruned in the background according to the retention settings.
+//
+// The writer can be used as the output of any handler, and its settings may be
+// changed while it is in use.
+type RotatingFileWriter struct {
+	path string
+
+	mu      sync.Mutex
+	cfg     RotationConfig
+	file    *os.File // nil if closing or reopening the file during a rotation failed
+	size    int64
+	openErr error // error of the last failed close or reopen during rotation, if any
+	closed  bool
+
+	millCh chan struct{}
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewRotatingFileWriter opens the log file at path for appending, creating it
+// and its parent directories if necessary.
+func NewRotatingFileWriter(path string, cfg RotationConfig) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		path:   path,
+		cfg:    cfg,
+		millCh: make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	w.wg.Add(1)
+	go w.millLoop()
+
+	// Apply the retention settings to files left over by previous runs.
+	w.triggerMill()
+	return w, nil
+}
+
+// Path returns the location of the current log file.
+func (w *RotatingFileWriter) Path() string {
+	return w.path
+}
+
+// Config returns the current rotation settings.
+func (w *RotatingFileWriter) Config() RotationConfig {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.cfg
+}
+
+// SetConfig changes the rotation settings. The new retention settings are
+// applied to the existing rotated files right away.
+func (w *RotatingFileWriter) SetConfig(cfg RotationConfig) {
+	w.mu.Lock()
+	w.cfg = cfg
+	w.mu.Unlock()
+
+	w.triggerMill()
+}
+
+// Write implements io.Writer, rotating the log file first if the write would
+// make it exceed the maximum size.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.ensureOpen(); err != nil {
+		return 0, err
+	}
+	if limit := int64(w.cfg.MaxSize) * megabyte; limit > 0 && w.size > 0 && w.size+int64(len(p)) > limit {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate moves the current log file aside and starts a new one, regardless of
+// its size.
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.ensureOpen(); err != nil {
+		return err
+	}
+	return w.rotate()
+}
+
+// Close closes the log file and waits for background compression to finish. If
+// the file could not be closed or reopened during a rotation, that error is
+// returned.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.openErr
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+
+	close(w.quit)
+	w.wg.Wait()
+	return err
+}
+
+// open opens the log file for appending. The caller must hold the lock or
+// have exclusive access to w.
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+// ensureOpen checks that the writer is not closed and retries opening the log
+// file if reopening it after a rotation failed. The caller must hold the lock.
+func (w *RotatingFileWriter) ensureOpen() error {
+	if w.closed {
+		return errWriterClosed
+	}
+	if w.file != nil {
+		return nil
+	}
+	if err := w.open(); err != nil {
+		w.openErr = err
+		return err
+	}
+	w.openErr = nil
+	return nil
+}
+
+// rotate renames the current log file to a timestamped backup and opens a new
+// one. The caller must hold the lock.
+func (w *RotatingFileWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		// The file can't be written anymore either way, let later writes retry
+		// opening it.
+		w.openErr = err
+		return err
+	}
+	if err := os.Rename(w.path, w.backupName(time.Now())); err != nil {
+		// Keep writing to the old file rather than losing the output.
+		if openErr := w.open(); openErr != nil {
+			w.openErr = openErr
+			return openErr
+		}
+		return err
+	}
+	if err := w.open(); err != nil {
+		w.openErr = err
+		return err
+	}
+	w.triggerMill()
+	return nil
+}
+
+// backupName returns an unused file name for a backup rotated at t.
+func (w *RotatingFileWriter) backupName(t time.Time) string {
+	dir, prefix, ext := w.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+		_, err := os.Stat(name)
+		_, errgz := os.Stat(name + compressSuffix)
+		if os.IsNotExist(err) && os.IsNotExist(errgz) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// nameParts splits the log file path into the directory, the prefix and the
+// extension used for backup file names.
+func (w *RotatingFileWriter) nameParts() (dir, prefix, ext string) {
+	dir, base := filepath.Split(w.path)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return filepath.Clean(dir), prefix, ext
+}
+
+func (w *RotatingFileWriter) triggerMill() {
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *RotatingFileWriter) millLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.millCh:
+			if err := w.mill(); err != nil {
+				// The root logger may write into this file, so don't log
+				// through it to avoid recursing into the writer.
+				fmt.Fprintf(os.Stderr, "Failed to clean up rotated log files: %v\n", err)
+			}
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// backupFile is a rotated log file.
+type backupFile struct {
+	name      string
+	timestamp time.Time
+}
+
+// mill removes rotated files exceeding the retention limits and compresses the
+// remaining ones if requested.
+func (w *RotatingFileWriter) mill() error {
+	cfg := w.Config()
+
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	var remove []backupFile
+	if cfg.MaxBackups > 0 && len(backups) > cfg.MaxBackups {
+		remove = append(remove, backups[cfg.MaxBackups:]...)
+		backups = backups[:cfg.MaxBackups]
+	}
+	if cfg.MaxAge > 0 {
+		cutoff := time.Now().Add(-time.Duration(cfg.MaxAge) * 24 * time.Hour)
+		keep := backups[:0]
+		for _, b := range backups {
+			if b.timestamp.Before(cutoff) {
+				remove = append(remove, b)
+			} else {
+				keep = append(keep, b)
+			}
+		}
+		backups = keep
+	}
+	var firstErr error
+	for _, b := range remove {
+		if err := os.Remove(b.name); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if cfg.Compress {
+		for _, b := range backups {
+			if strings.HasSuffix(b.name, compressSuffix) {
+				continue
+			}
+			if err := compressFile(b.name, b.name+compressSuffix); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// backups returns the rotated files of the log, newest first.
+func (w *RotatingFileWriter) backups() ([]backupFile, error) {
+	dir, prefix, ext := w.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backupFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(stamp, compressSuffix)
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(stamp, ext), time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{filepath.Join(dir, name), t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+	return backups, nil
+}
+
+// compressFile compresses src into dst using gzip and removes src.
+func compressFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(dst)
+		}
+	}()
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
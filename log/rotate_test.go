@@ -0,0 +1,262 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lowerMegabyte shrinks the rotation size unit for the duration of a test.
+func lowerMegabyte(t *testing.T, size int64) {
+	old := megabyte
+	megabyte = size
+	t.Cleanup(func() { megabyte = old })
+}
+
+// listBackups returns the names of the rotated files next to the log file.
+func listBackups(t *testing.T, w *RotatingFileWriter) []string {
+	t.Helper()
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(backups))
+	for i, b := range backups {
+		names[i] = filepath.Base(b.name)
+	}
+	return names
+}
+
+// waitFor polls cond until it holds or a deadline is reached.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	lowerMegabyte(t, 100)
+
+	path := filepath.Join(t.TempDir(), "geth.log")
+	w, err := NewRotatingFileWriter(path, RotationConfig{MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	line := []byte(strings.Repeat("x", 39) + "\n")
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 lines fit into 100 bytes, so the writes should have been spread
+	// across 3 files.
+	if backups := listBackups(t, w); len(backups) != 2 {
+		t.Fatalf("wrong number of backups: have %v, want 2", backups)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, line) {
+		t.Fatalf("wrong log file content: %q", data)
+	}
+	for _, name := range listBackups(t, w) {
+		if !strings.HasPrefix(name, "geth-") || !strings.HasSuffix(name, ".log") {
+			t.Errorf("unexpected backup name %q", name)
+		}
+	}
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "geth.log")
+
+	// Create an outdated backup left over by a previous run.
+	stale := filepath.Join(dir, "geth-"+time.Now().Add(-72*time.Hour).Format(backupTimeFormat)+".log")
+	if err := os.WriteFile(stale, []byte("stale\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewRotatingFileWriter(path, RotationConfig{MaxBackups: 2, MaxAge: 1, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool {
+		backups := listBackups(t, w)
+		if len(backups) != 2 {
+			return false
+		}
+		for _, name := range backups {
+			if !strings.HasSuffix(name, ".log"+compressSuffix) {
+				return false
+			}
+		}
+		return true
+	})
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatal("outdated backup not removed")
+	}
+	// Check that the compressed backups are intact.
+	for _, name := range listBackups(t, w) {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(gz)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "line\n" {
+			t.Errorf("wrong content in %s: %q", name, data)
+		}
+	}
+}
+
+func TestRotatingFileSetConfig(t *testing.T) {
+	lowerMegabyte(t, 10)
+
+	path := filepath.Join(t.TempDir(), "geth.log")
+	w, err := NewRotatingFileWriter(path, RotationConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 4; i++ {
+		w.Write([]byte("0123456789\n"))
+	}
+	if backups := listBackups(t, w); len(backups) != 0 {
+		t.Fatalf("rotated without size limit: %v", backups)
+	}
+	w.SetConfig(RotationConfig{MaxSize: 1, MaxBackups: 1})
+	for i := 0; i < 3; i++ {
+		w.Write([]byte("0123456789\n"))
+	}
+	if have := w.Config(); have.MaxSize != 1 || have.MaxBackups != 1 {
+		t.Fatalf("wrong config: %+v", have)
+	}
+	waitFor(t, func() bool { return len(listBackups(t, w)) == 1 })
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	w, err := NewRotatingFileWriter(filepath.Join(t.TempDir(), "geth.log"), RotationConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("x")); err != errWriterClosed {
+		t.Fatalf("wrong error after close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second close failed: %v", err)
+	}
+}
+
+func TestRotatingFileReopenFailure(t *testing.T) {
+	w, err := NewRotatingFileWriter(filepath.Join(t.TempDir(), "geth.log"), RotationConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// failReopen simulates a rotation which failed to reopen the log.
+	reopenErr := errors.New("reopen failed")
+	failReopen := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.file.Close()
+		w.file, w.openErr = nil, reopenErr
+	}
+	// Writes retry opening the log.
+	failReopen()
+	if _, err := w.Write([]byte("x")); err != nil {
+		t.Fatalf("write after reopen failure: %v", err)
+	}
+	if content, _ := os.ReadFile(w.Path()); string(content) != "x" {
+		t.Fatalf("wrong log content: %q", content)
+	}
+	// Closing reports the failure and stops the background loop.
+	failReopen()
+	if err := w.Close(); err != reopenErr {
+		t.Fatalf("wrong close error: %v", err)
+	}
+	select {
+	case <-w.quit:
+	default:
+		t.Fatal("background loop not stopped")
+	}
+	if _, err := w.Write([]byte("x")); err != errWriterClosed {
+		t.Fatalf("wrong error after close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second close failed: %v", err)
+	}
+}
+
+func TestRotatingFileCloseFailure(t *testing.T) {
+	w, err := NewRotatingFileWriter(filepath.Join(t.TempDir(), "geth.log"), RotationConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Close the file behind the writer's back, failing the rotation.
+	w.mu.Lock()
+	w.file.Close()
+	w.mu.Unlock()
+	if err := w.Rotate(); err == nil {
+		t.Fatal("rotation of closed file succeeded")
+	}
+	// The next write reopens the log.
+	if _, err := w.Write([]byte("x")); err != nil {
+		t.Fatalf("write after close failure: %v", err)
+	}
+	if content, _ := os.ReadFile(w.Path()); string(content) != "x" {
+		t.Fatalf("wrong log content: %q", content)
+	}
+}
@@ -4,55 +4,73 @@
 package log
 
 import (
+	"context"
 	"log/syslog"
 	"strings"
+
+	"golang.org/x/exp/slog"
 )
 
+// syslogHandler is a slog.Handler writing records in logfmt format to a syslog
+// daemon, at the syslog severity matching their level.
+type syslogHandler struct {
+	wr   *syslog.Writer
+	text *textHandler
+}
+
 // SyslogHandler opens a connection to the system syslog daemon by calling
-// syslog.New and writes all records to it.
-func SyslogHandler(priority syslog.Priority, tag string, fmtr Format) (Handler, error) {
+// syslog.New and returns a handler writing the records at or above the specified
+// level to it in logfmt format.
+func SyslogHandler(priority syslog.Priority, tag string, lvl slog.Level) (slog.Handler, error) {
 	wr, err := syslog.New(priority, tag)
-	return sharedSyslog(fmtr, wr, err)
+	if err != nil {
+		return nil, err
+	}
+	return newSyslogHandler(wr, lvl), nil
 }
 
-// SyslogNetHandler opens a connection to a log daemon over the network and writes
-// all log records to it.
-func SyslogNetHandler(net, addr string, priority syslog.Priority, tag string, fmtr Format) (Handler, error) {
+// SyslogNetHandler opens a connection to a log daemon over the network and returns
+// a handler writing the records at or above the specified level to it in logfmt
+// format.
+func SyslogNetHandler(net, addr string, priority syslog.Priority, tag string, lvl slog.Level) (slog.Handler, error) {
 	wr, err := syslog.Dial(net, addr, priority, tag)
-	return sharedSyslog(fmtr, wr, err)
-}
-
-func sharedSyslog(fmtr Format, sysWr *syslog.Writer, err error) (Handler, error) {
 	if err != nil {
 		return nil, err
 	}
-	h := FuncHandler(func(r *Record) error {
-		var syslogFn = sysWr.Info
-		switch r.Lvl {
-		case LvlCrit:
-			syslogFn = sysWr.Crit
-		case LvlError:
-			syslogFn = sysWr.Err
-		case LvlWarn:
-			syslogFn = sysWr.Warning
-		case LvlInfo:
-			syslogFn = sysWr.Info
-		case LvlDebug:
-			syslogFn = sysWr.Debug
-		case LvlTrace:
-			syslogFn = func(m string) error { return nil } // There's no syslog level for trace
-		}
-
-		s := strings.TrimSpace(string(fmtr.Format(r)))
-		return syslogFn(s)
-	})
-	return LazyHandler(&closingHandler{sysWr, h}), nil
-}
-
-func (m muster) SyslogHandler(priority syslog.Priority, tag string, fmtr Format) Handler {
-	return must(SyslogHandler(priority, tag, fmtr))
-}
-
-func (m muster) SyslogNetHandler(net, addr string, priority syslog.Priority, tag string, fmtr Format) Handler {
-	return must(SyslogNetHandler(net, addr, priority, tag, fmtr))
+	return newSyslogHandler(wr, lvl), nil
+}
+
+func newSyslogHandler(wr *syslog.Writer, lvl slog.Level) *syslogHandler {
+	return &syslogHandler{wr: wr, text: &textHandler{lvl: lvl}}
+}
+
+func (h *syslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.text.Enabled(ctx, level)
+}
+
+func (h *syslogHandler) Handle(_ context.Context, r slog.Record) error {
+	var syslogFn func(string) error
+	switch {
+	case r.Level >= LevelCrit:
+		syslogFn = h.wr.Crit
+	case r.Level >= LevelError:
+		syslogFn = h.wr.Err
+	case r.Level >= LevelWarn:
+		syslogFn = h.wr.Warning
+	case r.Level >= LevelInfo:
+		syslogFn = h.wr.Info
+	case r.Level >= LevelDebug:
+		syslogFn = h.wr.Debug
+	default:
+		return nil // There's no syslog level for trace
+	}
+	return syslogFn(strings.TrimSpace(h.text.format(r).String()))
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{wr: h.wr, text: h.text.WithAttrs(attrs).(*textHandler)}
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	return &syslogHandler{wr: h.wr, text: h.text.WithGroup(name).(*textHandler)}
 }
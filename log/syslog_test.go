@@ -0,0 +1,42 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package log
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogHandler(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen on UDP:", err)
+	}
+	defer conn.Close()
+
+	h, err := SyslogNetHandler("udp", conn.LocalAddr().String(), 0, "geth", LevelDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(h).With("module", "test")
+	logger.Trace("dropped")
+	logger.Warn("sent", "key", "value")
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := strings.TrimSpace(string(buf[:n]))
+	// The priority is the facility (LOG_KERN, 0) times 8 plus the severity.
+	if !strings.HasPrefix(msg, "<4>") {
+		t.Errorf("wrong priority in message %q", msg)
+	}
+	if !strings.Contains(msg, "lvl=warn msg=sent") || !strings.HasSuffix(msg, "module=test key=value") {
+		t.Errorf("wrong message %q", msg)
+	}
+}
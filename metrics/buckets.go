@@ -0,0 +1,321 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bucketSchema is the resolution of the exponential buckets histograms count their
+// values in, using the same definition as Prometheus native histograms: bucket
+// boundaries grow by a factor of 2^(2^-bucketSchema), i.e. there are 8 buckets
+// per power of two and each value is placed within ~9% of its bucket boundary.
+const bucketSchema = 3
+
+// BucketedHistogram is implemented by histogram and timer snapshots which, besides
+// their sample, count every recorded value in exponentially growing buckets.
+// Unlike the sample, the bucket counts are never decayed or reset, so they can be
+// aggregated across processes.
+type BucketedHistogram interface {
+	Buckets() *HistogramBuckets
+}
+
+// Exemplar is a recorded value along with the trace it was observed in.
+type Exemplar struct {
+	Value   float64
+	TraceID string
+	Time    time.Time
+}
+
+// bucketKey identifies an exponential bucket. The zero bucket holds the value 0,
+// the others hold values with an absolute value in (2^((index-1)/8), 2^(index/8)].
+type bucketKey struct {
+	sign  int8
+	index int
+}
+
+// bucketCount is the number of buckets per sign needed to hold any int64 value.
+// The smallest absolute value is 1 (index 0), the largest is 2^63.
+const bucketCount = 63<<bucketSchema + 1
+
+// bucketFractions are the upper boundaries of the buckets within a power of two,
+// scaled into [0.5, 1) like the fractions returned by math.Frexp.
+var bucketFractions = func() [1 << bucketSchema]float64 {
+	var fracs [1 << bucketSchema]float64
+	for i := range fracs {
+		fracs[i] = math.Exp2(float64(i)/(1<<bucketSchema) - 1)
+	}
+	return fracs
+}()
+
+func keyOf(v int64) bucketKey {
+	switch {
+	case v == 0:
+		return bucketKey{}
+	case v < 0:
+		return bucketKey{-1, bucketIndex(-float64(v))}
+	default:
+		return bucketKey{1, bucketIndex(float64(v))}
+	}
+}
+
+// bucketIndex returns ceil(log2(v) * 2^bucketSchema) for v >= 1 without
+// computing the logarithm.
+func bucketIndex(v float64) int {
+	frac, exp := math.Frexp(v)
+	i := 0
+	for i < len(bucketFractions) && bucketFractions[i] < frac {
+		i++
+	}
+	return (exp-1)<<bucketSchema + i
+}
+
+// upperBound returns the largest integer that falls into the bucket. As only
+// integers are recorded, counting values up to it is exact.
+func (k bucketKey) upperBound() float64 {
+	switch k.sign {
+	case 0:
+		return 0
+	case -1:
+		return -math.Floor(math.Exp2(float64(k.index-1)/(1<<bucketSchema))) - 1
+	default:
+		return math.Floor(math.Exp2(float64(k.index) / (1 << bucketSchema)))
+	}
+}
+
+// expBuckets counts values in exponential buckets, keeping the last exemplar
+// recorded for each bucket. Updates are lock-free, except for recording
+// exemplars. The bucket arrays are only allocated once a value of their sign is
+// recorded.
+type expBuckets struct {
+	sum      atomic.Int64
+	zero     atomic.Int64
+	positive atomic.Pointer[[bucketCount]atomic.Int64]
+	negative atomic.Pointer[[bucketCount]atomic.Int64]
+
+	mu        sync.Mutex // protects the exemplars
+	exemplars map[bucketKey]Exemplar
+}
+
+func (b *expBuckets) update(v int64, traceID string) {
+	key := keyOf(v)
+
+	b.sum.Add(v)
+	switch key.sign {
+	case 0:
+		b.zero.Add(1)
+	case 1:
+		bucketsOf(&b.positive)[key.index].Add(1)
+	case -1:
+		bucketsOf(&b.negative)[key.index].Add(1)
+	}
+	if traceID != "" {
+		b.mu.Lock()
+		if b.exemplars == nil {
+			b.exemplars = make(map[bucketKey]Exemplar)
+		}
+		b.exemplars[key] = Exemplar{Value: float64(v), TraceID: traceID, Time: time.Now()}
+		b.mu.Unlock()
+	}
+}
+
+// bucketsOf returns the bucket array behind the pointer, allocating it if needed.
+func bucketsOf(p *atomic.Pointer[[bucketCount]atomic.Int64]) *[bucketCount]atomic.Int64 {
+	if buckets := p.Load(); buckets != nil {
+		return buckets
+	}
+	p.CompareAndSwap(nil, new([bucketCount]atomic.Int64))
+	return p.Load()
+}
+
+func (b *expBuckets) clear() {
+	b.sum.Store(0)
+	b.zero.Store(0)
+	b.positive.Store(nil)
+	b.negative.Store(nil)
+
+	b.mu.Lock()
+	b.exemplars = nil
+	b.mu.Unlock()
+}
+
+func (b *expBuckets) snapshot() *HistogramBuckets {
+	s := &HistogramBuckets{
+		sum:    float64(b.sum.Load()),
+		counts: make(map[bucketKey]int64),
+	}
+	// The count is derived from the buckets, so it matches their total even if
+	// values are recorded concurrently.
+	if c := b.zero.Load(); c != 0 {
+		s.counts[bucketKey{}] = c
+		s.count += c
+	}
+	for _, signed := range []struct {
+		sign    int8
+		buckets *[bucketCount]atomic.Int64
+	}{{1, b.positive.Load()}, {-1, b.negative.Load()}} {
+		if signed.buckets == nil {
+			continue
+		}
+		for i := range signed.buckets {
+			if c := signed.buckets[i].Load(); c != 0 {
+				s.counts[bucketKey{signed.sign, i}] = c
+				s.count += c
+			}
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s.exemplars = make(map[bucketKey]Exemplar, len(b.exemplars))
+	for k, e := range b.exemplars {
+		s.exemplars[k] = e
+	}
+	return s
+}
+
+// HistogramBuckets is a read-only copy of the exponential bucket counts of a
+// histogram.
+type HistogramBuckets struct {
+	count     int64
+	sum       float64
+	counts    map[bucketKey]int64
+	exemplars map[bucketKey]Exemplar
+}
+
+// Count returns the number of recorded values.
+func (b *HistogramBuckets) Count() int64 { return b.count }
+
+// Sum returns the sum of the recorded values.
+func (b *HistogramBuckets) Sum() float64 { return b.sum }
+
+// Cumulative returns the number of recorded values less than or equal to each of
+// the given ascending upper bounds, followed by the total count. The returned
+// exemplars are the most recent ones recorded within each bucket, nil if there
+// are none.
+//
+// The bounds are rounded up to the nearest exponential bucket boundary, which
+// keeps the counts exact. The rounded bounds are returned, with duplicates
+// merged, and should be reported instead of the requested ones.
+func (b *HistogramBuckets) Cumulative(bounds []float64) ([]float64, []int64, []*Exemplar) {
+	rounded := make([]float64, 0, len(bounds))
+	for _, bound := range bounds {
+		if math.IsNaN(bound) {
+			continue
+		}
+		r := roundBound(bound)
+		if len(rounded) == 0 || r > rounded[len(rounded)-1] {
+			rounded = append(rounded, r)
+		}
+	}
+	var (
+		counts    = make([]int64, len(rounded)+1)
+		exemplars = make([]*Exemplar, len(rounded)+1)
+	)
+	for key, c := range b.counts {
+		counts[sort.SearchFloat64s(rounded, key.upperBound())] += c
+	}
+	for key, e := range b.exemplars {
+		i := sort.SearchFloat64s(rounded, key.upperBound())
+		if exemplars[i] == nil || e.Time.After(exemplars[i].Time) {
+			e := e
+			exemplars[i] = &e
+		}
+	}
+	for i := 1; i < len(counts); i++ {
+		counts[i] += counts[i-1]
+	}
+	return rounded, counts, exemplars
+}
+
+// roundBound rounds a bound up to the upper boundary of the bucket containing it.
+func roundBound(bound float64) float64 {
+	switch {
+	case bound >= math.MaxInt64:
+		return keyOf(math.MaxInt64).upperBound()
+	case bound <= math.MinInt64:
+		return keyOf(math.MinInt64).upperBound()
+	}
+	return keyOf(int64(math.Floor(bound))).upperBound()
+}
+
+// ExponentialBuckets are the bucket counts of a histogram in the layout of an
+// OpenTelemetry exponential histogram. Bucket i of each range counts values with
+// an absolute value in (base^(offset+i), base^(offset+i+1)], where the base is
+// 2^(2^-Scale).
+type ExponentialBuckets struct {
+	Scale          int
+	ZeroCount      int64
+	PositiveOffset int
+	Positive       []int64
+	NegativeOffset int
+	Negative       []int64
+}
+
+// Exponential returns the bucket counts in the layout of an OpenTelemetry
+// exponential histogram.
+func (b *HistogramBuckets) Exponential() ExponentialBuckets {
+	e := ExponentialBuckets{Scale: bucketSchema}
+	positive, negative := make(map[int]int64), make(map[int]int64)
+	for key, c := range b.counts {
+		switch key.sign {
+		case 0:
+			e.ZeroCount += c
+		case 1:
+			positive[key.index-1] += c
+		case -1:
+			negative[key.index-1] += c
+		}
+	}
+	e.PositiveOffset, e.Positive = denseBuckets(positive)
+	e.NegativeOffset, e.Negative = denseBuckets(negative)
+	return e
+}
+
+// denseBuckets converts sparse bucket counts into a list of consecutive buckets
+// starting at the returned offset.
+func denseBuckets(sparse map[int]int64) (int, []int64) {
+	if len(sparse) == 0 {
+		return 0, nil
+	}
+	lo, hi := math.MaxInt, math.MinInt
+	for i := range sparse {
+		if i < lo {
+			lo = i
+		}
+		if i > hi {
+			hi = i
+		}
+	}
+	dense := make([]int64, hi-lo+1)
+	for i, c := range sparse {
+		dense[i-lo] = c
+	}
+	return lo, dense
+}
+
+// UpdateWithExemplar records a value in the histogram, attaching the given trace
+// ID to it if the histogram supports exemplars. An empty trace ID records a plain
+// value.
+func UpdateWithExemplar(h Histogram, v int64, traceID string) {
+	if e, ok := h.(interface{ UpdateWithExemplar(int64, string) }); ok {
+		e.UpdateWithExemplar(v, traceID)
+		return
+	}
+	h.Update(v)
+}
+
+// UpdateTimerWithExemplar records a duration in the timer, attaching the given
+// trace ID to it if the timer supports exemplars. An empty trace ID records a
+// plain duration.
+func UpdateTimerWithExemplar(t Timer, d time.Duration, traceID string) {
+	if e, ok := t.(interface {
+		UpdateWithExemplar(time.Duration, string)
+	}); ok {
+		e.UpdateWithExemplar(d, traceID)
+		return
+	}
+	t.Update(d)
+}
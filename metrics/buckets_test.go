@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBucketIndex(t *testing.T) {
+	for _, v := range []int64{1, 2, 3, 7, 8, 100, 1000, 123456789, math.MaxInt64} {
+		key := keyOf(v)
+		upper := key.upperBound()
+		lower := bucketKey{key.sign, key.index - 1}.upperBound()
+		if f := float64(v); f > upper*(1+1e-9) || f <= lower*(1-1e-9) {
+			t.Errorf("value %d outside of its bucket (%v, %v]", v, lower, upper)
+		}
+		if v != math.MaxInt64 && keyOf(-v) != (bucketKey{-1, key.index}) {
+			t.Errorf("negative bucket of %d not mirrored: %+v", v, keyOf(-v))
+		}
+	}
+}
+
+// Tests that the upper bound of a bucket is the largest integer within it, which
+// makes the cumulative counts exact.
+func TestBucketUpperBound(t *testing.T) {
+	for v := int64(-5000); v <= 5000; v++ {
+		key := keyOf(v)
+		if want := int(math.Ceil(math.Log2(math.Abs(float64(v))) * (1 << bucketSchema))); v != 0 && key.index != want {
+			t.Fatalf("value %d: wrong bucket index: have %d, want %d", v, key.index, want)
+		}
+		upper := int64(key.upperBound())
+		if keyOf(upper) != key || keyOf(upper+1) == key {
+			t.Fatalf("value %d: %d is not the largest integer of bucket %+v", v, upper, key)
+		}
+	}
+}
+
+func TestHistogramBucketsCumulative(t *testing.T) {
+	h := NewHistogram(NewUniformSample(10))
+	for i := int64(1); i <= 1000; i++ {
+		h.Update(i)
+	}
+	h.Update(0)
+	UpdateWithExemplar(h, 50, "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	buckets := h.Snapshot().(BucketedHistogram).Buckets()
+	if buckets.Count() != 1002 {
+		t.Fatalf("wrong count: have %d, want 1002", buckets.Count())
+	}
+	if buckets.Sum() != 500500+50 {
+		t.Fatalf("wrong sum: have %v, want %v", buckets.Sum(), 500500+50)
+	}
+	// The bounds are rounded up to bucket boundaries, the counts are exact.
+	bounds, counts, exemplars := buckets.Cumulative([]float64{0, 10, 100, 105, 1000})
+	if fmt.Sprint(bounds) != "[0 10 107 1024]" {
+		t.Fatalf("wrong bounds: %v", bounds)
+	}
+	if want := []int64{1, 1 + 10, 1 + 107 + 1, 1002, 1002}; fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("wrong counts: have %v, want %v", counts, want)
+	}
+	for i, e := range exemplars {
+		if i == 2 {
+			if e == nil || e.Value != 50 || e.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("wrong exemplar in bucket le=100: %+v", e)
+			}
+		} else if e != nil {
+			t.Errorf("unexpected exemplar in bucket %d: %+v", i, e)
+		}
+	}
+	// Clearing the histogram resets the buckets as well.
+	h.Clear()
+	if n := h.Snapshot().(BucketedHistogram).Buckets().Count(); n != 0 {
+		t.Fatalf("buckets not cleared: %d", n)
+	}
+}
+
+func TestTimerBuckets(t *testing.T) {
+	tm := NewTimer()
+	defer tm.Stop()
+
+	tm.Update(time.Millisecond)
+	UpdateTimerWithExemplar(tm, time.Second, "00000000000000000000000000000001")
+
+	buckets := tm.Snapshot().(BucketedHistogram).Buckets()
+	_, counts, exemplars := buckets.Cumulative([]float64{float64(10 * time.Millisecond)})
+	if counts[0] != 1 || counts[1] != 2 {
+		t.Fatalf("wrong counts: %v", counts)
+	}
+	if exemplars[0] != nil || exemplars[1] == nil || exemplars[1].Value != float64(time.Second) {
+		t.Fatalf("wrong exemplars: %v", exemplars)
+	}
+}
+
+func TestHistogramBucketsExponential(t *testing.T) {
+	h := NewHistogram(NewUniformSample(10))
+	for _, v := range []int64{0, 1, 2, 2, 3, -1} {
+		h.Update(v)
+	}
+	e := h.Snapshot().(BucketedHistogram).Buckets().Exponential()
+	if e.Scale != bucketSchema || e.ZeroCount != 1 {
+		t.Fatalf("wrong scale or zero count: %+v", e)
+	}
+	// With scale 3, 1 is in bucket -1 (base^-1, 1], 2 in bucket 7 and 3 in bucket 12.
+	if e.PositiveOffset != -1 || len(e.Positive) != 14 || e.Positive[0] != 1 || e.Positive[8] != 2 || e.Positive[13] != 1 {
+		t.Fatalf("wrong positive buckets: offset %d, %v", e.PositiveOffset, e.Positive)
+	}
+	if e.NegativeOffset != -1 || len(e.Negative) != 1 || e.Negative[0] != 1 {
+		t.Fatalf("wrong negative buckets: offset %d, %v", e.NegativeOffset, e.Negative)
+	}
+}
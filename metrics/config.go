@@ -33,6 +33,12 @@ type Config struct {
 	InfluxDBToken        string `toml:",omitempty"`
 	InfluxDBBucket       string `toml:",omitempty"`
 	InfluxDBOrganization string `toml:",omitempty"`
+
+	PrometheusBuckets string `toml:",omitempty"`
+
+	EnableOTLP   bool   `toml:",omitempty"`
+	OTLPEndpoint string `toml:",omitempty"`
+	OTLPResource string `toml:",omitempty"`
 }
 
 // DefaultConfig is the default config for metrics used in go-ethereum.
@@ -53,4 +59,8 @@ var DefaultConfig = Config{
 	InfluxDBToken:        "test",
 	InfluxDBBucket:       "geth",
 	InfluxDBOrganization: "geth",
+
+	// otlp-specific flags
+	EnableOTLP:   false,
+	OTLPEndpoint: "http://localhost:4318",
 }
@@ -45,6 +45,7 @@ func Exp(r metrics.Registry) {
 	// haven't found an elegant way, so just use a different endpoint
 	http.Handle("/debug/metrics", h)
 	http.Handle("/debug/metrics/prometheus", prometheus.Handler(r))
+	http.Handle("/debug/metrics/openmetrics", prometheus.OpenMetricsHandler(r))
 }
 
 // ExpHandler will return an expvar powered metrics handler.
@@ -59,6 +60,7 @@ func Setup(address string) {
 	m := http.NewServeMux()
 	m.Handle("/debug/metrics", ExpHandler(metrics.DefaultRegistry))
 	m.Handle("/debug/metrics/prometheus", prometheus.Handler(metrics.DefaultRegistry))
+	m.Handle("/debug/metrics/openmetrics", prometheus.OpenMetricsHandler(metrics.DefaultRegistry))
 	log.Info("Starting metrics server", "addr", fmt.Sprintf("http://%s/debug/metrics", address))
 	go func() {
 		if err := http.ListenAndServe(address, m); err != nil {
@@ -56,9 +56,14 @@ func NewRegisteredHistogram(name string, r Registry, s Sample) Histogram {
 
 // HistogramSnapshot is a read-only copy of another Histogram.
 type HistogramSnapshot struct {
-	sample *SampleSnapshot
+	sample  *SampleSnapshot
+	buckets *HistogramBuckets
 }
 
+// Buckets returns the exponential bucket counts at the time the snapshot was
+// taken.
+func (h *HistogramSnapshot) Buckets() *HistogramBuckets { return h.buckets }
+
 // Clear panics.
 func (*HistogramSnapshot) Clear() {
 	panic("Clear called on a HistogramSnapshot")
@@ -158,13 +163,18 @@ func (NilHistogram) Update(v int64) {}
 func (NilHistogram) Variance() float64 { return 0.0 }
 
 // StandardHistogram is the standard implementation of a Histogram and uses a
-// Sample to bound its memory use.
+// Sample to bound its memory use. All values are additionally counted in
+// exponential buckets, see BucketedHistogram.
 type StandardHistogram struct {
-	sample Sample
+	sample  Sample
+	buckets expBuckets
 }
 
 // Clear clears the histogram and its sample.
-func (h *StandardHistogram) Clear() { h.sample.Clear() }
+func (h *StandardHistogram) Clear() {
+	h.sample.Clear()
+	h.buckets.clear()
+}
 
 // Count returns the number of samples recorded since the histogram was last
 // cleared.
@@ -195,7 +205,10 @@ func (h *StandardHistogram) Sample() Sample { return h.sample }
 
 // Snapshot returns a read-only copy of the histogram.
 func (h *StandardHistogram) Snapshot() Histogram {
-	return &HistogramSnapshot{sample: h.sample.Snapshot().(*SampleSnapshot)}
+	return &HistogramSnapshot{
+		sample:  h.sample.Snapshot().(*SampleSnapshot),
+		buckets: h.buckets.snapshot(),
+	}
 }
 
 // StdDev returns the standard deviation of the values in the sample.
@@ -205,7 +218,17 @@ func (h *StandardHistogram) StdDev() float64 { return h.sample.StdDev() }
 func (h *StandardHistogram) Sum() int64 { return h.sample.Sum() }
 
 // Update samples a new value.
-func (h *StandardHistogram) Update(v int64) { h.sample.Update(v) }
+func (h *StandardHistogram) Update(v int64) {
+	h.sample.Update(v)
+	h.buckets.update(v, "")
+}
+
+// UpdateWithExemplar samples a new value, recording it as an exemplar of the
+// given trace.
+func (h *StandardHistogram) UpdateWithExemplar(v int64, traceID string) {
+	h.sample.Update(v)
+	h.buckets.update(v, traceID)
+}
 
 // Variance returns the variance of the values in the sample.
 func (h *StandardHistogram) Variance() float64 { return h.sample.Variance() }
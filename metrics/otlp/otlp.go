@@ -0,0 +1,440 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package otlp pushes metrics to an OpenTelemetry collector using the OTLP/HTTP
+// protocol with JSON encoding.
+package otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	metricsPath           = "/v1/metrics"    // Default path of the OTLP/HTTP metrics endpoint
+	scopeName             = "go-ethereum"    // Instrumentation scope reported with all metrics
+	httpTimeout           = 10 * time.Second // Timeout of a single export request
+	errorMaxSize          = 512              // Maximum number of bytes read from error responses
+	temporalityCumulative = 2                // AGGREGATION_TEMPORALITY_CUMULATIVE
+)
+
+// Resource attributes identifying the reporting node.
+const (
+	AttrServiceName    = "service.name"
+	AttrServiceVersion = "service.version"
+	AttrNodeID         = "service.instance.id"
+	AttrNetworkID      = "ethereum.network.id"
+)
+
+var (
+	resourceLock sync.Mutex
+	resource     = make(map[string]string)
+)
+
+// SetResourceAttribute sets an attribute of the resource all metrics are reported
+// for. It may be called while reporters are running, e.g. to add the node ID once
+// it is known, and takes effect with the next report. An empty value removes the
+// attribute.
+func SetResourceAttribute(key, value string) {
+	resourceLock.Lock()
+	defer resourceLock.Unlock()
+
+	if value == "" {
+		delete(resource, key)
+	} else {
+		resource[key] = value
+	}
+}
+
+// resourceAttributes returns the attributes of the reporting resource, with the
+// given ones taking precedence over those set by SetResourceAttribute.
+func resourceAttributes(extra map[string]string) []keyValue {
+	resourceLock.Lock()
+	attrs := make(map[string]string, len(resource)+len(extra))
+	for k, v := range resource {
+		attrs[k] = v
+	}
+	resourceLock.Unlock()
+
+	for k, v := range extra {
+		attrs[k] = v
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]keyValue, len(keys))
+	for i, k := range keys {
+		v := attrs[k]
+		kvs[i] = keyValue{Key: k, Value: anyValue{StringValue: &v}}
+	}
+	return kvs
+}
+
+type reporter struct {
+	reg       metrics.Registry
+	interval  time.Duration
+	url       string
+	namespace string
+	resource  map[string]string
+	client    *http.Client
+
+	start time.Time // Start of the cumulative metrics
+	last  time.Time // Time of the last report, start of resetting timer windows
+}
+
+// OTLP starts an OTLP/HTTP reporter which pushes the metrics of the given registry
+// to the collector at endpoint (e.g. http://localhost:4318) at each d interval. The
+// namespace is prepended to all metric names, the resource attributes are reported
+// along with those set by SetResourceAttribute. This function blocks forever.
+func OTLP(r metrics.Registry, d time.Duration, endpoint string, namespace string, resource map[string]string) {
+	rep, err := newReporter(r, d, endpoint, namespace, resource)
+	if err != nil {
+		log.Warn("Unable to start OTLP metrics reporter", "err", err)
+		return
+	}
+	rep.run()
+}
+
+func newReporter(r metrics.Registry, d time.Duration, endpoint string, namespace string, resource map[string]string) (*reporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: scheme must be http or https", endpoint)
+	}
+	// Like the OpenTelemetry SDKs, treat endpoints without a path as the base URL
+	// of the collector.
+	if u.Path == "" || u.Path == "/" {
+		u.Path = metricsPath
+	}
+	now := time.Now()
+	return &reporter{
+		reg:       r,
+		interval:  d,
+		url:       u.String(),
+		namespace: namespace,
+		resource:  resource,
+		client:    &http.Client{Timeout: httpTimeout},
+		start:     now,
+		last:      now,
+	}, nil
+}
+
+func (r *reporter) run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := r.send(); err != nil {
+			log.Warn("Unable to send to OTLP collector", "err", err)
+		}
+	}
+}
+
+func (r *reporter) send() error {
+	now := time.Now()
+	body, err := json.Marshal(r.collect(now))
+	if err != nil {
+		return err
+	}
+	r.last = now
+
+	resp, err := r.client.Post(r.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, errorMaxSize))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// collect converts all metrics of the registry into an export request.
+func (r *reporter) collect(now time.Time) *exportRequest {
+	var (
+		names []string
+		ms    []metric
+	)
+	r.reg.Each(func(name string, _ interface{}) {
+		names = append(names, name)
+	})
+	sort.Strings(names)
+
+	for _, name := range names {
+		if m, ok := r.convert(name, r.reg.Get(name), now); ok {
+			ms = append(ms, m)
+		}
+	}
+	return &exportRequest{
+		ResourceMetrics: []resourceMetrics{{
+			Resource: resourceInfo{Attributes: resourceAttributes(r.resource)},
+			ScopeMetrics: []scopeMetrics{{
+				Scope:   scope{Name: scopeName},
+				Metrics: ms,
+			}},
+		}},
+	}
+}
+
+// convert maps a single metric to its OTLP representation.
+func (r *reporter) convert(name string, i interface{}, now time.Time) (metric, bool) {
+	var (
+		m     = metric{Name: r.namespace + strings.ReplaceAll(name, "/", ".")}
+		start = unixNano(r.start)
+		ts    = unixNano(now)
+	)
+	switch mt := i.(type) {
+	case metrics.Counter:
+		// Counters can be decremented, so they are non-monotonic sums.
+		m.Sum = &sum{
+			DataPoints:             []numberDataPoint{{StartTimeUnixNano: start, TimeUnixNano: ts, AsInt: intString(mt.Snapshot().Count())}},
+			AggregationTemporality: temporalityCumulative,
+		}
+	case metrics.CounterFloat64:
+		v := mt.Snapshot().Count()
+		m.Sum = &sum{
+			DataPoints:             []numberDataPoint{{StartTimeUnixNano: start, TimeUnixNano: ts, AsDouble: &v}},
+			AggregationTemporality: temporalityCumulative,
+		}
+	case metrics.Gauge:
+		m.Gauge = &gauge{
+			DataPoints: []numberDataPoint{{TimeUnixNano: ts, AsInt: intString(mt.Snapshot().Value())}},
+		}
+	case metrics.GaugeFloat64:
+		v := mt.Snapshot().Value()
+		m.Gauge = &gauge{
+			DataPoints: []numberDataPoint{{TimeUnixNano: ts, AsDouble: &v}},
+		}
+	case metrics.Meter:
+		// Meter snapshots only include the events of completed ticks, query the
+		// meter itself for an up-to-date count.
+		m.Sum = &sum{
+			DataPoints:             []numberDataPoint{{StartTimeUnixNano: start, TimeUnixNano: ts, AsInt: intString(mt.Count())}},
+			AggregationTemporality: temporalityCumulative,
+			IsMonotonic:            true,
+		}
+	case metrics.Histogram:
+		snap := mt.Snapshot()
+		if h, ok := snap.(metrics.BucketedHistogram); ok && h.Buckets() != nil {
+			m.ExponentialHistogram = exponentialHistogramOf(h.Buckets(), start, ts)
+		} else {
+			m.Summary = summaryOf(snap.Count(), float64(snap.Sum()), snap.Percentiles(quantiles), start, ts)
+		}
+	case metrics.Timer:
+		m.Unit = "ns"
+		snap := mt.Snapshot()
+		if h, ok := snap.(metrics.BucketedHistogram); ok && h.Buckets() != nil {
+			m.ExponentialHistogram = exponentialHistogramOf(h.Buckets(), start, ts)
+		} else {
+			m.Summary = summaryOf(snap.Count(), float64(snap.Sum()), snap.Percentiles(quantiles), start, ts)
+		}
+	case metrics.ResettingTimer:
+		// Resetting timers only contain the values recorded since the last report,
+		// so they are reported as summaries of that window.
+		m.Unit = "ns"
+		snap := mt.Snapshot()
+		values := snap.Values()
+		if len(values) == 0 {
+			return m, false
+		}
+		var total float64
+		for _, v := range values {
+			total += float64(v)
+		}
+		ps := snap.Percentiles([]float64{50, 95, 99})
+		m.Summary = &summary{DataPoints: []summaryDataPoint{{
+			StartTimeUnixNano: unixNano(r.last),
+			TimeUnixNano:      ts,
+			Count:             intString(int64(len(values))),
+			Sum:               total,
+			QuantileValues: []quantileValue{
+				{Quantile: 0.5, Value: float64(ps[0])},
+				{Quantile: 0.95, Value: float64(ps[1])},
+				{Quantile: 0.99, Value: float64(ps[2])},
+			},
+		}}}
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+
+func summaryOf(count int64, total float64, ps []float64, start, ts string) *summary {
+	dp := summaryDataPoint{
+		StartTimeUnixNano: start,
+		TimeUnixNano:      ts,
+		Count:             intString(count),
+		Sum:               total,
+	}
+	for i, q := range quantiles {
+		dp.QuantileValues = append(dp.QuantileValues, quantileValue{Quantile: q, Value: ps[i]})
+	}
+	return &summary{DataPoints: []summaryDataPoint{dp}}
+}
+
+func exponentialHistogramOf(b *metrics.HistogramBuckets, start, ts string) *exponentialHistogram {
+	e := b.Exponential()
+	dp := exponentialHistogramDataPoint{
+		StartTimeUnixNano: start,
+		TimeUnixNano:      ts,
+		Count:             intString(b.Count()),
+		Sum:               b.Sum(),
+		Scale:             e.Scale,
+		ZeroCount:         intString(e.ZeroCount),
+		Positive:          bucketsOf(e.PositiveOffset, e.Positive),
+		Negative:          bucketsOf(e.NegativeOffset, e.Negative),
+	}
+	return &exponentialHistogram{
+		DataPoints:             []exponentialHistogramDataPoint{dp},
+		AggregationTemporality: temporalityCumulative,
+	}
+}
+
+func bucketsOf(offset int, counts []int64) *buckets {
+	if len(counts) == 0 {
+		return nil
+	}
+	b := &buckets{Offset: offset, BucketCounts: make([]string, len(counts))}
+	for i, c := range counts {
+		b.BucketCounts[i] = strconv.FormatInt(c, 10)
+	}
+	return b
+}
+
+// 64 bit integers are encoded as strings in OTLP/JSON.
+func intString(v int64) string { return strconv.FormatInt(v, 10) }
+
+func unixNano(t time.Time) string { return strconv.FormatInt(t.UnixNano(), 10) }
+
+// The types below mirror the JSON encoding of the OTLP ExportMetricsServiceRequest
+// message, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+
+type exportRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resourceInfo   `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resourceInfo struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+}
+
+type metric struct {
+	Name                 string                `json:"name"`
+	Unit                 string                `json:"unit,omitempty"`
+	Gauge                *gauge                `json:"gauge,omitempty"`
+	Sum                  *sum                  `json:"sum,omitempty"`
+	Summary              *summary              `json:"summary,omitempty"`
+	ExponentialHistogram *exponentialHistogram `json:"exponentialHistogram,omitempty"`
+}
+
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic,omitempty"`
+}
+
+type numberDataPoint struct {
+	StartTimeUnixNano string   `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string   `json:"timeUnixNano"`
+	AsInt             string   `json:"asInt,omitempty"`
+	AsDouble          *float64 `json:"asDouble,omitempty"`
+}
+
+type summary struct {
+	DataPoints []summaryDataPoint `json:"dataPoints"`
+}
+
+type summaryDataPoint struct {
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	Count             string          `json:"count"`
+	Sum               float64         `json:"sum"`
+	QuantileValues    []quantileValue `json:"quantileValues"`
+}
+
+type quantileValue struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+type exponentialHistogram struct {
+	DataPoints             []exponentialHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                             `json:"aggregationTemporality"`
+}
+
+type exponentialHistogramDataPoint struct {
+	StartTimeUnixNano string   `json:"startTimeUnixNano"`
+	TimeUnixNano      string   `json:"timeUnixNano"`
+	Count             string   `json:"count"`
+	Sum               float64  `json:"sum"`
+	Scale             int      `json:"scale"`
+	ZeroCount         string   `json:"zeroCount"`
+	Positive          *buckets `json:"positive,omitempty"`
+	Negative          *buckets `json:"negative,omitempty"`
+}
+
+type buckets struct {
+	Offset       int      `json:"offset"`
+	BucketCounts []string `json:"bucketCounts"`
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package otlp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func init() {
+	metrics.Enabled = true
+}
+
+func TestExport(t *testing.T) {
+	var (
+		requests = make(chan []byte, 1)
+		srv      = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/json" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			requests <- body
+		}))
+	)
+	defer srv.Close()
+
+	r := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("test/counter", r).Inc(3)
+	metrics.NewRegisteredGaugeFloat64("test/gauge", r).Update(1.5)
+	meter := metrics.NewRegisteredMeter("test/meter", r)
+	defer meter.Stop()
+	meter.Mark(7)
+	timer := metrics.NewRegisteredTimer("test/timer", r)
+	defer timer.Stop()
+	timer.Update(time.Second)
+	timer.Update(0)
+	metrics.NewRegisteredResettingTimer("test/resetting", r).Update(time.Millisecond)
+
+	SetResourceAttribute(AttrNodeID, "abcd")
+	defer SetResourceAttribute(AttrNodeID, "")
+
+	rep, err := newReporter(r, time.Second, srv.URL, "geth.", map[string]string{AttrNetworkID: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rep.send(); err != nil {
+		t.Fatal(err)
+	}
+	var req exportRequest
+	if err := json.Unmarshal(<-requests, &req); err != nil {
+		t.Fatal(err)
+	}
+	res := req.ResourceMetrics[0]
+	attrs := make(map[string]string)
+	for _, kv := range res.Resource.Attributes {
+		attrs[kv.Key] = *kv.Value.StringValue
+	}
+	if attrs[AttrNodeID] != "abcd" || attrs[AttrNetworkID] != "1" {
+		t.Errorf("wrong resource attributes: %v", attrs)
+	}
+	ms := make(map[string]metric)
+	for _, m := range res.ScopeMetrics[0].Metrics {
+		ms[m.Name] = m
+	}
+	if len(ms) != 5 {
+		t.Fatalf("wrong number of metrics: %d", len(ms))
+	}
+	if m := ms["geth.test.counter"]; m.Sum == nil || m.Sum.IsMonotonic || m.Sum.DataPoints[0].AsInt != "3" {
+		t.Errorf("wrong counter: %+v", m.Sum)
+	}
+	if m := ms["geth.test.gauge"]; m.Gauge == nil || *m.Gauge.DataPoints[0].AsDouble != 1.5 {
+		t.Errorf("wrong gauge: %+v", m.Gauge)
+	}
+	if m := ms["geth.test.meter"]; m.Sum == nil || !m.Sum.IsMonotonic || m.Sum.DataPoints[0].AsInt != "7" {
+		t.Errorf("wrong meter: %+v", m.Sum)
+	}
+	m := ms["geth.test.timer"]
+	if m.Unit != "ns" || m.ExponentialHistogram == nil {
+		t.Fatalf("wrong timer: %+v", m)
+	}
+	dp := m.ExponentialHistogram.DataPoints[0]
+	if dp.Count != "2" || dp.Sum != float64(time.Second) || dp.ZeroCount != "1" || dp.Scale != 3 {
+		t.Errorf("wrong timer data point: %+v", dp)
+	}
+	// 1e9 is in bucket floor(log2(1e9)*8) = 239 of scale 3.
+	if dp.Positive == nil || dp.Positive.Offset != 239 || len(dp.Positive.BucketCounts) != 1 || dp.Positive.BucketCounts[0] != "1" {
+		t.Errorf("wrong timer buckets: %+v", dp.Positive)
+	}
+	if m := ms["geth.test.resetting"]; m.Summary == nil || m.Summary.DataPoints[0].Count != "1" || m.Summary.DataPoints[0].QuantileValues[0].Value != float64(time.Millisecond) {
+		t.Errorf("wrong resetting timer: %+v", m.Summary)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint, url string
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/metrics"},
+		{"https://collector/", "https://collector/v1/metrics"},
+		{"http://collector/custom/path", "http://collector/custom/path"},
+	}
+	for _, test := range tests {
+		rep, err := newReporter(metrics.NewRegistry(), time.Second, test.endpoint, "", nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.endpoint, err)
+		}
+		if rep.url != test.url {
+			t.Errorf("%s: wrong url %s, want %s", test.endpoint, rep.url, test.url)
+		}
+	}
+	if _, err := newReporter(metrics.NewRegistry(), time.Second, "localhost:4318", "", nil); err == nil {
+		t.Error("no error for endpoint without scheme")
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// DefaultTimerBuckets is the bucket layout of timer families without an
+	// explicitly configured one. Timers record nanoseconds, the bounds range from
+	// ~1µs to ~69s, growing by a factor of 4. The bounds are powers of two, so
+	// they are reported without rounding.
+	DefaultTimerBuckets = exponentialBounds(1<<10, 4, 14)
+
+	// DefaultHistogramBuckets is the bucket layout of histogram families without
+	// an explicitly configured one, ranging from 1 to ~10^9, growing by a factor
+	// of 4.
+	DefaultHistogramBuckets = exponentialBounds(1, 4, 16)
+)
+
+// exponentialBounds returns count bucket bounds, starting at start and each
+// growing by factor.
+func exponentialBounds(start, factor float64, count int) []float64 {
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+var (
+	bucketsLock sync.RWMutex
+	buckets     = make(map[string][]float64)
+)
+
+// SetBuckets sets the bucket bounds used in the OpenMetrics exposition of the
+// histograms and timers of a metric family, given by its name or name prefix
+// (e.g. "rpc/duration" or "chain"). Families use the layout configured for their
+// longest matching prefix. Bounds must be ascending, an empty list restores the
+// default layout.
+func SetBuckets(family string, bounds []float64) error {
+	family = strings.Trim(family, "/")
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			return fmt.Errorf("bucket bounds of %q not ascending", family)
+		}
+	}
+	bucketsLock.Lock()
+	defer bucketsLock.Unlock()
+
+	if len(bounds) == 0 {
+		delete(buckets, family)
+	} else {
+		buckets[family] = append([]float64(nil), bounds...)
+	}
+	return nil
+}
+
+// ParseBuckets parses a list of bucket layouts in the form
+// "family=bound,bound,...;family=bound,...".
+func ParseBuckets(spec string) (map[string][]float64, error) {
+	layouts := make(map[string][]float64)
+	for _, layout := range strings.Split(spec, ";") {
+		layout = strings.TrimSpace(layout)
+		if layout == "" {
+			continue
+		}
+		family, list, ok := strings.Cut(layout, "=")
+		if !ok || strings.TrimSpace(family) == "" {
+			return nil, fmt.Errorf("invalid bucket layout %q, want family=bound,bound,...", layout)
+		}
+		var bounds []float64
+		for _, field := range strings.Split(list, ",") {
+			bound, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bucket bound in layout %q: %v", layout, err)
+			}
+			bounds = append(bounds, bound)
+		}
+		if !sort.Float64sAreSorted(bounds) {
+			return nil, fmt.Errorf("bucket bounds of %q not ascending", family)
+		}
+		layouts[strings.TrimSpace(family)] = bounds
+	}
+	return layouts, nil
+}
+
+// bucketsFor returns the bucket bounds configured for the given family.
+func bucketsFor(family string, timer bool) []float64 {
+	bucketsLock.RLock()
+	defer bucketsLock.RUnlock()
+
+	for prefix := family; prefix != ""; {
+		if bounds, ok := buckets[prefix]; ok {
+			return bounds
+		}
+		i := strings.LastIndexByte(prefix, '/')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	if timer {
+		return DefaultTimerBuckets
+	}
+	return DefaultHistogramBuckets
+}
+
+// labelRule maps metric names matching a pattern into a labelled family.
+type labelRule struct {
+	family   string
+	segments []string // name segments, empty for label captures
+	labels   []string // label names, indexed like segments
+}
+
+// defaultLabelRules are the metrics which encode properties into their names.
+var defaultLabelRules = []struct{ pattern, family string }{
+	{"p2p/ingress/{protocol}/{version}/{code}", "p2p/ingress/messages"},
+	{"p2p/ingress/{protocol}/{version}/{code}/packets", "p2p/ingress/messages/packets"},
+	{"p2p/egress/{protocol}/{version}/{code}", "p2p/egress/messages"},
+	{"p2p/egress/{protocol}/{version}/{code}/packets", "p2p/egress/messages/packets"},
+	{"discover/bucket/{bucket}/count", ""},
+	{"rpc/duration/{method}/{result}", ""},
+	{"rpc/ratelimit/method/{method}/cost", ""},
+	{"rpc/ratelimit/method/{method}/rejected", ""},
+	{"rpc/ratelimit/client/{client}/cost", ""},
+	{"rpc/ratelimit/client/{client}/rejected", ""},
+}
+
+var (
+	labelRulesLock sync.RWMutex
+	labelRules     []*labelRule
+)
+
+func init() {
+	for _, r := range defaultLabelRules {
+		if err := AddLabelRule(r.pattern, r.family); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// AddLabelRule makes the OpenMetrics exposition report the metrics matching the
+// given pattern as labelled series of a single family, instead of a family per
+// metric. Patterns are metric names in which segments of the form {label} match
+// any value, which becomes the value of the label, e.g.
+//
+//	rpc/duration/{method}/{result}
+//
+// The family is named after the pattern without its label segments, unless an
+// explicit name is given. Rules added later take precedence.
+func AddLabelRule(pattern, family string) error {
+	r := &labelRule{family: family}
+	for _, segment := range strings.Split(pattern, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			label := segment[1 : len(segment)-1]
+			if !validLabelName(label) {
+				return fmt.Errorf("invalid label name %q in pattern %q", label, pattern)
+			}
+			r.segments = append(r.segments, "")
+			r.labels = append(r.labels, label)
+			continue
+		}
+		if segment == "" {
+			return fmt.Errorf("empty segment in pattern %q", pattern)
+		}
+		r.segments = append(r.segments, segment)
+		r.labels = append(r.labels, "")
+		if family == "" {
+			r.family = strings.TrimPrefix(r.family+"/"+segment, "/")
+		}
+	}
+	if r.family == "" {
+		return errors.New("label rule pattern without fixed segments")
+	}
+	labelRulesLock.Lock()
+	defer labelRulesLock.Unlock()
+
+	labelRules = append([]*labelRule{r}, labelRules...)
+	return nil
+}
+
+// match returns the family and labels of a metric name, or false if the rule
+// doesn't match it.
+func (r *labelRule) match(name string) (string, []label, bool) {
+	segments := strings.Split(name, "/")
+	if len(segments) != len(r.segments) {
+		return "", nil, false
+	}
+	var labels []label
+	for i, segment := range segments {
+		if r.labels[i] == "" {
+			if segment != r.segments[i] {
+				return "", nil, false
+			}
+			continue
+		}
+		if segment == "" {
+			return "", nil, false
+		}
+		labels = append(labels, label{r.labels[i], segment})
+	}
+	return r.family, labels, true
+}
+
+// familyOf returns the family and labels a metric is reported under.
+func familyOf(name string) (string, []label) {
+	labelRulesLock.RLock()
+	defer labelRulesLock.RUnlock()
+
+	for _, r := range labelRules {
+		if family, labels, ok := r.match(name); ok {
+			return family, labels
+		}
+	}
+	return name, nil
+}
+
+func validLabelName(name string) bool {
+	if name == "" || name == "quantile" || name == "le" {
+		return false
+	}
+	for i, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,282 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// OpenMetricsContentType is the content type of the OpenMetrics text format.
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// OpenMetricsHandler returns an HTTP handler which dumps metrics in the OpenMetrics
+// text format. Unlike Handler, histograms and timers are reported as histograms
+// with cumulative buckets, which can be aggregated across nodes, and metrics
+// which encode properties in their names are reported as labelled series, see
+// AddLabelRule.
+func OpenMetricsHandler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		writeOpenMetrics(&buf, reg)
+
+		w.Header().Add("Content-Type", OpenMetricsContentType)
+		w.Header().Add("Content-Length", fmt.Sprint(buf.Len()))
+		w.Write(buf.Bytes())
+	})
+}
+
+// label is a name/value pair identifying a series within a family.
+type label struct {
+	name, value string
+}
+
+// family is a group of series of the same type reported under a common name.
+type family struct {
+	name   string // metric name of the family, in registry form
+	typ    string
+	series []series
+}
+
+// series is a single labelled metric of a family.
+type series struct {
+	labels []label
+	metric interface{}
+}
+
+// writeOpenMetrics gathers all metrics of the registry into families and writes
+// them in the OpenMetrics format.
+func writeOpenMetrics(buf *bytes.Buffer, reg metrics.Registry) {
+	families := make(map[string]*family)
+	reg.Each(func(name string, i interface{}) {
+		typ := openMetricsType(i)
+		if typ == "" {
+			log.Warn("Unknown OpenMetrics metric type", "type", fmt.Sprintf("%T", i))
+			return
+		}
+		fname, labels := familyOf(name)
+		key := sanitizeName(fname)
+		f := families[key]
+		if f == nil {
+			f = &family{name: fname, typ: typ}
+			families[key] = f
+		}
+		if f.typ != typ {
+			log.Warn("Conflicting OpenMetrics metric types", "family", fname, "metric", name, "type", typ, "want", f.typ)
+			return
+		}
+		f.series = append(f.series, series{labels, i})
+	})
+	keys := make([]string, 0, len(families))
+	for key := range families {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f := families[key]
+		sort.Slice(f.series, func(i, j int) bool {
+			return formatLabels(f.series[i].labels) < formatLabels(f.series[j].labels)
+		})
+		fmt.Fprintf(buf, "# TYPE %s %s\n", key, f.typ)
+		for _, s := range f.series {
+			writeSeries(buf, key, f, s)
+		}
+	}
+	buf.WriteString("# EOF\n")
+}
+
+// openMetricsType returns the OpenMetrics type a metric is reported as.
+func openMetricsType(i interface{}) string {
+	switch m := i.(type) {
+	case metrics.Counter, metrics.CounterFloat64, metrics.Gauge, metrics.GaugeFloat64:
+		// Counters can be decremented, so they are not OpenMetrics counters.
+		return "gauge"
+	case metrics.Meter:
+		return "counter"
+	case metrics.Histogram, metrics.Timer:
+		if _, ok := bucketsOf(m); ok {
+			return "histogram"
+		}
+		return "summary"
+	case metrics.ResettingTimer:
+		return "summary"
+	}
+	return ""
+}
+
+// bucketsOf returns the exponential bucket counts of a histogram or timer
+// snapshot, if it has any.
+func bucketsOf(i interface{}) (*metrics.HistogramBuckets, bool) {
+	var snap interface{}
+	switch m := i.(type) {
+	case metrics.Histogram:
+		snap = m.Snapshot()
+	case metrics.Timer:
+		snap = m.Snapshot()
+	}
+	if h, ok := snap.(metrics.BucketedHistogram); ok && h.Buckets() != nil {
+		return h.Buckets(), true
+	}
+	return nil, false
+}
+
+var summaryQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+
+func writeSeries(buf *bytes.Buffer, name string, f *family, s series) {
+	switch m := s.metric.(type) {
+	case metrics.Counter:
+		writeSample(buf, name, s.labels, m.Snapshot().Count())
+	case metrics.CounterFloat64:
+		writeSample(buf, name, s.labels, m.Snapshot().Count())
+	case metrics.Gauge:
+		writeSample(buf, name, s.labels, m.Snapshot().Value())
+	case metrics.GaugeFloat64:
+		writeSample(buf, name, s.labels, m.Snapshot().Value())
+	case metrics.Meter:
+		// Meter snapshots only include the events of completed ticks, query the
+		// meter itself for an up-to-date count.
+		writeSample(buf, name+"_total", s.labels, m.Count())
+	case metrics.Histogram, metrics.Timer:
+		if buckets, ok := bucketsOf(m); ok {
+			_, timer := m.(metrics.Timer)
+			writeHistogram(buf, name, s.labels, buckets, bucketsFor(f.name, timer))
+			return
+		}
+		var (
+			count, sum int64
+			ps         []float64
+		)
+		if h, ok := m.(metrics.Histogram); ok {
+			snap := h.Snapshot()
+			count, sum, ps = snap.Count(), snap.Sum(), snap.Percentiles(summaryQuantiles)
+		} else {
+			snap := m.(metrics.Timer).Snapshot()
+			count, sum, ps = snap.Count(), snap.Sum(), snap.Percentiles(summaryQuantiles)
+		}
+		for i, q := range summaryQuantiles {
+			writeSample(buf, name, withLabel(s.labels, "quantile", formatFloat(q)), ps[i])
+		}
+		writeSample(buf, name+"_count", s.labels, count)
+		writeSample(buf, name+"_sum", s.labels, sum)
+	case metrics.ResettingTimer:
+		// The values of resetting timers only cover the time since the last
+		// report, so neither their count nor their sum is cumulative. Report
+		// the quantiles only.
+		snap := m.Snapshot()
+		if len(snap.Values()) == 0 {
+			return
+		}
+		qs := []float64{50, 95, 99}
+		ps := snap.Percentiles(qs)
+		for i, q := range qs {
+			writeSample(buf, name, withLabel(s.labels, "quantile", formatFloat(q/100)), ps[i])
+		}
+	}
+}
+
+// writeHistogram writes the cumulative buckets of a histogram, along with the
+// latest exemplar of each bucket. The bounds are reported as rounded to the
+// boundaries of the exponential buckets.
+func writeHistogram(buf *bytes.Buffer, name string, labels []label, buckets *metrics.HistogramBuckets, bounds []float64) {
+	bounds, counts, exemplars := buckets.Cumulative(bounds)
+	for i, count := range counts {
+		le := math.Inf(+1)
+		if i < len(bounds) {
+			le = bounds[i]
+		}
+		buf.WriteString(name + "_bucket" + formatLabels(withLabel(labels, "le", formatFloat(le))))
+		buf.WriteString(" " + strconv.FormatInt(count, 10))
+		if e := exemplars[i]; e != nil {
+			exemplar := []label{{"trace_id", e.TraceID}}
+			fmt.Fprintf(buf, " # %s %s %s", formatLabels(exemplar), formatFloat(e.Value), formatFloat(float64(e.Time.UnixNano())/1e9))
+		}
+		buf.WriteByte('\n')
+	}
+	writeSample(buf, name+"_count", labels, buckets.Count())
+	writeSample(buf, name+"_sum", labels, buckets.Sum())
+}
+
+func writeSample(buf *bytes.Buffer, name string, labels []label, value interface{}) {
+	buf.WriteString(name + formatLabels(labels) + " ")
+	switch v := value.(type) {
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		buf.WriteString(formatFloat(v))
+	}
+	buf.WriteByte('\n')
+}
+
+// withLabel returns a copy of labels with an additional label appended.
+func withLabel(labels []label, name, value string) []label {
+	return append(append(make([]label, 0, len(labels)+1), labels...), label{name, value})
+}
+
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.name + `="` + escapeLabelValue(l.value) + `"`)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sanitizeName converts a metric name of the registry into a valid OpenMetrics
+// metric name.
+func sanitizeName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
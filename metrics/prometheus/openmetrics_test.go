@@ -0,0 +1,167 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func TestOpenMetrics(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	metrics.NewRegisteredCounter("test/counter", r).Inc(12345)
+	metrics.NewRegisteredGaugeFloat64("test/gauge-float64", r).Update(34567.89)
+	meter := metrics.NewRegisteredMeter("test/meter", r)
+	defer meter.Stop()
+	meter.Mark(9999999)
+
+	hist := metrics.NewRegisteredHistogram("test/histogram", r, metrics.NewUniformSample(100))
+	for _, v := range []int64{0, 1, 5, 20, 20} {
+		hist.Update(v)
+	}
+	metrics.UpdateWithExemplar(hist, 3, "4bf92f3577b34da6a3ce929d0e0e4736")
+	if err := SetBuckets("test/histogram", []float64{1, 4, 16}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetBuckets("test/histogram", nil)
+
+	timer := metrics.NewRegisteredTimer("test/timer", r)
+	defer timer.Stop()
+	timer.Update(20 * time.Millisecond)
+
+	// Metrics encoding properties into their names are reported with labels.
+	metrics.NewRegisteredMeter("p2p/ingress/eth/68/0x03", r).Mark(100)
+	metrics.NewRegisteredMeter("p2p/ingress/eth/68/0x01", r).Mark(200)
+	metrics.NewRegisteredMeter("p2p/ingress/eth/68/0x01/packets", r).Mark(2)
+
+	var buf bytes.Buffer
+	writeOpenMetrics(&buf, r)
+	have := buf.String()
+
+	// Exemplar timestamps vary, strip them for the comparison.
+	lines := strings.Split(have, "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, " # {"); j >= 0 {
+			fields := strings.Fields(line[j:])
+			lines[i] = line[:j] + " " + strings.Join(fields[:len(fields)-1], " ")
+		}
+	}
+	have = strings.Join(lines, "\n")
+
+	const want = `# TYPE p2p_ingress_messages counter
+p2p_ingress_messages_total{protocol="eth",version="68",code="0x01"} 200
+p2p_ingress_messages_total{protocol="eth",version="68",code="0x03"} 100
+# TYPE p2p_ingress_messages_packets counter
+p2p_ingress_messages_packets_total{protocol="eth",version="68",code="0x01"} 2
+# TYPE test_counter gauge
+test_counter 12345
+# TYPE test_gauge_float64 gauge
+test_gauge_float64 34567.89
+# TYPE test_histogram histogram
+test_histogram_bucket{le="1"} 2
+test_histogram_bucket{le="4"} 3 # {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"} 3
+test_histogram_bucket{le="16"} 4
+test_histogram_bucket{le="+Inf"} 6
+test_histogram_count 6
+test_histogram_sum 49
+# TYPE test_meter counter
+test_meter_total 9999999
+# TYPE test_timer histogram
+test_timer_bucket{le="1024"} 0
+test_timer_bucket{le="4096"} 0
+test_timer_bucket{le="16384"} 0
+test_timer_bucket{le="65536"} 0
+test_timer_bucket{le="262144"} 0
+test_timer_bucket{le="1.048576e+06"} 0
+test_timer_bucket{le="4.194304e+06"} 0
+test_timer_bucket{le="1.6777216e+07"} 0
+test_timer_bucket{le="6.7108864e+07"} 1
+test_timer_bucket{le="2.68435456e+08"} 1
+test_timer_bucket{le="1.073741824e+09"} 1
+test_timer_bucket{le="4.294967296e+09"} 1
+test_timer_bucket{le="1.7179869184e+10"} 1
+test_timer_bucket{le="6.8719476736e+10"} 1
+test_timer_bucket{le="+Inf"} 1
+test_timer_count 1
+test_timer_sum 2e+07
+# EOF
+`
+	if have != want {
+		t.Fatalf("wrong output:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
+
+func TestParseBuckets(t *testing.T) {
+	layouts, err := ParseBuckets("rpc/duration=100, 1000,1e4; chain=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts) != 2 || len(layouts["rpc/duration"]) != 3 || layouts["rpc/duration"][2] != 1e4 || layouts["chain"][0] != 1 {
+		t.Fatalf("wrong layouts: %v", layouts)
+	}
+	for _, spec := range []string{"rpc/duration", "=1,2", "chain=2,1", "chain=x"} {
+		if _, err := ParseBuckets(spec); err == nil {
+			t.Errorf("no error for invalid layout %q", spec)
+		}
+	}
+}
+
+func TestBucketsFor(t *testing.T) {
+	if err := SetBuckets("chain", []float64{1}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetBuckets("chain", nil)
+
+	if b := bucketsFor("chain/execution", true); len(b) != 1 {
+		t.Errorf("prefix layout not used: %v", b)
+	}
+	if b := bucketsFor("chainx/execution", true); len(b) != len(DefaultTimerBuckets) {
+		t.Errorf("layout applied to unrelated family: %v", b)
+	}
+	if b := bucketsFor("rpc/duration", false); len(b) != len(DefaultHistogramBuckets) {
+		t.Errorf("wrong default layout: %v", b)
+	}
+}
+
+func TestLabelRules(t *testing.T) {
+	tests := []struct {
+		name, family, labels string
+	}{
+		{"p2p/egress/snap/1/0x02/packets", "p2p/egress/messages/packets", `{protocol="snap",version="1",code="0x02"}`},
+		{"rpc/duration/eth_call/success", "rpc/duration", `{method="eth_call",result="success"}`},
+		{"rpc/duration/all", "rpc/duration/all", ""},
+		{"rpc/ratelimit/client/ip:10.0.0.1/rejected", "rpc/ratelimit/client/rejected", `{client="ip:10.0.0.1"}`},
+		{"discover/bucket/3/count", "discover/bucket/count", `{bucket="3"}`},
+	}
+	for _, test := range tests {
+		family, labels := familyOf(test.name)
+		if family != test.family || formatLabels(labels) != test.labels {
+			t.Errorf("%s: have %s%s, want %s%s", test.name, family, formatLabels(labels), test.family, test.labels)
+		}
+	}
+	if err := AddLabelRule("{a}/{b}", ""); err == nil {
+		t.Error("no error for pattern without fixed segments")
+	}
+	if err := AddLabelRule("x/{le}", ""); err == nil {
+		t.Error("no error for reserved label name")
+	}
+}
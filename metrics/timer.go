@@ -233,6 +233,15 @@ func (t *StandardTimer) Update(d time.Duration) {
 	t.meter.Mark(1)
 }
 
+// UpdateWithExemplar records the duration of an event, attaching the given trace
+// ID to it if the underlying histogram supports exemplars.
+func (t *StandardTimer) UpdateWithExemplar(d time.Duration, traceID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	UpdateWithExemplar(t.histogram, int64(d), traceID)
+	t.meter.Mark(1)
+}
+
 // Record the duration of an event that started at a time and ends now.
 func (t *StandardTimer) UpdateSince(ts time.Time) {
 	t.mutex.Lock()
@@ -252,6 +261,10 @@ type TimerSnapshot struct {
 	meter     *MeterSnapshot
 }
 
+// Buckets returns the exponential bucket counts at the time the snapshot was
+// taken.
+func (t *TimerSnapshot) Buckets() *HistogramBuckets { return t.histogram.Buckets() }
+
 // Count returns the number of events recorded at the time the snapshot was
 // taken.
 func (t *TimerSnapshot) Count() int64 { return t.histogram.Count() }
@@ -18,6 +18,7 @@
 package miner
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"sync"
@@ -53,6 +54,8 @@ type Config struct {
 	Recommit  time.Duration  // The time interval for miner to re-create mining work.
 
 	NewPayloadTimeout time.Duration // The maximum time allowance for creating a new payload
+
+	SequencerKey *ecdsa.PrivateKey `toml:"-"` // Key to sign inclusion preconfirmations with (nil = sequencing disabled)
 }
 
 // DefaultConfig contains default settings for miner.
@@ -234,6 +237,22 @@ func (miner *Miner) SetGasCeil(ceil uint64) {
 	miner.worker.setGasCeil(ceil)
 }
 
+// Sequencing reports whether the miner orders transactions first-come-first-served
+// and hands out inclusion preconfirmations for them.
+func (miner *Miner) Sequencing() bool {
+	return miner.worker.sequencer != nil
+}
+
+// Sequence assigns the next free inclusion slot to the given transaction, adds
+// it to the transaction pool and returns the signed promise of including it at
+// the assigned slot. The miner honors the promise when sealing the block.
+func (miner *Miner) Sequence(tx *types.Transaction) (*Preconfirmation, error) {
+	if miner.worker.sequencer == nil {
+		return nil, errSequencerDisabled
+	}
+	return miner.worker.sequencer.sequence(tx)
+}
+
 // SubscribePendingLogs starts delivering logs from pending transactions
 // to the given channel.
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
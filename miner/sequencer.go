@@ -0,0 +1,355 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// maxSequencedBlocks is the maximum number of blocks ahead of the chain head
+// the sequencer is willing to hand out inclusion slots for.
+const maxSequencedBlocks = 64
+
+var (
+	// errSequencerDisabled is returned if a transaction is submitted for sequencing
+	// but the miner was not configured with a sequencer key.
+	errSequencerDisabled = errors.New("sequencer disabled")
+
+	// errSequencerFull is returned if all the blocks the sequencer is willing to
+	// hand out inclusion slots for are already full.
+	errSequencerFull = errors.New("sequencer full")
+
+	// errSequencedNonce is returned if a transaction is submitted for sequencing
+	// which does not directly follow the last pending transaction of its sender,
+	// so its execution at the promised slot could not be guaranteed.
+	errSequencedNonce = errors.New("nonce not sequential")
+
+	// errSequencedGas is returned if a transaction is submitted for sequencing
+	// which does not fit into a block at all.
+	errSequencedGas = errors.New("exceeds block gas limit")
+
+	// errSequencedFailed is returned if a sequenced transaction cannot be executed
+	// at its promised slot, aborting the sealing of the block.
+	errSequencedFailed = errors.New("sequenced transaction failed")
+)
+
+// Preconfirmation is a signed promise of the sequencer to include a transaction
+// in a specific block at a specific position.
+type Preconfirmation struct {
+	ChainID     *big.Int    // Chain the promise is valid on
+	TxHash      common.Hash // Hash of the sequenced transaction
+	BlockNumber uint64      // Number of the block the transaction will be included in
+	Index       uint64      // Position of the transaction within the block
+	Signature   []byte      // 65 byte [R || S || V] signature of the sequencer
+}
+
+// SigHash returns the hash signed by the sequencer to attest the preconfirmation.
+func (p *Preconfirmation) SigHash() common.Hash {
+	blob, _ := rlp.EncodeToBytes([]interface{}{p.ChainID, p.TxHash, p.BlockNumber, p.Index})
+	return crypto.Keccak256Hash(blob)
+}
+
+// Sequencer recovers the address of the sequencer that signed the preconfirmation.
+func (p *Preconfirmation) Sequencer() (common.Address, error) {
+	pubkey, err := crypto.SigToPub(p.SigHash().Bytes(), p.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// sequencedBatch is the list of transactions sequenced into a single block.
+type sequencedBatch struct {
+	txs    []*types.Transaction // Transactions in their promised order
+	gas    uint64               // Gas limit reserved by the sequenced transactions
+	sealed bool                 // Whether the block is being sealed, admitting no new slots
+}
+
+// sequencer orders transactions first-come-first-served into the upcoming blocks
+// and issues signed preconfirmations of the assigned inclusion slots. It is only
+// meaningful on chains where the local node produces all the blocks.
+type sequencer struct {
+	chainConfig *params.ChainConfig
+	chain       *core.BlockChain
+	pool        *txpool.TxPool
+	signer      types.Signer
+	key         *ecdsa.PrivateKey
+	gasCeil     func() uint64 // Retrieves the gas ceiling of the miner
+
+	head     common.Hash                // Chain head the sequencer was last synced to
+	number   uint64                     // Number of the chain head
+	gasLimit uint64                     // Gas limit of the chain head
+	batches  map[uint64]*sequencedBatch // Sequenced transactions of the upcoming blocks
+	nonces   map[common.Address]uint64  // Nonce following the last sequenced transaction of the accounts
+	blocks   map[common.Address]uint64  // Block of the last sequenced transaction of the accounts
+	lock     sync.Mutex
+
+	seqLock sync.Mutex // Serializes sequencing calls, held while adding to the pool but never taken by the miner
+}
+
+// newSequencer creates a sequencer signing its preconfirmations with the given key.
+func newSequencer(chainConfig *params.ChainConfig, chain *core.BlockChain, pool *txpool.TxPool, key *ecdsa.PrivateKey, gasCeil func() uint64) *sequencer {
+	return &sequencer{
+		chainConfig: chainConfig,
+		chain:       chain,
+		pool:        pool,
+		signer:      types.LatestSigner(chainConfig),
+		key:         key,
+		gasCeil:     gasCeil,
+		batches:     make(map[uint64]*sequencedBatch),
+		nonces:      make(map[common.Address]uint64),
+		blocks:      make(map[common.Address]uint64),
+	}
+}
+
+// sync discards the batches of all the blocks already imported into the chain,
+// reporting any promised slots that were not honored. The nonce tracking is also
+// reset to the new chain head. The method assumes the lock is held.
+func (s *sequencer) sync() {
+	head := s.chain.CurrentBlock()
+	if head.Hash() == s.head {
+		return
+	}
+	s.head, s.number, s.gasLimit = head.Hash(), head.Number.Uint64(), head.GasLimit
+
+	for number, batch := range s.batches {
+		if number > s.number {
+			continue
+		}
+		delete(s.batches, number)
+
+		var included types.Transactions
+		if block := s.chain.GetBlockByNumber(number); block != nil {
+			included = block.Transactions()
+		}
+		for i, tx := range batch.txs {
+			if i >= len(included) || included[i].Hash() != tx.Hash() {
+				log.Error("Sequenced transaction not included at promised slot", "number", number, "index", i, "hash", tx.Hash())
+			}
+		}
+	}
+	s.resetNonces()
+}
+
+// resetNonces recomputes the nonce tracking from the sequenced batches. The method
+// assumes the lock is held.
+func (s *sequencer) resetNonces() {
+	s.nonces = make(map[common.Address]uint64)
+	s.blocks = make(map[common.Address]uint64)
+	for number, batch := range s.batches {
+		for _, tx := range batch.txs {
+			from, _ := types.Sender(s.signer, tx) // already validated on sequencing
+			if next := tx.Nonce() + 1; next > s.nonces[from] {
+				s.nonces[from] = next
+				s.blocks[from] = number
+			}
+		}
+	}
+}
+
+// slot finds the first block from the given one not yet being sealed which has
+// enough gas left for the given transactions. The method assumes the lock is held.
+func (s *sequencer) slot(from uint64, gas uint64) (uint64, *sequencedBatch, error) {
+	limit := s.gasLimit
+	for number := s.number + 1; number <= s.number+maxSequencedBlocks; number++ {
+		limit = core.CalcGasLimit(limit, s.gasCeil())
+		if gas > limit {
+			return 0, nil, errSequencedGas
+		}
+		if number < from {
+			continue
+		}
+		batch := s.batches[number]
+		if batch == nil {
+			return number, &sequencedBatch{}, nil
+		}
+		if !batch.sealed && batch.gas+gas <= limit {
+			return number, batch, nil
+		}
+	}
+	return 0, nil, errSequencerFull
+}
+
+// sequence assigns an inclusion slot to a transaction on a first-come-first-served
+// basis, injects it into the transaction pool and returns the signed promise of
+// including it at the assigned slot. Pending pool transactions of the sender that
+// were not sequenced yet are sequenced right before it, as it depends on them.
+func (s *sequencer) sequence(tx *types.Transaction) (*Preconfirmation, error) {
+	s.seqLock.Lock()
+	defer s.seqLock.Unlock()
+
+	// Only accept transactions which directly follow the pending ones of the sender
+	from, err := types.Sender(s.signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	if next := s.pool.Nonce(from); tx.Nonce() != next {
+		return nil, fmt.Errorf("%w: have %d, want %d", errSequencedNonce, tx.Nonce(), next)
+	}
+	// Run the transaction through the pool validation and make it available for
+	// propagation too. Only the sequencing lock is held, which the miner never
+	// takes, while the state lock is not, as the pool may need to wait for the
+	// miner to release it.
+	if err := s.pool.Add([]*txpool.Transaction{{Tx: tx}}, true, true)[0]; err != nil {
+		return nil, err
+	}
+	pending, _ := s.pool.ContentFrom(from)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sync()
+
+	// Collect the unsequenced transactions the new one depends on and commit them
+	// to the slot along with it
+	var (
+		txs []*types.Transaction
+		gas uint64
+	)
+	first, sequenced := s.nonces[from]
+	for _, ptx := range pending {
+		if ptx.Nonce() >= tx.Nonce() {
+			break
+		}
+		if !sequenced || ptx.Nonce() >= first {
+			txs = append(txs, ptx)
+			gas += ptx.Gas()
+		}
+	}
+	txs = append(txs, tx)
+	gas += tx.Gas()
+
+	// The transactions can't precede the previously sequenced ones of the sender
+	number, batch, err := s.slot(s.blocks[from], gas)
+	if err != nil {
+		return nil, err
+	}
+	preconf := &Preconfirmation{
+		ChainID:     s.chainConfig.ChainID,
+		TxHash:      tx.Hash(),
+		BlockNumber: number,
+		Index:       uint64(len(batch.txs) + len(txs) - 1),
+	}
+	if preconf.Signature, err = crypto.Sign(preconf.SigHash().Bytes(), s.key); err != nil {
+		return nil, err
+	}
+	batch.txs = append(batch.txs, txs...)
+	batch.gas += gas
+	s.batches[number] = batch
+	s.nonces[from] = tx.Nonce() + 1
+	s.blocks[from] = number
+
+	log.Debug("Sequenced transaction", "hash", tx.Hash(), "number", number, "index", preconf.Index, "dependencies", len(txs)-1)
+	return preconf, nil
+}
+
+// fail discards a sequenced transaction which could not be executed at its slot,
+// along with all the later sequenced transactions of its sender. Their promises are
+// broken either way, but the remaining batches can still be honored.
+func (s *sequencer) fail(number uint64, hash common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	batch := s.batches[number]
+	if batch == nil {
+		return
+	}
+	var (
+		from  common.Address
+		nonce uint64
+		found bool
+	)
+	for _, tx := range batch.txs {
+		if tx.Hash() == hash {
+			from, _ = types.Sender(s.signer, tx)
+			nonce, found = tx.Nonce(), true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+	for n, batch := range s.batches {
+		if n < number {
+			continue
+		}
+		kept := batch.txs[:0]
+		for _, tx := range batch.txs {
+			if sender, _ := types.Sender(s.signer, tx); sender == from && tx.Nonce() >= nonce {
+				log.Error("Discarding sequenced transaction", "number", n, "hash", tx.Hash())
+				batch.gas -= tx.Gas()
+				continue
+			}
+			kept = append(kept, tx)
+		}
+		batch.txs = kept
+	}
+	s.resetNonces()
+}
+
+// seal marks the batch of the given block as being sealed, assigning any further
+// transactions to subsequent blocks.
+func (s *sequencer) seal(number uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sync()
+	if batch := s.batches[number]; batch != nil {
+		batch.sealed = true
+	} else if number > s.number {
+		s.batches[number] = &sequencedBatch{sealed: true}
+	}
+}
+
+// batch returns the transactions sequenced into the given block in their promised
+// order, along with the set of transactions sequenced into later blocks which
+// must be kept out of the block to not break their own promised slots.
+func (s *sequencer) batch(number uint64) ([]*types.Transaction, map[common.Hash]struct{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sync()
+
+	var (
+		txs   []*types.Transaction
+		later = make(map[common.Hash]struct{})
+	)
+	if batch := s.batches[number]; batch != nil {
+		txs = append(txs, batch.txs...)
+	}
+	for n, batch := range s.batches {
+		if n > number {
+			for _, tx := range batch.txs {
+				later[tx.Hash()] = struct{}{}
+			}
+		}
+	}
+	return txs, later
+}
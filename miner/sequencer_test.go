@@ -0,0 +1,213 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that sequenced transactions are assigned first-come-first-served slots,
+// that the preconfirmations are signed by the sequencer and that the sealing
+// blocks honor the promised slots.
+func TestSequencedInclusion(t *testing.T) {
+	var (
+		db        = rawdb.NewMemoryDatabase()
+		engine    = ethash.NewFaker()
+		key, _    = crypto.GenerateKey()
+		sequencer = crypto.PubkeyToAddress(key.PublicKey)
+		config    = *testConfig
+	)
+	config.SequencerKey = key
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, db, 0)
+	w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(10 * params.InitialBaseFee),
+		})
+	}
+	// Sequence a few transactions and ensure they are lined up in the next block
+	var txs []*types.Transaction
+	for i := uint64(0); i < 3; i++ {
+		tx := newTx(i)
+		preconf, err := w.sequencer.sequence(tx)
+		if err != nil {
+			t.Fatalf("failed to sequence transaction %d: %v", i, err)
+		}
+		if preconf.TxHash != tx.Hash() || preconf.BlockNumber != 1 || preconf.Index != i {
+			t.Fatalf("transaction %d: slot mismatch: have (%d, %d), want (%d, %d)", i, preconf.BlockNumber, preconf.Index, 1, i)
+		}
+		if addr, err := preconf.Sequencer(); err != nil || addr != sequencer {
+			t.Fatalf("transaction %d: signer mismatch: have %x, want %x (err %v)", i, addr, sequencer, err)
+		}
+		txs = append(txs, tx)
+	}
+	// Transactions not directly following the sequenced ones should be rejected
+	if _, err := w.sequencer.sequence(newTx(5)); !errors.Is(err, errSequencedNonce) {
+		t.Fatalf("nonce gap error mismatch: have %v, want %v", err, errSequencedNonce)
+	}
+	// Build the next block, sealing its batch and pushing new slots to the one after
+	timestamp := uint64(time.Now().Unix())
+	genesis := b.chain.Genesis().Hash()
+
+	block, _, err := w.getSealingBlock(genesis, timestamp, testUserAddress, common.Hash{}, nil, false)
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	checkTxs := func(block *types.Block, want []*types.Transaction) {
+		t.Helper()
+
+		if have := block.Transactions(); len(have) != len(want) {
+			t.Fatalf("transaction count mismatch: have %d, want %d", len(have), len(want))
+		}
+		for i, tx := range block.Transactions() {
+			if tx.Hash() != want[i].Hash() {
+				t.Errorf("transaction %d: hash mismatch: have %x, want %x", i, tx.Hash(), want[i].Hash())
+			}
+		}
+	}
+	checkTxs(block, txs)
+
+	late := newTx(3)
+	preconf, err := w.sequencer.sequence(late)
+	if err != nil {
+		t.Fatalf("failed to sequence late transaction: %v", err)
+	}
+	if preconf.BlockNumber != 2 || preconf.Index != 0 {
+		t.Fatalf("late transaction slot mismatch: have (%d, %d), want (2, 0)", preconf.BlockNumber, preconf.Index)
+	}
+	// Rebuilding the sealed block must not pull in the late transaction
+	block, _, err = w.getSealingBlock(genesis, timestamp, testUserAddress, common.Hash{}, nil, false)
+	if err != nil {
+		t.Fatalf("failed to rebuild block: %v", err)
+	}
+	checkTxs(block, txs)
+
+	// Import the block and ensure the late transaction leads the subsequent one
+	if _, err := b.chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to import block: %v", err)
+	}
+	block, _, err = w.getSealingBlock(block.Hash(), timestamp+1, testUserAddress, common.Hash{}, nil, false)
+	if err != nil {
+		t.Fatalf("failed to build subsequent block: %v", err)
+	}
+	checkTxs(block, []*types.Transaction{late})
+}
+
+// Tests that sequencing is refused if the miner has no sequencer key.
+func TestSequencingDisabled(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = ethash.NewFaker()
+	)
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, db, 0)
+	miner := &Miner{worker: newWorker(testConfig, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)}
+	defer miner.worker.close()
+
+	if miner.Sequencing() {
+		t.Fatalf("sequencing enabled without key")
+	}
+	if _, err := miner.Sequence(b.newRandomTx(false)); !errors.Is(err, errSequencerDisabled) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errSequencerDisabled)
+	}
+}
+
+// Tests that pending pool transactions of the sender are sequenced in front of the
+// transaction depending on them, and that a sequenced transaction which fails at
+// its slot aborts the sealing of the block.
+func TestSequencedDependencies(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = ethash.NewFaker()
+		key, _ = crypto.GenerateKey()
+		config = *testConfig
+	)
+	config.SequencerKey = key
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, db, 0)
+	w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(key *ecdsa.PrivateKey, nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(10 * params.InitialBaseFee),
+		})
+	}
+	// Sequence a transaction following an unsequenced pending one
+	pending := newTx(testBankKey, 0)
+	if err := b.txPool.Add([]*txpool.Transaction{{Tx: pending}}, true, true)[0]; err != nil {
+		t.Fatalf("failed to add pending transaction: %v", err)
+	}
+	tx := newTx(testBankKey, 1)
+	preconf, err := w.sequencer.sequence(tx)
+	if err != nil {
+		t.Fatalf("failed to sequence transaction: %v", err)
+	}
+	if preconf.BlockNumber != 1 || preconf.Index != 1 {
+		t.Fatalf("slot mismatch: have (%d, %d), want (1, 1)", preconf.BlockNumber, preconf.Index)
+	}
+	// Break the batch with a transaction which can't be executed
+	unfunded, _ := crypto.GenerateKey()
+	w.sequencer.lock.Lock()
+	w.sequencer.batches[1].txs = append(w.sequencer.batches[1].txs, newTx(unfunded, 0))
+	w.sequencer.lock.Unlock()
+
+	timestamp := uint64(time.Now().Unix())
+	genesis := b.chain.Genesis().Hash()
+	if _, _, err := w.getSealingBlock(genesis, timestamp, testUserAddress, common.Hash{}, nil, false); !errors.Is(err, errSequencedFailed) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errSequencedFailed)
+	}
+	// The failed transaction is discarded, the remaining slots are honored
+	block, _, err := w.getSealingBlock(genesis, timestamp, testUserAddress, common.Hash{}, nil, false)
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	want := []*types.Transaction{pending, tx}
+	if have := block.Transactions(); len(have) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i, tx := range block.Transactions() {
+		if tx.Hash() != want[i].Hash() {
+			t.Errorf("transaction %d: hash mismatch: have %x, want %x", i, tx.Hash(), want[i].Hash())
+		}
+	}
+}
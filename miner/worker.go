@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -220,6 +221,10 @@ type worker struct {
 	// payload in proof-of-stake stage.
 	recommit time.Duration
 
+	// sequencer assigns inclusion slots to transactions first-come-first-served,
+	// which the sealing blocks must honor. It's nil if sequencing is disabled.
+	sequencer *sequencer
+
 	// External functions
 	isLocalBlock func(header *types.Header) bool // Function used to determine whether the specified block is mined by local miner.
 
@@ -277,6 +282,11 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 	}
 	worker.newpayloadTimeout = newpayloadTimeout
 
+	// Enable transaction sequencing if a key was provided to sign the promises.
+	if config.SequencerKey != nil {
+		worker.sequencer = newSequencer(chainConfig, worker.chain, eth.TxPool(), config.SequencerKey, worker.gasCeil)
+		log.Info("Enabled transaction sequencing", "sequencer", crypto.PubkeyToAddress(config.SequencerKey.PublicKey))
+	}
 	worker.wg.Add(4)
 	go worker.mainLoop()
 	go worker.newWorkLoop(recommit)
@@ -310,6 +320,13 @@ func (w *worker) setGasCeil(ceil uint64) {
 	w.config.GasCeil = ceil
 }
 
+// gasCeil retrieves the configured gas ceiling for the sealing blocks.
+func (w *worker) gasCeil() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.config.GasCeil
+}
+
 // setExtra sets the content used to initialize the block extra field.
 func (w *worker) setExtra(extra []byte) {
 	w.mu.Lock()
@@ -744,6 +761,26 @@ func (w *worker) commitTransaction(env *environment, tx *types.Transaction) ([]*
 	return receipt.Logs, nil
 }
 
+// commitSequenced commits the transactions sequenced into the block in their
+// promised order. Unlike pool transactions, these are never interrupted as the
+// sequencer already reserved the gas for them. If any of them fails, the promised
+// slots can't be honored and the block must not be sealed.
+func (w *worker) commitSequenced(env *environment, txs []*types.Transaction) error {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for _, tx := range txs {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx); err != nil {
+			log.Error("Failed to include sequenced transaction", "number", env.header.Number, "index", env.tcount, "hash", tx.Hash(), "err", err)
+			w.sequencer.fail(env.header.Number.Uint64(), tx.Hash())
+			return fmt.Errorf("%w: index %d: %v", errSequencedFailed, env.tcount, err)
+		}
+		env.tcount++
+	}
+	return nil
+}
+
 func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByPriceAndNonce, interrupt *atomic.Int32) error {
 	gasLimit := env.header.GasLimit
 	if env.gasPool == nil {
@@ -881,6 +918,16 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 			header.GasLimit = core.CalcGasLimit(parentGasLimit, w.config.GasCeil)
 		}
 	}
+	// Set the data gas fields if we are past Cancun. Blob transactions are not
+	// included in blocks yet, so no data gas is used.
+	if w.chainConfig.IsCancun(header.Number, header.Time) {
+		var excessDataGas uint64
+		if parent.ExcessDataGas != nil {
+			excessDataGas = misc.CalcExcessDataGas(*parent.ExcessDataGas, *parent.DataGasUsed)
+		}
+		header.ExcessDataGas = &excessDataGas
+		header.DataGasUsed = new(uint64)
+	}
 	// Run the consensus preparation with the default or customized consensus engine.
 	if err := w.engine.Prepare(w.chain, header); err != nil {
 		log.Error("Failed to prepare header for sealing", "err", err)
@@ -894,6 +941,9 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		log.Error("Failed to create sealing context", "err", err)
 		return nil, err
 	}
+	if w.chainConfig.IsPrague(header.Number, header.Time) {
+		core.ProcessParentBlockHash(header.ParentHash, parent.Number.Uint64(), env.state)
+	}
 	return env, nil
 }
 
@@ -905,6 +955,28 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) err
 	// Fill the block with all available pending transactions.
 	pending := w.eth.TxPool().Pending(true)
 
+	// If sequencing is enabled, the sequenced transactions take the head of the
+	// block in their promised order. Any transactions sequenced into later blocks
+	// need to be held back, along with everything after them from the same sender.
+	if w.sequencer != nil {
+		txs, later := w.sequencer.batch(env.header.Number.Uint64())
+		if err := w.commitSequenced(env, txs); err != nil {
+			return err
+		}
+
+		for addr, txs := range pending {
+			for i, tx := range txs {
+				if _, ok := later[tx.Hash()]; ok {
+					if i == 0 {
+						delete(pending, addr)
+					} else {
+						pending[addr] = txs[:i]
+					}
+					break
+				}
+			}
+		}
+	}
 	localTxs, remoteTxs := make(map[common.Address][]*types.Transaction), pending
 	for _, account := range w.eth.TxPool().Locals() {
 		if txs := remoteTxs[account]; len(txs) > 0 {
@@ -936,6 +1008,10 @@ func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, e
 	defer work.discard()
 
 	if !params.noTxs {
+		// Stop handing out slots in this block, as it might be the final payload
+		if w.sequencer != nil {
+			w.sequencer.seal(work.header.Number.Uint64())
+		}
 		interrupt := new(atomic.Int32)
 		timer := time.AfterFunc(w.newpayloadTimeout, func() {
 			interrupt.Store(commitInterruptTimeout)
@@ -946,6 +1022,9 @@ func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, e
 		if errors.Is(err, errBlockInterruptedByTimeout) {
 			log.Warn("Block building is interrupted", "allowance", common.PrettyDuration(w.newpayloadTimeout))
 		}
+		if errors.Is(err, errSequencedFailed) {
+			return nil, nil, err
+		}
 	}
 	block, err := w.engine.FinalizeAndAssemble(w.chain, work.header, work.state, work.txs, nil, work.receipts, params.withdrawals)
 	if err != nil {
@@ -979,6 +1058,10 @@ func (w *worker) commitWork(interrupt *atomic.Int32, timestamp int64) {
 	if err != nil {
 		return
 	}
+	// Stop handing out slots in the block if it's going to be sealed
+	if w.sequencer != nil && w.isRunning() {
+		w.sequencer.seal(work.header.Number.Uint64())
+	}
 	// Fill pending transactions from the txpool into the block.
 	err = w.fillTransactions(interrupt, work)
 	switch {
@@ -1000,6 +1083,11 @@ func (w *worker) commitWork(interrupt *atomic.Int32, timestamp int64) {
 			inc:   true,
 		}
 
+	case errors.Is(err, errSequencedFailed):
+		// The promised slots were broken, try again with the remaining ones.
+		work.discard()
+		return
+
 	case errors.Is(err, errBlockInterruptedByNewHead):
 		// If the block building is interrupted by newhead event, discard it
 		// totally. Committing the interrupted block introduces unnecessary
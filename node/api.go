@@ -176,10 +176,10 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
-		rpcEndpointConfig: rpcEndpointConfig{
+		rpcEndpointConfig: api.node.endpointConfig(rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
-		},
+		}, api.node.config.HTTPAccessControl, api.node.config.HTTPJWTAuth),
 	}
 	if cors != nil {
 		config.CorsAllowedOrigins = nil
@@ -254,10 +254,10 @@ func (api *adminAPI) StartWS(host *string, port *int, allowedOrigins *string, ap
 		Modules: api.node.config.WSModules,
 		Origins: api.node.config.WSOrigins,
 		// ExposeAll: api.node.config.WSExposeAll,
-		rpcEndpointConfig: rpcEndpointConfig{
+		rpcEndpointConfig: api.node.endpointConfig(rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
-		},
+		}, api.node.config.WSAccessControl, api.node.config.WSJWTAuth),
 	}
 	if apis != nil {
 		config.Modules = nil
@@ -130,6 +130,15 @@ type Config struct {
 	// exposed.
 	HTTPModules []string
 
+	// HTTPAccessControl restricts the methods available via the HTTP RPC interface
+	// beyond the module list.
+	HTTPAccessControl rpc.AccessControlConfig `toml:",omitempty"`
+
+	// HTTPJWTAuth requires clients of the HTTP RPC interface to authenticate with a
+	// token signed by the JWT secret, like on the authenticated APIs. This allows
+	// HTTPAccessControl to select rules by the claims of the client's token.
+	HTTPJWTAuth bool `toml:",omitempty"`
+
 	// HTTPTimeouts allows for customization of the timeout values used by the HTTP RPC
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
@@ -147,6 +156,10 @@ type Config struct {
 	// for the authenticated api. This is by default {'localhost'}.
 	AuthVirtualHosts []string `toml:",omitempty"`
 
+	// AuthAccessControl restricts the methods available via the authenticated APIs.
+	// Rules can be keyed by the claims of the client's JWT token.
+	AuthAccessControl rpc.AccessControlConfig `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string
@@ -169,6 +182,14 @@ type Config struct {
 	// exposed.
 	WSModules []string
 
+	// WSAccessControl restricts the methods available via the websocket RPC interface
+	// beyond the module list.
+	WSAccessControl rpc.AccessControlConfig `toml:",omitempty"`
+
+	// WSJWTAuth requires clients of the websocket RPC interface to authenticate with
+	// a token signed by the JWT secret.
+	WSJWTAuth bool `toml:",omitempty"`
+
 	// WSExposeAll exposes all API modules via the WebSocket RPC interface rather
 	// than just the public ones.
 	//
@@ -176,6 +197,30 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// HTTP2Host is the host interface on which to start the HTTP/2 streaming RPC
+	// server. If this field is empty, no HTTP/2 streaming endpoint will be started.
+	HTTP2Host string `toml:",omitempty"`
+
+	// HTTP2Port is the TCP port number on which to start the HTTP/2 streaming RPC
+	// server. It must differ from the ports of the HTTP and websocket servers.
+	HTTP2Port int `toml:",omitempty"`
+
+	// HTTP2PathPrefix specifies a path prefix on which HTTP/2 streams are served.
+	HTTP2PathPrefix string `toml:",omitempty"`
+
+	// HTTP2Modules is a list of API modules to expose via the HTTP/2 streaming RPC
+	// interface. If the module list is empty, all RPC API endpoints designated public
+	// will be exposed.
+	HTTP2Modules []string `toml:",omitempty"`
+
+	// HTTP2AccessControl restricts the methods available via the HTTP/2 streaming RPC
+	// interface beyond the module list.
+	HTTP2AccessControl rpc.AccessControlConfig `toml:",omitempty"`
+
+	// HTTP2JWTAuth requires clients of the HTTP/2 streaming RPC interface to
+	// authenticate with a token signed by the JWT secret.
+	HTTP2JWTAuth bool `toml:",omitempty"`
+
 	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
 	// clients. Please be aware that CORS is a browser enforced security, it's fully
 	// useless for custom HTTP clients.
@@ -204,7 +249,16 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
-	// JWTSecret is the path to the hex-encoded jwt secret.
+	// RPCRateLimit configures the per-method cost accounting and rate limits of the
+	// HTTP and WebSocket endpoints. Rate limiting is disabled if no rate is set.
+	RPCRateLimit rpc.RateLimitConfig `toml:",omitempty"`
+
+	// RPCExecutionTimeouts limits how long method calls on the HTTP, WebSocket and
+	// HTTP/2 endpoints may run. The authenticated endpoints are never limited.
+	RPCExecutionTimeouts rpc.ExecutionTimeouts `toml:",omitempty"`
+
+	// JWTSecret is the path to the hex-encoded jwt secret. It is used by the
+	// authenticated APIs and by the public endpoints which enable JWT authentication.
 	JWTSecret string `toml:",omitempty"`
 
 	// EnablePersonal enables the deprecated personal namespace.
@@ -288,10 +342,19 @@ func DefaultWSEndpoint() string {
 	return config.WSEndpoint()
 }
 
+// HTTP2Endpoint resolves the HTTP/2 streaming endpoint based on the configured
+// host interface and port parameters.
+func (c *Config) HTTP2Endpoint() string {
+	if c.HTTP2Host == "" {
+		return ""
+	}
+	return net.JoinHostPort(c.HTTP2Host, fmt.Sprintf("%d", c.HTTP2Port))
+}
+
 // ExtRPCEnabled returns the indicator whether node enables the external
-// RPC(http, ws or graphql).
+// RPC(http, ws, http2 or graphql).
 func (c *Config) ExtRPCEnabled() bool {
-	return c.HTTPHost != "" || c.WSHost != ""
+	return c.HTTPHost != "" || c.WSHost != "" || c.HTTP2Host != ""
 }
 
 // NodeName returns the devp2p node identifier.
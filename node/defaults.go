@@ -34,6 +34,9 @@ const (
 	DefaultWSPort   = 8546        // Default TCP port for the websocket RPC server
 	DefaultAuthHost = "localhost" // Default host interface for the authenticated apis
 	DefaultAuthPort = 8551        // Default port for the authenticated apis
+
+	DefaultHTTP2Host = "localhost" // Default host interface for the HTTP/2 streaming RPC server
+	DefaultHTTP2Port = 8547        // Default TCP port for the HTTP/2 streaming RPC server
 )
 
 var (
@@ -56,6 +59,8 @@ var DefaultConfig = Config{
 	HTTPTimeouts:         rpc.DefaultHTTPTimeouts,
 	WSPort:               DefaultWSPort,
 	WSModules:            []string{"net", "web3"},
+	HTTP2Port:            DefaultHTTP2Port,
+	HTTP2Modules:         []string{"net", "web3"},
 	BatchRequestLimit:    1000,
 	BatchResponseMaxSize: 25 * 1000 * 1000,
 	GraphQLVirtualHosts:  []string{"localhost"},
@@ -17,10 +17,12 @@
 package node
 
 import (
+	"encoding/json"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -45,7 +47,7 @@ func newJWTHandler(secret []byte, next http.Handler) http.Handler {
 func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	var (
 		strToken string
-		claims   jwt.RegisteredClaims
+		claims   jwtClaims
 	)
 	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
 		strToken = strings.TrimPrefix(auth, "Bearer ")
@@ -75,6 +77,21 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
 		http.Error(out, "future token", http.StatusUnauthorized)
 	default:
-		handler.next.ServeHTTP(out, r)
+		auth := rpc.AuthInfo{Subject: claims.Subject, Claims: claims.all}
+		handler.next.ServeHTTP(out, r.WithContext(rpc.WithAuthInfo(r.Context(), auth)))
 	}
 }
+
+// jwtClaims decodes the registered claims of a token, and also retains all claims,
+// so custom ones can be used for access control.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	all map[string]interface{}
+}
+
+func (c *jwtClaims) UnmarshalJSON(input []byte) error {
+	if err := json.Unmarshal(input, &c.RegisteredClaims); err != nil {
+		return err
+	}
+	return json.Unmarshal(input, &c.all)
+}
@@ -61,9 +61,13 @@ type Node struct {
 	ws            *httpServer //
 	httpAuth      *httpServer //
 	wsAuth        *httpServer //
+	http2         *httpServer //
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
+	rpcPolicy rpc.CallPolicy // Rate limiting policy of the HTTP and WebSocket endpoints
+	jwtSecret []byte         // JWT secret of the authenticated endpoints, loaded by startRPC
+
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
 
@@ -101,6 +105,19 @@ func New(conf *Config) (*Node, error) {
 	if strings.HasSuffix(conf.Name, ".ipc") {
 		return nil, errors.New(`Config.Name cannot end in ".ipc"`)
 	}
+	for name, endpoint := range map[string]struct {
+		acl           rpc.AccessControlConfig
+		authenticated bool
+	}{
+		"HTTPAccessControl":  {conf.HTTPAccessControl, conf.HTTPJWTAuth},
+		"WSAccessControl":    {conf.WSAccessControl, conf.WSJWTAuth},
+		"AuthAccessControl":  {conf.AuthAccessControl, true},
+		"HTTP2AccessControl": {conf.HTTP2AccessControl, conf.HTTP2JWTAuth},
+	} {
+		if err := endpoint.acl.Validate(endpoint.authenticated); err != nil {
+			return nil, fmt.Errorf("Config.%s: %v", name, err)
+		}
+	}
 	server := rpc.NewServer()
 	server.SetBatchLimits(conf.BatchRequestLimit, conf.BatchResponseMaxSize)
 	node := &Node{
@@ -112,6 +129,9 @@ func New(conf *Config) (*Node, error) {
 		server:        &p2p.Server{Config: conf.P2P},
 		databases:     make(map[*closeTrackingDB]struct{}),
 	}
+	if conf.RPCRateLimit.Enabled() {
+		node.rpcPolicy = rpc.NewRateLimiter(conf.RPCRateLimit)
+	}
 
 	// Register built-in APIs.
 	node.rpcAPIs = append(node.rpcAPIs, node.apis()...)
@@ -146,12 +166,16 @@ func New(conf *Config) (*Node, error) {
 	if err := validatePrefix("WebSocket", conf.WSPathPrefix); err != nil {
 		return nil, err
 	}
+	if err := validatePrefix("HTTP/2", conf.HTTP2PathPrefix); err != nil {
+		return nil, err
+	}
 
 	// Configure RPC servers.
 	node.http = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
+	node.http2 = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
 
 	return node, nil
@@ -418,7 +442,7 @@ func (n *Node) startRPC() error {
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
 			prefix:             n.config.HTTPPathPrefix,
-			rpcEndpointConfig:  rpcConfig,
+			rpcEndpointConfig:  n.endpointConfig(rpcConfig, n.config.HTTPAccessControl, n.config.HTTPJWTAuth),
 		}); err != nil {
 			return err
 		}
@@ -435,7 +459,7 @@ func (n *Node) startRPC() error {
 			Modules:           n.config.WSModules,
 			Origins:           n.config.WSOrigins,
 			prefix:            n.config.WSPathPrefix,
-			rpcEndpointConfig: rpcConfig,
+			rpcEndpointConfig: n.endpointConfig(rpcConfig, n.config.WSAccessControl, n.config.WSJWTAuth),
 		}); err != nil {
 			return err
 		}
@@ -443,14 +467,31 @@ func (n *Node) startRPC() error {
 		return nil
 	}
 
-	initAuth := func(port int, secret []byte) error {
+	initHTTP2 := func() error {
+		server := n.http2
+		if err := server.setListenAddr(n.config.HTTP2Host, n.config.HTTP2Port); err != nil {
+			return err
+		}
+		if err := server.enableHTTP2(openAPIs, http2Config{
+			Modules:           n.config.HTTP2Modules,
+			prefix:            n.config.HTTP2PathPrefix,
+			rpcEndpointConfig: n.endpointConfig(rpcConfig, n.config.HTTP2AccessControl, n.config.HTTP2JWTAuth),
+		}); err != nil {
+			return err
+		}
+		servers = append(servers, server)
+		return nil
+	}
+
+	initAuth := func(port int) error {
 		// Enable auth via HTTP
 		server := n.httpAuth
 		if err := server.setListenAddr(n.config.AuthAddr, port); err != nil {
 			return err
 		}
-		sharedConfig := rpcConfig
-		sharedConfig.jwtSecret = secret
+		sharedConfig := n.endpointConfig(rpcConfig, n.config.AuthAccessControl, true)
+		// Engine API calls must not be cut short by the public endpoint limits.
+		sharedConfig.execTimeouts = rpc.ExecutionTimeouts{}
 		if err := server.enableRPC(allAPIs, httpConfig{
 			CorsAllowedOrigins: DefaultAuthCors,
 			Vhosts:             n.config.AuthVirtualHosts,
@@ -479,6 +520,14 @@ func (n *Node) startRPC() error {
 		return nil
 	}
 
+	// Load the JWT secret if any endpoint authenticates its clients.
+	if len(openAPIs) != len(allAPIs) || n.config.HTTPJWTAuth || n.config.WSJWTAuth || n.config.HTTP2JWTAuth {
+		jwtSecret, err := n.obtainJWTSecret(n.config.JWTSecret)
+		if err != nil {
+			return err
+		}
+		n.jwtSecret = jwtSecret
+	}
 	// Set up HTTP.
 	if n.config.HTTPHost != "" {
 		// Configure legacy unauthenticated HTTP.
@@ -493,13 +542,15 @@ func (n *Node) startRPC() error {
 			return err
 		}
 	}
-	// Configure authenticated API
-	if len(openAPIs) != len(allAPIs) {
-		jwtSecret, err := n.obtainJWTSecret(n.config.JWTSecret)
-		if err != nil {
+	// Configure HTTP/2 streaming.
+	if n.config.HTTP2Host != "" {
+		if err := initHTTP2(); err != nil {
 			return err
 		}
-		if err := initAuth(n.config.AuthPort, jwtSecret); err != nil {
+	}
+	// Configure authenticated API
+	if len(openAPIs) != len(allAPIs) {
+		if err := initAuth(n.config.AuthPort); err != nil {
 			return err
 		}
 	}
@@ -512,6 +563,32 @@ func (n *Node) startRPC() error {
 	return nil
 }
 
+// endpointConfig returns the configuration of an RPC endpoint with the given method
+// access rules, which are enforced ahead of the rate limits. If jwtAuth is set, the
+// endpoint requires clients to authenticate using the node's JWT secret.
+func (n *Node) endpointConfig(base rpcEndpointConfig, acl rpc.AccessControlConfig, jwtAuth bool) rpcEndpointConfig {
+	base.execTimeouts = n.config.RPCExecutionTimeouts
+	if jwtAuth {
+		base.jwtSecret = n.jwtSecret
+	}
+	var policies rpc.CallPolicies
+	if acl.Enabled() {
+		policies = append(policies, rpc.NewAccessControl(acl))
+	}
+	if n.rpcPolicy != nil {
+		policies = append(policies, n.rpcPolicy)
+	}
+	switch len(policies) {
+	case 0:
+		base.callPolicy = nil
+	case 1:
+		base.callPolicy = policies[0]
+	default:
+		base.callPolicy = policies
+	}
+	return base
+}
+
 func (n *Node) wsServerForPort(port int, authenticated bool) *httpServer {
 	httpServer, wsServer := n.http, n.ws
 	if authenticated {
@@ -528,6 +605,7 @@ func (n *Node) stopRPC() {
 	n.ws.stop()
 	n.httpAuth.stop()
 	n.wsAuth.stop()
+	n.http2.stop()
 	n.ipc.stop()
 	n.stopInProc()
 }
@@ -686,6 +764,11 @@ func (n *Node) WSEndpoint() string {
 	return "ws://" + n.ws.listenAddr() + n.ws.wsConfig.prefix
 }
 
+// HTTP2Endpoint returns the URL of the HTTP/2 streaming server.
+func (n *Node) HTTP2Endpoint() string {
+	return "http://" + n.http2.listenAddr() + n.http2.http2Config.prefix
+}
+
 // HTTPAuthEndpoint returns the URL of the authenticated HTTP server.
 func (n *Node) HTTPAuthEndpoint() string {
 	return "http://" + n.httpAuth.listenAddr()
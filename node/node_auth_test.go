@@ -20,9 +20,11 @@ import (
 	"context"
 	crand "crypto/rand"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -197,6 +199,66 @@ func TestAuthEndpoints(t *testing.T) {
 	}
 }
 
+// TestPublicEndpointJWTAuth checks that client access rules can be used on a public
+// endpoint which authenticates its clients.
+func TestPublicEndpointJWTAuth(t *testing.T) {
+	var secret [32]byte
+	if _, err := crand.Read(secret[:]); err != nil {
+		t.Fatalf("failed to create jwt secret: %v", err)
+	}
+	jwtPath := path.Join(t.TempDir(), "jwt_secret")
+	if err := os.WriteFile(jwtPath, []byte(hexutil.Encode(secret[:])), 0600); err != nil {
+		t.Fatalf("failed to prepare jwt secret file: %v", err)
+	}
+	conf := &Config{
+		HTTPHost:  "127.0.0.1",
+		JWTSecret: jwtPath,
+		HTTPAccessControl: rpc.AccessControlConfig{
+			Deny:    []string{"web3_*"},
+			Clients: map[string]rpc.AccessRule{"reader": {Allow: []string{"web3_clientVersion"}}},
+		},
+	}
+	// Client rules can't be matched without authentication.
+	if _, err := New(conf); err == nil {
+		t.Fatal("client rules accepted on unauthenticated endpoint")
+	}
+	conf.HTTPJWTAuth = true
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create a new node: %v", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("failed to start test node: %v", err)
+	}
+	defer node.Close()
+
+	call := func(claims jwt.MapClaims) (int, string) {
+		t.Helper()
+		var header []string
+		if claims != nil {
+			claims["iat"] = time.Now().Unix()
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret[:])
+			header = []string{"Authorization", "Bearer " + token}
+		}
+		resp := rpcRequest(t, node.HTTPEndpoint(), "web3_clientVersion", header...)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+	if code, _ := call(nil); code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated request: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+	if _, body := call(jwt.MapClaims{}); !strings.Contains(body, `"code":-32601`) {
+		t.Errorf("expected method to be unavailable without subject, got %s", body)
+	}
+	if _, body := call(jwt.MapClaims{"sub": "reader"}); !strings.Contains(body, `"result"`) {
+		t.Errorf("expected method to be available to client, got %s", body)
+	}
+}
+
 func noneAuth(secret [32]byte) rpc.HTTPAuth {
 	return func(header http.Header) error {
 		token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
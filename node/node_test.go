@@ -17,6 +17,7 @@
 package node
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -479,6 +480,45 @@ func TestWebsocketHTTPOnSeparatePort_WSRequest(t *testing.T) {
 	}
 }
 
+func TestNodeHTTP2(t *testing.T) {
+	node, err := New(&Config{
+		HTTPHost:        "127.0.0.1",
+		HTTP2Host:       "127.0.0.1",
+		HTTP2PathPrefix: "/stream",
+		HTTP2AccessControl: rpc.AccessControlConfig{
+			Deny: []string{"rpc_modules"},
+		},
+	})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+	defer node.Close()
+	if err := node.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+	if node.HTTP2Endpoint() == node.HTTPEndpoint()+"/stream" {
+		t.Fatal("HTTP/2 endpoint should be on its own port")
+	}
+	client, err := rpc.DialHTTP2(context.Background(), node.HTTP2Endpoint())
+	if err != nil {
+		t.Fatal("can't dial HTTP/2 endpoint:", err)
+	}
+	defer client.Close()
+
+	var version string
+	if err := client.Call(&version, "web3_clientVersion"); err != nil {
+		t.Fatal("call failed:", err)
+	}
+	if version != node.Server().Name {
+		t.Fatalf("wrong client version %q", version)
+	}
+	// The access rules of the endpoint apply.
+	var modules map[string]string
+	if err := client.Call(&modules, "rpc_modules"); err == nil {
+		t.Fatal("denied method is available")
+	}
+}
+
 type rpcPrefixTest struct {
 	httpPrefix, wsPrefix string
 	// These lists paths on which JSON-RPC should be served / not served.
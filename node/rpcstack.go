@@ -33,6 +33,8 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/rs/cors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // httpConfig is the JSON-RPC/HTTP configuration.
@@ -52,10 +54,19 @@ type wsConfig struct {
 	rpcEndpointConfig
 }
 
+// http2Config is the JSON-RPC/HTTP2 streaming configuration.
+type http2Config struct {
+	Modules []string
+	prefix  string // path prefix on which to mount the stream handler
+	rpcEndpointConfig
+}
+
 type rpcEndpointConfig struct {
 	jwtSecret              []byte // optional JWT secret
 	batchItemLimit         int
 	batchResponseSizeLimit int
+	callPolicy             rpc.CallPolicy // optional, applied to all method calls
+	execTimeouts           rpc.ExecutionTimeouts
 }
 
 type rpcHandler struct {
@@ -81,6 +92,10 @@ type httpServer struct {
 	wsConfig  wsConfig
 	wsHandler atomic.Value // *rpcHandler
 
+	// HTTP/2 stream handler things.
+	http2Config  http2Config
+	http2Handler atomic.Value // *rpcHandler
+
 	// These are set by setListenAddr.
 	endpoint string
 	host     string
@@ -98,6 +113,7 @@ func newHTTPServer(log log.Logger, timeouts rpc.HTTPTimeouts) *httpServer {
 
 	h.httpHandler.Store((*rpcHandler)(nil))
 	h.wsHandler.Store((*rpcHandler)(nil))
+	h.http2Handler.Store((*rpcHandler)(nil))
 	return h
 }
 
@@ -138,12 +154,21 @@ func (h *httpServer) start() error {
 
 	// Initialize the server.
 	h.server = &http.Server{Handler: h}
+	if h.http2Allowed() {
+		// Accept HTTP/2 without TLS, which is what load balancers use to talk to
+		// their backends.
+		h.server.Handler = h2c.NewHandler(h, new(http2.Server))
+	}
 	if h.timeouts != (rpc.HTTPTimeouts{}) {
 		CheckTimeouts(&h.timeouts)
-		h.server.ReadTimeout = h.timeouts.ReadTimeout
 		h.server.ReadHeaderTimeout = h.timeouts.ReadHeaderTimeout
-		h.server.WriteTimeout = h.timeouts.WriteTimeout
 		h.server.IdleTimeout = h.timeouts.IdleTimeout
+		// Streams stay open indefinitely, so the request timeouts can't apply.
+		// Writes to streams are bounded by the write deadlines of the RPC server.
+		if !h.http2Allowed() {
+			h.server.ReadTimeout = h.timeouts.ReadTimeout
+			h.server.WriteTimeout = h.timeouts.WriteTimeout
+		}
 	}
 
 	// Start the server.
@@ -153,6 +178,7 @@ func (h *httpServer) start() error {
 		// configuration so they can be configured another time.
 		h.disableRPC()
 		h.disableWS()
+		h.disableHTTP2()
 		return err
 	}
 	h.listener = listener
@@ -165,6 +191,13 @@ func (h *httpServer) start() error {
 		}
 		h.log.Info("WebSocket enabled", "url", url)
 	}
+	if h.http2Allowed() {
+		url := fmt.Sprintf("http://%v", listener.Addr())
+		if h.http2Config.prefix != "" {
+			url += h.http2Config.prefix
+		}
+		h.log.Info("HTTP/2 streaming enabled", "url", url)
+	}
 	// if server is websocket only, return after logging
 	if !h.rpcAllowed() {
 		return nil
@@ -204,6 +237,12 @@ func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// serve HTTP/2 streams if enabled
+	if stream := h.http2Handler.Load().(*rpcHandler); stream != nil && checkPath(r, h.http2Config.prefix) {
+		stream.ServeHTTP(w, r)
+		return
+	}
+
 	// if http-rpc is enabled, try to serve request
 	rpc := h.httpHandler.Load().(*rpcHandler)
 	if rpc != nil {
@@ -275,6 +314,7 @@ func (h *httpServer) doStop() {
 		h.wsHandler.Store((*rpcHandler)(nil))
 		wsHandler.server.Stop()
 	}
+	h.disableHTTP2()
 
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
@@ -304,6 +344,10 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	if config.callPolicy != nil {
+		srv.SetCallPolicy(config.callPolicy)
+	}
+	srv.SetExecutionTimeouts(config.execTimeouts)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -336,6 +380,10 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	if config.callPolicy != nil {
+		srv.SetCallPolicy(config.callPolicy)
+	}
+	srv.SetExecutionTimeouts(config.execTimeouts)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -369,6 +417,46 @@ func (h *httpServer) disableWS() bool {
 	return ws != nil
 }
 
+// enableHTTP2 turns on JSON-RPC over HTTP/2 streams on the server.
+func (h *httpServer) enableHTTP2(apis []rpc.API, config http2Config) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.http2Allowed() {
+		return fmt.Errorf("JSON-RPC over HTTP/2 is already enabled")
+	}
+	// Create RPC server and handler.
+	srv := rpc.NewServer()
+	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	if config.callPolicy != nil {
+		srv.SetCallPolicy(config.callPolicy)
+	}
+	srv.SetExecutionTimeouts(config.execTimeouts)
+	if err := RegisterApis(apis, config.Modules, srv); err != nil {
+		return err
+	}
+	var handler = srv.StreamHandler()
+	if len(config.jwtSecret) != 0 {
+		handler = newJWTHandler(config.jwtSecret, handler)
+	}
+	h.http2Config = config
+	h.http2Handler.Store(&rpcHandler{
+		Handler: handler,
+		server:  srv,
+	})
+	return nil
+}
+
+// disableHTTP2 disables the HTTP/2 stream handler. This is internal, the caller must hold h.mu.
+func (h *httpServer) disableHTTP2() bool {
+	stream := h.http2Handler.Load().(*rpcHandler)
+	if stream != nil {
+		h.http2Handler.Store((*rpcHandler)(nil))
+		stream.server.Stop()
+	}
+	return stream != nil
+}
+
 // rpcAllowed returns true when JSON-RPC over HTTP is enabled.
 func (h *httpServer) rpcAllowed() bool {
 	return h.httpHandler.Load().(*rpcHandler) != nil
@@ -379,6 +467,11 @@ func (h *httpServer) wsAllowed() bool {
 	return h.wsHandler.Load().(*rpcHandler) != nil
 }
 
+// http2Allowed returns true when JSON-RPC over HTTP/2 streams is enabled.
+func (h *httpServer) http2Allowed() bool {
+	return h.http2Handler.Load().(*rpcHandler) != nil
+}
+
 // isWebsocket checks the header of an http request for a websocket upgrade request.
 func isWebsocket(r *http.Request) bool {
 	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
@@ -449,6 +449,42 @@ func TestJWT(t *testing.T) {
 	srv.stop()
 }
 
+// TestJWTAccessControl checks that method access rules can be selected by the claims
+// of the client's token.
+func TestJWTAccessControl(t *testing.T) {
+	var secret = []byte("secret")
+	acl := rpc.AccessControlConfig{
+		Deny:    []string{"test_*"},
+		Claim:   "scope",
+		Clients: map[string]rpc.AccessRule{"greeter": {Allow: []string{"test_greet"}}},
+	}
+	cfg := rpcEndpointConfig{jwtSecret: secret, callPolicy: rpc.NewAccessControl(acl)}
+	srv := createAndStartServer(t, &httpConfig{rpcEndpointConfig: cfg}, false, nil, nil)
+	defer srv.stop()
+	url := fmt.Sprintf("http://%v", srv.listenAddr())
+
+	call := func(claims testClaim) string {
+		t.Helper()
+		claims["iat"] = time.Now().Unix()
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		resp := rpcRequest(t, url, "test_greet", "Authorization", "Bearer "+token)
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+	if body := call(testClaim{}); !strings.Contains(body, `"code":-32601`) {
+		t.Errorf("expected method to be unavailable without scope, got %s", body)
+	}
+	if body := call(testClaim{"scope": "admin"}); !strings.Contains(body, `"code":-32601`) {
+		t.Errorf("expected method to be unavailable with wrong scope, got %s", body)
+	}
+	if body := call(testClaim{"scope": "read greeter"}); !strings.Contains(body, `"result":"Hello"`) {
+		t.Errorf("expected method to be available with scope, got %s", body)
+	}
+}
+
 func TestGzipHandler(t *testing.T) {
 	type gzipTest struct {
 		name    string
@@ -125,5 +125,8 @@ compile_fuzzer tests/fuzzers/snap  FuzzSRange fuzz_storage_range
 compile_fuzzer tests/fuzzers/snap  FuzzByteCodes fuzz_byte_codes
 compile_fuzzer tests/fuzzers/snap  FuzzTrieNodes fuzz_trie_nodes
 
+# The differential fuzzer needs the internal t8n package, so it lives in cmd/evm
+compile_fuzzer cmd/evm/internal/difftest  Fuzz fuzzDifftest
+
 #TODO: move this to tests/fuzzers, if possible
 compile_fuzzer crypto/blake2b  Fuzz      fuzzBlake2b
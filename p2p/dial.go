@@ -79,6 +79,7 @@ var (
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNetRestrict      = errors.New("not contained in netrestrict list")
 	errNoPort           = errors.New("node does not provide TCP port")
+	errLowScore         = errors.New("reputation score too low")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -116,6 +117,10 @@ type dialScheduler struct {
 	static     map[enode.ID]*dialTask
 	staticPool []*dialTask
 
+	// The seed pool holds the nodes which scored well in previous sessions, best
+	// first. They are dialed ahead of nodes found by discovery.
+	seedPool []*enode.Node
+
 	// The dial history keeps recently dialed nodes. Members of history are not dialed.
 	history      expHeap
 	historyTimer *mclock.Alarm
@@ -133,6 +138,8 @@ type dialConfig struct {
 	maxActiveDials int              // maximum number of active dials
 	netRestrict    *netutil.Netlist // IP netrestrict list, disabled if nil
 	resolver       nodeResolver
+	scoreOf        func(enode.ID) float64 // reputation score lookup, disabled if nil
+	seeds          []*enode.Node          // well-scored nodes to dial first, best first
 	dialer         NodeDialer
 	log            log.Logger
 	clock          mclock.Clock
@@ -166,6 +173,7 @@ func newDialScheduler(config dialConfig, it enode.Iterator, setupFunc dialSetupF
 		setupFunc:    setupFunc,
 		dialing:      make(map[enode.ID]*dialTask),
 		static:       make(map[enode.ID]*dialTask),
+		seedPool:     append([]*enode.Node(nil), cfg.seeds...),
 		peers:        make(map[enode.ID]struct{}),
 		doneCh:       make(chan *dialTask),
 		nodesIn:      make(chan *enode.Node),
@@ -231,6 +239,7 @@ loop:
 		// Launch new dials if slots are available.
 		slots := d.freeDialSlots()
 		slots -= d.startStaticDials(slots)
+		slots -= d.startSeedDials(slots)
 		if slots > 0 {
 			nodesCh = d.nodesIn
 		} else {
@@ -241,7 +250,7 @@ loop:
 
 		select {
 		case node := <-nodesCh:
-			if err := d.checkDial(node); err != nil {
+			if err := d.checkDynDial(node); err != nil {
 				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IP(), "reason", err)
 			} else {
 				d.startDial(newDialTask(node, dynDialedConn))
@@ -396,6 +405,32 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	return nil
 }
 
+// checkDynDial returns an error if discovered node n should not be dialed.
+func (d *dialScheduler) checkDynDial(n *enode.Node) error {
+	if err := d.checkDial(n); err != nil {
+		return err
+	}
+	if d.scoreOf != nil && d.scoreOf(n.ID()) < dialScoreThreshold {
+		return errLowScore
+	}
+	return nil
+}
+
+// startSeedDials starts up to n dynamic dial tasks to nodes of the seed pool.
+func (d *dialScheduler) startSeedDials(n int) (started int) {
+	for started < n && len(d.seedPool) > 0 {
+		node := d.seedPool[0]
+		d.seedPool = d.seedPool[1:]
+		if err := d.checkDynDial(node); err != nil {
+			d.log.Trace("Discarding seed dial candidate", "id", node.ID(), "ip", node.IP(), "reason", err)
+			continue
+		}
+		d.startDial(newDialTask(node, dynDialedConn))
+		started++
+	}
+	return started
+}
+
 // startStaticDials starts n static dial tasks.
 func (d *dialScheduler) startStaticDials(n int) (started int) {
 	for started = 0; started < n && len(d.staticPool) > 0; started++ {
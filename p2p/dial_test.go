@@ -152,6 +152,73 @@ func TestDialSchedNetRestrict(t *testing.T) {
 	})
 }
 
+// This test checks that discovered candidates with a low reputation score are not dialed.
+func TestDialSchedLowScore(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*enode.Node{
+		newNode(uintID(0x01), "127.0.0.1:30303"),
+		newNode(uintID(0x02), "127.0.0.2:30303"),
+		newNode(uintID(0x03), "127.0.0.3:30303"),
+		newNode(uintID(0x04), "127.0.0.4:30303"),
+	}
+	scores := map[enode.ID]float64{
+		nodes[0].ID(): 50,
+		nodes[1].ID(): dialScoreThreshold - 1,
+		nodes[2].ID(): dialScoreThreshold,
+	}
+	config := dialConfig{
+		maxActiveDials: 10,
+		maxDialPeers:   10,
+		scoreOf:        func(id enode.ID) float64 { return scores[id] },
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			discovered:   nodes,
+			wantNewDials: []*enode.Node{nodes[0], nodes[2], nodes[3]},
+		},
+		{
+			succeeded: []enode.ID{
+				nodes[0].ID(),
+				nodes[2].ID(),
+				nodes[3].ID(),
+			},
+		},
+	})
+}
+
+// This test checks that the well-scored nodes of previous sessions are dialed
+// ahead of discovered nodes.
+func TestDialSchedSeeds(t *testing.T) {
+	t.Parallel()
+
+	seeds := []*enode.Node{
+		newNode(uintID(0x01), "127.0.0.1:30303"),
+		newNode(uintID(0x02), "127.0.0.2:30303"),
+		newNode(uintID(0x03), "127.0.0.3:30303"),
+	}
+	discovered := []*enode.Node{
+		newNode(uintID(0x04), "127.0.0.4:30303"),
+		newNode(uintID(0x05), "127.0.0.5:30303"),
+	}
+	config := dialConfig{
+		maxActiveDials: 2,
+		maxDialPeers:   10,
+		seeds:          seeds,
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			discovered:   discovered,
+			wantNewDials: seeds[:2],
+		},
+		{
+			succeeded:    []enode.ID{seeds[0].ID()},
+			failed:       []enode.ID{seeds[1].ID()},
+			wantNewDials: []*enode.Node{seeds[2], discovered[0]},
+		},
+	})
+}
+
 // This test checks that static dials work and obey the limits.
 func TestDialSchedStaticDial(t *testing.T) {
 	t.Parallel()
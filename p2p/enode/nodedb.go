@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -40,6 +41,7 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbScorePrefix  = "score:" // Identifier to prefix peer score entries with
 	dbDiscoverRoot = "v4"
 	dbDiscv5Root   = "v5"
 
@@ -56,9 +58,10 @@ const (
 )
 
 const (
-	dbNodeExpiration = 24 * time.Hour // Time after which an unseen node should be dropped.
-	dbCleanupCycle   = time.Hour      // Time period for running the expiration task.
-	dbVersion        = 9
+	dbNodeExpiration  = 24 * time.Hour     // Time after which an unseen node should be dropped.
+	dbScoreExpiration = 7 * 24 * time.Hour // Time after which an unchanged peer score should be dropped.
+	dbCleanupCycle    = time.Hour          // Time period for running the expiration task.
+	dbVersion         = 9
 )
 
 var (
@@ -315,6 +318,7 @@ func (db *DB) expirer() {
 		select {
 		case <-tick.C:
 			db.expireNodes()
+			db.expireScores()
 		case <-db.quit:
 			return
 		}
@@ -322,7 +326,8 @@ func (db *DB) expirer() {
 }
 
 // expireNodes iterates over the database and deletes all nodes that have not
-// been seen (i.e. received a pong from) for some time.
+// been seen (i.e. received a pong from) for some time. Nodes with a positive
+// reputation score are kept.
 func (db *DB) expireNodes() {
 	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbNodePrefix)), nil)
 	defer it.Release()
@@ -337,7 +342,7 @@ func (db *DB) expireNodes() {
 	)
 	for !atEnd {
 		id, ip, field := splitNodeItemKey(it.Key())
-		if field == dbNodePong {
+		if field == dbNodePong && !db.hasPositiveScore(id) {
 			time, _ := binary.Varint(it.Value())
 			if time > youngestPong {
 				youngestPong = time
@@ -360,6 +365,27 @@ func (db *DB) expireNodes() {
 	}
 }
 
+// hasPositiveScore reports whether a positive reputation score is stored for the
+// node. The records of such nodes are kept until their score expires, so they can
+// be dialed again.
+func (db *DB) hasPositiveScore(id ID) bool {
+	score, updated := db.PeerScore(id)
+	return !updated.IsZero() && score > 0
+}
+
+// expireScores deletes all peer scores which have not been updated for some time.
+func (db *DB) expireScores() {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbScorePrefix)), nil)
+	defer it.Release()
+
+	threshold := time.Now().Add(-dbScoreExpiration)
+	for it.Next() {
+		if _, updated, ok := decodeScore(it.Value()); !ok || updated.Before(threshold) {
+			db.lvl.Delete(it.Key(), nil)
+		}
+	}
+}
+
 // LastPingReceived retrieves the time of the last ping packet received from
 // a remote node.
 func (db *DB) LastPingReceived(id ID, ip net.IP) time.Time {
@@ -427,6 +453,82 @@ func (db *DB) UpdateFindFailsV5(id ID, ip net.IP, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// scoreKey returns the database key of a peer score.
+func scoreKey(id ID) []byte {
+	return append([]byte(dbScorePrefix), id[:]...)
+}
+
+// decodeScore decodes a stored peer score and the time it was stored at.
+func decodeScore(blob []byte) (score int64, updated time.Time, ok bool) {
+	score, n := binary.Varint(blob)
+	if n <= 0 {
+		return 0, time.Time{}, false
+	}
+	stamp, m := binary.Varint(blob[n:])
+	if m <= 0 {
+		return 0, time.Time{}, false
+	}
+	return score, time.Unix(stamp, 0), true
+}
+
+// PeerScore retrieves the reputation score of a node, along with the time it was
+// last updated. The zero time is returned if no score is known.
+func (db *DB) PeerScore(id ID) (int64, time.Time) {
+	blob, err := db.lvl.Get(scoreKey(id), nil)
+	if err != nil {
+		return 0, time.Time{}
+	}
+	score, updated, ok := decodeScore(blob)
+	if !ok {
+		return 0, time.Time{}
+	}
+	return score, updated
+}
+
+// UpdatePeerScore stores the reputation score of a node.
+func (db *DB) UpdatePeerScore(id ID, score int64, updated time.Time) error {
+	blob := make([]byte, 2*binary.MaxVarintLen64)
+	n := binary.PutVarint(blob, score)
+	n += binary.PutVarint(blob[n:], updated.Unix())
+	return db.lvl.Put(scoreKey(id), blob[:n], nil)
+}
+
+// QueryScored retrieves up to n nodes with a stored reputation score of at least
+// minScore, highest scores first. Only nodes whose record is also stored in the
+// database are returned.
+func (db *DB) QueryScored(n int, minScore int64) []*Node {
+	type scored struct {
+		node  *Node
+		score int64
+	}
+	var (
+		found []scored
+		it    = db.lvl.NewIterator(util.BytesPrefix([]byte(dbScorePrefix)), nil)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		score, _, ok := decodeScore(it.Value())
+		if !ok || score < minScore {
+			continue
+		}
+		var id ID
+		copy(id[:], it.Key()[len(dbScorePrefix):])
+		if node := db.Node(id); node != nil {
+			found = append(found, scored{node, score})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].score > found[j].score })
+	if len(found) > n {
+		found = found[:n]
+	}
+	nodes := make([]*Node, len(found))
+	for i := range found {
+		nodes[i] = found[i].node
+	}
+	return nodes
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
@@ -467,3 +467,57 @@ func TestDBExpireV5(t *testing.T) {
 	db.UpdateFindFailsV5(ID{}, ip, 4)
 	db.expireNodes()
 }
+
+func TestDBPeerScore(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	if score, updated := db.PeerScore(ID{1}); score != 0 || !updated.IsZero() {
+		t.Fatalf("unknown node has score %d (updated %v)", score, updated)
+	}
+	var (
+		now   = time.Now().Truncate(time.Second)
+		nodes = nodeDBSeedQueryNodes[2:5]
+	)
+	for i, seed := range nodes {
+		if err := db.UpdateNode(seed.node); err != nil {
+			t.Fatalf("node %d: failed to insert: %v", i, err)
+		}
+	}
+	db.UpdatePeerScore(nodes[0].node.ID(), -20, now)
+	db.UpdatePeerScore(nodes[1].node.ID(), 300, now)
+	db.UpdatePeerScore(nodes[2].node.ID(), 40, now.Add(-dbScoreExpiration-time.Minute))
+	db.UpdatePeerScore(ID{1}, 500, now) // no node record
+
+	if score, updated := db.PeerScore(nodes[0].node.ID()); score != -20 || !updated.Equal(now) {
+		t.Fatalf("wrong score: have %d (updated %v), want -20 (updated %v)", score, updated, now)
+	}
+	scored := db.QueryScored(10, 0)
+	if len(scored) != 2 || scored[0].ID() != nodes[1].node.ID() || scored[1].ID() != nodes[2].node.ID() {
+		t.Fatalf("wrong scored nodes: %v", scored)
+	}
+	if scored := db.QueryScored(1, -100); len(scored) != 1 || scored[0].ID() != nodes[1].node.ID() {
+		t.Fatalf("wrong scored nodes with limit: %v", scored)
+	}
+
+	// Scores which have not been updated for a long time are dropped.
+	db.expireScores()
+	if score, _ := db.PeerScore(nodes[2].node.ID()); score != 0 {
+		t.Fatalf("stale score not expired: %d", score)
+	}
+	if score, _ := db.PeerScore(nodes[1].node.ID()); score != 300 {
+		t.Fatalf("recent score expired: %d", score)
+	}
+
+	// The records of nodes with a positive score outlive the node expiration.
+	for _, seed := range nodes[:2] {
+		db.UpdateLastPongReceived(seed.node.ID(), seed.node.IP(), now.Add(-dbNodeExpiration-time.Minute))
+	}
+	db.expireNodes()
+	if db.Node(nodes[0].node.ID()) != nil {
+		t.Fatal("record of node with negative score not expired")
+	}
+	if db.Node(nodes[1].node.ID()) == nil {
+		t.Fatal("record of node with positive score expired")
+	}
+}
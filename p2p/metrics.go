@@ -57,6 +57,9 @@ var (
 	dialUnexpectedIdentity  = metrics.NewRegisteredMeter("p2p/dials/error/id/unexpected", nil)
 	dialEncHandshakeError   = metrics.NewRegisteredMeter("p2p/dials/error/rlpx/enc", nil)
 	dialProtoHandshakeError = metrics.NewRegisteredMeter("p2p/dials/error/rlpx/proto", nil)
+
+	// inbound peers disconnected in favour of better scoring connections
+	serveEvictionMeter = metrics.NewRegisteredMeter("p2p/serves/evicted", nil)
 )
 
 func init() {
@@ -0,0 +1,197 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// ScoreEvent is a behaviour of a peer observed by a protocol, which is reported to
+// the server in order to adjust the reputation score of the peer.
+type ScoreEvent int
+
+const (
+	// ScoreUseful is reported when the peer answers a request with useful data.
+	ScoreUseful ScoreEvent = iota
+
+	// ScoreTimeout is reported when the peer does not answer a request in time.
+	ScoreTimeout
+
+	// ScoreInvalid is reported when the peer sends invalid or unrequested data.
+	ScoreInvalid
+)
+
+const (
+	// Score adjustments for the reported events.
+	scoreUseful  = 1
+	scoreTimeout = -5
+	scoreInvalid = -50
+
+	// scoreServedUnit is the amount of data a peer needs to serve to
+	// gain a single point of score.
+	scoreServedUnit = 256 * 1024
+
+	// Scores are bounded so that neither good nor bad behaviour is remembered
+	// forever, and decay towards zero with the given half-life.
+	maxScore      = 1000
+	minScore      = -1000
+	scoreHalfLife = 6 * time.Hour
+
+	// dialScoreThreshold is the score below which discovered nodes are not
+	// dialed. Static nodes are always dialed.
+	dialScoreThreshold = -100
+
+	// evictScoreMargin is the minimum score difference by which an inbound
+	// connection must outscore the worst inbound peer in order to replace it
+	// when the inbound slots are full.
+	evictScoreMargin = 10
+
+	// Up to scoreSeedCount nodes with a stored score of at least scoreSeedThreshold
+	// are dialed on startup, ahead of the nodes found by discovery.
+	scoreSeedCount     = 30
+	scoreSeedThreshold = 20
+)
+
+// peerScore tracks the reputation score of a peer.
+type peerScore struct {
+	clock mclock.Clock
+
+	mu      sync.Mutex
+	value   float64
+	updated mclock.AbsTime
+}
+
+func newPeerScore(clock mclock.Clock, value float64) *peerScore {
+	return &peerScore{clock: clock, value: value, updated: clock.Now()}
+}
+
+// add adjusts the score by the given amount.
+func (s *peerScore) add(delta float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.decay()
+	s.value = math.Max(minScore, math.Min(maxScore, s.value+delta))
+}
+
+// get returns the current score.
+func (s *peerScore) get() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.decay()
+	return s.value
+}
+
+func (s *peerScore) decay() {
+	now := s.clock.Now()
+	s.value = decayScore(s.value, time.Duration(now-s.updated))
+	s.updated = now
+}
+
+// decayScore returns the given score, decayed over the elapsed time.
+func decayScore(score float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return score
+	}
+	return score * math.Exp2(-float64(elapsed)/float64(scoreHalfLife))
+}
+
+// Report records a behaviour of the peer, adjusting its reputation score.
+func (p *Peer) Report(ev ScoreEvent) {
+	switch ev {
+	case ScoreUseful:
+		p.score.add(scoreUseful)
+	case ScoreTimeout:
+		p.score.add(scoreTimeout)
+	case ScoreInvalid:
+		p.score.add(scoreInvalid)
+	}
+}
+
+// ReportServed records that the peer served the given amount of data in response
+// to requests, adjusting its reputation score.
+func (p *Peer) ReportServed(bytes int) {
+	if bytes > 0 {
+		p.score.add(float64(bytes) / scoreServedUnit)
+	}
+}
+
+// Score returns the reputation score of the peer. Scores are positive for peers
+// which have been serving useful data, and negative for peers which have been
+// timing out or misbehaving.
+func (p *Peer) Score() float64 {
+	return p.score.get()
+}
+
+// storedScore returns the score of the given node stored in the node database,
+// decayed until now.
+func (srv *Server) storedScore(id enode.ID) float64 {
+	score, updated := srv.nodedb.PeerScore(id)
+	if updated.IsZero() {
+		return 0
+	}
+	return decayScore(float64(score), time.Since(updated))
+}
+
+// storeScore persists the current score of the peer in the node database.
+func (srv *Server) storeScore(p *Peer) {
+	if err := srv.nodedb.UpdatePeerScore(p.ID(), int64(math.Round(p.Score())), time.Now()); err != nil {
+		srv.log.Debug("Failed to store peer score", "id", p.ID(), "err", err)
+	}
+}
+
+// evictionCandidate returns the lowest scoring inbound peer which the given inbound
+// connection should replace because the inbound slots are full, or nil if no peer
+// scores low enough. Only connections with a positive stored score may replace
+// other peers.
+func (srv *Server) evictionCandidate(peers map[enode.ID]*Peer, inboundCount int, c *conn) *Peer {
+	id := c.node.ID()
+	switch {
+	case !c.is(inboundConn) || c.is(trustedConn):
+		return nil
+	case peers[id] != nil || id == srv.localnode.ID():
+		return nil
+	case len(peers) < srv.MaxPeers && inboundCount < srv.maxInboundConns():
+		return nil
+	}
+	stored := srv.storedScore(id)
+	if stored <= 0 {
+		return nil
+	}
+	var (
+		worst      *Peer
+		worstScore float64
+	)
+	for _, p := range peers {
+		if !p.rw.is(inboundConn) || p.rw.is(trustedConn) {
+			continue
+		}
+		if score := p.Score(); worst == nil || score < worstScore {
+			worst, worstScore = p, score
+		}
+	}
+	if worst == nil || worstScore > stored-evictScoreMargin {
+		return nil
+	}
+	return worst
+}
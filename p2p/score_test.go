@@ -0,0 +1,55 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestPeerScore(t *testing.T) {
+	var (
+		clock mclock.Simulated
+		p     = NewPeer(randomID(), "test", nil)
+	)
+	p.score = newPeerScore(&clock, 0)
+
+	p.Report(ScoreUseful)
+	p.Report(ScoreUseful)
+	p.ReportServed(scoreServedUnit * 3)
+	if s := p.Score(); s != 2*scoreUseful+3 {
+		t.Fatalf("wrong score: have %v, want %v", s, 2*scoreUseful+3)
+	}
+	p.Report(ScoreTimeout)
+	if s := p.Score(); s != 2*scoreUseful+3+scoreTimeout {
+		t.Fatalf("wrong score: have %v, want %v", s, 2*scoreUseful+3+scoreTimeout)
+	}
+	// Scores are bounded.
+	for i := 0; i < 100; i++ {
+		p.Report(ScoreInvalid)
+	}
+	if s := p.Score(); s != minScore {
+		t.Fatalf("wrong score: have %v, want %v", s, minScore)
+	}
+	// Scores decay towards zero.
+	clock.Run(scoreHalfLife)
+	if s := p.Score(); math.Abs(s-minScore/2) > 1e-9 {
+		t.Fatalf("wrong decayed score: have %v, want %v", s, minScore/2)
+	}
+}
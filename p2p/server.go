@@ -638,6 +638,8 @@ func (srv *Server) setupDialScheduler() {
 		netRestrict:    srv.NetRestrict,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
+		scoreOf:        srv.storedScore,
+		seeds:          srv.nodedb.QueryScored(scoreSeedCount, scoreSeedThreshold),
 	}
 	if srv.ntab != nil {
 		config.resolver = srv.ntab
@@ -715,6 +717,7 @@ func (srv *Server) run() {
 
 	var (
 		peers        = make(map[enode.ID]*Peer)
+		evicted      = make(map[enode.ID]*Peer) // disconnecting to make room for better peers
 		inboundCount = 0
 		trusted      = make(map[enode.ID]bool, len(srv.TrustedNodes))
 	)
@@ -762,12 +765,31 @@ running:
 				c.flags |= trustedConn
 			}
 			// TODO: track in-progress inbound node IDs (pre-Peer) to avoid dialing them.
-			c.cont <- srv.postHandshakeChecks(peers, inboundCount, c)
+			err := srv.postHandshakeChecks(peers, inboundCount, c)
+			if err == DiscTooManyPeers && srv.evictionCandidate(peers, inboundCount, c) != nil {
+				// The connection may replace a low-scoring peer, which is decided
+				// once it has passed the protocol handshake.
+				err = nil
+			}
+			c.cont <- err
 
 		case c := <-srv.checkpointAddPeer:
 			// At this point the connection is past the protocol handshake.
 			// Its capabilities are known and the remote identity is verified.
 			err := srv.addPeerChecks(peers, inboundCount, c)
+			if err == DiscTooManyPeers {
+				// Make room for the connection if a connected peer scores
+				// sufficiently worse.
+				if p := srv.evictionCandidate(peers, inboundCount, c); p != nil {
+					srv.log.Debug("Evicting low-scoring peer", "id", p.ID(), "score", p.Score(), "for", c.node.ID())
+					delete(peers, p.ID())
+					evicted[p.ID()] = p
+					inboundCount--
+					serveEvictionMeter.Mark(1)
+					p.Disconnect(DiscTooManyPeers)
+					err = srv.addPeerChecks(peers, inboundCount, c)
+				}
+			}
 			if err == nil {
 				// The handshakes are done and it passed all checks.
 				p := srv.launchPeer(c)
@@ -787,12 +809,18 @@ running:
 		case pd := <-srv.delpeer:
 			// A peer disconnected.
 			d := common.PrettyDuration(mclock.Now() - pd.created)
-			delete(peers, pd.ID())
+			if evicted[pd.ID()] == pd.Peer {
+				// Evicted peers were already removed from the peer set.
+				delete(evicted, pd.ID())
+			} else {
+				delete(peers, pd.ID())
+				if pd.Inbound() {
+					inboundCount--
+				}
+			}
 			srv.log.Debug("Removing p2p peer", "peercount", len(peers), "id", pd.ID(), "duration", d, "req", pd.requested, "err", pd.err)
+			srv.storeScore(pd.Peer)
 			srv.dialsched.peerRemoved(pd.rw)
-			if pd.Inbound() {
-				inboundCount--
-			}
 			activePeerGauge.Dec(1)
 		}
 	}
@@ -813,10 +841,15 @@ running:
 	// Wait for peers to shut down. Pending connections and tasks are
 	// not handled here and will terminate soon-ish because srv.quit
 	// is closed.
-	for len(peers) > 0 {
+	for len(peers)+len(evicted) > 0 {
 		p := <-srv.delpeer
 		p.log.Trace("<-delpeer (spindown)")
-		delete(peers, p.ID())
+		srv.storeScore(p.Peer)
+		if evicted[p.ID()] == p.Peer {
+			delete(evicted, p.ID())
+		} else {
+			delete(peers, p.ID())
+		}
 	}
 }
 
@@ -1033,6 +1066,7 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 
 func (srv *Server) launchPeer(c *conn) *Peer {
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.score = newPeerScore(srv.clock, srv.storedScore(c.node.ID()))
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
@@ -300,6 +300,82 @@ func TestServerAtCap(t *testing.T) {
 	}
 }
 
+// This test checks that inbound connections replace low-scoring peers when the
+// inbound slots are full.
+func TestServerEvictLowScore(t *testing.T) {
+	remoteKey := newkey()
+	srv := &Server{
+		Config: Config{
+			PrivateKey:  newkey(),
+			MaxPeers:    3,
+			NoDial:      true,
+			NoDiscovery: true,
+			Logger:      testlog.Logger(t, log.LvlTrace),
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+	defer srv.Stop()
+
+	newconn := func(id enode.ID) *conn {
+		fd, _ := net.Pipe()
+		tx := newTestTransport(&remoteKey.PublicKey, fd, nil)
+		node := enode.SignNull(new(enr.Record), id)
+		return &conn{fd: fd, transport: tx, flags: inboundConn, node: node, cont: make(chan error)}
+	}
+	for i := 0; i < 3; i++ {
+		if err := srv.checkpoint(newconn(randomID()), srv.checkpointAddPeer); err != nil {
+			t.Fatalf("could not add conn %d: %v", i, err)
+		}
+	}
+	// A new connection with a positive stored score does not replace peers with
+	// a neutral score.
+	c := newconn(randomID())
+	srv.nodedb.UpdatePeerScore(c.node.ID(), 5, time.Now())
+	if err := srv.checkpoint(c, srv.checkpointPostHandshake); err != DiscTooManyPeers {
+		t.Fatal("wrong error for insert:", err)
+	}
+	// Peers which have been timing out are only replaced by connections which
+	// have a positive stored score.
+	peers := srv.Peers()
+	worst := peers[0]
+	worst.Report(ScoreTimeout)
+	worst.Report(ScoreTimeout)
+	worst.Report(ScoreTimeout)
+	peers[1].Report(ScoreUseful)
+
+	if err := srv.checkpoint(newconn(randomID()), srv.checkpointPostHandshake); err != DiscTooManyPeers {
+		t.Fatal("wrong error for insert of unscored conn:", err)
+	}
+	if err := srv.checkpoint(c, srv.checkpointPostHandshake); err != nil {
+		t.Fatal("unexpected error for insert:", err)
+	}
+	// The peer is evicted only once the connection is past the protocol handshake.
+	if srv.PeerCount() != 3 {
+		t.Fatal("peer evicted before protocol handshake")
+	}
+	if err := srv.checkpoint(c, srv.checkpointAddPeer); err != nil {
+		t.Fatal("unexpected error for insert:", err)
+	}
+	for _, p := range srv.Peers() {
+		if p.ID() == worst.ID() {
+			t.Fatal("low-scoring peer not evicted")
+		}
+	}
+	// The score of the evicted peer is persisted once it has disconnected.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if score, _ := srv.nodedb.PeerScore(worst.ID()); score == 3*scoreTimeout {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("score of evicted peer not stored")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func TestServerPeerLimits(t *testing.T) {
 	srvkey := newkey()
 	clientkey := newkey()
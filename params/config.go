@@ -300,6 +300,12 @@ type ChainConfig struct {
 	// even without having seen the TTD locally (safer long term).
 	TerminalTotalDifficultyPassed bool `json:"terminalTotalDifficultyPassed,omitempty"`
 
+	// BlockHashWindow is the number of recent block hashes accessible to the
+	// BLOCKHASH opcode from Prague on, when they are served from the history
+	// storage (EIP-2935). Nil means the default of 256, private chains may
+	// extend it up to the size of the history storage.
+	BlockHashWindow *uint64 `json:"blockHashWindow,omitempty"`
+
 	// Various consensus engines
 	Ethash    *EthashConfig `json:"ethash,omitempty"`
 	Clique    *CliqueConfig `json:"clique,omitempty"`
@@ -607,6 +613,9 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	if w := c.BlockHashWindow; w != nil && (*w == 0 || *w > HistoryServeWindow) {
+		return fmt.Errorf("invalid block hash window %d, must be between 1 and %d", *w, HistoryServeWindow)
+	}
 	return nil
 }
 
@@ -678,6 +687,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, headTimestamp) {
 		return newTimestampCompatError("Verkle fork timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
+	if isTimestampForked(c.PragueTime, headTimestamp) && c.BlockHashServeWindow() != newcfg.BlockHashServeWindow() {
+		return newTimestampCompatError("Block hash window", c.PragueTime, newcfg.PragueTime)
+	}
 	return nil
 }
 
@@ -686,6 +698,15 @@ func (c *ChainConfig) BaseFeeChangeDenominator() uint64 {
 	return DefaultBaseFeeChangeDenominator
 }
 
+// BlockHashServeWindow returns the number of recent block hashes accessible to
+// the BLOCKHASH opcode once they are served from the history storage.
+func (c *ChainConfig) BlockHashServeWindow() uint64 {
+	if c.BlockHashWindow != nil {
+		return *c.BlockHashWindow
+	}
+	return BlockHashServeWindow
+}
+
 // ElasticityMultiplier bounds the maximum gas limit an EIP-1559 block may have.
 func (c *ChainConfig) ElasticityMultiplier() uint64 {
 	return DefaultElasticityMultiplier
@@ -109,6 +109,23 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToTime: 9,
 			},
 		},
+		{
+			stored:        &ChainConfig{PragueTime: newUint64(10)},
+			new:           &ChainConfig{PragueTime: newUint64(10), BlockHashWindow: newUint64(512)},
+			headTimestamp: 9,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{PragueTime: newUint64(10)},
+			new:           &ChainConfig{PragueTime: newUint64(10), BlockHashWindow: newUint64(512)},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "Block hash window",
+				StoredTime:   newUint64(10),
+				NewTime:      newUint64(10),
+				RewindToTime: 9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,183 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AccessRule restricts the methods a client may call. Methods are listed by their
+// full name, like "debug_traceTransaction". A pattern of the form "debug_*" matches
+// all methods of a namespace, and "*" matches any method.
+type AccessRule struct {
+	Allow []string `toml:",omitempty"` // If non-empty, only matching methods may be called
+	Deny  []string `toml:",omitempty"` // Matching methods may never be called
+}
+
+// AccessControlConfig configures an AccessControl policy.
+type AccessControlConfig struct {
+	// Allow and Deny apply to all clients which are not matched by any of the
+	// rules in Clients.
+	Allow []string `toml:",omitempty"`
+	Deny  []string `toml:",omitempty"`
+
+	// Claim is the JWT claim used to look up the rules of authenticated clients.
+	// If empty, clients are identified by the subject ('sub') of their token.
+	Claim string `toml:",omitempty"`
+
+	// Clients holds the rules of authenticated clients, keyed by the value of
+	// their claim. The claim may hold multiple values, either as a list or as a
+	// space-separated string like the OAuth 'scope' claim. In that case a method
+	// may be called if any of the matched rules allows it.
+	Clients map[string]AccessRule `toml:",omitempty"`
+}
+
+// Enabled reports whether the configuration restricts any method.
+func (cfg *AccessControlConfig) Enabled() bool {
+	return len(cfg.Allow) > 0 || len(cfg.Deny) > 0 || len(cfg.Clients) > 0
+}
+
+// Validate checks the method patterns of the configuration. Client rules can only
+// be matched on endpoints which authenticate their clients, so they are rejected
+// unless authenticated is set.
+func (cfg *AccessControlConfig) Validate(authenticated bool) error {
+	if len(cfg.Clients) > 0 && !authenticated {
+		return errors.New("client rules require JWT authentication on the endpoint")
+	}
+	check := func(patterns []string) error {
+		for _, p := range patterns {
+			if err := validateMethodPattern(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := check(cfg.Allow); err != nil {
+		return err
+	}
+	if err := check(cfg.Deny); err != nil {
+		return err
+	}
+	for _, rule := range cfg.Clients {
+		if err := check(rule.Allow); err != nil {
+			return err
+		}
+		if err := check(rule.Deny); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateMethodPattern(p string) error {
+	switch {
+	case p == "*":
+		return nil
+	case p == "", strings.Contains(strings.TrimSuffix(p, "_*"), "*"):
+		return fmt.Errorf("invalid method pattern %q", p)
+	case !strings.Contains(p, serviceMethodSeparator):
+		return fmt.Errorf("invalid method pattern %q, expected namespace_method or namespace_*", p)
+	}
+	return nil
+}
+
+// AccessControl is a CallPolicy which enforces method allow and deny lists. Calls of
+// methods which are not permitted fail as if the method did not exist.
+type AccessControl struct {
+	cfg AccessControlConfig
+}
+
+// NewAccessControl creates an access control policy. The configuration should be
+// checked using Validate beforehand.
+func NewAccessControl(cfg AccessControlConfig) *AccessControl {
+	return &AccessControl{cfg: cfg}
+}
+
+// Admit implements CallPolicy.
+func (ac *AccessControl) Admit(call *CallInfo) error {
+	if !ac.permitted(call) {
+		return &methodNotFoundError{method: call.Method}
+	}
+	return nil
+}
+
+// Done implements CallPolicy.
+func (ac *AccessControl) Done(call *CallInfo, elapsed time.Duration) {}
+
+func (ac *AccessControl) permitted(call *CallInfo) bool {
+	var matched bool
+	for _, value := range ac.claimValues(call.Peer.Auth) {
+		rule, ok := ac.cfg.Clients[value]
+		if !ok {
+			continue
+		}
+		if rule.permits(call.Method) {
+			return true
+		}
+		matched = true
+	}
+	if matched {
+		return false
+	}
+	return AccessRule{Allow: ac.cfg.Allow, Deny: ac.cfg.Deny}.permits(call.Method)
+}
+
+// claimValues returns the values of the configured claim in the client's token.
+func (ac *AccessControl) claimValues(auth AuthInfo) []string {
+	if ac.cfg.Claim == "" || ac.cfg.Claim == "sub" {
+		if auth.Subject == "" {
+			return nil
+		}
+		return []string{auth.Subject}
+	}
+	switch v := auth.Claims[ac.cfg.Claim].(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			if s, ok := elem.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// permits reports whether the rule allows calling the given method.
+func (r AccessRule) permits(method string) bool {
+	if matchMethod(r.Deny, method) {
+		return false
+	}
+	return len(r.Allow) == 0 || matchMethod(r.Allow, method)
+}
+
+func matchMethod(patterns []string, method string) bool {
+	for _, p := range patterns {
+		if p == "*" || p == method {
+			return true
+		}
+		if ns := strings.TrimSuffix(p, "*"); ns != p && strings.HasPrefix(method, ns) {
+			return true
+		}
+	}
+	return false
+}
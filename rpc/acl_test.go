@@ -0,0 +1,146 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAccessControl(t *testing.T) {
+	acl := NewAccessControl(AccessControlConfig{
+		Allow: []string{"eth_*", "debug_traceTransaction"},
+		Deny:  []string{"eth_sendRawTransaction"},
+		Claim: "scope",
+		Clients: map[string]AccessRule{
+			"admin":  {Allow: []string{"*"}},
+			"tracer": {Allow: []string{"debug_*"}, Deny: []string{"debug_setHead"}},
+		},
+	})
+	tests := []struct {
+		method  string
+		claims  map[string]interface{}
+		allowed bool
+	}{
+		{method: "eth_blockNumber", allowed: true},
+		{method: "debug_traceTransaction", allowed: true},
+		{method: "eth_sendRawTransaction", allowed: false},
+		{method: "debug_setHead", allowed: false},
+		{method: "admin_peers", allowed: false},
+		// Unknown scopes fall back to the default rules.
+		{method: "eth_blockNumber", claims: map[string]interface{}{"scope": "unknown"}, allowed: true},
+		// Client rules replace the default rules.
+		{method: "debug_traceBlockByNumber", claims: map[string]interface{}{"scope": "tracer"}, allowed: true},
+		{method: "debug_setHead", claims: map[string]interface{}{"scope": "tracer"}, allowed: false},
+		{method: "eth_blockNumber", claims: map[string]interface{}{"scope": "tracer"}, allowed: false},
+		// Any matching rule may permit the call.
+		{method: "debug_setHead", claims: map[string]interface{}{"scope": "tracer admin"}, allowed: true},
+		{method: "admin_peers", claims: map[string]interface{}{"scope": []interface{}{"tracer", "admin"}}, allowed: true},
+	}
+	for i, test := range tests {
+		call := &CallInfo{Method: test.method, Peer: PeerInfo{Auth: AuthInfo{Claims: test.claims}}}
+		err := acl.Admit(call)
+		if test.allowed && err != nil {
+			t.Errorf("test %d: %s denied: %v", i, test.method, err)
+		}
+		if !test.allowed && err == nil {
+			t.Errorf("test %d: %s allowed", i, test.method)
+		}
+	}
+}
+
+func TestAccessControlSubject(t *testing.T) {
+	acl := NewAccessControl(AccessControlConfig{
+		Deny:    []string{"*"},
+		Clients: map[string]AccessRule{"analytics": {Allow: []string{"debug_traceTransaction"}}},
+	})
+	call := &CallInfo{Method: "debug_traceTransaction"}
+	if err := acl.Admit(call); err == nil {
+		t.Fatal("anonymous call allowed")
+	}
+	call.Peer.Auth.Subject = "analytics"
+	if err := acl.Admit(call); err != nil {
+		t.Fatalf("call denied: %v", err)
+	}
+}
+
+func TestAccessControlValidate(t *testing.T) {
+	for _, pattern := range []string{"", "eth", "eth*", "*_call", "e*_call"} {
+		cfg := AccessControlConfig{Clients: map[string]AccessRule{"x": {Deny: []string{pattern}}}}
+		if err := cfg.Validate(true); err == nil {
+			t.Errorf("pattern %q accepted", pattern)
+		}
+	}
+	cfg := AccessControlConfig{Allow: []string{"*", "eth_*", "debug_traceTransaction"}}
+	if err := cfg.Validate(false); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Clients = map[string]AccessRule{"x": {Allow: []string{"eth_*"}}}
+	if err := cfg.Validate(false); err == nil {
+		t.Error("client rules accepted on unauthenticated endpoint")
+	}
+	if err := cfg.Validate(true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type countingPolicy struct {
+	err         error
+	admit, done int
+}
+
+func (p *countingPolicy) Admit(*CallInfo) error { p.admit++; return p.err }
+
+func (p *countingPolicy) Done(*CallInfo, time.Duration) { p.done++ }
+
+func TestCallPolicies(t *testing.T) {
+	first, second := new(countingPolicy), &countingPolicy{err: errors.New("denied")}
+	policies := CallPolicies{first, second}
+	if err := policies.Admit(&CallInfo{Method: "test_echo"}); err == nil {
+		t.Fatal("call admitted")
+	}
+	if first.admit != 1 || first.done != 1 || second.admit != 1 || second.done != 0 {
+		t.Fatalf("wrong policy invocations: first %+v, second %+v", first, second)
+	}
+}
+
+func TestServerAccessControl(t *testing.T) {
+	server := newTestServer()
+	server.SetCallPolicy(NewAccessControl(AccessControlConfig{Deny: []string{"test_echo"}}))
+	defer server.Stop()
+
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result echoResult
+	err = client.Call(&result, "test_echo", "hello", 10, &echoArgs{"world"})
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32601 {
+		t.Fatalf("expected method not found error, got %v", err)
+	}
+	var peer PeerInfo
+	if err := client.Call(&peer, "test_peerInfo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
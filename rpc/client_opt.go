@@ -40,6 +40,8 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	callPolicy         CallPolicy
+	execTimeouts       ExecutionTimeouts
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -16,7 +16,10 @@
 
 package rpc
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // HTTPError is returned by client operations when the HTTP status code of the
 // response is not a 2xx status.
@@ -61,6 +64,7 @@ const (
 	errcodeDefault          = -32000
 	errcodeTimeout          = -32002
 	errcodeResponseTooLarge = -32003
+	errcodeLimitExceeded    = -32005
 	errcodePanic            = -32603
 	errcodeMarshalError     = -32603
 
@@ -81,6 +85,14 @@ func (e *methodNotFoundError) Error() string {
 	return fmt.Sprintf("the method %s does not exist/is not available", e.method)
 }
 
+type executionTimeoutError struct{ timeout time.Duration }
+
+func (e *executionTimeoutError) ErrorCode() int { return errcodeTimeout }
+
+func (e *executionTimeoutError) Error() string {
+	return fmt.Sprintf("execution timeout exceeded (%v)", e.timeout)
+}
+
 type notificationsUnsupportedError struct{}
 
 func (e notificationsUnsupportedError) Error() string {
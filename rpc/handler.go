@@ -25,7 +25,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 // handler handles JSON-RPC messages. There is one handler per connection. Note that
@@ -62,6 +64,8 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	policy               CallPolicy        // optional policy applied to method calls
+	execTimeouts         ExecutionTimeouts // execution time limits of method calls
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -508,8 +512,30 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
+	if callb != h.unsubscribeCb {
+		done, err := h.admit(cp.ctx, msg)
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer done()
+	}
+	ctx, timeout, cancel := h.executionContext(cp.ctx, msg.Method)
+	defer cancel()
+	ctx, span := h.startSpan(ctx, msg)
+
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	answer := h.runMethod(ctx, msg, callb, args)
+
+	// Report calls aborted by their execution timeout as such, instead of passing on
+	// whatever error the method returned when its context was canceled.
+	if answer.Error != nil && timeout > 0 && ctx.Err() == context.DeadlineExceeded && cp.ctx.Err() == nil {
+		answer = msg.errorResponse(&executionTimeoutError{timeout})
+	}
+	if answer.Error != nil {
+		span.SetAttributes(telemetry.Int("rpc.jsonrpc.error_code", answer.Error.Code))
+		span.SetError(answer.Error)
+	}
+	span.End()
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -520,8 +546,14 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		} else {
 			successfulRequestGauge.Inc(1)
 		}
-		rpcServingTimer.UpdateSince(start)
-		updateServeTimeHistogram(msg.Method, answer.Error == nil, time.Since(start))
+		// Link the timings to the trace of the call, if it is recorded.
+		var traceID string
+		if sc := span.Context(); sc.IsValid() && sc.Sampled {
+			traceID = sc.TraceID.String()
+		}
+		elapsed := time.Since(start)
+		metrics.UpdateTimerWithExemplar(rpcServingTimer, elapsed, traceID)
+		updateServeTimeHistogram(msg.Method, answer.Error == nil, elapsed, traceID)
 	}
 
 	return answer
@@ -552,6 +584,12 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	}
 	args = args[1:]
 
+	done, err := h.admit(cp.ctx, msg)
+	if err != nil {
+		return msg.errorResponse(err)
+	}
+	defer done()
+
 	// Install notifier in context so the subscription handler can find it.
 	n := &Notifier{h: h, namespace: namespace}
 	cp.notifiers = append(cp.notifiers, n)
@@ -560,6 +598,52 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	return h.runMethod(ctx, msg, callb, args)
 }
 
+// admit consults the call policy of the handler. It returns an error if the call must
+// be rejected, or otherwise a function to be invoked after the call has finished.
+func (h *handler) admit(ctx context.Context, msg *jsonrpcMessage) (func(), error) {
+	if h.policy == nil {
+		return func() {}, nil
+	}
+	call := &CallInfo{Method: msg.Method, Peer: PeerInfoFromContext(ctx)}
+	if err := h.policy.Admit(call); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	return func() { h.policy.Done(call, time.Since(start)) }, nil
+}
+
+// executionContext derives the context in which a method runs, which is canceled once
+// the execution timeout of the call expires. It also returns the timeout, which is
+// zero if the call is not limited.
+func (h *handler) executionContext(ctx context.Context, method string) (context.Context, time.Duration, context.CancelFunc) {
+	timeout := h.execTimeouts.timeout(method, PeerInfoFromContext(ctx).HTTP.Timeout)
+	if timeout == 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, 0, cancel
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, timeout, cancel
+}
+
+// startSpan starts the trace span of a method call. If the client sent its trace
+// context along with the request, the span becomes part of the client's trace.
+func (h *handler) startSpan(ctx context.Context, msg *jsonrpcMessage) (context.Context, *telemetry.Span) {
+	if !telemetry.Enabled() {
+		return ctx, nil
+	}
+	info := PeerInfoFromContext(ctx)
+	if info.HTTP.Traceparent != "" {
+		if remote, err := telemetry.ParseTraceparent(info.HTTP.Traceparent); err == nil {
+			ctx = telemetry.ContextWithRemoteSpanContext(ctx, remote)
+		}
+	}
+	return telemetry.Start(ctx, msg.Method, telemetry.WithKind(telemetry.KindServer), telemetry.WithAttributes(
+		telemetry.String("rpc.system", "jsonrpc"),
+		telemetry.String("rpc.method", msg.Method),
+		telemetry.String("rpc.transport", info.Transport),
+	))
+}
+
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
 	result, err := callb.call(ctx, msg.Method, args)
@@ -0,0 +1,385 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/telemetry"
+	"golang.org/x/net/http2"
+)
+
+// http2MessageSizeLimit is the maximum size of a single message on a stream.
+const http2MessageSizeLimit = 32 * 1024 * 1024
+
+var errMessageTooLarge = errors.New("message too large")
+
+// StreamHandler returns a handler that serves JSON-RPC over bidirectional HTTP/2
+// streams. Clients open a stream with a POST request and keep writing requests to
+// the request body. Responses and subscription notifications are written to the
+// response body as soon as they are available, so a single stream carries any
+// number of concurrent calls, like a WebSocket connection does. Back-pressure is
+// provided by HTTP/2 flow control.
+//
+// The handler must be served over HTTP/2, e.g. by wrapping it with h2c for
+// unencrypted connections.
+func (s *Server) StreamHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor < 2 {
+			http.Error(w, "HTTP/2 required", http.StatusHTTPVersionNotSupported)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if code, err := validateRequest(r); err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		timeout, err := requestTimeout(r.Header)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+		// Send the response headers right away, the client waits for them
+		// before it starts sending requests.
+		w.Header().Set("content-type", contentType)
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		conn := &http2ServerConn{body: r.Body, w: w, flusher: flusher, aborted: make(chan struct{})}
+		codec := newHTTP2Codec(conn, r.Body)
+		codec.info.RemoteAddr = r.RemoteAddr
+		codec.info.HTTP.Version = r.Proto
+		codec.info.HTTP.Host = r.Host
+		codec.info.HTTP.Origin = r.Header.Get("Origin")
+		codec.info.HTTP.UserAgent = r.Header.Get("User-Agent")
+		codec.info.HTTP.Traceparent = r.Header.Get(telemetry.TraceparentHeader)
+		codec.info.HTTP.Timeout = timeout
+		codec.info.Auth = authInfoFromContext(r.Context())
+
+		done := make(chan struct{})
+		go func() {
+			s.ServeCodec(codec, 0)
+			conn.finish()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-conn.aborted:
+			// Reset the stream, which also unblocks the pending writes. The other
+			// streams of the connection are not affected.
+			panic(http.ErrAbortHandler)
+		}
+	})
+}
+
+// DialHTTP2 creates a new RPC client that communicates with a JSON-RPC server over a
+// bidirectional HTTP/2 stream. Endpoints with the "http" scheme are dialed using
+// HTTP/2 without TLS (h2c), "https" endpoints negotiate HTTP/2 via TLS.
+//
+// The context is used for the initial connection establishment. It does not
+// affect subsequent interactions with the client.
+func DialHTTP2(ctx context.Context, endpoint string, options ...ClientOption) (*Client, error) {
+	cfg := new(clientConfig)
+	for _, opt := range options {
+		opt.applyOption(cfg)
+	}
+	connect, err := newClientTransportHTTP2(endpoint, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(ctx, cfg, connect)
+}
+
+func newClientTransportHTTP2(endpoint string, cfg *clientConfig) (reconnectFunc, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	client := cfg.httpClient
+	if client == nil {
+		transport := new(http2.Transport)
+		switch u.Scheme {
+		case "http":
+			transport.AllowHTTP = true
+			transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			}
+		case "https":
+		default:
+			return nil, fmt.Errorf("no known transport for URL scheme %q", u.Scheme)
+		}
+		client = &http.Client{Transport: transport}
+	}
+	headers := make(http.Header, 2+len(cfg.httpHeaders))
+	headers.Set("accept", contentType)
+	headers.Set("content-type", contentType)
+	for key, values := range cfg.httpHeaders {
+		headers[key] = values
+	}
+
+	connect := func(ctx context.Context) (ServerCodec, error) {
+		// The stream outlives the dial context, so the request gets its own.
+		reqctx, cancel := context.WithCancel(context.Background())
+		body, pipe := io.Pipe()
+		req, err := http.NewRequestWithContext(reqctx, http.MethodPost, endpoint, body)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		req.Header = headers.Clone()
+		if cfg.httpAuth != nil {
+			if err := cfg.httpAuth(req.Header); err != nil {
+				cancel()
+				return nil, err
+			}
+		}
+		type result struct {
+			resp *http.Response
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			resp, err := client.Do(req)
+			done <- result{resp, err}
+		}()
+		var res result
+		select {
+		case res = <-done:
+		case <-ctx.Done():
+			cancel()
+			pipe.Close()
+			return nil, ctx.Err()
+		}
+		if res.err != nil {
+			cancel()
+			pipe.Close()
+			return nil, res.err
+		}
+		if res.resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(io.LimitReader(res.resp.Body, 4096))
+			res.resp.Body.Close()
+			cancel()
+			pipe.Close()
+			return nil, HTTPError{Status: res.resp.Status, StatusCode: res.resp.StatusCode, Body: body}
+		}
+		conn := &http2ClientConn{body: res.resp.Body, pipe: pipe, cancel: cancel, url: endpoint}
+		codec := newHTTP2Codec(conn, res.resp.Body)
+		codec.info.RemoteAddr = endpoint
+		return codec, nil
+	}
+	return connect, nil
+}
+
+// http2Codec is the codec of HTTP/2 streams. It differs from the plain JSON codec
+// only by its peer information, and by limiting the size of incoming messages.
+type http2Codec struct {
+	*jsonCodec
+	info PeerInfo
+}
+
+func newHTTP2Codec(conn interface {
+	io.Writer
+	deadlineCloser
+}, r io.Reader) *http2Codec {
+	var (
+		limiter = &messageLimitReader{r: r}
+		enc     = json.NewEncoder(conn)
+		dec     = json.NewDecoder(limiter)
+	)
+	dec.UseNumber()
+	encode := func(v interface{}, isErrorResponse bool) error {
+		return enc.Encode(v)
+	}
+	decode := func(v interface{}) error {
+		limiter.remaining = http2MessageSizeLimit
+		return dec.Decode(v)
+	}
+	return &http2Codec{
+		jsonCodec: NewFuncCodec(conn, encode, decode).(*jsonCodec),
+		info:      PeerInfo{Transport: "http2"},
+	}
+}
+
+func (c *http2Codec) peerInfo() PeerInfo {
+	return c.info
+}
+
+// messageLimitReader fails reads once the size limit of the current message has
+// been exceeded.
+type messageLimitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (lr *messageLimitReader) Read(p []byte) (int, error) {
+	if lr.remaining <= 0 {
+		return 0, errMessageTooLarge
+	}
+	if int64(len(p)) > lr.remaining {
+		p = p[:lr.remaining]
+	}
+	n, err := lr.r.Read(p)
+	lr.remaining -= int64(n)
+	return n, err
+}
+
+// http2ServerConn is the server side of a stream. It reads requests from the request
+// body and flushes every response written to the response body.
+type http2ServerConn struct {
+	body    io.ReadCloser
+	w       io.Writer
+	flusher http.Flusher
+
+	aborted   chan struct{} // closed when a write misses its deadline
+	abortOnce sync.Once
+
+	mu       sync.Mutex // protects the fields below
+	deadline time.Time
+	finished bool
+	writes   sync.WaitGroup // pending writes, w must not be used after the handler returns
+}
+
+func (c *http2ServerConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	if c.finished {
+		c.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	deadline := c.deadline
+	c.writes.Add(1)
+	c.mu.Unlock()
+	defer c.writes.Done()
+
+	var expired atomic.Bool
+	if !deadline.IsZero() {
+		// The response writer has no deadlines of its own. A write blocked by flow
+		// control is aborted by resetting the stream, as the handler would when
+		// panicking.
+		timer := time.AfterFunc(time.Until(deadline), func() {
+			expired.Store(true)
+			c.abort()
+		})
+		defer timer.Stop()
+	}
+	n, err := c.w.Write(p)
+	if err == nil {
+		c.flusher.Flush()
+	}
+	if expired.Load() {
+		return n, os.ErrDeadlineExceeded
+	}
+	return n, err
+}
+
+// abort prevents any further writes and signals the handler to reset the stream.
+func (c *http2ServerConn) abort() {
+	c.mu.Lock()
+	c.finished = true
+	c.mu.Unlock()
+
+	c.abortOnce.Do(func() { close(c.aborted) })
+}
+
+// Close stops reading requests from the stream.
+func (c *http2ServerConn) Close() error {
+	return c.body.Close()
+}
+
+// SetWriteDeadline sets the deadline of subsequent writes. The stream is reset
+// when a write does not complete in time.
+func (c *http2ServerConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+// finish waits for pending writes and prevents any further ones. It must be called
+// before the handler returns, unless the stream is reset.
+func (c *http2ServerConn) finish() {
+	c.mu.Lock()
+	c.finished = true
+	c.mu.Unlock()
+	c.writes.Wait()
+}
+
+// http2ClientConn is the client side of a stream. Requests are written to a pipe
+// which feeds the request body.
+type http2ClientConn struct {
+	body   io.ReadCloser
+	pipe   *io.PipeWriter
+	cancel context.CancelFunc
+	url    string
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func (c *http2ClientConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.deadline
+	c.mu.Unlock()
+
+	if !deadline.IsZero() {
+		// A write which does not complete in time breaks the stream, as it
+		// would on a network connection.
+		timer := time.AfterFunc(time.Until(deadline), func() {
+			c.pipe.CloseWithError(os.ErrDeadlineExceeded)
+		})
+		defer timer.Stop()
+	}
+	return c.pipe.Write(p)
+}
+
+func (c *http2ClientConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *http2ClientConn) Close() error {
+	c.pipe.Close()
+	err := c.body.Close()
+	c.cancel()
+	return err
+}
+
+func (c *http2ClientConn) RemoteAddr() string {
+	return c.url
+}
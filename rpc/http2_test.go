@@ -0,0 +1,207 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func newHTTP2TestServer(srv *Server) *httptest.Server {
+	return httptest.NewServer(h2c.NewHandler(srv.StreamHandler(), new(http2.Server)))
+}
+
+func TestHTTP2Calls(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ts := newHTTP2TestServer(server)
+	defer ts.Close()
+
+	client, err := DialHTTP2(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Blocked calls must not hold up other calls on the stream.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.CallContext(ctx, nil, "test_sleep", 500*time.Millisecond)
+		}()
+	}
+	start := time.Now()
+	var result echoResult
+	if err := client.CallContext(ctx, &result, "test_echo", "hello", 10, &echoArgs{"world"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (echoResult{"hello", 10, &echoArgs{"world"}}); result.String != want.String || result.Int != want.Int || result.Args.S != want.Args.S {
+		t.Fatalf("wrong result: %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("call took %v, expected it not to wait for other calls", elapsed)
+	}
+	wg.Wait()
+
+	var peer PeerInfo
+	if err := client.Call(&peer, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if peer.Transport != "http2" {
+		t.Errorf("wrong Transport %q", peer.Transport)
+	}
+	if peer.HTTP.Version != "HTTP/2.0" {
+		t.Errorf("wrong HTTP.Version %q", peer.HTTP.Version)
+	}
+	if peer.RemoteAddr == "" {
+		t.Error("RemoteAddr not set")
+	}
+}
+
+func TestHTTP2Subscription(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ts := newHTTP2TestServer(server)
+	defer ts.Close()
+
+	client, err := DialHTTP2(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	nc := make(chan int)
+	count := 10
+	sub, err := client.Subscribe(context.Background(), "nftest", nc, "someSubscription", count, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	for i := 0; i < count; i++ {
+		select {
+		case val := <-nc:
+			if val != i {
+				t.Fatalf("value mismatch: got %d, want %d", val, i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for notification")
+		}
+	}
+	sub.Unsubscribe()
+}
+
+func TestHTTP2RequiresStream(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ts := newHTTP2TestServer(server)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, contentType, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"rpc_modules"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusHTTPVersionNotSupported {
+		t.Fatalf("wrong status code %d for HTTP/1.1 request", resp.StatusCode)
+	}
+
+	// Dialing a non-streaming endpoint fails.
+	ts2 := httptest.NewServer(h2c.NewHandler(http.NotFoundHandler(), new(http2.Server)))
+	defer ts2.Close()
+	_, err = DialHTTP2(context.Background(), ts2.URL)
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected HTTP error, got %v", err)
+	}
+}
+
+func TestHTTP2LargeMessage(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ts := newHTTP2TestServer(server)
+	defer ts.Close()
+
+	client, err := DialHTTP2(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Messages above the limit close the stream.
+	arg := strings.Repeat("x", http2MessageSizeLimit)
+	var result echoResult
+	if err := client.Call(&result, "test_echo", arg, 1); err == nil {
+		t.Fatal("no error for call with oversized message")
+	}
+	// The client reconnects for the next call.
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// blockingWriter simulates a response body which is blocked by flow control until
+// the stream is reset.
+type blockingWriter struct{ reset chan struct{} }
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	<-w.reset
+	return 0, errors.New("stream reset")
+}
+
+func (blockingWriter) Flush() {}
+
+// This test checks that a write blocked by a stalled client signals the handler to
+// reset the stream once the deadline expires, and that no further writes are
+// accepted afterwards.
+func TestHTTP2ServerWriteDeadline(t *testing.T) {
+	w := blockingWriter{make(chan struct{})}
+	conn := &http2ServerConn{body: io.NopCloser(nil), w: w, flusher: w, aborted: make(chan struct{})}
+
+	conn.SetWriteDeadline(time.Now().Add(100 * time.Millisecond))
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Write([]byte("{}"))
+		done <- err
+	}()
+	select {
+	case <-conn.aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not aborted at deadline")
+	}
+	// Resetting the stream unblocks the write.
+	close(w.reset)
+	if err := <-done; !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("wrong error: %v", err)
+	}
+	if _, err := conn.Write([]byte("{}")); err != io.ErrClosedPipe {
+		t.Fatalf("write after abort: %v", err)
+	}
+}
@@ -17,12 +17,18 @@
 package rpc
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 )
 
 func confirmStatusCode(t *testing.T, got, want int) {
@@ -241,3 +247,67 @@ func TestNewContextWithHeaders(t *testing.T) {
 		t.Error("call failed:", err)
 	}
 }
+
+func TestHTTPTraceparent(t *testing.T) {
+	spanFile := filepath.Join(t.TempDir(), "spans.json")
+	stop, err := telemetry.Setup(telemetry.Config{File: spanFile, SampleRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithHeader("traceparent", traceparent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	stop()
+
+	// The span of the call must be a child of the client's span.
+	file, err := os.Open(spanFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var found bool
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						TraceID      string `json:"traceId"`
+						ParentSpanID string `json:"parentSpanId"`
+						Name         string `json:"name"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			t.Fatal(err)
+		}
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					if span.Name != "test_echo" {
+						continue
+					}
+					found = true
+					if span.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || span.ParentSpanID != "00f067aa0ba902b7" {
+						t.Errorf("span not linked to client trace: trace %s, parent %s", span.TraceID, span.ParentSpanID)
+					}
+				}
+			}
+		}
+	}
+	if !found {
+		t.Fatal("no span recorded for call")
+	}
+}
@@ -32,10 +32,20 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// Aggregate call policy metrics.
+	rateLimitCostCounter     = metrics.NewRegisteredCounterFloat64("rpc/ratelimit/cost", nil)
+	rateLimitRejectedCounter = metrics.NewRegisteredCounter("rpc/ratelimit/rejected", nil)
+
+	// rateLimitMethodName and rateLimitClientName are the prefixes of the
+	// per-method and per-client call policy metrics.
+	rateLimitMethodName = "rpc/ratelimit/method"
+	rateLimitClientName = "rpc/ratelimit/client"
 )
 
-// updateServeTimeHistogram tracks the serving time of a remote RPC call.
-func updateServeTimeHistogram(method string, success bool, elapsed time.Duration) {
+// updateServeTimeHistogram tracks the serving time of a remote RPC call. The trace
+// ID, if not empty, is recorded as an exemplar of the serving time.
+func updateServeTimeHistogram(method string, success bool, elapsed time.Duration, traceID string) {
 	note := "success"
 	if !success {
 		note = "failure"
@@ -46,5 +56,26 @@ func updateServeTimeHistogram(method string, success bool, elapsed time.Duration
 			metrics.NewExpDecaySample(1028, 0.015),
 		)
 	}
-	metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(elapsed.Microseconds())
+	metrics.UpdateWithExemplar(metrics.GetOrRegisterHistogramLazy(h, nil, sampler), elapsed.Microseconds(), traceID)
+}
+
+// updateRateLimitMetrics tracks the cost charged for, or the rejection of, a call
+// by the rate limiter. The client is the name of the client's series, which must
+// come from a bounded set.
+func updateRateLimitMetrics(method string, client string, cost float64, rejected bool) {
+	if !metrics.Enabled {
+		return
+	}
+	if rejected {
+		rateLimitRejectedCounter.Inc(1)
+	} else {
+		rateLimitCostCounter.Inc(cost)
+	}
+	for _, prefix := range []string{rateLimitMethodName + "/" + method, rateLimitClientName + "/" + client} {
+		if rejected {
+			metrics.GetOrRegisterCounter(prefix+"/rejected", nil).Inc(1)
+		} else {
+			metrics.GetOrRegisterCounterFloat64(prefix+"/cost", nil).Inc(cost)
+		}
+	}
 }
@@ -0,0 +1,368 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// CallPolicy decides whether method calls may be executed. When set on a Server,
+// the policy is consulted before each call and informed about the runtime of every
+// call it admitted.
+type CallPolicy interface {
+	// Admit is called before the method is executed. A non-nil error aborts
+	// the call, and is sent to the client in place of the result.
+	Admit(call *CallInfo) error
+
+	// Done is called after an admitted call has finished.
+	Done(call *CallInfo, elapsed time.Duration)
+}
+
+// CallInfo describes a method call subject to a CallPolicy.
+type CallInfo struct {
+	Method string   // Name of the method, e.g. "eth_getLogs"
+	Peer   PeerInfo // Connection the call was received on
+}
+
+// CallPolicies combines multiple policies. A call is admitted if all policies admit
+// it, and the policies are consulted in order.
+type CallPolicies []CallPolicy
+
+// Admit implements CallPolicy.
+func (ps CallPolicies) Admit(call *CallInfo) error {
+	for i, p := range ps {
+		if err := p.Admit(call); err != nil {
+			// Release the policies which already admitted the call.
+			for _, admitted := range ps[:i] {
+				admitted.Done(call, 0)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Done implements CallPolicy.
+func (ps CallPolicies) Done(call *CallInfo, elapsed time.Duration) {
+	for _, p := range ps {
+		p.Done(call, elapsed)
+	}
+}
+
+// SetCallPolicy sets the policy applied to all method calls served by the server.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetCallPolicy(policy CallPolicy) {
+	s.callPolicy = policy
+}
+
+// RateLimitConfig configures a RateLimiter.
+//
+// Every call is charged a cost, which is taken from two token buckets: one for the
+// connection the call arrived on, and one shared by all connections of the same client.
+// Clients are identified by the subject of their JWT token if they authenticated, and
+// by their IP address otherwise. A bucket holds at most 'Burst' tokens and is refilled
+// at 'Rate' tokens per second. Setting the rate of a bucket to zero disables it.
+type RateLimitConfig struct {
+	ConnRate    float64 `toml:",omitempty"` // Refill rate of the per-connection bucket
+	ConnBurst   float64 `toml:",omitempty"` // Capacity of the per-connection bucket
+	ClientRate  float64 `toml:",omitempty"` // Refill rate of the per-client bucket
+	ClientBurst float64 `toml:",omitempty"` // Capacity of the per-client bucket
+
+	// DefaultCost is charged for methods that are not listed in MethodCosts.
+	// If zero, calls cost 1.
+	DefaultCost float64            `toml:",omitempty"`
+	MethodCosts map[string]float64 `toml:",omitempty"`
+
+	// RuntimeCost is charged per second of measured method runtime, once the call
+	// has finished. This allows limiting methods whose cost depends on their
+	// parameters, like eth_getLogs.
+	RuntimeCost float64 `toml:",omitempty"`
+}
+
+// Enabled reports whether any of the buckets is configured.
+func (cfg *RateLimitConfig) Enabled() bool {
+	return cfg.ConnRate > 0 || cfg.ClientRate > 0
+}
+
+// cost returns the static cost of a method.
+func (cfg *RateLimitConfig) cost(method string) float64 {
+	if cost, ok := cfg.MethodCosts[method]; ok {
+		return cost
+	}
+	if cfg.DefaultCost > 0 {
+		return cfg.DefaultCost
+	}
+	return 1
+}
+
+// rateLimitSweepInterval is the interval at which idle buckets are dropped.
+const rateLimitSweepInterval = time.Minute
+
+// rateLimitMaxClientMetrics is the number of unauthenticated clients which get
+// metrics of their own. Calls of the clients seen later are accounted to a shared
+// series, so that arbitrary peers can't create unbounded numbers of metrics.
+const rateLimitMaxClientMetrics = 64
+
+// rateLimitOtherClients is the name of the metrics series shared by the clients
+// exceeding rateLimitMaxClientMetrics.
+const rateLimitOtherClients = "ip:other"
+
+// RateLimiter is a CallPolicy which enforces per-connection and per-client token
+// buckets on method calls. Calls arriving through in-process and IPC connections, as
+// well as calls of the engine API made by the consensus client, are not limited.
+type RateLimiter struct {
+	cfg   RateLimitConfig
+	clock mclock.Clock
+
+	mu        sync.Mutex
+	conns     map[string]*tokenBucket
+	clients   map[string]*tokenBucket
+	lastSweep mclock.AbsTime
+
+	metricClients map[string]struct{} // Unauthenticated clients with their own metrics
+}
+
+// NewRateLimiter creates a rate limiting call policy.
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	return newRateLimiter(cfg, mclock.System{})
+}
+
+func newRateLimiter(cfg RateLimitConfig, clock mclock.Clock) *RateLimiter {
+	return &RateLimiter{
+		cfg:       cfg,
+		clock:     clock,
+		conns:     make(map[string]*tokenBucket),
+		clients:   make(map[string]*tokenBucket),
+		lastSweep: clock.Now(),
+
+		metricClients: make(map[string]struct{}),
+	}
+}
+
+// Admit implements CallPolicy.
+func (l *RateLimiter) Admit(call *CallInfo) error {
+	if !l.limited(call) {
+		return nil
+	}
+	var (
+		cost   = l.cfg.cost(call.Method)
+		client = clientKey(call.Peer)
+	)
+	l.mu.Lock()
+	now := l.clock.Now()
+	l.sweep(now)
+	conn, cl := l.buckets(call.Peer, client, now)
+
+	// Both buckets must have capacity before anything is taken from either.
+	var err error
+	if wait := conn.wait(cost, now); wait > 0 {
+		err = &rateLimitError{scope: "connection", retryAfter: wait}
+	} else if wait := cl.wait(cost, now); wait > 0 {
+		err = &rateLimitError{scope: "client", retryAfter: wait}
+	} else {
+		conn.take(cost)
+		cl.take(cost)
+	}
+	series := l.metricClient(call.Peer, client)
+	l.mu.Unlock()
+
+	if err != nil {
+		updateRateLimitMetrics(call.Method, series, 0, true)
+		return err
+	}
+	updateRateLimitMetrics(call.Method, series, cost, false)
+	return nil
+}
+
+// Done implements CallPolicy. It charges the runtime cost of the call, which may
+// take the buckets below zero. Subsequent calls are then rejected until the debt
+// has been refilled.
+func (l *RateLimiter) Done(call *CallInfo, elapsed time.Duration) {
+	if !l.limited(call) || l.cfg.RuntimeCost == 0 {
+		return
+	}
+	var (
+		cost   = l.cfg.RuntimeCost * elapsed.Seconds()
+		client = clientKey(call.Peer)
+	)
+	l.mu.Lock()
+	conn, cl := l.buckets(call.Peer, client, l.clock.Now())
+	conn.take(cost)
+	cl.take(cost)
+	series := l.metricClient(call.Peer, client)
+	l.mu.Unlock()
+
+	updateRateLimitMetrics(call.Method, series, cost, false)
+}
+
+// metricClient returns the name of the metrics series of a client. Authenticated
+// clients are named by their JWT subject, as only holders of the secret can pick
+// one. Unauthenticated clients are named by their IP address, up to a limit.
+// This assumes l.mu is held.
+func (l *RateLimiter) metricClient(peer PeerInfo, client string) string {
+	if peer.Auth.Subject != "" {
+		return client
+	}
+	if _, ok := l.metricClients[client]; ok {
+		return client
+	}
+	if len(l.metricClients) >= rateLimitMaxClientMetrics {
+		return rateLimitOtherClients
+	}
+	l.metricClients[client] = struct{}{}
+	return client
+}
+
+// buckets returns the connection and client buckets of a call, refilled up to now.
+// This assumes l.mu is held.
+func (l *RateLimiter) buckets(peer PeerInfo, client string, now mclock.AbsTime) (*tokenBucket, *tokenBucket) {
+	conn := l.conns[peer.Transport+"/"+peer.RemoteAddr]
+	if conn == nil {
+		conn = newTokenBucket(l.cfg.ConnRate, l.cfg.ConnBurst, now)
+		l.conns[peer.Transport+"/"+peer.RemoteAddr] = conn
+	}
+	cl := l.clients[client]
+	if cl == nil {
+		cl = newTokenBucket(l.cfg.ClientRate, l.cfg.ClientBurst, now)
+		l.clients[client] = cl
+	}
+	conn.refill(now)
+	cl.refill(now)
+	return conn, cl
+}
+
+// sweep drops all buckets that have been refilled completely, as they are
+// indistinguishable from new ones. This assumes l.mu is held.
+func (l *RateLimiter) sweep(now mclock.AbsTime) {
+	if time.Duration(now-l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for _, buckets := range []map[string]*tokenBucket{l.conns, l.clients} {
+		for key, b := range buckets {
+			if b.refill(now); b.full() {
+				delete(buckets, key)
+			}
+		}
+	}
+}
+
+// limited reports whether a call is subject to rate limiting.
+func (l *RateLimiter) limited(call *CallInfo) bool {
+	switch call.Peer.Transport {
+	case "http", "ws", "http2":
+	default:
+		return false
+	}
+	return !strings.HasPrefix(call.Method, EngineApi+"_")
+}
+
+// clientKey returns the identifier of the client a connection belongs to.
+func clientKey(peer PeerInfo) string {
+	if peer.Auth.Subject != "" {
+		return "sub:" + peer.Auth.Subject
+	}
+	host, _, err := net.SplitHostPort(peer.RemoteAddr)
+	if err != nil {
+		return "ip:" + peer.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// tokenBucket is a token bucket which may go into debt. A disabled bucket (with a
+// zero rate) admits every call.
+type tokenBucket struct {
+	rate, burst float64
+	tokens      float64
+	last        mclock.AbsTime
+}
+
+func newTokenBucket(rate, burst float64, now mclock.AbsTime) *tokenBucket {
+	if burst < rate {
+		burst = rate
+	}
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (b *tokenBucket) refill(now mclock.AbsTime) {
+	if b.rate <= 0 {
+		return
+	}
+	elapsed := time.Duration(now - b.last).Seconds()
+	b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	b.last = now
+}
+
+// full reports whether the bucket holds its full capacity.
+func (b *tokenBucket) full() bool {
+	return b.rate <= 0 || b.tokens >= b.burst
+}
+
+// wait returns how long it takes until a call of the given cost is admitted, or
+// zero if it can proceed now. Calls costing more than the capacity of the bucket
+// are admitted when the bucket is full.
+func (b *tokenBucket) wait(cost float64, now mclock.AbsTime) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	need := math.Min(cost, b.burst)
+	if b.tokens >= need {
+		return 0
+	}
+	wait := time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+	if wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+	return wait
+}
+
+// take removes tokens from the bucket.
+func (b *tokenBucket) take(cost float64) {
+	if b.rate > 0 {
+		b.tokens -= cost
+	}
+}
+
+// rateLimitError is returned for calls rejected by the RateLimiter. It uses the
+// "limit exceeded" code defined by EIP-1474.
+type rateLimitError struct {
+	scope      string
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) ErrorCode() int { return errcodeLimitExceeded }
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded (%s), retry after %v", e.scope, e.retryAfter.Round(time.Millisecond))
+}
+
+func (e *rateLimitError) ErrorData() interface{} {
+	return map[string]interface{}{
+		"scope":      e.scope,
+		"retryAfter": math.Ceil(e.retryAfter.Seconds()*1000) / 1000,
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestRateLimiterBuckets(t *testing.T) {
+	var (
+		clock   = new(mclock.Simulated)
+		limiter = newRateLimiter(RateLimitConfig{
+			ConnRate:    2,
+			ConnBurst:   4,
+			ClientRate:  3,
+			ClientBurst: 6,
+			MethodCosts: map[string]float64{"test_expensive": 10},
+			RuntimeCost: 1,
+		}, clock)
+		conn1 = PeerInfo{Transport: "http", RemoteAddr: "10.0.0.1:1000"}
+		conn2 = PeerInfo{Transport: "ws", RemoteAddr: "10.0.0.1:1001"}
+		other = PeerInfo{Transport: "ws", RemoteAddr: "10.0.0.2:1000"}
+	)
+	call := func(method string, peer PeerInfo) error {
+		return limiter.Admit(&CallInfo{Method: method, Peer: peer})
+	}
+	expectLimited := func(err error, scope string) {
+		t.Helper()
+		var limitErr *rateLimitError
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("expected rate limit error, got %v", err)
+		}
+		if limitErr.scope != scope {
+			t.Fatalf("wrong rate limit scope: have %s, want %s", limitErr.scope, scope)
+		}
+	}
+	// The connection bucket allows a burst of 4 calls.
+	for i := 0; i < 4; i++ {
+		if err := call("test_echo", conn1); err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+	}
+	expectLimited(call("test_echo", conn1), "connection")
+
+	// Another connection of the same client drains the client bucket.
+	if err := call("test_echo", conn2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := call("test_echo", conn2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectLimited(call("test_echo", conn2), "client")
+
+	// Other clients are unaffected, and local transports are never limited.
+	if err := call("test_echo", other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := call("test_echo", PeerInfo{Transport: "ipc"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Engine API calls are not limited either.
+	if err := call("engine_forkchoiceUpdatedV2", conn1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// After refilling, calls costing more than the burst are admitted, and the
+	// debt they create blocks further calls.
+	clock.Run(10 * time.Second)
+	if err := call("test_expensive", other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectLimited(call("test_echo", other), "connection")
+
+	// Runtime is charged after the call.
+	clock.Run(10 * time.Second)
+	limiter.Done(&CallInfo{Method: "test_echo", Peer: conn1}, 5*time.Second)
+	expectLimited(call("test_echo", conn1), "connection")
+	clock.Run(time.Second)
+	if err := call("test_echo", conn1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	var (
+		clock   = new(mclock.Simulated)
+		limiter = newRateLimiter(RateLimitConfig{ConnRate: 1, ClientRate: 1}, clock)
+		peer    = PeerInfo{Transport: "http", RemoteAddr: "10.0.0.1:1000"}
+	)
+	if err := limiter.Admit(&CallInfo{Method: "test_echo", Peer: peer}); err != nil {
+		t.Fatal(err)
+	}
+	clock.Run(2 * rateLimitSweepInterval)
+	limiter.Admit(&CallInfo{Method: "test_echo", Peer: PeerInfo{Transport: "http", RemoteAddr: "10.0.0.2:1000"}})
+	if _, ok := limiter.conns["http/10.0.0.1:1000"]; ok {
+		t.Fatal("idle connection bucket not dropped")
+	}
+	if _, ok := limiter.clients["ip:10.0.0.1"]; ok {
+		t.Fatal("idle client bucket not dropped")
+	}
+}
+
+func TestRateLimiterClientKey(t *testing.T) {
+	peer := PeerInfo{RemoteAddr: "10.0.0.1:1000"}
+	if key := clientKey(peer); key != "ip:10.0.0.1" {
+		t.Fatalf("wrong key %q", key)
+	}
+	peer.Auth.Subject = "analytics"
+	if key := clientKey(peer); key != "sub:analytics" {
+		t.Fatalf("wrong key %q", key)
+	}
+}
+
+func TestRateLimiterMetricClients(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{ConnRate: 1, ClientRate: 1})
+	for i := 0; i < rateLimitMaxClientMetrics; i++ {
+		peer := PeerInfo{RemoteAddr: fmt.Sprintf("10.0.0.%d:1000", i)}
+		if series := limiter.metricClient(peer, clientKey(peer)); series != clientKey(peer) {
+			t.Fatalf("client %d: wrong series %q", i, series)
+		}
+	}
+	// Further unauthenticated clients share a series, known and authenticated
+	// ones keep theirs.
+	peer := PeerInfo{RemoteAddr: "10.0.1.0:1000"}
+	if series := limiter.metricClient(peer, clientKey(peer)); series != rateLimitOtherClients {
+		t.Fatalf("wrong series %q for client over the limit", series)
+	}
+	peer = PeerInfo{RemoteAddr: "10.0.0.0:2000"}
+	if series := limiter.metricClient(peer, clientKey(peer)); series != "ip:10.0.0.0" {
+		t.Fatalf("wrong series %q for known client", series)
+	}
+	peer.Auth.Subject = "analytics"
+	if series := limiter.metricClient(peer, clientKey(peer)); series != "sub:analytics" {
+		t.Fatalf("wrong series %q for authenticated client", series)
+	}
+}
+
+func TestServerCallPolicyHTTP(t *testing.T) {
+	server := newTestServer()
+	server.SetCallPolicy(NewRateLimiter(RateLimitConfig{ConnRate: 0.001, ConnBurst: 2}))
+	defer server.Stop()
+
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result echoResult
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&result, "test_echo", "hello", 10, &echoArgs{"world"}); err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+	}
+	err = client.CallContext(context.Background(), &result, "test_echo", "hello", 10, &echoArgs{"world"})
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("expected limit exceeded error, got %v", err)
+	}
+	var dataErr DataError
+	if !errors.As(err, &dataErr) || dataErr.ErrorData() == nil {
+		t.Fatalf("expected retry information in error data, got %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"io"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -51,6 +52,8 @@ type Server struct {
 	run                atomic.Bool
 	batchItemLimit     int
 	batchResponseLimit int
+	callPolicy         CallPolicy
+	execTimeouts       ExecutionTimeouts
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -103,6 +106,8 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		callPolicy:         s.callPolicy,
+		execTimeouts:       s.execTimeouts,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -138,6 +143,8 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.policy = s.callPolicy
+	h.execTimeouts = s.execTimeouts
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -195,7 +202,7 @@ func (s *RPCService) Modules() map[string]string {
 // the current method call.
 type PeerInfo struct {
 	// Transport is name of the protocol used by the client.
-	// This can be "http", "ws" or "ipc".
+	// This can be "http", "ws", "http2" or "ipc".
 	Transport string
 
 	// Address of client. This will usually contain the IP address and port.
@@ -209,11 +216,46 @@ type PeerInfo struct {
 		UserAgent string
 		Origin    string
 		Host      string
+
+		// Timeout is the execution timeout requested by the client through
+		// the Rpc-Timeout header, if any.
+		Timeout time.Duration
+
+		// Traceparent is the W3C trace context of the client, which becomes
+		// the parent of the spans recorded for its calls.
+		Traceparent string
 	}
+
+	// Credentials the client authenticated with, if any.
+	Auth AuthInfo
+}
+
+// AuthInfo contains the credentials presented by an authenticated client.
+type AuthInfo struct {
+	// Subject is the 'sub' claim of the client's JWT token.
+	Subject string
+
+	// Claims holds all claims of the token, as decoded from JSON.
+	Claims map[string]interface{}
 }
 
 type peerInfoContextKey struct{}
 
+type authInfoContextKey struct{}
+
+// WithAuthInfo attaches client credentials to the context of an HTTP request. The
+// HTTP and WebSocket handlers of the server make them available as part of the
+// PeerInfo of the connection.
+func WithAuthInfo(ctx context.Context, info AuthInfo) context.Context {
+	return context.WithValue(ctx, authInfoContextKey{}, info)
+}
+
+// authInfoFromContext returns the client credentials attached to ctx.
+func authInfoFromContext(ctx context.Context) AuthInfo {
+	info, _ := ctx.Value(authInfoContextKey{}).(AuthInfo)
+	return info
+}
+
 // PeerInfoFromContext returns information about the client's network connection.
 // Use this with the context passed to RPC method handler functions.
 //
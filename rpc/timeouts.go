@@ -0,0 +1,82 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// requestTimeoutHeader is the HTTP header through which clients can request an
+// execution timeout for their calls, e.g. "Rpc-Timeout: 5s". On WebSocket and
+// HTTP/2 stream connections, the timeout applies to all calls of the connection.
+const requestTimeoutHeader = "Rpc-Timeout"
+
+// ExecutionTimeouts limits the execution time of method calls. When the timeout of a
+// call expires, the context passed to the method is canceled and the call fails with
+// a timeout error.
+type ExecutionTimeouts struct {
+	// Default applies to all methods not listed in Methods. Zero means no limit.
+	Default time.Duration            `toml:",omitempty"`
+	Methods map[string]time.Duration `toml:",omitempty"`
+
+	// Max is the longest timeout clients may request. If zero, clients can only
+	// shorten the configured timeout of a method.
+	Max time.Duration `toml:",omitempty"`
+}
+
+// SetExecutionTimeouts sets the execution time limits of method calls.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetExecutionTimeouts(timeouts ExecutionTimeouts) {
+	s.execTimeouts = timeouts
+}
+
+// timeout returns the execution timeout of a method call. The 'requested' timeout is
+// the one asked for by the client, or zero.
+func (t *ExecutionTimeouts) timeout(method string, requested time.Duration) time.Duration {
+	configured, ok := t.Methods[method]
+	if !ok {
+		configured = t.Default
+	}
+	if requested <= 0 {
+		return configured
+	}
+	limit := t.Max
+	if limit == 0 {
+		limit = configured
+	}
+	if limit > 0 && requested > limit {
+		return limit
+	}
+	return requested
+}
+
+// requestTimeout returns the execution timeout requested in the given HTTP headers.
+func requestTimeout(header http.Header) (time.Duration, error) {
+	value := header.Get(requestTimeoutHeader)
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s header %q", requestTimeoutHeader, value)
+	}
+	return timeout, nil
+}
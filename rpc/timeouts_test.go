@@ -0,0 +1,171 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecutionTimeoutsResolve(t *testing.T) {
+	timeouts := ExecutionTimeouts{
+		Default: 10 * time.Second,
+		Methods: map[string]time.Duration{"eth_getLogs": time.Minute, "eth_call": 0},
+	}
+	tests := []struct {
+		method    string
+		requested time.Duration
+		max       time.Duration
+		want      time.Duration
+	}{
+		{method: "eth_blockNumber", want: 10 * time.Second},
+		{method: "eth_getLogs", want: time.Minute},
+		{method: "eth_call", want: 0},
+		// Clients may shorten the timeout, but not extend it beyond the configured one.
+		{method: "eth_getLogs", requested: time.Second, want: time.Second},
+		{method: "eth_getLogs", requested: time.Hour, want: time.Minute},
+		{method: "eth_call", requested: time.Hour, want: time.Hour},
+		// ...unless a maximum is set.
+		{method: "eth_getLogs", requested: time.Hour, max: 2 * time.Minute, want: 2 * time.Minute},
+		{method: "eth_blockNumber", requested: 30 * time.Second, max: 2 * time.Minute, want: 30 * time.Second},
+	}
+	for i, test := range tests {
+		timeouts.Max = test.max
+		if have := timeouts.timeout(test.method, test.requested); have != test.want {
+			t.Errorf("test %d: wrong timeout for %s: have %v, want %v", i, test.method, have, test.want)
+		}
+	}
+}
+
+func TestServerExecutionTimeout(t *testing.T) {
+	server := newTestServer()
+	server.SetExecutionTimeouts(ExecutionTimeouts{Methods: map[string]time.Duration{"test_block": 100 * time.Millisecond}})
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	err := client.Call(nil, "test_block")
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeTimeout {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "execution timeout exceeded") {
+		t.Fatalf("wrong error message: %v", err)
+	}
+	// Other methods are not limited.
+	if err := client.Call(nil, "test_sleep", 200*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPRequestTimeoutHeader(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithHeader(requestTimeoutHeader, "100ms"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	err = client.Call(nil, "test_block")
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeTimeout {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+
+	// Invalid values are rejected.
+	client, err = DialOptions(context.Background(), httpsrv.URL, WithHeader(requestTimeoutHeader, "soon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var httpErr HTTPError
+	if err := client.Call(nil, "test_echo", "x", 1); !errors.As(err, &httpErr) || httpErr.StatusCode != 400 {
+		t.Fatalf("expected HTTP 400 error, got %v", err)
+	}
+}
+
+type cancelTestService struct {
+	started  chan struct{}
+	canceled chan struct{}
+}
+
+func (s *cancelTestService) Wait(ctx context.Context) error {
+	close(s.started)
+	<-ctx.Done()
+	close(s.canceled)
+	return ctx.Err()
+}
+
+// This test checks that calls are canceled when the client goes away.
+func TestServerClientDisconnect(t *testing.T) {
+	for _, transport := range []string{"http", "ws", "http2"} {
+		t.Run(transport, func(t *testing.T) {
+			var (
+				server  = newTestServer()
+				service = &cancelTestService{started: make(chan struct{}), canceled: make(chan struct{})}
+				httpsrv *httptest.Server
+				client  *Client
+				err     error
+			)
+			if err := server.RegisterName("cancel", service); err != nil {
+				t.Fatal(err)
+			}
+			defer server.Stop()
+
+			switch transport {
+			case "http":
+				httpsrv = httptest.NewServer(server)
+				client, err = DialHTTP(httpsrv.URL)
+			case "ws":
+				httpsrv = httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+				client, err = DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(httpsrv.URL, "http:"), "")
+			case "http2":
+				httpsrv = newHTTP2TestServer(server)
+				client, err = DialHTTP2(context.Background(), httpsrv.URL)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer httpsrv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			errc := make(chan error, 1)
+			go func() { errc <- client.CallContext(ctx, nil, "cancel_wait") }()
+			<-service.started
+
+			// Abandon the call. HTTP requests are aborted by canceling the context,
+			// for the other transports the connection is closed.
+			cancel()
+			if transport != "http" {
+				client.Close()
+			}
+			<-errc
+			select {
+			case <-service.canceled:
+			case <-time.After(5 * time.Second):
+				t.Fatal("method context not canceled after client disconnect")
+			}
+		})
+	}
+}